@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -162,6 +163,7 @@ const (
 	// Radio extended errors.
 	ErrReadQuorum
 	ErrWriteQuorum
+	ErrQuorumNotMet
 	ErrParentIsObject
 	ErrStorageFull
 	ErrRequestBodyParse
@@ -175,6 +177,10 @@ const (
 	// Radio storage class error codes
 	ErrInvalidStorageClass
 	ErrBackendDown
+	ErrReplicaConflict
+	ErrObjectQuarantined
+	ErrInvalidReplica
+	ErrMultipleRanges
 	// Add new extended error codes here.
 	// Please open a https://github.com/minio/minio/issues before adding
 	// new error codes here.
@@ -307,6 +313,26 @@ var errorCodes = errorCodeMap{
 		Description:    "Invalid storage class.",
 		HTTPStatusCode: http.StatusBadRequest,
 	},
+	ErrReplicaConflict: {
+		Code:           "ReplicaConflict",
+		Description:    "The replicas of the object hold different versions.",
+		HTTPStatusCode: http.StatusConflict,
+	},
+	ErrObjectQuarantined: {
+		Code:           "XRadioObjectQuarantined",
+		Description:    "The replicas of the object hold different versions and their heal was given up.",
+		HTTPStatusCode: http.StatusConflict,
+	},
+	ErrMultipleRanges: {
+		Code:           "NotImplemented",
+		Description:    "Multiple byte ranges in a single request are not supported",
+		HTTPStatusCode: http.StatusNotImplemented,
+	},
+	ErrInvalidReplica: {
+		Code:           "InvalidArgument",
+		Description:    "The requested replica does not exist.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
 	ErrInvalidRequestBody: {
 		Code:           "InvalidArgument",
 		Description:    "Body shouldn't be set for this request.",
@@ -767,6 +793,11 @@ var errorCodes = errorCodeMap{
 		Description:    "Your metadata headers exceed the maximum allowed metadata size.",
 		HTTPStatusCode: http.StatusBadRequest,
 	},
+	ErrSSEEncryptedObject: {
+		Code:           "InvalidRequest",
+		Description:    "The object was stored using a form of Server Side Encryption. The correct parameters must be provided to retrieve the object.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
 	ErrInvalidSSECustomerAlgorithm: {
 		Code:           "InvalidArgument",
 		Description:    "Requests specifying Server Side Encryption with Customer provided keys must provide a valid encryption algorithm.",
@@ -836,6 +867,11 @@ var errorCodes = errorCodeMap{
 		Description:    "Object name already exists as a directory.",
 		HTTPStatusCode: http.StatusConflict,
 	},
+	ErrQuorumNotMet: {
+		Code:           "XRadioQuorumNotMet",
+		Description:    "Not enough replicas succeeded the write operation.",
+		HTTPStatusCode: http.StatusServiceUnavailable,
+	},
 	ErrUnsupportedMetadata: {
 		Code:           "InvalidArgument",
 		Description:    "Your metadata headers are not supported.",
@@ -1299,6 +1335,10 @@ func toAPIErrorCode(ctx context.Context, err error) (apiErr APIErrorCode) {
 		apiErr = ErrSignatureDoesNotMatch
 	case errInvalidRange:
 		apiErr = ErrInvalidRange
+	case errMultipleRanges:
+		apiErr = ErrMultipleRanges
+	case errMissingContentMD5:
+		apiErr = ErrMissingContentMD5
 	case errDataTooLarge:
 		apiErr = ErrEntityTooLarge
 	case errDataTooSmall:
@@ -1318,6 +1358,8 @@ func toAPIErrorCode(ctx context.Context, err error) (apiErr APIErrorCode) {
 		apiErr = ErrSSECustomerKeyMD5Mismatch
 	case ErrInvalidCustomerKey, ErrSecretKeyMismatch:
 		apiErr = ErrAccessDenied // no access without correct key
+	case errEncryptedObject:
+		apiErr = ErrSSEEncryptedObject
 	case errOperationTimedOut, context.Canceled, context.DeadlineExceeded:
 		apiErr = ErrOperationTimedOut
 	}
@@ -1356,6 +1398,8 @@ func toAPIErrorCode(ctx context.Context, err error) (apiErr APIErrorCode) {
 		apiErr = ErrBucketAlreadyOwnedByYou
 	case ObjectNotFound:
 		apiErr = ErrNoSuchKey
+	case ObjectArchived:
+		apiErr = ErrInvalidObjectState
 	case ObjectAlreadyExists:
 		apiErr = ErrMethodNotAllowed
 	case ObjectNameInvalid:
@@ -1368,6 +1412,8 @@ func toAPIErrorCode(ctx context.Context, err error) (apiErr APIErrorCode) {
 		apiErr = ErrInvalidPart
 	case InsufficientWriteQuorum:
 		apiErr = ErrSlowDown
+	case QuorumNotMet:
+		apiErr = ErrQuorumNotMet
 	case InsufficientReadQuorum:
 		apiErr = ErrSlowDown
 	case UnsupportedDelimiter:
@@ -1390,6 +1436,10 @@ func toAPIErrorCode(ctx context.Context, err error) (apiErr APIErrorCode) {
 		apiErr = ErrEntityTooSmall
 	case NotImplemented:
 		apiErr = ErrNotImplemented
+	case OperationNotAllowed:
+		apiErr = ErrMethodNotAllowed
+	case InvalidStorageClass:
+		apiErr = ErrInvalidStorageClass
 	case PartTooBig:
 		apiErr = ErrEntityTooLarge
 	case UnsupportedMetadata:
@@ -1400,6 +1450,16 @@ func toAPIErrorCode(ctx context.Context, err error) (apiErr APIErrorCode) {
 		apiErr = ErrNoSuchBucketLifecycle
 	case BackendDown:
 		apiErr = ErrBackendDown
+	case BackendThrottled, SlowDown:
+		apiErr = ErrSlowDown
+	case PreConditionFailed:
+		apiErr = ErrPreconditionFailed
+	case ReplicaConflict:
+		apiErr = ErrReplicaConflict
+	case ObjectQuarantined:
+		apiErr = ErrObjectQuarantined
+	case InvalidReplica:
+		apiErr = ErrInvalidReplica
 	case ObjectNameTooLong:
 		apiErr = ErrKeyTooLongError
 	default:
@@ -1437,7 +1497,17 @@ func toAPIError(ctx context.Context, err error) APIError {
 		return noError
 	}
 
+	// Errors of replicas are reported as the remote error they wrap.
+	var rerr replicaError
+	if errors.As(err, &rerr) {
+		err = rerr.Err
+	}
+
 	var apiErr = errorCodes.ToAPIErr(toAPIErrorCode(ctx, err))
+	if e, ok := err.(QuorumNotMet); ok {
+		// Report how many replicas succeeded.
+		apiErr.Description = e.Error()
+	}
 	if apiErr.Code == "InternalError" {
 		// If we see an internal error try to interpret
 		// any underlying errors if possible depending on
@@ -49,6 +49,10 @@ func setObjectHeaders(w http.ResponseWriter, objInfo ObjectInfo, rs *HTTPRangeSp
 	lastModified := objInfo.ModTime.UTC().Format(http.TimeFormat)
 	w.Header().Set(xhttp.LastModified, lastModified)
 
+	if objInfo.Stale {
+		w.Header().Set(xhttp.RadioStale, "true")
+	}
+
 	// Set Etag if available.
 	if objInfo.ETag != "" {
 		w.Header()[xhttp.ETag] = []string{"\"" + objInfo.ETag + "\""}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	minio "github.com/minio/minio-go/v6"
+	xhttp "github.com/minio/radio/cmd/http"
+)
+
+// Tests that caching directives of a remote object are returned on
+// full and partial content responses.
+func TestSetObjectHeadersCaching(t *testing.T) {
+	expires := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+	oi := minio.ObjectInfo{
+		Key:          "object",
+		Size:         100,
+		ETag:         "\"5d41402abc4b2a76b9719d911017c592\"",
+		LastModified: time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
+		ContentType:  "text/plain",
+		Expires:      expires,
+		Metadata: http.Header{
+			"Cache-Control":       []string{"max-age=3600"},
+			"Content-Disposition": []string{"attachment; filename=\"object.txt\""},
+			"Content-Language":    []string{"en"},
+		},
+	}
+
+	testCases := []struct {
+		rs            *HTTPRangeSpec
+		contentLength string
+		contentRange  string
+	}{
+		{nil, "100", ""},
+		{&HTTPRangeSpec{Start: 10, End: 19}, "10", "bytes 10-19/100"},
+		{&HTTPRangeSpec{IsSuffixLength: true, Start: -5}, "5", "bytes 95-99/100"},
+	}
+
+	for i, testCase := range testCases {
+		w := httptest.NewRecorder()
+		if err := setObjectHeaders(w, FromMinioClientObjectInfo("bucket", oi, 0), testCase.rs); err != nil {
+			t.Fatalf("Test %d: Unexpected error %s", i+1, err)
+		}
+		expected := map[string]string{
+			xhttp.CacheControl:       "max-age=3600",
+			xhttp.ContentDisposition: "attachment; filename=\"object.txt\"",
+			xhttp.ContentLanguage:    "en",
+			xhttp.Expires:            expires.Format(http.TimeFormat),
+			xhttp.ContentType:        "text/plain",
+			xhttp.ContentLength:      testCase.contentLength,
+			xhttp.ContentRange:       testCase.contentRange,
+		}
+		for k, v := range expected {
+			if got := w.Header().Get(k); got != v {
+				t.Errorf("Test %d: Expected %s %q, got %q", i+1, k, v, got)
+			}
+		}
+	}
+}
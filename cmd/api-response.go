@@ -63,6 +63,7 @@ type ListVersionsResponse struct {
 
 	CommonPrefixes []CommonPrefix
 	Versions       []ObjectVersion
+	DeleteMarkers  []DeleteMarkerVersion
 
 	// Encoding type used to encode object keys in the response.
 	EncodingType string `xml:"EncodingType,omitempty"`
@@ -223,6 +224,16 @@ type ObjectVersion struct {
 	IsLatest  bool
 }
 
+// DeleteMarkerVersion container for delete marker metadata
+type DeleteMarkerVersion struct {
+	XMLName      xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ DeleteMarker" json:"-"`
+	Key          string
+	LastModified string // time string of format "2006-01-02T15:04:05.000Z"
+	Owner        Owner
+	VersionID    string `xml:"VersionId"`
+	IsLatest     bool
+}
+
 // StringMap is a map[string]string.
 type StringMap map[string]string
 
@@ -395,18 +406,33 @@ func generateListBucketsResponse(buckets []BucketInfo) ListBucketsResponse {
 }
 
 // generates an ListBucketVersions response for the said bucket with other enumerated options.
-func generateListVersionsResponse(bucket, prefix, marker, delimiter, encodingType string, maxKeys int, resp ListObjectsInfo) ListVersionsResponse {
+func generateListVersionsResponse(bucket, prefix, marker, versionIDMarker, delimiter, encodingType string, maxKeys int, resp ListObjectVersionsInfo) ListVersionsResponse {
 	var versions []ObjectVersion
+	var deleteMarkers []DeleteMarkerVersion
 	var prefixes []CommonPrefix
 	var owner = Owner{}
 	var data = ListVersionsResponse{}
 
 	owner.ID = globalRadioDefaultOwnerID
 	for _, object := range resp.Objects {
-		var content = ObjectVersion{}
 		if object.Name == "" {
 			continue
 		}
+		versionID := object.VersionID
+		if versionID == "" {
+			versionID = "null"
+		}
+		if object.DeleteMarker {
+			deleteMarkers = append(deleteMarkers, DeleteMarkerVersion{
+				Key:          s3EncodeName(object.Name, encodingType),
+				LastModified: object.ModTime.UTC().Format(timeFormatAMZLong),
+				Owner:        owner,
+				VersionID:    versionID,
+				IsLatest:     object.IsLatest,
+			})
+			continue
+		}
+		var content = ObjectVersion{}
 		content.Key = s3EncodeName(object.Name, encodingType)
 		content.LastModified = object.ModTime.UTC().Format(timeFormatAMZLong)
 		if object.ETag != "" {
@@ -415,20 +441,23 @@ func generateListVersionsResponse(bucket, prefix, marker, delimiter, encodingTyp
 		content.Size = object.Size
 		content.StorageClass = object.StorageClass
 		content.Owner = owner
-		content.VersionID = "null"
-		content.IsLatest = true
+		content.VersionID = versionID
+		content.IsLatest = object.IsLatest
 		versions = append(versions, content)
 	}
 	data.Name = bucket
 	data.Versions = versions
+	data.DeleteMarkers = deleteMarkers
 
 	data.EncodingType = encodingType
 	data.Prefix = s3EncodeName(prefix, encodingType)
 	data.KeyMarker = s3EncodeName(marker, encodingType)
+	data.VersionIDMarker = versionIDMarker
 	data.Delimiter = s3EncodeName(delimiter, encodingType)
 	data.MaxKeys = maxKeys
 
 	data.NextKeyMarker = s3EncodeName(resp.NextMarker, encodingType)
+	data.NextVersionIDMarker = resp.NextVersionIDMarker
 	data.IsTruncated = resp.IsTruncated
 
 	for _, prefix := range resp.Prefixes {
@@ -664,6 +693,10 @@ const (
 	mimeNone mimeType = ""
 	// Means response type is XML.
 	mimeXML mimeType = "application/xml"
+	// Means response type is JSON.
+	mimeJSON mimeType = "application/json"
+	// Means response type is newline delimited JSON.
+	mimeNDJSON mimeType = "application/x-ndjson"
 )
 
 // writeSuccessResponseXML writes success headers and response if any,
@@ -689,12 +722,7 @@ func writeSuccessResponseHeadersOnly(w http.ResponseWriter) {
 
 // writeErrorRespone writes error headers
 func writeErrorResponse(ctx context.Context, w http.ResponseWriter, err APIError, reqURL *url.URL) {
-	switch err.Code {
-	case "SlowDown", "XMinioServerNotInitialized", "XMinioReadQuorum", "XMinioWriteQuorum":
-		// Set retry-after header to indicate user-agents to retry request after 120secs.
-		// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Retry-After
-		w.Header().Set(xhttp.RetryAfter, "120")
-	}
+	setRetryAfter(ctx, w, err.Code)
 
 	// Generate error response.
 	errorResponse := getAPIErrorResponse(ctx, err, reqURL.Path,
@@ -716,12 +744,7 @@ func writeErrorResponseString(ctx context.Context, w http.ResponseWriter, err AP
 // but accepts the error message directly (this allows messages to be
 // dynamically generated.)
 func writeCustomErrorResponseXML(ctx context.Context, w http.ResponseWriter, err APIError, errBody string, reqURL *url.URL) {
-	switch err.Code {
-	case "SlowDown", "XMinioServerNotInitialized", "XMinioReadQuorum", "XMinioWriteQuorum":
-		// Set retry-after header to indicate user-agents to retry request after 120secs.
-		// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Retry-After
-		w.Header().Set(xhttp.RetryAfter, "120")
-	}
+	setRetryAfter(ctx, w, err.Code)
 
 	reqInfo := logger.GetReqInfo(ctx)
 	errorResponse := APIErrorResponse{
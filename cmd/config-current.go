@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/minio/radio/cmd/config"
 	"github.com/minio/radio/cmd/config/cache"
@@ -27,6 +29,17 @@ func lookupConfigEnv(rconfig radioConfig) (err error) {
 		globalCacheConfig.Enabled = len(rconfig.Cache.Drives) > 0
 	}
 
+	if rconfig.Debug.Pprof && rconfig.Debug.Address == "" {
+		return fmt.Errorf("Invalid debug configuration: pprof requires an address")
+	}
+
+	if rconfig.RadioTag != "" {
+		if !strings.HasPrefix(strings.ToLower(rconfig.RadioTag), "x-amz-meta-") {
+			return fmt.Errorf("Invalid radio tag %q: must be an x-amz-meta- metadata key", rconfig.RadioTag)
+		}
+		globalRadioTagKey = http.CanonicalHeaderKey(rconfig.RadioTag)
+	}
+
 	// Enable console logging
 	logger.AddTarget(globalConsoleSys.Console())
 
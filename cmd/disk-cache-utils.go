@@ -135,6 +135,13 @@ func cacheControlOpts(o ObjectInfo) (c cacheControl) {
 	return c
 }
 
+// isCacheFresh returns true if the cached object is the same version
+// as the backend object, going by ETag and radio tag.
+func isCacheFresh(cached, backend ObjectInfo) bool {
+	return cached.ETag == backend.ETag &&
+		cached.UserDefined[globalRadioTagKey] == backend.UserDefined[globalRadioTagKey]
+}
+
 // backendDownError returns true if err is due to backend failure or faulty disk if in server mode
 func backendDownError(err error) bool {
 	_, backendDown := err.(BackendDown)
@@ -40,6 +40,9 @@ type CacheObjectLayer interface {
 	DeleteObject(ctx context.Context, bucket, object string) error
 	DeleteObjects(ctx context.Context, bucket string, objects []string) ([]error, error)
 	PutObject(ctx context.Context, bucket, object string, data *PutObjReader, opts ObjectOptions) (objInfo ObjectInfo, err error)
+	// InvalidateObject drops the cached object after a backend write
+	// bypassing the cache.
+	InvalidateObject(ctx context.Context, bucket, object string)
 	// Storage operations.
 	StorageInfo(ctx context.Context) CacheStorageInfo
 	CacheStats() *CacheStats
@@ -129,6 +132,16 @@ func (c *cacheObjects) DeleteObject(ctx context.Context, bucket, object string)
 	return
 }
 
+// InvalidateObject clears the cache entry of an object overwritten on
+// the backend.
+func (c *cacheObjects) InvalidateObject(ctx context.Context, bucket, object string) {
+	dcache, err := c.getCacheToLoc(ctx, bucket, object)
+	if err != nil {
+		return
+	}
+	c.delete(ctx, dcache, bucket, object)
+}
+
 // DeleteObjects batch deletes objects in slice, and clears any cached entries
 func (c *cacheObjects) DeleteObjects(ctx context.Context, bucket string, objects []string) ([]error, error) {
 	errs := make([]error, len(objects))
@@ -202,8 +215,8 @@ func (c *cacheObjects) GetObjectNInfo(ctx context.Context, bucket, object string
 	}
 
 	if cacheErr == nil {
-		// if ETag matches for stale cache entry, serve from cache
-		if cacheReader.ObjInfo.ETag == objInfo.ETag {
+		// if ETag and radio tag match for stale cache entry, serve from cache
+		if isCacheFresh(cacheReader.ObjInfo, objInfo) {
 			// Update metadata in case server-side copy might have changed object metadata
 			dcache.updateMetadataIfChanged(ctx, bucket, object, objInfo, cacheReader.ObjInfo)
 			return cacheReader, nil
@@ -235,7 +248,7 @@ func (c *cacheObjects) GetObjectNInfo(ctx context.Context, bucket, object string
 			defer bReader.Close()
 			oi, err := c.stat(ctx, dcache, bucket, object)
 			// avoid cache overwrite if another background routine filled cache
-			if err != nil || oi.ETag != bReader.ObjInfo.ETag {
+			if err != nil || !isCacheFresh(oi, bReader.ObjInfo) {
 				c.put(ctx, dcache, bucket, object, bReader, bReader.ObjInfo.Size, ObjectOptions{UserDefined: getMetadata(bReader.ObjInfo)})
 			}
 		}()
@@ -308,7 +321,7 @@ func (c *cacheObjects) GetObjectInfo(ctx context.Context, bucket, object string,
 	if cerr != nil {
 		return objInfo, nil
 	}
-	if cachedObjInfo.ETag != objInfo.ETag {
+	if !isCacheFresh(cachedObjInfo, objInfo) {
 		// Delete the cached entry if the backend object was replaced.
 		c.delete(ctx, dcache, bucket, object)
 	}
@@ -564,7 +577,7 @@ func (c *cacheObjects) PutObject(ctx context.Context, bucket, object string, r *
 			defer bReader.Close()
 			oi, err := c.stat(ctx, dcache, bucket, object)
 			// avoid cache overwrite if another background routine filled cache
-			if err != nil || oi.ETag != bReader.ObjInfo.ETag {
+			if err != nil || !isCacheFresh(oi, bReader.ObjInfo) {
 				c.put(ctx, dcache, bucket, object, bReader, bReader.ObjInfo.Size, ObjectOptions{UserDefined: getMetadata(bReader.ObjInfo)})
 			}
 		}()
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// Tests that cached objects are dropped once the backend object is
+// replaced, going by ETag and radio tag, or written bypassing the cache.
+func TestCacheInvalidate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "radio-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dcache, err := newDiskCache(dir, 90, 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var backend ObjectInfo
+	c := &cacheObjects{
+		cache:      []*diskCache{dcache},
+		nsMutex:    newNSLock(false),
+		cacheStats: newCacheStats(),
+		GetObjectInfoFn: func(ctx context.Context, bucket, object string, opts ObjectOptions) (ObjectInfo, error) {
+			return backend, nil
+		},
+	}
+	c.NewNSLockFn = func(ctx context.Context, bucket, object string) RWLocker {
+		return c.nsMutex.NewNSLock(ctx, nil, bucket, object)
+	}
+
+	testCases := []struct {
+		backend    ObjectInfo
+		invalidate bool
+		cached     bool
+	}{
+		// Same version.
+		{ObjectInfo{ETag: "etag", UserDefined: map[string]string{globalRadioTagKey: "v1"}}, false, true},
+		// Overwritten with the same content.
+		{ObjectInfo{ETag: "etag", UserDefined: map[string]string{globalRadioTagKey: "v2"}}, false, false},
+		// Overwritten with other content.
+		{ObjectInfo{ETag: "other", UserDefined: map[string]string{globalRadioTagKey: "v1"}}, false, false},
+		// Written bypassing the cache.
+		{ObjectInfo{}, true, false},
+	}
+	ctx := context.Background()
+	for i, testCase := range testCases {
+		data := []byte("data")
+		// Cached entries are revalidated against the backend.
+		err = c.put(ctx, dcache, "bucket", "object", bytes.NewReader(data), int64(len(data)), ObjectOptions{
+			UserDefined: map[string]string{"etag": "etag", globalRadioTagKey: "v1", "Cache-Control": "no-cache"},
+		})
+		if err != nil {
+			t.Fatalf("Test %d: unexpected error: %v", i+1, err)
+		}
+		if testCase.invalidate {
+			c.InvalidateObject(ctx, "bucket", "object")
+		} else {
+			backend = testCase.backend
+			if _, err = c.GetObjectInfo(ctx, "bucket", "object", ObjectOptions{}); err != nil {
+				t.Fatalf("Test %d: unexpected error: %v", i+1, err)
+			}
+		}
+		if _, err = c.stat(ctx, dcache, "bucket", "object"); (err == nil) != testCase.cached {
+			t.Errorf("Test %d: expected cached %v, got error %v", i+1, testCase.cached, err)
+		}
+	}
+}
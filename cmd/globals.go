@@ -33,6 +33,10 @@ const (
 	globalRadioDefaultStorageClass = "STANDARD"
 	globalWindowsOSName            = "windows"
 
+	// Default metadata key of the tag identifying object versions
+	// across replicas.
+	globalRadioDefaultTagKey = "X-Amz-Meta-Radio-Tag"
+
 	// Add new global values here.
 )
 
@@ -60,6 +64,9 @@ var (
 	// This flag is set to 'us-east-1' by default
 	globalServerRegion = globalRadioDefaultRegion
 
+	// Metadata key of the radio tag, can be changed through configuration.
+	globalRadioTagKey = globalRadioDefaultTagKey
+
 	// MinIO default port, can be changed through command line.
 	globalRadioPort = globalRadioDefaultPort
 	// Holds the host that was passed using --address
@@ -68,6 +75,18 @@ var (
 	// CA root certificates, a nil value means system certs pool will be used
 	globalRootCAs *x509.CertPool
 
+	// TLS versions and cipher suites of the server and of the
+	// connections to remotes and peers.
+	globalTLSPolicy = defaultTLSPolicy
+
+	// Addresses of remote hosts, a nil value leaves caching to the
+	// system resolver.
+	globalDNSCache *dnsCache
+
+	// Limits of the client operations in flight, a nil value leaves
+	// them unlimited.
+	globalAdmission *admissionControl
+
 	// IsSSL indicates if the server is configured with SSL.
 	globalIsSSL bool
 
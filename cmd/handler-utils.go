@@ -93,6 +93,13 @@ func extractMetadata(ctx context.Context, r *http.Request) (metadata map[string]
 		return nil, err
 	}
 
+	// Extract the ACL to set on the object.
+	extractACLHeaders(header, metadata)
+
+	if err = checkStorageClass(metadata); err != nil {
+		return nil, err
+	}
+
 	// Set content-type to default value if it is not set.
 	if _, ok := metadata["content-type"]; !ok {
 		metadata["content-type"] = "application/octet-stream"
@@ -34,6 +34,16 @@ const (
 	// S3 storage class
 	AmzStorageClass = "x-amz-storage-class"
 
+	// S3 restore status of archived objects
+	AmzRestore = "x-amz-restore"
+
+	// S3 object ACL
+	AmzACL              = "X-Amz-Acl"
+	AmzGrantRead        = "X-Amz-Grant-Read"
+	AmzGrantReadACP     = "X-Amz-Grant-Read-Acp"
+	AmzGrantWriteACP    = "X-Amz-Grant-Write-Acp"
+	AmzGrantFullControl = "X-Amz-Grant-Full-Control"
+
 	// S3 extensions
 	AmzCopySourceIfModifiedSince   = "x-amz-copy-source-if-modified-since"
 	AmzCopySourceIfUnmodifiedSince = "x-amz-copy-source-if-unmodified-since"
@@ -73,4 +83,20 @@ const (
 
 	// Server-Status
 	MinIOServerStatus = "x-minio-server-status"
+
+	// Append the request body to the existing object.
+	RadioAppend = "x-radio-append"
+
+	// Replicas skipped by an incomplete merged listing.
+	RadioListingIncomplete = "x-radio-listing-incomplete"
+
+	// Replica index a diagnostic read is served from.
+	RadioReplica = "x-radio-replica"
+
+	// Region of the client, matched against the read affinity rules.
+	RadioRegion = "x-radio-region"
+
+	// Set on object info served from the recent writes of radio while
+	// all replicas are unreachable.
+	RadioStale = "x-radio-stale"
 )
@@ -35,6 +35,10 @@ func (h *HTTPRangeSpec) GetLength(resourceSize int64) (rangeLength int64, err er
 	case h == nil:
 		rangeLength = resourceSize
 
+	case h.IsSuffixLength && resourceSize == 0:
+		// No suffix of an empty resource is satisfiable.
+		return 0, errInvalidRange
+
 	case h.IsSuffixLength:
 		specifiedLen := -h.Start
 		rangeLength = specifiedLen
@@ -95,6 +99,11 @@ func parseRequestRangeSpec(rangeString string) (hrange *HTTPRangeSpec, err error
 	// Trim byte range prefix.
 	byteRangeString := strings.TrimPrefix(rangeString, byteRangePrefix)
 
+	// Like S3, serve a single range per request. eg. "bytes=0-9,20-29"
+	if strings.Contains(byteRangeString, ",") {
+		return nil, errMultipleRanges
+	}
+
 	// Check if range string contains delimiter '-', else return error. eg. "bytes=8"
 	sepIndex := strings.Index(byteRangeString, "-")
 	if sepIndex == -1 {
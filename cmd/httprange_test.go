@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"testing"
+)
+
+// Tests that single ranges, including open ended and suffix ranges, are
+// resolved against the resource size and that multiple ranges are
+// rejected.
+func TestHTTPRequestRangeSpec(t *testing.T) {
+	resourceSize := int64(10)
+	testCases := []struct {
+		spec           string
+		expectedErr    error
+		expectedStart  int64
+		expectedLength int64
+	}{
+		{"bytes=0-0", nil, 0, 1},
+		{"bytes=1-9", nil, 1, 9},
+		{"bytes=2-100", nil, 2, 8},
+		// Open ended ranges.
+		{"bytes=0-", nil, 0, 10},
+		{"bytes=9-", nil, 9, 1},
+		{"bytes=10-", errInvalidRange, 0, 0},
+		// Suffix ranges.
+		{"bytes=-1", nil, 9, 1},
+		{"bytes=-4", nil, 6, 4},
+		{"bytes=-100", nil, 0, 10},
+		{"bytes=-0", errInvalidRange, 0, 0},
+		{"bytes=5-2", errInvalidRange, 0, 0},
+		// Multiple ranges.
+		{"bytes=0-1,5-6", errMultipleRanges, 0, 0},
+		{"bytes=-1,0-", errMultipleRanges, 0, 0},
+	}
+
+	for i, testCase := range testCases {
+		rs, err := parseRequestRangeSpec(testCase.spec)
+		if err == nil {
+			var start, length int64
+			start, length, err = rs.GetOffsetLength(resourceSize)
+			if err == nil && (start != testCase.expectedStart || length != testCase.expectedLength) {
+				t.Errorf("Test %d: %s: expected offset %d and length %d, got %d and %d", i+1, testCase.spec,
+					testCase.expectedStart, testCase.expectedLength, start, length)
+			}
+		}
+		if err != testCase.expectedErr {
+			t.Errorf("Test %d: %s: expected error %v, got %v", i+1, testCase.spec, testCase.expectedErr, err)
+		}
+	}
+
+	// No range of an empty resource is satisfiable.
+	for _, spec := range []string{"bytes=0-", "bytes=-1"} {
+		rs, err := parseRequestRangeSpec(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err = rs.GetOffsetLength(0); err != errInvalidRange {
+			t.Errorf("%s: expected an invalid range of an empty resource, got %v", spec, err)
+		}
+	}
+
+	for _, spec := range []string{"bytes=", "bytes=-", "bytes=a-b", "items=0-1"} {
+		if _, err := parseRequestRangeSpec(spec); err == nil {
+			t.Errorf("%s: expected a parse error", spec)
+		}
+	}
+}
@@ -149,6 +149,7 @@ func newlockRESTClient(endpoint Endpoint) *lockRESTClient {
 			RootCAs:    globalRootCAs,
 			NextProtos: []string{"http/1.1"}, // Force http1.1
 		}
+		globalTLSPolicy.apply(tlsConfig)
 	}
 
 	trFn := newCustomHTTPTransport(tlsConfig, rest.DefaultRESTTimeout, rest.DefaultRESTTimeout)
@@ -54,11 +54,16 @@ type fatalMsg struct {
 }
 
 func (f fatalMsg) json(msg string, args ...interface{}) {
-	logJSON, err := json.Marshal(&log.Entry{
+	entry := log.Entry{
 		Level: FatalLvl.String(),
 		Time:  time.Now().UTC().Format(time.RFC3339Nano),
 		Trace: &log.Trace{Message: fmt.Sprintf(msg, args...), Source: []string{getSource(6)}},
-	})
+	}
+	if structuredFlag {
+		printRecord(entry.Record())
+		os.Exit(1)
+	}
+	logJSON, err := json.Marshal(&entry)
 	if err != nil {
 		panic(err)
 	}
@@ -126,11 +131,17 @@ type infoMsg struct{}
 var info infoMsg
 
 func (i infoMsg) json(msg string, args ...interface{}) {
-	logJSON, err := json.Marshal(&log.Entry{
+	entry := log.Entry{
 		Level:   InformationLvl.String(),
 		Message: fmt.Sprintf(msg, args...),
 		Time:    time.Now().UTC().Format(time.RFC3339Nano),
-	})
+	}
+	if structuredFlag {
+		entry.Message = strings.TrimSuffix(entry.Message, "\n")
+		printRecord(entry.Record())
+		return
+	}
+	logJSON, err := json.Marshal(&entry)
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/minio/radio/cmd/logger/message/log"
+)
+
+// Log formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// structuredFlag outputs all logs as flat JSON records, see log.Record.
+var structuredFlag bool
+
+// SetFormat sets the format of all log output, either FormatText or
+// FormatJSON. Empty keeps the current format.
+func SetFormat(format string) error {
+	switch format {
+	case "", FormatText:
+	case FormatJSON:
+		structuredFlag = true
+		jsonFlag = true
+	default:
+		return fmt.Errorf("unknown log format %q, expected %q or %q", format, FormatText, FormatJSON)
+	}
+	return nil
+}
+
+// IsStructured returns true if logs are output as flat JSON records.
+func IsStructured() bool {
+	return structuredFlag
+}
+
+// Field keys set by callers outside of requests, for their entries to
+// be logged with the operation and object they relate to.
+const (
+	FieldOp     = "op"
+	FieldBucket = "bucket"
+	FieldObject = "object"
+)
+
+const contextFieldsKey = contextKeyType("fields")
+
+// WithFields returns ctx with the given key and value pairs added to
+// the fields logged with the entries logged under ctx, replacing the
+// fields of the same keys.
+func WithFields(ctx context.Context, keyvals ...string) context.Context {
+	fields := make(map[string]string)
+	for k, v := range contextFields(ctx) {
+		fields[k] = v
+	}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		fields[keyvals[i]] = keyvals[i+1]
+	}
+	return context.WithValue(ctx, contextFieldsKey, fields)
+}
+
+// contextFields returns the fields of ctx, never to be modified.
+func contextFields(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(contextFieldsKey).(map[string]string)
+	return fields
+}
+
+// LogInfo logs an informational message along with the fields of ctx.
+func LogInfo(ctx context.Context, msg string, data ...interface{}) {
+	if !structuredFlag {
+		Info(msg, data...)
+		return
+	}
+	printRecord(withFields(log.Record{
+		Level:   InformationLvl.String(),
+		Time:    time.Now().UTC().Format(time.RFC3339Nano),
+		Message: fmt.Sprintf(msg, data...),
+	}, contextFields(ctx)))
+}
+
+// withFields returns r with fields set, the op, bucket and object
+// fields replacing those of r.
+func withFields(r log.Record, fields map[string]string) log.Record {
+	for k, v := range fields {
+		switch k {
+		case FieldOp:
+			r.Op = v
+		case FieldBucket:
+			r.Bucket = v
+		case FieldObject:
+			r.Object = v
+		default:
+			if r.Fields == nil {
+				r.Fields = make(map[string]string)
+			}
+			r.Fields[k] = v
+		}
+	}
+	return r
+}
+
+// printRecord prints r as a line of JSON.
+func printRecord(r log.Record) {
+	logJSON, err := json.Marshal(&r)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(logJSON))
+}
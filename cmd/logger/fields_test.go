@@ -0,0 +1,106 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/minio/radio/cmd/logger/message/log"
+)
+
+type recordTarget struct {
+	records []log.Record
+}
+
+func (t *recordTarget) Send(e interface{}, errKind string) error {
+	t.records = append(t.records, e.(log.Entry).Record())
+	return nil
+}
+
+// captureStdout returns what f prints.
+func captureStdout(t *testing.T, f func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	f()
+	os.Stdout = stdout
+	w.Close()
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	return buf.String()
+}
+
+// Tests that the JSON format outputs flat records carrying the fields
+// of the context logged under.
+func TestStructuredLogging(t *testing.T) {
+	if err := SetFormat("xml"); err == nil {
+		t.Fatal("Expected an unknown format rejected")
+	}
+	if err := SetFormat(FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		structuredFlag, jsonFlag = false, false
+	}()
+
+	target := &recordTarget{}
+	targets := Targets
+	Targets = []Target{target}
+	defer func() { Targets = targets }()
+
+	ctx := SetReqInfo(context.Background(), &ReqInfo{API: "PutObject", BucketName: "bucket", ObjectName: "object"})
+	LogIf(ctx, errors.New("request failed"))
+	hctx := WithFields(context.Background(), FieldOp, "Heal", FieldBucket, "bucket", "entry", "1")
+	hctx = WithFields(hctx, FieldObject, "object")
+	LogIf(hctx, errors.New("heal failed"))
+	if len(target.records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(target.records))
+	}
+	testCases := []struct {
+		op, err string
+		fields  map[string]string
+	}{
+		{"PutObject", "request failed", nil},
+		{"Heal", "heal failed", map[string]string{"entry": "1"}},
+	}
+	for i, testCase := range testCases {
+		r := target.records[i]
+		if r.Level != ErrorLvl.String() || r.Op != testCase.op || r.Bucket != "bucket" || r.Object != "object" ||
+			r.Error != testCase.err || r.Message != testCase.err {
+			t.Errorf("Test %d: unexpected record %+v", i+1, r)
+		}
+		if len(r.Fields) != len(testCase.fields) || r.Fields["entry"] != testCase.fields["entry"] {
+			t.Errorf("Test %d: expected fields %v, got %v", i+1, testCase.fields, r.Fields)
+		}
+	}
+
+	out := captureStdout(t, func() {
+		LogInfo(hctx, "healed %s", "object")
+		Info("started")
+	})
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %q", out)
+	}
+	var records [2]log.Record
+	for i, line := range lines {
+		if err := json.Unmarshal([]byte(line), &records[i]); err != nil {
+			t.Fatalf("Line %d: %v", i+1, err)
+		}
+	}
+	if r := records[0]; r.Level != InformationLvl.String() || r.Message != "healed object" || r.Op != "Heal" ||
+		r.Bucket != "bucket" || r.Object != "object" || r.Fields["entry"] != "1" {
+		t.Errorf("Unexpected info record %+v", r)
+	}
+	if r := records[1]; r.Message != "started" || r.Time == "" {
+		t.Errorf("Unexpected info record %+v", r)
+	}
+}
@@ -297,6 +297,21 @@ func logIf(ctx context.Context, err error, errKind ...interface{}) {
 		tags[entry.Key] = entry.Val
 	}
 
+	// Fields of ctx replace those of the request.
+	bucket, object := req.BucketName, req.ObjectName
+	for k, v := range contextFields(ctx) {
+		switch k {
+		case FieldOp:
+			API = v
+		case FieldBucket:
+			bucket = v
+		case FieldObject:
+			object = v
+		default:
+			tags[k] = v
+		}
+	}
+
 	// Get full stack trace
 	trace := getTrace(3)
 
@@ -317,8 +332,8 @@ func logIf(ctx context.Context, err error, errKind ...interface{}) {
 		API: &log.API{
 			Name: API,
 			Args: &log.Args{
-				Bucket: req.BucketName,
-				Object: req.ObjectName,
+				Bucket: bucket,
+				Object: object,
 			},
 		},
 		Trace: &log.Trace{
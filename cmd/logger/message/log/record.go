@@ -0,0 +1,55 @@
+package log
+
+// Record - defines the flat fields and values of each log entry output
+// in the structured JSON format.
+type Record struct {
+	Level        string            `json:"level"`
+	Time         string            `json:"time"`
+	Message      string            `json:"msg,omitempty"`
+	Op           string            `json:"op,omitempty"`
+	Bucket       string            `json:"bucket,omitempty"`
+	Object       string            `json:"object,omitempty"`
+	Error        string            `json:"error,omitempty"`
+	Kind         string            `json:"errKind,omitempty"`
+	DeploymentID string            `json:"deploymentid,omitempty"`
+	RequestID    string            `json:"requestID,omitempty"`
+	RemoteHost   string            `json:"remotehost,omitempty"`
+	Host         string            `json:"host,omitempty"`
+	UserAgent    string            `json:"userAgent,omitempty"`
+	Source       []string          `json:"source,omitempty"`
+	Fields       map[string]string `json:"fields,omitempty"`
+}
+
+// Record - returns the structured record of the entry. Error entries
+// are given their error as message.
+func (e Entry) Record() Record {
+	r := Record{
+		Level:        e.Level,
+		Time:         e.Time,
+		Message:      e.Message,
+		Kind:         e.LogKind,
+		DeploymentID: e.DeploymentID,
+		RequestID:    e.RequestID,
+		RemoteHost:   e.RemoteHost,
+		Host:         e.Host,
+		UserAgent:    e.UserAgent,
+	}
+	if e.API != nil {
+		r.Op = e.API.Name
+		if e.API.Args != nil {
+			r.Bucket = e.API.Args.Bucket
+			r.Object = e.API.Args.Object
+		}
+	}
+	if e.Trace != nil {
+		r.Error = e.Trace.Message
+		if r.Message == "" {
+			r.Message = e.Trace.Message
+		}
+		r.Source = e.Trace.Source
+		if len(e.Trace.Variables) > 0 {
+			r.Fields = e.Trace.Variables
+		}
+	}
+	return r
+}
@@ -21,6 +21,15 @@ func (c *Target) Send(e interface{}, logKind string) error {
 	if !ok {
 		return fmt.Errorf("uexpected log entry structure %#v", e)
 	}
+	if logger.IsStructured() {
+		record := entry.Record()
+		logJSON, err := json.Marshal(&record)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(logJSON))
+		return nil
+	}
 	if logger.IsJSON() {
 		logJSON, err := json.Marshal(&entry)
 		if err != nil {
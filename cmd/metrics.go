@@ -18,6 +18,183 @@ var (
 		},
 		[]string{"api"},
 	)
+	eventsDropped = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "radio",
+			Name:      "events_dropped_total",
+			Help:      "Total number of bucket notifications dropped as the queue of the bucket was full",
+		},
+		[]string{"bucket"},
+	)
+	healCompleted = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "radio",
+			Name:      "heal_completed_total",
+			Help:      "Total number of heal journal entries healed by current Radio server instance",
+		},
+		[]string{"op"},
+	)
+	healFailed = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "radio",
+			Name:      "heal_failed_total",
+			Help:      "Total number of failed attempts to heal a heal journal entry",
+		},
+		[]string{"op"},
+	)
+	healDeadLettered = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "radio",
+			Name:      "heal_dead_lettered_total",
+			Help:      "Total number of heal journal entries given up after exceeding the journal max retries or TTL",
+		},
+		[]string{"op"},
+	)
+	healDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "radio",
+			Name:      "heal_duration_seconds",
+			Help:      "Time taken to heal a heal journal entry",
+			Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+		},
+		[]string{"op"},
+	)
+	healBacklog = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "radio",
+			Name:      "heal_backlog",
+			Help:      "Number of heal journal entries of a bucket left to heal at the last heal pass",
+		},
+		[]string{"bucket"},
+	)
+	replicaDivergentObjects = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "radio",
+			Name:      "replica_divergent_objects",
+			Help:      "Number of objects in the last sample whose replicas disagree on the radio tag",
+		},
+		[]string{"bucket"},
+	)
+	replicaDivergentRatio = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "radio",
+			Name:      "replica_divergent_ratio",
+			Help:      "Fraction of objects in the last sample whose replicas disagree on the radio tag",
+		},
+		[]string{"bucket"},
+	)
+	scannerHealsQueued = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "radio",
+			Name:      "scanner_heals_queued_total",
+			Help:      "Total number of heals journaled by the divergence scanner",
+		},
+		[]string{"bucket"},
+	)
+	shadowWriteErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "radio",
+			Name:      "shadow_write_errors_total",
+			Help:      "Total number of failed writes to shadow remotes",
+		},
+		[]string{"bucket", "remote"},
+	)
+	etagDivergence = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "radio",
+			Name:      "etag_divergence_total",
+			Help:      "Total number of objects written with different ETags on the replicas",
+		},
+		[]string{"bucket"},
+	)
+	shortWrites = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "radio",
+			Name:      "short_writes_total",
+			Help:      "Total number of uploads whose stream to a replica ended before the upload size",
+		},
+		[]string{"bucket"},
+	)
+	stalledWrites = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "radio",
+			Name:      "stalled_writes_total",
+			Help:      "Total number of uploads abandoning a replica reading none of them within the write stall timeout",
+		},
+		[]string{"bucket"},
+	)
+	spoolRetries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "radio",
+			Name:      "spool_retries_total",
+			Help:      "Total number of uploads to a replica retried from the spooled upload",
+		},
+		[]string{"bucket"},
+	)
+	degradedOperations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "radio",
+			Name:      "degraded_operations_total",
+			Help:      "Total number of operations completed without the full replica set",
+		},
+		[]string{"op"},
+	)
+	remoteRetries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "radio",
+			Name:      "remote_retries_total",
+			Help:      "Total number of requests to a remote retried after a network or server error",
+		},
+		[]string{"remote"},
+	)
+	remoteRetryBudgetExhausted = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "radio",
+			Name:      "remote_retry_budget_exhausted_total",
+			Help:      "Total number of failed requests to a remote not retried as its retry budget was spent",
+		},
+		[]string{"remote"},
+	)
+	inflightOperations = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "radio",
+			Name:      "inflight_operations",
+			Help:      "Number of client operations in flight, tracked when admission limits are configured",
+		},
+		[]string{"kind"},
+	)
+	rejectedOperations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "radio",
+			Name:      "rejected_operations_total",
+			Help:      "Total number of client operations rejected by the admission limits",
+		},
+		[]string{"kind"},
+	)
+	multipartUploadsInProgress = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "radio",
+			Name:      "multipart_uploads_in_progress",
+			Help:      "Number of multipart uploads created and neither completed nor aborted",
+		},
+		[]string{"bucket"},
+	)
+	rejectedMultipart = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "radio",
+			Name:      "rejected_multipart_total",
+			Help:      "Total number of multipart uploads and parts rejected by the multipart limits of a bucket",
+		},
+		[]string{"bucket", "kind"},
+	)
+	staleObjectInfo = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "radio",
+			Name:      "stale_object_info_total",
+			Help:      "Total number of HEAD requests served the info of a recent write while all replicas were unreachable",
+		},
+		[]string{"bucket"},
+	)
 	minioVersionInfo = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: "minio",
@@ -37,6 +214,28 @@ func init() {
 	prometheus.MustRegister(httpRequestsDuration)
 	prometheus.MustRegister(newMinioCollector())
 	prometheus.MustRegister(minioVersionInfo)
+	prometheus.MustRegister(healCompleted)
+	prometheus.MustRegister(healFailed)
+	prometheus.MustRegister(healDeadLettered)
+	prometheus.MustRegister(healDuration)
+	prometheus.MustRegister(healBacklog)
+	prometheus.MustRegister(replicaDivergentObjects)
+	prometheus.MustRegister(replicaDivergentRatio)
+	prometheus.MustRegister(scannerHealsQueued)
+	prometheus.MustRegister(shadowWriteErrors)
+	prometheus.MustRegister(etagDivergence)
+	prometheus.MustRegister(degradedOperations)
+	prometheus.MustRegister(shortWrites)
+	prometheus.MustRegister(stalledWrites)
+	prometheus.MustRegister(spoolRetries)
+	prometheus.MustRegister(remoteRetries)
+	prometheus.MustRegister(remoteRetryBudgetExhausted)
+	prometheus.MustRegister(inflightOperations)
+	prometheus.MustRegister(rejectedOperations)
+	prometheus.MustRegister(multipartUploadsInProgress)
+	prometheus.MustRegister(rejectedMultipart)
+	prometheus.MustRegister(staleObjectInfo)
+	prometheus.MustRegister(eventsDropped)
 }
 
 // newMinioCollector describes the collector
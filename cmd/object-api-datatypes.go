@@ -77,8 +77,24 @@ type ObjectInfo struct {
 	// about the object and its actual location at the backend
 	ReplicaIndex int
 
+	// Replicas lists all replicas holding the same
+	// version of the object as ReplicaIndex
+	Replicas []int
+
+	// Stale is set on the info of a recently written object served
+	// while its replicas are unreachable, which may have changed since.
+	Stale bool
+
 	// Date and time when the object was last accessed.
 	AccTime time.Time
+
+	// Version of the object on a versioned remote, set by version
+	// listings only.
+	VersionID string
+	// IsLatest is set on the current version of the object.
+	IsLatest bool
+	// DeleteMarker is set if the version is a delete marker.
+	DeleteMarker bool
 }
 
 // ListPartsInfo - represents list of all parts.
@@ -198,6 +214,27 @@ type ListObjectsInfo struct {
 	Prefixes []string
 }
 
+// ListObjectVersionsInfo - container for list object versions.
+type ListObjectVersionsInfo struct {
+	// Indicates whether the returned list objects response is truncated.
+	IsTruncated bool
+
+	// When response is truncated, the key and version id markers of
+	// the subsequent request to get the next set of versions.
+	NextMarker          string
+	NextVersionIDMarker string
+
+	// List of object versions and delete markers for this request.
+	Objects []ObjectInfo
+
+	// List of prefixes for this request.
+	Prefixes []string
+
+	// Replicas skipped by a merged listing for not responding in
+	// time, the listing may be incomplete.
+	SkippedReplicas []int
+}
+
 // ListObjectsV2Info - container for list objects version 2.
 type ListObjectsV2Info struct {
 	// Indicates whether the returned list objects response is truncated. A
@@ -124,6 +124,21 @@ func (e InsufficientWriteQuorum) Error() string {
 	return "Storage resources are insufficient for the write operation."
 }
 
+// QuorumNotMet fewer replicas than required succeeded a write operation.
+type QuorumNotMet struct {
+	Succeeded int
+	Required  int
+	// Weighted quorums count the write weights of the replicas.
+	Weighted bool
+}
+
+func (e QuorumNotMet) Error() string {
+	if e.Weighted {
+		return fmt.Sprintf("Write quorum not met: replicas of weight %d succeeded, weight %d required.", e.Succeeded, e.Required)
+	}
+	return fmt.Sprintf("Write quorum not met: %d replicas succeeded, %d required.", e.Succeeded, e.Required)
+}
+
 // GenericError - generic object layer error.
 type GenericError struct {
 	Bucket string
@@ -165,6 +180,13 @@ func (e ObjectNotFound) Error() string {
 	return "Object not found: " + e.Bucket + "#" + e.Object
 }
 
+// ObjectArchived object is archived and has no restored copy to read.
+type ObjectArchived GenericError
+
+func (e ObjectArchived) Error() string {
+	return "Object archived: " + e.Bucket + "#" + e.Object
+}
+
 // ObjectAlreadyExists object already exists.
 type ObjectAlreadyExists GenericError
 
@@ -393,6 +415,24 @@ func (e NotImplemented) Error() string {
 	return "Not Implemented"
 }
 
+// OperationNotAllowed - operation disabled by configuration.
+type OperationNotAllowed struct {
+	Operation string
+}
+
+func (e OperationNotAllowed) Error() string {
+	return "Operation " + e.Operation + " is disabled"
+}
+
+// InvalidStorageClass - unknown storage class requested.
+type InvalidStorageClass struct {
+	StorageClass string
+}
+
+func (e InvalidStorageClass) Error() string {
+	return "Invalid storage class " + e.StorageClass
+}
+
 // UnsupportedMetadata - unsupported metadata
 type UnsupportedMetadata struct{}
 
@@ -400,6 +440,34 @@ func (e UnsupportedMetadata) Error() string {
 	return "Unsupported headers in Metadata"
 }
 
+// ReplicaConflict - replicas hold different versions of an object read
+// from a bucket with strict reads.
+type ReplicaConflict GenericError
+
+func (e ReplicaConflict) Error() string {
+	return "Replicas of " + e.Bucket + "/" + e.Object + " hold different versions"
+}
+
+// ObjectQuarantined - replicas hold different versions of an object read
+// whose heal was given up.
+type ObjectQuarantined GenericError
+
+func (e ObjectQuarantined) Error() string {
+	return "Replicas of " + e.Bucket + "/" + e.Object + " diverge and their heal was given up"
+}
+
+// InvalidReplica - replica requested by a diagnostic read does not
+// exist.
+type InvalidReplica struct {
+	Bucket  string
+	Object  string
+	Replica string
+}
+
+func (e InvalidReplica) Error() string {
+	return "Replica " + e.Replica + " of " + e.Bucket + "/" + e.Object + " does not exist"
+}
+
 // BackendDown is returned for network errors or if the radio's backend is down.
 type BackendDown struct{}
 
@@ -407,6 +475,13 @@ func (e BackendDown) Error() string {
 	return "Backend down"
 }
 
+// BackendThrottled is returned if the radio's backend throttles requests.
+type BackendThrottled GenericError
+
+func (e BackendThrottled) Error() string {
+	return "Backend throttled requests to " + e.Bucket + "/" + e.Object
+}
+
 // PreConditionFailed - Check if copy precondition failed
 type PreConditionFailed struct{}
 
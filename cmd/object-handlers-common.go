@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"regexp"
+	"strings"
 	"time"
 
 	xhttp "github.com/minio/radio/cmd/http"
@@ -195,6 +196,43 @@ func checkPreconditions(ctx context.Context, w http.ResponseWriter, r *http.Requ
 	return false
 }
 
+// putPreconditionFn returns the check of the preconditions of a PUT
+// request against the object it overwrites, nil if none are set.
+// Preconditions supported are:
+//  If-Match
+//  If-None-Match
+func putPreconditionFn(h http.Header) CheckPutPreconditionFn {
+	ifMatch, ifNoneMatch := h.Get(xhttp.IfMatch), h.Get(xhttp.IfNoneMatch)
+	if ifMatch == "" && ifNoneMatch == "" {
+		return nil
+	}
+	return func(oi ObjectInfo, exists bool) bool {
+		// If-Match : Overwrite the object only if it exists and its entity
+		// tag (ETag) is the same as one of those specified.
+		if ifMatch != "" && (!exists || !etagListMatches(oi.ETag, ifMatch)) {
+			return true
+		}
+		// If-None-Match : Write the object only if it does not exist or, unless
+		// "*" is specified, its entity tag (ETag) differs from those specified.
+		if ifNoneMatch != "" && exists && etagListMatches(oi.ETag, ifNoneMatch) {
+			return true
+		}
+		return false
+	}
+}
+
+// etagListMatches returns true if etag is one of the comma separated
+// ETags of list, any ETag matches "*".
+func etagListMatches(etag, list string) bool {
+	for _, e := range strings.Split(list, ",") {
+		e = strings.TrimSpace(e)
+		if e == "*" || isETagEqual(etag, e) {
+			return true
+		}
+	}
+	return false
+}
+
 // returns true if object was modified after givenTime.
 func ifModifiedSince(objTime time.Time, givenTime time.Time) bool {
 	// The Date-Modified header truncates sub-second precision, so
@@ -13,12 +13,12 @@ import (
 
 	"time"
 
+	miniogo "github.com/minio/minio-go/v6"
 	"github.com/minio/minio-go/v6/pkg/encrypt"
 	"github.com/minio/minio/pkg/hash"
 	iampolicy "github.com/minio/minio/pkg/iam/policy"
 	"github.com/minio/minio/pkg/ioutil"
 	"github.com/minio/minio/pkg/policy"
-	"github.com/minio/minio/pkg/s3select"
 	xhttp "github.com/minio/radio/cmd/http"
 	"github.com/minio/radio/cmd/logger"
 )
@@ -80,63 +80,109 @@ func (api objectAPIHandlers) SelectObjectContentHandler(w http.ResponseWriter, r
 		return
 	}
 
-	s3Select, err := s3select.NewS3Select(r.Body)
+	var sopts miniogo.SelectObjectOptions
+	if err := xml.NewDecoder(r.Body).Decode(&sopts); err != nil {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrMalformedXML), r.URL)
+		return
+	}
+	// Objects encrypted with a customer key are selected with the key
+	// of the request headers.
+	opts, err := ssecObjectOptions(r.Header, ObjectOptions{})
 	if err != nil {
-		if serr, ok := err.(s3select.SelectError); ok {
-			encodedErrorResponse := encodeResponse(APIErrorResponse{
-				Code:       serr.ErrorCode(),
-				Message:    serr.ErrorMessage(),
-				BucketName: bucket,
-				Key:        object,
-				Resource:   r.URL.Path,
-				RequestID:  w.Header().Get(xhttp.AmzRequestID),
-				HostID:     globalDeploymentID,
-			})
-			writeResponse(w, serr.HTTPStatusCode(), encodedErrorResponse, mimeXML)
-		} else {
-			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
-		}
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
 		return
 	}
+	sopts.ServerSideEncryption = opts.ServerSideEncryption
 
-	getObjectNInfo := objectAPI.GetObjectNInfo
-	if api.CacheAPI() != nil {
-		getObjectNInfo = api.CacheAPI().GetObjectNInfo
+	results, err := objectAPI.SelectObjectContent(ctx, bucket, object, sopts)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
 	}
-	getObject := func(offset, length int64) (rc io.ReadCloser, err error) {
-		isSuffixLength := false
-		if offset < 0 {
-			isSuffixLength = true
-		}
-		rs := &HTTPRangeSpec{
-			IsSuffixLength: isSuffixLength,
-			Start:          offset,
-			End:            offset + length,
-		}
+	defer results.Close()
 
-		return getObjectNInfo(ctx, bucket, object, rs, r.Header, ReadLock, ObjectOptions{})
-	}
-
-	if err = s3Select.Open(getObject); err != nil {
-		if serr, ok := err.(s3select.SelectError); ok {
-			encodedErrorResponse := encodeResponse(APIErrorResponse{
-				Code:       serr.ErrorCode(),
-				Message:    serr.ErrorMessage(),
-				BucketName: bucket,
-				Key:        object,
-				Resource:   r.URL.Path,
-				RequestID:  w.Header().Get(xhttp.AmzRequestID),
-				HostID:     globalDeploymentID,
-			})
-			writeResponse(w, serr.HTTPStatusCode(), encodedErrorResponse, mimeXML)
-		} else {
-			writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+	setCommonHeaders(w)
+	w.WriteHeader(http.StatusOK)
+
+	flush := func() {
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
 		}
+	}
+	bytesReturned, err := copySelectRecords(w, results, flush)
+	if err != nil {
+		errResp := miniogo.ToErrorResponse(err)
+		w.Write(newSelectErrorMessage(errResp.Code, errResp.Message))
+		flush()
 		return
 	}
+	if stats := results.Stats(); stats != nil {
+		w.Write(newSelectStatsMessage(stats.BytesScanned, stats.BytesProcessed, bytesReturned))
+	}
+	w.Write(newSelectEndMessage())
+	flush()
+}
+
+// Maximum size of a RestoreObject request body.
+const maxRestoreRequestSize = 1 << 20
 
-	s3Select.Evaluate(w)
-	s3Select.Close()
+// RestoreObjectHandler - POST Object?restore
+// ----------
+// This implementation of the POST operation initiates the retrieval of
+// an archived object, responding 202 Accepted while retrieving it and
+// 200 OK once the object can be read.
+func (api objectAPIHandlers) RestoreObjectHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "RestoreObject")
+
+	defer logger.AuditLog(w, r, "RestoreObject")
+
+	objectAPI := api.ObjectAPI()
+	if objectAPI == nil {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	bucket, object := request2BucketObjectName(r)
+
+	// Restoring writes a temporary copy of the object, the policies
+	// know no s3:RestoreObject action.
+	if s3Error := checkRequestAuthType(ctx, r, policy.PutObjectAction, bucket, object); s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+		return
+	}
+
+	if r.ContentLength <= 0 {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrEmptyRequestBody), r.URL)
+		return
+	}
+	restoreRequest, err := goioutil.ReadAll(io.LimitReader(r.Body, maxRestoreRequestSize+1))
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	if len(restoreRequest) > maxRestoreRequestSize {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrEntityTooLarge), r.URL)
+		return
+	}
+	// The request is forwarded as is to the remotes, which validate it.
+	var v struct {
+		XMLName xml.Name `xml:"RestoreRequest"`
+	}
+	if err = xml.Unmarshal(restoreRequest, &v); err != nil {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrMalformedXML), r.URL)
+		return
+	}
+
+	restored, err := objectAPI.RestoreObject(ctx, bucket, object, restoreRequest)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	if restored {
+		writeSuccessResponseHeadersOnly(w)
+		return
+	}
+	writeResponse(w, http.StatusAccepted, nil, mimeNone)
 }
 
 // GetObjectHandler - GET Object
@@ -179,11 +225,11 @@ func (api objectAPIHandlers) GetObjectHandler(w http.ResponseWriter, r *http.Req
 	if rangeHeader != "" {
 		var err error
 		if rs, err = parseRequestRangeSpec(rangeHeader); err != nil {
-			// Handle only errInvalidRange. Ignore other
+			// Handle only errInvalidRange and errMultipleRanges. Ignore other
 			// parse error and treat it as regular Get
 			// request like Amazon S3.
-			if err == errInvalidRange {
-				writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidRange), r.URL)
+			if err == errInvalidRange || err == errMultipleRanges {
+				writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
 				return
 			}
 
@@ -191,7 +237,20 @@ func (api objectAPIHandlers) GetObjectHandler(w http.ResponseWriter, r *http.Req
 		}
 	}
 
-	gr, err := getObjectNInfo(ctx, bucket, object, rs, r.Header, ReadLock, ObjectOptions{})
+	opts, err := ssecObjectOptions(r.Header, ObjectOptions{})
+	if err == nil {
+		opts, err = replicaReadOptions(r.Header, bucket, object, opts)
+	}
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	if opts.Replica != nil {
+		// Reads forced to a replica are never served from the cache.
+		getObjectNInfo = objectAPI.GetObjectNInfo
+	}
+
+	gr, err := getObjectNInfo(ctx, bucket, object, rs, r.Header, ReadLock, opts)
 	if err != nil {
 		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
 		return
@@ -254,7 +313,7 @@ func (api objectAPIHandlers) HeadObjectHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	getObjectInfo := objectAPI.GetObjectInfo
+	getObjectInfo := objectAPI.HeadObject
 	if api.CacheAPI() != nil {
 		getObjectInfo = api.CacheAPI().GetObjectInfo
 	}
@@ -270,11 +329,11 @@ func (api objectAPIHandlers) HeadObjectHandler(w http.ResponseWriter, r *http.Re
 	if rangeHeader != "" {
 		var err error
 		if rs, err = parseRequestRangeSpec(rangeHeader); err != nil {
-			// Handle only errInvalidRange. Ignore other
+			// Handle only errInvalidRange and errMultipleRanges. Ignore other
 			// parse error and treat it as regular Get
 			// request like Amazon S3.
-			if err == errInvalidRange {
-				writeErrorResponseHeadersOnly(w, errorCodes.ToAPIErr(ErrInvalidRange))
+			if err == errInvalidRange || err == errMultipleRanges {
+				writeErrorResponseHeadersOnly(w, toAPIError(ctx, err))
 				return
 			}
 
@@ -282,7 +341,20 @@ func (api objectAPIHandlers) HeadObjectHandler(w http.ResponseWriter, r *http.Re
 		}
 	}
 
-	objInfo, err := getObjectInfo(ctx, bucket, object, ObjectOptions{})
+	opts, err := ssecObjectOptions(r.Header, ObjectOptions{})
+	if err == nil {
+		opts, err = replicaReadOptions(r.Header, bucket, object, opts)
+	}
+	if err != nil {
+		writeErrorResponseHeadersOnly(w, toAPIError(ctx, err))
+		return
+	}
+	if opts.Replica != nil {
+		// Reads forced to a replica are never served from the cache.
+		getObjectInfo = objectAPI.GetObjectInfo
+	}
+
+	objInfo, err := getObjectInfo(ctx, bucket, object, opts)
 	if err != nil {
 		writeErrorResponseHeadersOnly(w, toAPIError(ctx, err))
 		return
@@ -326,6 +398,13 @@ func getCpObjMetadataFromHeader(ctx context.Context, r *http.Request, userMeta m
 		return extractMetadata(ctx, r)
 	}
 
+	// The ACL and storage class of the copy are set by the request,
+	// whatever the x-amz-metadata-directive.
+	extractACLHeaders(r.Header, defaultMeta)
+	if err := extractStorageClassHeader(r.Header, defaultMeta); err != nil {
+		return nil, err
+	}
+
 	// if x-amz-metadata-directive says COPY then we
 	// return the default metadata.
 	if isMetadataCopy(r.Header) {
@@ -423,8 +502,19 @@ func (api objectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 	if !cpSrcDstSame {
 		lock = ReadLock
 	}
+	srcOpts, err := ssecCopySourceOptions(r.Header)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	dstOpts, err := ssecObjectOptions(r.Header, ObjectOptions{})
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
 	var rs *HTTPRangeSpec
-	gr, err := getObjectNInfo(ctx, srcBucket, srcObject, rs, r.Header, lock, ObjectOptions{})
+	gr, err := getObjectNInfo(ctx, srcBucket, srcObject, rs, r.Header, lock, srcOpts)
 	if err != nil {
 		if isErrPreconditionFailed(err) {
 			return
@@ -481,11 +571,14 @@ func (api objectAPIHandlers) CopyObjectHandler(w http.ResponseWriter, r *http.Re
 
 	// Copy source object to destination, if source and destination
 	// object is same then only metadata is updated.
-	objInfo, err := objectAPI.CopyObject(ctx, srcBucket, srcObject, dstBucket, dstObject, srcInfo, ObjectOptions{}, ObjectOptions{})
+	objInfo, err := objectAPI.CopyObject(ctx, srcBucket, srcObject, dstBucket, dstObject, srcInfo, srcOpts, dstOpts)
 	if err != nil {
 		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
 		return
 	}
+	if api.CacheAPI() != nil {
+		api.CacheAPI().InvalidateObject(ctx, dstBucket, dstObject)
+	}
 
 	response := generateCopyObjectResponse(objInfo.ETag, objInfo.ModTime)
 	encodedSuccessResponse := encodeResponse(response)
@@ -636,13 +729,28 @@ func (api objectAPIHandlers) PutObjectHandler(w http.ResponseWriter, r *http.Req
 	if api.CacheAPI() != nil {
 		putObject = api.CacheAPI().PutObject
 	}
+	if r.Header.Get(xhttp.RadioAppend) == "true" {
+		putObject = objectAPI.AppendObject
+	}
+
+	opts, err := ssecObjectOptions(r.Header, ObjectOptions{
+		UserDefined:       metadata,
+		CheckPutPrecondFn: putPreconditionFn(r.Header),
+	})
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
 
 	// Create the object..
-	objInfo, err := putObject(ctx, bucket, object, pReader, ObjectOptions{UserDefined: metadata})
+	objInfo, err := putObject(ctx, bucket, object, pReader, opts)
 	if err != nil {
 		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
 		return
 	}
+	if r.Header.Get(xhttp.RadioAppend) == "true" && api.CacheAPI() != nil {
+		api.CacheAPI().InvalidateObject(ctx, bucket, object)
+	}
 
 	etag := objInfo.ETag
 	w.Header()[xhttp.ETag] = []string{"\"" + etag + "\""}
@@ -683,9 +791,15 @@ func (api objectAPIHandlers) NewMultipartUploadHandler(w http.ResponseWriter, r
 		return
 	}
 
+	opts, err := ssecObjectOptions(r.Header, ObjectOptions{UserDefined: metadata})
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
 	newMultipartUpload := objectAPI.NewMultipartUpload
 
-	uploadID, err := newMultipartUpload(ctx, bucket, object, ObjectOptions{UserDefined: metadata})
+	uploadID, err := newMultipartUpload(ctx, bucket, object, opts)
 	if err != nil {
 		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
 		return
@@ -773,7 +887,16 @@ func (api objectAPIHandlers) CopyObjectPartHandler(w http.ResponseWriter, r *htt
 		return
 	}
 
-	var srcOpts, dstOpts ObjectOptions
+	srcOpts, err := ssecCopySourceOptions(r.Header)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	dstOpts, err := ssecObjectOptions(r.Header, ObjectOptions{})
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
 	var getOpts = ObjectOptions{}
 	if srcOpts.ServerSideEncryption != nil {
 		getOpts.ServerSideEncryption = encrypt.SSE(srcOpts.ServerSideEncryption)
@@ -983,7 +1106,13 @@ func (api objectAPIHandlers) PutObjectPartHandler(w http.ResponseWriter, r *http
 
 	putObjectPart := objectAPI.PutObjectPart
 
-	partInfo, err := putObjectPart(ctx, bucket, object, uploadID, partID, pReader, ObjectOptions{})
+	opts, err := ssecObjectOptions(r.Header, ObjectOptions{})
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+
+	partInfo, err := putObjectPart(ctx, bucket, object, uploadID, partID, pReader, opts)
 	if err != nil {
 		// Verify if the underlying error is signature mismatch.
 		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
@@ -1187,12 +1316,7 @@ func (api objectAPIHandlers) CompleteMultipartUploadHandler(w http.ResponseWrite
 	// This code is specifically to handle the requirements for slow
 	// complete multipart upload operations on FS mode.
 	writeErrorResponseWithoutXMLHeader := func(ctx context.Context, w http.ResponseWriter, err APIError, reqURL *url.URL) {
-		switch err.Code {
-		case "SlowDown", "XMinioServerNotInitialized", "XMinioReadQuorum", "XMinioWriteQuorum":
-			// Set retry-after header to indicate user-agents to retry request after 120secs.
-			// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Retry-After
-			w.Header().Set(xhttp.RetryAfter, "120")
-		}
+		setRetryAfter(ctx, w, err.Code)
 
 		// Generate error response.
 		errorResponse := getAPIErrorResponse(ctx, err, reqURL.Path,
@@ -1220,6 +1344,9 @@ func (api objectAPIHandlers) CompleteMultipartUploadHandler(w http.ResponseWrite
 		}
 		return
 	}
+	if api.CacheAPI() != nil {
+		api.CacheAPI().InvalidateObject(ctx, bucket, object)
+	}
 
 	// Get object location.
 	location := getObjectLocation(r, globalDomainNames, bucket, object)
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	miniogo "github.com/minio/minio-go/v6"
+	xhttp "github.com/minio/radio/cmd/http"
+)
+
+// Canned ACLs accepted as bucket defaults.
+var cannedACLs = map[string]bool{
+	"private":                   true,
+	"public-read":               true,
+	"public-read-write":         true,
+	"authenticated-read":        true,
+	"aws-exec-read":             true,
+	"bucket-owner-read":         true,
+	"bucket-owner-full-control": true,
+}
+
+// aclHeaders are the request headers setting the ACL of a new object.
+var aclHeaders = []string{
+	xhttp.AmzACL,
+	xhttp.AmzGrantRead,
+	xhttp.AmzGrantReadACP,
+	xhttp.AmzGrantWriteACP,
+	xhttp.AmzGrantFullControl,
+}
+
+// parseCannedACL validates the default ACL of bucket.
+func parseCannedACL(bucket, acl string) (string, error) {
+	if acl != "" && !cannedACLs[acl] {
+		return "", fmt.Errorf("bucket %s: unknown canned ACL %q", bucket, acl)
+	}
+	return acl, nil
+}
+
+// isACLKey returns true if the metadata key sets the ACL of an object.
+func isACLKey(key string) bool {
+	key = http.CanonicalHeaderKey(key)
+	for _, h := range aclHeaders {
+		if key == h {
+			return true
+		}
+	}
+	return false
+}
+
+// extractACLHeaders copies the ACL headers of h into metadata, dropping
+// any ACL already set there.
+func extractACLHeaders(h http.Header, metadata map[string]string) {
+	for k := range metadata {
+		if isACLKey(k) {
+			delete(metadata, k)
+		}
+	}
+	for _, key := range aclHeaders {
+		if v := h.Get(key); v != "" {
+			metadata[key] = v
+		}
+	}
+}
+
+// withDefaultACL returns metadata with the canned acl set, unless acl is
+// empty or metadata already sets an ACL.
+func withDefaultACL(metadata map[string]string, acl string) map[string]string {
+	if acl == "" {
+		return metadata
+	}
+	for k := range metadata {
+		if isACLKey(k) {
+			return metadata
+		}
+	}
+	withACL := make(map[string]string, len(metadata)+1)
+	for k, v := range metadata {
+		withACL[k] = v
+	}
+	withACL[xhttp.AmzACL] = acl
+	return withACL
+}
+
+// aclMetadata returns the ACL headers reproducing the ACL of an object
+// as read with GetObjectACL.
+func aclMetadata(info *miniogo.ObjectInfo) map[string]string {
+	metadata := make(map[string]string)
+	if acl := info.Metadata.Get(xhttp.AmzACL); acl != "" {
+		metadata[xhttp.AmzACL] = acl
+		return metadata
+	}
+	for _, key := range aclHeaders[1:] {
+		if grantees := info.Metadata[key]; len(grantees) > 0 {
+			metadata[key] = strings.Join(grantees, ", ")
+		}
+	}
+	return metadata
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"testing"
+)
+
+// Tests that the bucket default ACL only applies to objects written
+// without an ACL.
+func TestWithDefaultACL(t *testing.T) {
+	testCases := []struct {
+		metadata    map[string]string
+		acl         string
+		expectedACL string
+	}{
+		{nil, "", ""},
+		{nil, "public-read", "public-read"},
+		{map[string]string{"x-amz-acl": "private"}, "public-read", "private"},
+		{map[string]string{"X-Amz-Grant-Read": "id=owner"}, "public-read", ""},
+		{map[string]string{"Content-Type": "text/plain"}, "authenticated-read", "authenticated-read"},
+	}
+
+	for i, testCase := range testCases {
+		metadata := ToMinioClientMetadata(withDefaultACL(testCase.metadata, testCase.acl))
+		if metadata["X-Amz-Acl"] != testCase.expectedACL {
+			t.Errorf("Test %d: Expected ACL %q, got %q", i+1, testCase.expectedACL, metadata["X-Amz-Acl"])
+		}
+		if v, ok := testCase.metadata["Content-Type"]; ok && metadata["Content-Type"] != v {
+			t.Errorf("Test %d: Expected content type %s, got %s", i+1, v, metadata["Content-Type"])
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// admissionConfig limits the client operations served concurrently,
+// zero leaves the operations of a kind unlimited.
+type admissionConfig struct {
+	// MaxReads limits concurrent GET and HEAD requests.
+	MaxReads int `yaml:"max_reads"`
+	// MaxWrites limits concurrent requests of all other methods.
+	MaxWrites int `yaml:"max_writes"`
+	// Wait is how long an operation waits for a slot before it is
+	// rejected with SlowDown, zero rejects it right away.
+	Wait time.Duration `yaml:"wait"`
+}
+
+func (c admissionConfig) validate() error {
+	if c.MaxReads < 0 || c.MaxWrites < 0 {
+		return fmt.Errorf("admission limits must not be negative")
+	}
+	if c.Wait < 0 {
+		return fmt.Errorf("admission wait must not be negative")
+	}
+	return nil
+}
+
+// admissionControl bounds the client operations in flight, so that
+// traffic spikes cannot exhaust the memory and descriptors of radio and
+// overload its remotes.
+type admissionControl struct {
+	reads  chan struct{}
+	writes chan struct{}
+	wait   time.Duration
+}
+
+// newAdmissionControl returns the admission control of cfg, nil if no
+// limit is configured.
+func newAdmissionControl(cfg admissionConfig) *admissionControl {
+	if cfg.MaxReads <= 0 && cfg.MaxWrites <= 0 {
+		return nil
+	}
+	a := &admissionControl{wait: cfg.Wait}
+	if cfg.MaxReads > 0 {
+		a.reads = make(chan struct{}, cfg.MaxReads)
+	}
+	if cfg.MaxWrites > 0 {
+		a.writes = make(chan struct{}, cfg.MaxWrites)
+	}
+	return a
+}
+
+func admissionKind(method string) string {
+	if method == http.MethodGet || method == http.MethodHead {
+		return "read"
+	}
+	return "write"
+}
+
+// admit reserves a slot for an operation of kind, returning false if
+// none frees up in time. Callers release admitted slots with done.
+func (a *admissionControl) admit(ctx context.Context, kind string) bool {
+	slots := a.slots(kind)
+	if slots == nil {
+		inflightOperations.WithLabelValues(kind).Inc()
+		return true
+	}
+
+	select {
+	case slots <- struct{}{}:
+		inflightOperations.WithLabelValues(kind).Inc()
+		return true
+	default:
+	}
+	if a.wait > 0 {
+		timer := time.NewTimer(a.wait)
+		defer timer.Stop()
+		select {
+		case slots <- struct{}{}:
+			inflightOperations.WithLabelValues(kind).Inc()
+			return true
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+	}
+	rejectedOperations.WithLabelValues(kind).Inc()
+	return false
+}
+
+func (a *admissionControl) done(kind string) {
+	inflightOperations.WithLabelValues(kind).Dec()
+	if slots := a.slots(kind); slots != nil {
+		<-slots
+	}
+}
+
+func (a *admissionControl) slots(kind string) chan struct{} {
+	if kind == "read" {
+		return a.reads
+	}
+	return a.writes
+}
+
+// admissionHandler rejects client operations beyond the configured
+// limits with SlowDown, admin, health and metrics requests are always
+// served.
+type admissionHandler struct {
+	handler http.Handler
+}
+
+func setAdmissionHandler(h http.Handler) http.Handler {
+	return admissionHandler{handler: h}
+}
+
+func (h admissionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a := globalAdmission
+	if a == nil || strings.HasPrefix(r.URL.Path, minioReservedBucketPath) {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+
+	kind := admissionKind(r.Method)
+	if !a.admit(r.Context(), kind) {
+		if r.Method == http.MethodHead {
+			writeErrorResponseHeadersOnly(w, errorCodes.ToAPIErr(ErrSlowDown))
+		} else {
+			writeErrorResponse(r.Context(), w, errorCodes.ToAPIErr(ErrSlowDown), r.URL)
+		}
+		return
+	}
+	defer a.done(kind)
+	h.handler.ServeHTTP(w, r)
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Tests that operations beyond the admission limits are rejected with
+// SlowDown while reserved paths and unlimited kinds are served.
+func TestAdmissionHandler(t *testing.T) {
+	defer func(a *admissionControl) { globalAdmission = a }(globalAdmission)
+	globalAdmission = newAdmissionControl(admissionConfig{MaxReads: 1})
+
+	entered, release := make(chan struct{}), make(chan struct{})
+	handler := setAdmissionHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/bucket/blocked" {
+			entered <- struct{}{}
+			<-release
+		}
+	}))
+
+	// Hold the only read slot.
+	done := make(chan struct{})
+	go func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/bucket/blocked", nil))
+		close(done)
+	}()
+	<-entered
+
+	testCases := []struct {
+		method         string
+		path           string
+		expectedStatus int
+	}{
+		{http.MethodGet, "/bucket/object", http.StatusServiceUnavailable},
+		{http.MethodHead, "/bucket/object", http.StatusServiceUnavailable},
+		{http.MethodPut, "/bucket/object", http.StatusOK},
+		{http.MethodGet, healthCheckPathPrefix + healthCheckLivenessPath, http.StatusOK},
+	}
+
+	for i, testCase := range testCases {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(testCase.method, testCase.path, nil))
+		if w.Code != testCase.expectedStatus {
+			t.Errorf("Test %d: Expected status %d, got %d", i+1, testCase.expectedStatus, w.Code)
+		}
+	}
+
+	close(release)
+	<-done
+
+	// The slot is released once the operation completes.
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/bucket/object", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d after release, got %d", http.StatusOK, w.Code)
+	}
+}
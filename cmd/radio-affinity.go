@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+
+	xhttp "github.com/minio/radio/cmd/http"
+	"github.com/minio/radio/cmd/logger"
+)
+
+// affinityRule prefers a replica for the reads of the clients in the
+// given networks or naming one of the given regions in the
+// x-radio-region header.
+type affinityRule struct {
+	// Client networks in CIDR notation.
+	Networks []string `yaml:"networks"`
+	Regions  []string `yaml:"regions"`
+	// Index of the preferred replica.
+	Replica int `yaml:"replica"`
+}
+
+// readAffinity holds the parsed affinity rules, evaluated in order.
+type readAffinity struct {
+	rules []parsedAffinityRule
+}
+
+type parsedAffinityRule struct {
+	networks []*net.IPNet
+	regions  map[string]bool
+	replica  int
+}
+
+// newReadAffinity parses rules, returning nil without rules.
+func newReadAffinity(rules []affinityRule) (*readAffinity, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	a := &readAffinity{}
+	for i, rule := range rules {
+		if rule.Replica < 0 {
+			return nil, fmt.Errorf("affinity rule %d: invalid replica %d", i+1, rule.Replica)
+		}
+		if len(rule.Networks) == 0 && len(rule.Regions) == 0 {
+			return nil, fmt.Errorf("affinity rule %d: no networks nor regions", i+1)
+		}
+		parsed := parsedAffinityRule{regions: make(map[string]bool), replica: rule.Replica}
+		for _, cidr := range rule.Networks {
+			_, network, err := net.ParseCIDR(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("affinity rule %d: %w", i+1, err)
+			}
+			parsed.networks = append(parsed.networks, network)
+		}
+		for _, region := range rule.Regions {
+			parsed.regions[region] = true
+		}
+		a.rules = append(a.rules, parsed)
+	}
+	return a, nil
+}
+
+// preferred returns the replica preferred for the reads of the client
+// of ctx sending headers h, by the first matching rule, or -1 if no rule
+// matches. The x-radio-region header takes precedence over the client
+// address, a nil affinity prefers no replica.
+func (a *readAffinity) preferred(ctx context.Context, h http.Header) int {
+	if a == nil {
+		return -1
+	}
+	if region := h.Get(xhttp.RadioRegion); region != "" {
+		for _, rule := range a.rules {
+			if rule.regions[region] {
+				return rule.replica
+			}
+		}
+	}
+	host := logger.GetReqInfo(ctx).RemoteHost
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return -1
+	}
+	for _, rule := range a.rules {
+		for _, network := range rule.networks {
+			if network.Contains(ip) {
+				return rule.replica
+			}
+		}
+	}
+	return -1
+}
+
+// preferReplica moves preferred to the front of replicas if it holds
+// the object, replicas are otherwise returned as is.
+func preferReplica(replicas []int, preferred int) []int {
+	for i, index := range replicas {
+		if index != preferred {
+			continue
+		}
+		ordered := append([]int{preferred}, replicas[:i]...)
+		return append(ordered, replicas[i+1:]...)
+	}
+	return replicas
+}
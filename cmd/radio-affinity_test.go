@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	xhttp "github.com/minio/radio/cmd/http"
+	"github.com/minio/radio/cmd/logger"
+)
+
+// Tests that reads prefer the replica of the first affinity rule
+// matching the client region or address.
+func TestReadAffinity(t *testing.T) {
+	for _, rules := range [][]affinityRule{
+		{{Networks: []string{"10.0.0.0/8"}, Replica: -1}},
+		{{Networks: []string{"10.0.0.0"}, Replica: 1}},
+		{{Replica: 1}},
+	} {
+		if _, err := newReadAffinity(rules); err == nil {
+			t.Errorf("%+v: expected invalid rules", rules)
+		}
+	}
+
+	affinity, err := newReadAffinity([]affinityRule{
+		{Networks: []string{"10.1.0.0/16"}, Regions: []string{"us-east"}, Replica: 0},
+		{Networks: []string{"10.0.0.0/8", "fd00::/8"}, Regions: []string{"eu-west"}, Replica: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		remoteHost string
+		region     string
+		expected   int
+	}{
+		{"10.1.2.3", "", 0},
+		{"10.2.3.4:9000", "", 1},
+		{"[fd00::1]:9000", "", 1},
+		{"192.168.1.1", "", -1},
+		// The region header takes precedence.
+		{"10.1.2.3", "eu-west", 1},
+		{"10.1.2.3", "ap-south", 0},
+		{"", "", -1},
+	}
+	for i, testCase := range testCases {
+		ctx := logger.SetReqInfo(context.Background(), &logger.ReqInfo{RemoteHost: testCase.remoteHost})
+		h := http.Header{}
+		if testCase.region != "" {
+			h.Set(xhttp.RadioRegion, testCase.region)
+		}
+		if got := affinity.preferred(ctx, h); got != testCase.expected {
+			t.Errorf("Test %d: expected replica %d, got %d", i+1, testCase.expected, got)
+		}
+	}
+
+	var none *readAffinity
+	if got := none.preferred(context.Background(), http.Header{}); got != -1 {
+		t.Errorf("Expected no preferred replica without rules, got %d", got)
+	}
+	if got := fmt.Sprint(preferReplica([]int{0, 2, 1}, 1)); got != "[1 0 2]" {
+		t.Errorf("Expected replica 1 first, got %s", got)
+	}
+	// Replicas not holding the object are not read.
+	if got := fmt.Sprint(preferReplica([]int{0, 2}, 1)); got != "[0 2]" {
+		t.Errorf("Expected replicas unchanged, got %s", got)
+	}
+}
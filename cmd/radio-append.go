@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// AppendObject appends data to the end of an existing object by writing
+// the existing content followed by data as a new version of the object
+// on all replicas, creating the object if it does not exist. Only
+// buckets configured with append enabled support it.
+func (l *radioObjects) AppendObject(ctx context.Context, bucket, object string, r *PutObjReader, opts ObjectOptions) (objInfo ObjectInfo, err error) {
+	if err := l.operationAllowed(operationAppendObject); err != nil {
+		return objInfo, err
+	}
+	if object, err = l.newObjectName(bucket, object); err != nil {
+		return objInfo, err
+	}
+
+	ctx, cancel := withDeadline(ctx, l.timeouts.Write)
+	defer cancel()
+
+	rs3s, ok := l.mirrorClients[bucket]
+	if !ok {
+		return objInfo, BucketNotFound{Bucket: bucket}
+	}
+	rs3s = rs3s.forUser(ctx).forObject(object)
+	if !rs3s.append {
+		return objInfo, NotImplemented{}
+	}
+
+	data := r.Reader
+
+	// Lock the object for the whole read-modify-write.
+	objectLock := l.NewNSLock(ctx, bucket, object)
+	if err = objectLock.GetLock(globalObjectTimeout); err != nil {
+		return objInfo, err
+	}
+	defer objectLock.Unlock()
+
+	info, err := l.getObjectInfo(ctx, bucket, object, opts)
+	if _, ok := err.(ObjectNotFound); err != nil && !ok {
+		return objInfo, err
+	}
+	if opts.CheckPutPrecondFn != nil && opts.CheckPutPrecondFn(info, err == nil) {
+		return objInfo, PreConditionFailed{}
+	}
+	if err != nil {
+		return l.putObject(ctx, bucket, object, data, data.Size(),
+			data.MD5Base64String(), data.SHA256HexString(), opts)
+	}
+
+	// Carry over the metadata of the existing object, metadata sent
+	// along with the appended data takes precedence.
+	metadata := copyableMetadata(info.UserDefined)
+	for k, v := range opts.UserDefined {
+		metadata[http.CanonicalHeaderKey(k)] = v
+	}
+	opts.UserDefined = metadata
+
+	size := int64(-1)
+	if data.Size() >= 0 {
+		size = info.Size + data.Size()
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(readReplicaRange(ctx, rs3s.clnts[info.ReplicaIndex],
+			object, 0, info.Size, opts, l.timeouts.ReadStall, pw))
+	}()
+	defer pr.Close()
+
+	return l.putObject(ctx, bucket, object, io.MultiReader(pr, data), size, "", "", opts)
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/minio/minio/pkg/hash"
+)
+
+// Tests that AppendObject writes the existing content followed by the
+// appended data to all replicas, creating missing objects.
+func TestAppendObject(t *testing.T) {
+	testCases := []struct {
+		existing     string
+		exists       bool
+		append       bool
+		shouldPass   bool
+		expectedData string
+	}{
+		// Existing object.
+		{"head", true, true, true, "headtail"},
+		// Empty object.
+		{"", true, true, true, "tail"},
+		// Missing object, created.
+		{"", false, true, true, "tail"},
+		// Append not enabled for the bucket.
+		{"head", true, false, false, "head"},
+	}
+
+	for i, testCase := range testCases {
+		remotes, clnts, shutdown := newTestRemotes(t, 2)
+		defer shutdown()
+		for _, remote := range remotes {
+			if testCase.exists {
+				remote.putTaggedObject("object", testCase.existing, "v1", time.Now())
+			}
+		}
+		l := &radioObjects{
+			nsMutex:       newNSLock(false),
+			mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts, append: testCase.append}},
+		}
+
+		data := []byte("tail")
+		reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), "", "", int64(len(data)), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		objInfo, err := l.AppendObject(context.Background(), "bucket", "object", NewPutObjReader(reader, nil, nil), ObjectOptions{})
+		if testCase.shouldPass && err != nil {
+			t.Fatalf("Test %d: Unexpected error %v", i+1, err)
+		}
+		if !testCase.shouldPass {
+			if _, ok := err.(NotImplemented); !ok {
+				t.Fatalf("Test %d: Expected NotImplemented, got %v", i+1, err)
+			}
+		} else if objInfo.Size != int64(len(testCase.expectedData)) {
+			t.Errorf("Test %d: Expected size %d, got %d", i+1, len(testCase.expectedData), objInfo.Size)
+		}
+		for index, remote := range remotes {
+			if data, _, ok := remote.object("object"); !ok || data != testCase.expectedData {
+				t.Errorf("Test %d: Expected replica %d to hold %q, got %q", i+1, index, testCase.expectedData, data)
+			}
+		}
+	}
+}
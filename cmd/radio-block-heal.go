@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"context"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io"
+
+	humanize "github.com/dustin/go-humanize"
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/minio-go/v6/pkg/encrypt"
+	"github.com/minio/minio/pkg/sync/errgroup"
+	"github.com/minio/radio/cmd/logger"
+)
+
+const (
+	// Defaults of the block heal sizes.
+	defaultBlockHealMinSize   = 64 * humanize.MiByte
+	defaultBlockHealBlockSize = 16 * humanize.MiByte
+	// Parts but the last of multipart uploads are at least this big,
+	// and uploads hold at most maxBlockHealParts parts.
+	minBlockHealBlockSize = 5 * humanize.MiByte
+	maxBlockHealParts     = 10000
+)
+
+// blockHealConfig configures heals rewriting only the differing blocks
+// of replicas already holding a copy of the same size as the source.
+type blockHealConfig struct {
+	Enable bool `yaml:"enable"`
+	// MinSize is the size such as 1GiB of the smallest objects healed
+	// block by block, smaller objects are copied whole.
+	MinSize string `yaml:"min_size"`
+	// BlockSize is the size of the compared blocks, at least 5MiB.
+	BlockSize string `yaml:"block_size"`
+}
+
+// blockHealer heals replicas by comparing the checksums of the blocks
+// of their copy with those of the source copy, then rewriting the copy
+// as a multipart upload whose parts are copied from the replica itself
+// for equal blocks and from the source for differing blocks. Only the
+// differing blocks are transferred between replicas, while both copies
+// are read once to be compared.
+type blockHealer struct {
+	minSize   int64
+	blockSize int64
+}
+
+// errBlockHealSkipped is returned by blockHealer.heal for objects which
+// are not healed block by block.
+var errBlockHealSkipped = errors.New("object not healed block by block")
+
+// newBlockHealer returns the block healer configured by cfg, nil if
+// block heals are not enabled.
+func newBlockHealer(cfg blockHealConfig) (*blockHealer, error) {
+	if !cfg.Enable {
+		return nil, nil
+	}
+	b := &blockHealer{minSize: defaultBlockHealMinSize, blockSize: defaultBlockHealBlockSize}
+	if cfg.MinSize != "" {
+		size, err := humanize.ParseBytes(cfg.MinSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid block heal min_size %q: %w", cfg.MinSize, err)
+		}
+		b.minSize = int64(size)
+	}
+	if cfg.BlockSize != "" {
+		size, err := humanize.ParseBytes(cfg.BlockSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid block heal block_size %q: %w", cfg.BlockSize, err)
+		}
+		b.blockSize = int64(size)
+	}
+	if b.blockSize < minBlockHealBlockSize {
+		return nil, fmt.Errorf("block heal block_size must be at least %s", humanize.IBytes(minBlockHealBlockSize))
+	}
+	return b, nil
+}
+
+// blockSizeOf returns the size of the blocks of an object of the given
+// size, grown for the object to fit in a multipart upload.
+func (b *blockHealer) blockSizeOf(size int64) int64 {
+	return healPartSize(b.blockSize, size)
+}
+
+// healPartSize returns partSize grown for an object of the given size
+// to fit in a multipart upload.
+func healPartSize(partSize, size int64) int64 {
+	if min := (size + maxBlockHealParts - 1) / maxBlockHealParts; partSize < min {
+		partSize = min
+	}
+	return partSize
+}
+
+// heal rewrites the differing blocks of the copy of object held by dst
+// from the copy held by src, returning errBlockHealSkipped without
+// writing anything if dst holds no copy of the same size or the object
+// is smaller than minSize.
+func (b *blockHealer) heal(ctx context.Context, src, dst bucketClient, object string) error {
+	srcKey, dstKey := src.objectKey(object), dst.objectKey(object)
+	srcInfo, err := src.StatObjectWithContext(ctx, src.Bucket, srcKey, miniogo.StatObjectOptions{})
+	if err != nil {
+		return err
+	}
+	dstInfo, err := dst.StatObjectWithContext(ctx, dst.Bucket, dstKey, miniogo.StatObjectOptions{})
+	if err != nil || srcInfo.Size < b.minSize || dstInfo.Size != srcInfo.Size {
+		return errBlockHealSkipped
+	}
+
+	blockSize := b.blockSizeOf(srcInfo.Size)
+	var srcSums, dstSums [][md5.Size]byte
+	g := errgroup.WithNErrs(2)
+	g.Go(func() (err error) {
+		srcSums, err = blockChecksums(ctx, src, srcKey, srcInfo, blockSize)
+		return err
+	}, 0)
+	g.Go(func() (err error) {
+		dstSums, err = blockChecksums(ctx, dst, dstKey, dstInfo, blockSize)
+		return err
+	}, 1)
+	for _, err := range g.Wait() {
+		if err != nil {
+			return err
+		}
+	}
+
+	uploadID, err := newHealUpload(ctx, src, dst, object, srcInfo)
+	if err != nil {
+		return err
+	}
+
+	parts := make([]miniogo.CompletePart, len(srcSums))
+	var differing int
+	for i := range srcSums {
+		offset := int64(i) * blockSize
+		length := blockSize
+		if offset+length > srcInfo.Size {
+			length = srcInfo.Size - offset
+		}
+		if srcSums[i] == dstSums[i] {
+			// The copy being replaced is the source of its equal
+			// blocks, as long as it is not overwritten meanwhile.
+			parts[i], err = dst.CopyObjectPartWithContext(ctx, dst.Bucket, dstKey, dst.Bucket, dstKey,
+				uploadID, i+1, offset, length, map[string]string{"x-amz-copy-source-if-match": "\"" + dstInfo.ETag + "\""})
+		} else {
+			differing++
+			parts[i], err = copyBlock(ctx, src, dst, srcKey, dstKey, srcInfo.ETag, uploadID, i+1, offset, length)
+		}
+		if err != nil {
+			break
+		}
+	}
+	if err == nil {
+		_, err = dst.CompleteMultipartUploadWithContext(ctx, dst.Bucket, dstKey, uploadID, parts)
+	}
+	if err != nil {
+		if aerr := dst.AbortMultipartUploadWithContext(ctx, dst.Bucket, dstKey, uploadID); aerr != nil {
+			logger.LogIf(ctx, aerr)
+		}
+		return err
+	}
+	logger.LogInfo(ctx, "healed %d of %d blocks of %s on %s/%s", differing, len(parts), object,
+		dst.EndpointURL().Host, dst.Bucket)
+	return nil
+}
+
+// newHealUpload starts the multipart upload of the copy of object held
+// by dst, with the metadata of the copy stated as info held by src.
+func newHealUpload(ctx context.Context, src, dst bucketClient, object string, info miniogo.ObjectInfo) (string, error) {
+	metadata, err := healObjectMetadata(ctx, src, object, info)
+	if err != nil {
+		return "", err
+	}
+	opts := miniogo.PutObjectOptions{UserMetadata: dst.storageClassMetadata(metadata)}
+	uploadID, err := dst.NewMultipartUpload(dst.Bucket, dst.objectKey(object), opts)
+	if dst.downgradeStorageClass(ctx, opts.UserMetadata, err) {
+		opts.UserMetadata = dst.storageClassMetadata(opts.UserMetadata)
+		uploadID, err = dst.NewMultipartUpload(dst.Bucket, dst.objectKey(object), opts)
+	}
+	return uploadID, err
+}
+
+// blockChecksums returns the MD5 checksums of the blocks of blockSize
+// bytes of the copy of key stated as info held by clnt.
+func blockChecksums(ctx context.Context, clnt bucketClient, key string, info miniogo.ObjectInfo, blockSize int64) ([][md5.Size]byte, error) {
+	opts := miniogo.GetObjectOptions{}
+	if err := opts.SetMatchETag(info.ETag); err != nil {
+		return nil, err
+	}
+	reader, _, _, err := clnt.GetObjectWithContext(ctx, clnt.Bucket, key, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	sums := make([][md5.Size]byte, 0, (info.Size+blockSize-1)/blockSize)
+	for remaining := info.Size; remaining > 0; remaining -= blockSize {
+		length := blockSize
+		if remaining < length {
+			length = remaining
+		}
+		h := md5.New()
+		if _, err = io.CopyN(h, reader, length); err != nil {
+			return nil, err
+		}
+		var sum [md5.Size]byte
+		copy(sum[:], h.Sum(nil))
+		sums = append(sums, sum)
+	}
+	return sums, nil
+}
+
+// copyBlock uploads the block of length bytes at offset of the copy of
+// srcKey tagged etag held by src as part partID of uploadID on dst.
+func copyBlock(ctx context.Context, src, dst bucketClient, srcKey, dstKey, etag, uploadID string,
+	partID int, offset, length int64) (miniogo.CompletePart, error) {
+	opts := miniogo.GetObjectOptions{}
+	if err := opts.SetMatchETag(etag); err != nil {
+		return miniogo.CompletePart{}, err
+	}
+	if err := opts.SetRange(offset, offset+length-1); err != nil {
+		return miniogo.CompletePart{}, err
+	}
+	reader, _, _, err := src.GetObjectWithContext(ctx, src.Bucket, srcKey, opts)
+	if err != nil {
+		return miniogo.CompletePart{}, err
+	}
+	defer reader.Close()
+	part, err := dst.PutObjectPartWithContext(ctx, dst.Bucket, dstKey, uploadID, partID, reader, length, "", "", nil)
+	if err != nil {
+		return miniogo.CompletePart{}, err
+	}
+	return miniogo.CompletePart{PartNumber: part.PartNumber, ETag: part.ETag}, nil
+}
+
+// healCopy heals the copy of object held by dst from the copy held by
+// src, block by block if enabled, falling back to copying the object
+// whole for objects not healed block by block or remotes failing to. A
+// nil healSys copies objects whole.
+func (h *healSys) healCopy(ctx context.Context, src, dst bucketClient, object string, sse encrypt.ServerSide) error {
+	if h.healBlocks(ctx, src, dst, object, sse) {
+		return nil
+	}
+	return healObjectCopy(ctx, src, dst, object, sse)
+}
+
+// healBlocks heals the copy of object held by dst block by block if
+// enabled, returning false if it is left to be copied whole.
+func (h *healSys) healBlocks(ctx context.Context, src, dst bucketClient, object string, sse encrypt.ServerSide) bool {
+	if h == nil || h.blockHeal == nil || sse != nil {
+		// Blocks of SSE-C encrypted copies cannot be copied within
+		// a replica without the key.
+		return false
+	}
+	err := h.blockHeal.heal(ctx, src, dst, object)
+	if err != nil && err != errBlockHealSkipped {
+		logger.LogIf(ctx, fmt.Errorf("block heal of %s on %s/%s failed, copying it whole: %w",
+			object, dst.EndpointURL().Host, dst.Bucket, err))
+	}
+	return err == nil
+}
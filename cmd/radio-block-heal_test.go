@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	humanize "github.com/dustin/go-humanize"
+)
+
+// Tests that heals rewrite only the differing blocks of replicas holding
+// a copy of the same size, and copy objects whole otherwise.
+func TestBlockHeal(t *testing.T) {
+	for _, cfg := range []blockHealConfig{
+		{Enable: true, MinSize: "lots"},
+		{Enable: true, BlockSize: "1MiB"},
+	} {
+		if _, err := newBlockHealer(cfg); err == nil {
+			t.Errorf("%+v: expected an invalid configuration", cfg)
+		}
+	}
+	if b, err := newBlockHealer(blockHealConfig{BlockSize: "1MiB"}); b != nil || err != nil {
+		t.Errorf("Expected no block healer while disabled, got %v (%v)", b, err)
+	}
+	b, err := newBlockHealer(blockHealConfig{Enable: true, MinSize: "10MiB", BlockSize: "5MiB"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blockSize := b.blockSizeOf(100 * humanize.GiByte); blockSize != 10737419 {
+		t.Errorf("Expected blocks grown to fit 10000 parts, got %d", blockSize)
+	}
+
+	const blockSize = 5 * humanize.MiByte
+	data := bytes.Repeat([]byte("0123456789abcdef"), 3*blockSize/16)
+	corrupted := append([]byte(nil), data...)
+	copy(corrupted[blockSize+7:], "corrupted")
+
+	testCases := []struct {
+		dst      []byte
+		noCopy   bool
+		received int
+	}{
+		// A single corrupted block is transferred.
+		{corrupted, false, blockSize},
+		// Remotes without UploadPartCopy are healed whole.
+		{corrupted, true, len(data)},
+		// Copies of another size are replaced whole.
+		{data[:2*blockSize], false, len(data)},
+		{nil, false, len(data)},
+		// Objects smaller than min_size are copied whole.
+		{corrupted[:blockSize], false, blockSize},
+	}
+	h := &healSys{blockHeal: b}
+	for i, testCase := range testCases {
+		srcData := data
+		if len(testCase.dst) == blockSize {
+			srcData = data[:blockSize]
+		}
+		remotes, clnts, shutdown := newTestRemotes(t, 2)
+		remotes[0].putObject("object", string(srcData), map[string]string{globalRadioTagKey: "v2"})
+		if testCase.dst != nil {
+			remotes[1].putObject("object", string(testCase.dst), map[string]string{globalRadioTagKey: "v1"})
+		}
+		if testCase.noCopy {
+			remotes[1].setHook(func(w http.ResponseWriter, r *http.Request) bool {
+				if r.Header.Get("X-Amz-Copy-Source") == "" || r.URL.Query().Get("partNumber") == "" {
+					return false
+				}
+				writeTestRemoteError(w, r, http.StatusNotImplemented, "NotImplemented")
+				return true
+			})
+		}
+
+		if err = h.healCopy(context.Background(), clnts[0], clnts[1], "object", nil); err != nil {
+			t.Errorf("Test %d: expected the replica healed, got %v", i+1, err)
+		}
+		got, header, _ := remotes[1].object("object")
+		if got != string(srcData) || header.Get(globalRadioTagKey) != "v2" {
+			t.Errorf("Test %d: expected the copy of the source, got tag %q", i+1, header.Get(globalRadioTagKey))
+		}
+		if received := remotes[1].bytesReceived(); received != testCase.received {
+			t.Errorf("Test %d: expected %d bytes transferred, got %d", i+1, testCase.received, received)
+		}
+		shutdown()
+	}
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+)
+
+// resolveBucketAliases returns buckets keyed by their client facing
+// name, which is the alias of a bucket if set and its configuration key
+// otherwise. Client facing names must be valid and unique.
+func resolveBucketAliases(buckets map[string]bucketConfig) (map[string]bucketConfig, error) {
+	keys := make([]string, 0, len(buckets))
+	for key := range buckets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	resolved := make(map[string]bucketConfig, len(buckets))
+	owners := make(map[string]string, len(buckets))
+	for _, key := range keys {
+		cfg := buckets[key]
+		name := key
+		if cfg.Alias != "" {
+			if !IsValidBucketName(cfg.Alias) {
+				return nil, fmt.Errorf("bucket %s: invalid alias %q", key, cfg.Alias)
+			}
+			name = cfg.Alias
+		}
+		if owner, ok := owners[name]; ok {
+			return nil, fmt.Errorf("bucket %s: name %q is already used by bucket %s", key, name, owner)
+		}
+		owners[name] = key
+		resolved[name] = cfg
+	}
+	return resolved, nil
+}
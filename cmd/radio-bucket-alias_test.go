@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestResolveBucketAliases(t *testing.T) {
+	testCases := []struct {
+		buckets    map[string]bucketConfig
+		shouldPass bool
+		expected   []string
+	}{
+		// No aliases.
+		{map[string]bucketConfig{"bucket1": {}, "bucket2": {}}, true, []string{"bucket1", "bucket2"}},
+		// Aliased bucket addressed by its alias only.
+		{map[string]bucketConfig{"bucket1": {Alias: "data"}, "bucket2": {}}, true, []string{"bucket2", "data"}},
+		// Aliases swapping configuration keys.
+		{map[string]bucketConfig{"bucket1": {Alias: "bucket2"}, "bucket2": {Alias: "bucket1"}}, true,
+			[]string{"bucket1", "bucket2"}},
+		// Alias clashing with another alias.
+		{map[string]bucketConfig{"bucket1": {Alias: "data"}, "bucket2": {Alias: "data"}}, false, nil},
+		// Alias clashing with a configuration key.
+		{map[string]bucketConfig{"bucket1": {Alias: "bucket2"}, "bucket2": {}}, false, nil},
+		// Invalid alias.
+		{map[string]bucketConfig{"bucket1": {Alias: "Data_"}}, false, nil},
+	}
+
+	for i, testCase := range testCases {
+		resolved, err := resolveBucketAliases(testCase.buckets)
+		if testCase.shouldPass && err != nil {
+			t.Errorf("Test %d: Unexpected error %v", i+1, err)
+			continue
+		}
+		if !testCase.shouldPass {
+			if err == nil {
+				t.Errorf("Test %d: Expected an error", i+1)
+			}
+			continue
+		}
+		var names []string
+		for name := range resolved {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if !reflect.DeepEqual(names, testCase.expected) {
+			t.Errorf("Test %d: Expected buckets %v, got %v", i+1, testCase.expected, names)
+		}
+	}
+}
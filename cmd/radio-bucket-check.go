@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/radio/cmd/logger"
+)
+
+// isAuthErrorCode returns true for S3 error codes indicating that the
+// configured remote credentials are unusable.
+func isAuthErrorCode(code string) bool {
+	switch code {
+	case "AccessDenied", "InvalidAccessKeyId", "SignatureDoesNotMatch",
+		"InvalidToken", "ExpiredToken":
+		return true
+	}
+	return false
+}
+
+// checkMirrorConsistency verifies that all remotes of a mirrored bucket
+// exist and look like they were initialized together, i.e. they are
+// either all empty or all populated. Credential failures are always
+// fatal, any other inconsistency is only logged unless strict is set.
+func checkMirrorConsistency(bucket string, clnts []bucketClient, strict bool) error {
+	var empty, populated []string
+	for _, clnt := range clnts {
+		remote := clnt.EndpointURL().Host + "/" + clnt.Bucket
+
+		found, err := clnt.BucketExists(clnt.Bucket)
+		if err != nil {
+			if isAuthErrorCode(miniogo.ToErrorResponse(err).Code) {
+				return fmt.Errorf("bucket %s: remote %s rejected credentials: %w", bucket, remote, err)
+			}
+			if err = mirrorInconsistent(strict, "bucket %s: unable to reach remote %s: %v", bucket, remote, err); err != nil {
+				return err
+			}
+			continue
+		}
+		if !found {
+			if err = mirrorInconsistent(strict, "bucket %s: remote bucket %s does not exist", bucket, remote); err != nil {
+				return err
+			}
+			continue
+		}
+
+		result, err := clnt.listObjectsV2(clnt.Prefix, "", false, "", 1, "")
+		if err != nil {
+			if isAuthErrorCode(miniogo.ToErrorResponse(err).Code) {
+				return fmt.Errorf("bucket %s: remote %s rejected credentials: %w", bucket, remote, err)
+			}
+			if err = mirrorInconsistent(strict, "bucket %s: unable to list remote %s: %v", bucket, remote, err); err != nil {
+				return err
+			}
+			continue
+		}
+		if len(result.Contents) == 0 && len(result.CommonPrefixes) == 0 {
+			empty = append(empty, remote)
+		} else {
+			populated = append(populated, remote)
+		}
+	}
+
+	if len(empty) > 0 && len(populated) > 0 {
+		return mirrorInconsistent(strict, "bucket %s: remotes %v are empty while %v have objects, they may not have been initialized as a mirror",
+			bucket, empty, populated)
+	}
+	return nil
+}
+
+func mirrorInconsistent(strict bool, format string, args ...interface{}) error {
+	if strict {
+		return fmt.Errorf(format, args...)
+	}
+	logger.Info("WARNING: "+format, args...)
+	return nil
+}
+
+// remoteIdentity returns the physical bucket addressed by remote, with
+// the endpoint normalized so that equivalent spellings compare equal.
+func remoteIdentity(remote remoteConfig) string {
+	endpoint := remote.Endpoint
+	if u, err := url.Parse(remote.Endpoint); err == nil && u.Host != "" {
+		host, port := strings.ToLower(u.Hostname()), u.Port()
+		if port == "" {
+			port = "80"
+			if strings.EqualFold(u.Scheme, "https") {
+				port = "443"
+			}
+		}
+		endpoint = net.JoinHostPort(host, port)
+	}
+	return endpoint + "/" + strings.ToLower(remote.Bucket)
+}
+
+// checkDistinctRemotes verifies that no two remotes of bucket point to
+// the same physical bucket, which would leave the bucket without any
+// redundancy. Duplicates are only logged if allowShared is set.
+func checkDistinctRemotes(bucket string, remotes []remoteConfig, allowShared bool) error {
+	seen := make(map[string]int, len(remotes))
+	for index, remote := range remotes {
+		id := remoteIdentity(remote)
+		first, ok := seen[id]
+		if !ok {
+			seen[id] = index
+			continue
+		}
+		if !allowShared {
+			return fmt.Errorf("bucket %s: remotes %d and %d both point to %s", bucket, first, index, id)
+		}
+		logger.Info("WARNING: bucket %s: remotes %d and %d both point to %s", bucket, first, index, id)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"net/http"
+	"testing"
+)
+
+// Tests that the startup check of mirrored buckets fails on credential
+// errors, and on missing or inconsistently populated remotes only if
+// strict.
+func TestCheckMirrorConsistency(t *testing.T) {
+	denied := func(w http.ResponseWriter, r *http.Request) bool {
+		writeTestRemoteError(w, r, http.StatusForbidden, "AccessDenied")
+		return true
+	}
+	testCases := []struct {
+		name      string
+		objects   []int
+		missing   int
+		hook      func(w http.ResponseWriter, r *http.Request) bool
+		strictErr bool
+		err       bool
+	}{
+		{name: "empty", missing: -1},
+		{name: "populated", objects: []int{0, 1, 2}, missing: -1},
+		{name: "partially populated", objects: []int{0, 1}, missing: -1, strictErr: true},
+		{name: "missing bucket", missing: 2, strictErr: true},
+		{name: "access denied", missing: -1, hook: denied, strictErr: true, err: true},
+	}
+	for _, testCase := range testCases {
+		for _, strict := range []bool{false, true} {
+			remotes, clnts, shutdown := newTestRemotes(t, 3)
+			for _, i := range testCase.objects {
+				remotes[i].putObject("object", "data", nil)
+			}
+			if testCase.missing >= 0 {
+				clnts[testCase.missing].Bucket = "missing"
+			}
+			remotes[1].setHook(testCase.hook)
+
+			err := checkMirrorConsistency("bucket", clnts, strict)
+			shutdown()
+			if wantErr := testCase.err || (strict && testCase.strictErr); (err != nil) != wantErr {
+				t.Errorf("%s, strict %v: expected error %v, got %v", testCase.name, strict, wantErr, err)
+			}
+		}
+	}
+}
+
+// Tests that remotes spelling the same physical bucket differently are
+// detected.
+func TestCheckDistinctRemotes(t *testing.T) {
+	testCases := []struct {
+		remotes []remoteConfig
+		shared  bool
+	}{
+		{[]remoteConfig{
+			{Endpoint: "http://minio1:9000", Bucket: "bucket"},
+			{Endpoint: "http://minio2:9000", Bucket: "bucket"},
+		}, false},
+		{[]remoteConfig{
+			{Endpoint: "http://minio1:9000", Bucket: "bucket1"},
+			{Endpoint: "http://minio1:9000", Bucket: "bucket2"},
+		}, false},
+		{[]remoteConfig{
+			{Endpoint: "http://minio1:9000", Bucket: "bucket"},
+			{Endpoint: "http://MINIO1:9000/", Bucket: "bucket", KeyPrefix: "tenant/"},
+		}, true},
+		{[]remoteConfig{
+			{Endpoint: "https://s3.amazonaws.com", Bucket: "bucket"},
+			{Endpoint: "https://s3.amazonaws.com:443", Bucket: "bucket"},
+		}, true},
+		{[]remoteConfig{
+			{Endpoint: "http://s3.amazonaws.com", Bucket: "bucket"},
+			{Endpoint: "https://s3.amazonaws.com", Bucket: "bucket"},
+		}, false},
+	}
+	for i, testCase := range testCases {
+		err := checkDistinctRemotes("radiobucket", testCase.remotes, false)
+		if shared := err != nil; shared != testCase.shared {
+			t.Errorf("Test %d: expected shared %v, got %v", i+1, testCase.shared, err)
+		}
+		if err = checkDistinctRemotes("radiobucket", testCase.remotes, true); err != nil {
+			t.Errorf("Test %d: unexpected error with shared remotes allowed: %v", i+1, err)
+		}
+	}
+}
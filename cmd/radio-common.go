@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"time"
 
@@ -286,17 +287,29 @@ func ErrorRespToObjectError(err error, params ...string) error {
 		object = params[1]
 	}
 
-	if xnet.IsNetworkOrHostDown(err) {
+	// Errors of replicas are converted by the remote error they wrap,
+	// errors not interpreted are returned with their replica.
+	remoteErr := err
+	var rerr replicaError
+	if errors.As(err, &rerr) {
+		remoteErr = rerr.Err
+	}
+
+	if xnet.IsNetworkOrHostDown(remoteErr) {
 		return BackendDown{}
 	}
 
-	minioErr, ok := err.(minio.ErrorResponse)
+	minioErr, ok := remoteErr.(minio.ErrorResponse)
 	if !ok {
 		// We don't interpret non MinIO errors. As minio errors will
 		// have StatusCode to help to convert to object errors.
 		return err
 	}
 
+	if isThrottleErr(minioErr) {
+		return BackendThrottled{Bucket: bucket, Object: object}
+	}
+
 	switch minioErr.Code {
 	case "BucketAlreadyOwnedByYou":
 		err = BucketAlreadyOwnedByYou{}
@@ -331,6 +344,8 @@ func ErrorRespToObjectError(err error, params ...string) error {
 		err = InvalidUploadID{}
 	case "EntityTooSmall":
 		err = PartTooSmall{}
+	case "InvalidStorageClass":
+		err = InvalidStorageClass{}
 	}
 
 	return err
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	minio "github.com/minio/minio-go/v6"
+)
+
+// Tests that part size, etag and modification time survive conversion.
+func TestFromMinioClientObjectPart(t *testing.T) {
+	lastModified := time.Date(2020, time.January, 10, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		part     minio.ObjectPart
+		expected PartInfo
+	}{
+		{
+			part: minio.ObjectPart{
+				PartNumber:   1,
+				ETag:         "\"5d41402abc4b2a76b9719d911017c592\"",
+				Size:         5 * 1024 * 1024,
+				LastModified: lastModified,
+			},
+			expected: PartInfo{
+				PartNumber:   1,
+				ETag:         "5d41402abc4b2a76b9719d911017c592",
+				Size:         5 * 1024 * 1024,
+				LastModified: lastModified,
+			},
+		},
+		{
+			part: minio.ObjectPart{
+				PartNumber: 10000,
+				ETag:       "7e1ab1a1d1ac1a4b6b1f1c3a1e0e0f1a",
+				Size:       1,
+			},
+			expected: PartInfo{
+				PartNumber: 10000,
+				ETag:       "7e1ab1a1d1ac1a4b6b1f1c3a1e0e0f1a",
+				Size:       1,
+			},
+		},
+	}
+
+	for i, testCase := range testCases {
+		pi := FromMinioClientObjectPart(testCase.part)
+		if pi.PartNumber != testCase.expected.PartNumber {
+			t.Errorf("Test %d: Expected part number %d, got %d", i+1, testCase.expected.PartNumber, pi.PartNumber)
+		}
+		if pi.ETag != testCase.expected.ETag {
+			t.Errorf("Test %d: Expected etag %s, got %s", i+1, testCase.expected.ETag, pi.ETag)
+		}
+		if pi.Size != testCase.expected.Size {
+			t.Errorf("Test %d: Expected size %d, got %d", i+1, testCase.expected.Size, pi.Size)
+		}
+		if !pi.LastModified.Equal(testCase.expected.LastModified) {
+			t.Errorf("Test %d: Expected last modified %s, got %s", i+1, testCase.expected.LastModified, pi.LastModified)
+		}
+	}
+}
+
+// Tests that listings page over keys holding url escapes, minio-go
+// unescaping the keys and markers of the url encoded remote listings.
+func TestListObjectsEncodedKeys(t *testing.T) {
+	remotes, clnts, shutdown := newTestRemotes(t, 2)
+	defer shutdown()
+	for _, remote := range remotes {
+		for _, key := range []string{"a+b", "a%2Fb", "a b", "a/b"} {
+			remote.putObject(key, key, map[string]string{"X-Amz-Meta-Radio-Tag": "tag"})
+		}
+	}
+	legacy := make([]bucketClient, len(clnts))
+	for i, clnt := range clnts {
+		legacy[i] = clnt
+		legacy[i].Compat = compatLegacy
+	}
+	l := &radioObjects{
+		mirrorClients: map[string]mirrorConfig{
+			"bucket": {clnts: clnts},
+			"legacy": {clnts: legacy},
+		},
+	}
+	expected := []string{"a b", "a%2Fb", "a+b", "a/"}
+	ctx := context.Background()
+
+	var keys []string
+	marker := ""
+	for i := 0; i < 2*len(expected); i++ {
+		loi, err := l.ListObjects(ctx, "bucket", "", marker, "/", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, oi := range loi.Objects {
+			keys = append(keys, oi.Name)
+		}
+		keys = append(keys, loi.Prefixes...)
+		if !loi.IsTruncated {
+			break
+		}
+		marker = loi.NextMarker
+	}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("ListObjects: expected %q, got %q", expected, keys)
+	}
+
+	for _, bucket := range []string{"bucket", "legacy"} {
+		keys = nil
+		token := ""
+		for i := 0; i < 2*len(expected); i++ {
+			loi, err := l.ListObjectsV2(ctx, bucket, "", token, "/", 1, false, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, oi := range loi.Objects {
+				keys = append(keys, oi.Name)
+			}
+			keys = append(keys, loi.Prefixes...)
+			if !loi.IsTruncated {
+				break
+			}
+			token = loi.NextContinuationToken
+		}
+		if !reflect.DeepEqual(keys, expected) {
+			t.Errorf("ListObjectsV2 of %s: expected %q, got %q", bucket, expected, keys)
+		}
+	}
+}
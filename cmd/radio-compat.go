@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	miniogo "github.com/minio/minio-go/v6"
+)
+
+// s3Compat is the S3 API compatibility level of a remote.
+type s3Compat string
+
+// Supported compatibility levels.
+const (
+	// Remotes implementing the current S3 API, the default.
+	compatFull s3Compat = "full"
+	// Older remotes without ListObjectsV2, session tokens or
+	// conditional copy headers.
+	compatLegacy s3Compat = "legacy"
+)
+
+func parseS3Compat(compat string) (s3Compat, error) {
+	switch c := s3Compat(compat); c {
+	case "":
+		return compatFull, nil
+	case compatFull, compatLegacy:
+		return c, nil
+	}
+	return "", fmt.Errorf("unknown compat level %q", compat)
+}
+
+// listObjectsV2 lists the remote with ListObjectsV2, or with ListObjects
+// on legacy remotes in which case the continuation token is the marker
+// to resume from.
+func (c bucketClient) listObjectsV2(prefix, continuationToken string, fetchOwner bool, delimiter string,
+	maxKeys int, startAfter string) (miniogo.ListBucketV2Result, error) {
+	if c.Compat != compatLegacy {
+		return c.ListObjectsV2(c.Bucket, prefix, continuationToken, fetchOwner, delimiter, maxKeys, startAfter)
+	}
+
+	marker := continuationToken
+	if marker == "" {
+		marker = startAfter
+	}
+	result, err := c.ListObjects(c.Bucket, prefix, marker, delimiter, maxKeys)
+	if err != nil {
+		return miniogo.ListBucketV2Result{}, err
+	}
+	nextMarker := result.NextMarker
+	if result.IsTruncated && nextMarker == "" && len(result.Contents) > 0 {
+		// NextMarker is only returned along with a delimiter.
+		nextMarker = result.Contents[len(result.Contents)-1].Key
+	}
+	return miniogo.ListBucketV2Result{
+		CommonPrefixes:        result.CommonPrefixes,
+		Contents:              result.Contents,
+		Delimiter:             result.Delimiter,
+		EncodingType:          result.EncodingType,
+		IsTruncated:           result.IsTruncated,
+		MaxKeys:               result.MaxKeys,
+		Name:                  result.Name,
+		NextContinuationToken: nextMarker,
+		ContinuationToken:     continuationToken,
+		Prefix:                result.Prefix,
+		StartAfter:            startAfter,
+	}, nil
+}
+
+// listObjects returns a channel of all objects under prefix on the
+// remote, listed with the API the remote supports.
+func (c bucketClient) listObjects(prefix string, doneCh <-chan struct{}) <-chan miniogo.ObjectInfo {
+	if c.Compat == compatLegacy {
+		return c.Client.ListObjects(c.Bucket, prefix, true, doneCh)
+	}
+	return c.Client.ListObjectsV2(c.Bucket, prefix, true, doneCh)
+}
+
+// copyMetadata returns the metadata of a server side copy to this
+// remote, without the conditional copy headers legacy remotes reject.
+func (c bucketClient) copyMetadata(metadata map[string]string) map[string]string {
+	if c.Compat != compatLegacy {
+		return metadata
+	}
+	m := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		if !strings.HasPrefix(strings.ToLower(k), "x-amz-copy-source-if-") {
+			m[k] = v
+		}
+	}
+	return m
+}
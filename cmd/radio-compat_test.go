@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// Tests that legacy remotes are listed with ListObjects, paging from the
+// last key when no NextMarker is returned.
+func TestLegacyListObjectsV2(t *testing.T) {
+	if _, err := parseS3Compat("v3"); err == nil {
+		t.Fatal("Expected an unknown compat level to be rejected")
+	}
+	remotes, clnts, shutdown := newTestRemotes(t, 1)
+	defer shutdown()
+	for _, key := range []string{"a", "b", "c"} {
+		remotes[0].putObject(key, key, nil)
+	}
+	remotes[0].setHook(func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Query().Get("list-type") != "2" {
+			return false
+		}
+		writeTestRemoteError(w, r, http.StatusNotImplemented, "NotImplemented")
+		return true
+	})
+	clnt := clnts[0]
+	clnt.Compat = compatLegacy
+
+	var keys []string
+	token := ""
+	for {
+		result, err := clnt.listObjectsV2("", token, false, "", 1, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, obj := range result.Contents {
+			keys = append(keys, obj.Key)
+		}
+		if !result.IsTruncated {
+			break
+		}
+		token = result.NextContinuationToken
+	}
+	if expected := []string{"a", "b", "c"}; !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected keys %v, got %v", expected, keys)
+	}
+
+	result, err := clnt.listObjectsV2("", "", false, "", 10, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Contents) != 2 || result.Contents[0].Key != "b" {
+		t.Errorf("Expected the listing to start after a, got %+v", result.Contents)
+	}
+
+	keys = nil
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+	for oi := range clnt.listObjects("", doneCh) {
+		if oi.Err != nil {
+			t.Fatal(oi.Err)
+		}
+		keys = append(keys, oi.Key)
+	}
+	if len(keys) != 3 {
+		t.Errorf("Expected 3 keys, got %v", keys)
+	}
+}
+
+// Tests that conditional copy headers are only sent to full remotes,
+// and that session tokens are refused for legacy remotes.
+func TestLegacyCompat(t *testing.T) {
+	metadata := map[string]string{
+		"X-Amz-Copy-Source-If-Match": "etag",
+		"X-Amz-Meta-Radio-Tag":       "tag",
+	}
+	if m := (bucketClient{Compat: compatFull}).copyMetadata(metadata); !reflect.DeepEqual(m, metadata) {
+		t.Errorf("Expected metadata %v, got %v", metadata, m)
+	}
+	expected := map[string]string{"X-Amz-Meta-Radio-Tag": "tag"}
+	if m := (bucketClient{Compat: compatLegacy}).copyMetadata(metadata); !reflect.DeepEqual(m, expected) {
+		t.Errorf("Expected metadata %v, got %v", expected, m)
+	}
+
+	if _, err := newBucketClients([]remoteConfig{{
+		Bucket:       "remote",
+		Endpoint:     "http://localhost:9000",
+		SessionToken: "token",
+		Compat:       string(compatLegacy),
+	}}, false); err == nil {
+		t.Error("Expected a session token to be refused for a legacy remote")
+	}
+}
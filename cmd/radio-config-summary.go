@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/minio/minio/pkg/color"
+	"github.com/minio/radio/cmd/logger"
+)
+
+// configSummary is the effective configuration reported at startup,
+// without any credentials.
+type configSummary struct {
+	Buckets []bucketSummary `json:"buckets"`
+	TLS     bool            `json:"tls"`
+	// Journal is the journal directory or remote, empty if healing is
+	// disabled.
+	Journal string `json:"journal,omitempty"`
+	Peers   int    `json:"peers"`
+}
+
+type bucketSummary struct {
+	Name       string          `json:"name"`
+	Protection ProtectionType  `json:"protection"`
+	Remotes    []remoteSummary `json:"remotes"`
+}
+
+type remoteSummary struct {
+	Endpoint     string `json:"endpoint"`
+	ReadEndpoint string `json:"readEndpoint,omitempty"`
+	Bucket       string `json:"bucket"`
+	ReadOnly     bool   `json:"readOnly,omitempty"`
+	Shadow       bool   `json:"shadow,omitempty"`
+	AsyncWrites  bool   `json:"asyncWrites,omitempty"`
+	WriteWeight  int    `json:"writeWeight,omitempty"`
+}
+
+// newConfigSummary summarizes the buckets initialized by l along with
+// the journal and peers of rconfig.
+func newConfigSummary(rconfig radioConfig, l *radioObjects) configSummary {
+	s := configSummary{TLS: globalIsSSL}
+	for bucket, rs3s := range l.mirrorClients {
+		s.Buckets = append(s.Buckets, newBucketSummary(bucket, MirrorType, rs3s.clnts))
+	}
+	for bucket, ecfg := range l.erasureClients {
+		s.Buckets = append(s.Buckets, newBucketSummary(bucket, ErasureType, ecfg.clnts))
+	}
+	sort.Slice(s.Buckets, func(i, j int) bool {
+		return s.Buckets[i].Name < s.Buckets[j].Name
+	})
+
+	if l.healSys != nil {
+		s.Journal = rconfig.Journal.Dir
+		if remote := rconfig.Journal.Remote; remote.Endpoint != "" {
+			s.Journal = redactEndpoint(remote.Endpoint) + "/" + remote.Bucket
+		}
+	}
+	for _, endpoint := range l.endpoints {
+		if !endpoint.IsLocal {
+			s.Peers++
+		}
+	}
+	return s
+}
+
+func newBucketSummary(bucket string, protection ProtectionType, clnts []bucketClient) bucketSummary {
+	b := bucketSummary{Name: bucket, Protection: protection}
+	for _, clnt := range clnts {
+		r := remoteSummary{
+			Endpoint: redactEndpoint(clnt.EndpointURL().String()),
+			Bucket:   clnt.Bucket,
+			ReadOnly: clnt.ReadOnly,
+			Shadow:   clnt.Shadow,
+
+			AsyncWrites: clnt.asyncWrites,
+		}
+		if w := clnt.weight(); w > 1 {
+			r.WriteWeight = w
+		}
+		if clnt.readCore != nil {
+			r.ReadEndpoint = redactEndpoint(clnt.readCore.EndpointURL().String())
+		}
+		b.Remotes = append(b.Remotes, r)
+	}
+	return b
+}
+
+// redactEndpoint strips the user info and query of endpoint, which may
+// carry credentials.
+func redactEndpoint(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "<invalid endpoint>"
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// printConfigSummary prints s as part of the startup banner, or logs it
+// as JSON for JSON logging which has no banner.
+func printConfigSummary(s configSummary) {
+	if logger.IsJSON() {
+		data, err := json.Marshal(s)
+		if err != nil {
+			return
+		}
+		logger.Info("Effective configuration: %s", string(data))
+		return
+	}
+
+	logStartupMessage(color.Blue(fmt.Sprintf("\nBuckets: %d", len(s.Buckets))))
+	for _, b := range s.Buckets {
+		var remotes []string
+		for _, r := range b.Remotes {
+			remote := r.Endpoint + "/" + r.Bucket
+			if r.ReadEndpoint != "" {
+				remote += " (reads " + r.ReadEndpoint + ")"
+			}
+			switch {
+			case r.ReadOnly:
+				remote += " (read-only)"
+			case r.Shadow:
+				remote += " (shadow)"
+			case r.AsyncWrites:
+				remote += " (async)"
+			case r.WriteWeight > 1:
+				remote += fmt.Sprintf(" (weight %d)", r.WriteWeight)
+			}
+			remotes = append(remotes, remote)
+		}
+		logStartupMessage(fmt.Sprintf("   %s (%s): %s", color.Bold(b.Name), b.Protection, strings.Join(remotes, ", ")))
+	}
+	journal := s.Journal
+	if journal == "" {
+		journal = "disabled"
+	}
+	tls := "disabled"
+	if s.TLS {
+		tls = "enabled"
+	}
+	logStartupMessage(color.Blue("Journal: ") + journal)
+	logStartupMessage(color.Blue("TLS: ") + tls)
+	logStartupMessage(color.Blue("Peers: ") + fmt.Sprint(s.Peers))
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Timeout fetching a configuration served over http(s).
+const configFetchTimeout = 30 * time.Second
+
+// Files looked up in a configuration directory, as mounted from a
+// Kubernetes ConfigMap.
+var configDirFiles = []string{"config.yml", "config.yaml"}
+
+// readRadioConfig reads the configuration at path, which is "-" for
+// stdin, an http(s) URL, a file or a directory holding one of
+// configDirFiles.
+func readRadioConfig(path string) ([]byte, error) {
+	switch {
+	case path == "":
+		return nil, fmt.Errorf("missing configuration, use --config")
+	case path == "-":
+		return ioutil.ReadAll(os.Stdin)
+	case strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://"):
+		return fetchRadioConfig(path)
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return ioutil.ReadFile(path)
+	}
+	for _, name := range configDirFiles {
+		data, err := ioutil.ReadFile(filepath.Join(path, name))
+		if !os.IsNotExist(err) {
+			return data, err
+		}
+	}
+	return nil, fmt.Errorf("no %s in configuration directory %s", strings.Join(configDirFiles, " or "), path)
+}
+
+// fetchRadioConfig downloads the configuration served at urlStr.
+func fetchRadioConfig(urlStr string) ([]byte, error) {
+	clnt := &http.Client{
+		Transport: NewCustomHTTPTransport(),
+		Timeout:   configFetchTimeout,
+	}
+	resp, err := clnt.Get(urlStr)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch configuration: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(ioutil.Discard, resp.Body)
+		return nil, fmt.Errorf("unable to fetch configuration from %s: %s", urlStr, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
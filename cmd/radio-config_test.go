@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Tests reading the configuration from files, ConfigMap style
+// directories and URLs.
+func TestReadRadioConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "radio-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// ConfigMap mounts link the file to a timestamped data directory.
+	dataDir := filepath.Join(dir, "..data")
+	if err = os.Mkdir(dataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dataDir, "config.yml"), []byte("config"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Symlink(filepath.Join("..data", "config.yml"), filepath.Join(dir, "config.yml")); err != nil {
+		t.Fatal(err)
+	}
+
+	emptyDir := filepath.Join(dir, "empty")
+	if err = os.Mkdir(emptyDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/config.yml" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("config"))
+	}))
+	defer ts.Close()
+
+	testCases := []struct {
+		path      string
+		shouldErr bool
+	}{
+		{filepath.Join(dir, "config.yml"), false},
+		{dir, false},
+		{dataDir, false},
+		{ts.URL + "/config.yml", false},
+		{ts.URL + "/missing.yml", true},
+		{filepath.Join(dir, "missing.yml"), true},
+		{emptyDir, true},
+		{"", true},
+	}
+	for i, testCase := range testCases {
+		data, err := readRadioConfig(testCase.path)
+		if testCase.shouldErr {
+			if err == nil {
+				t.Errorf("Test %d: expected an error for %s", i+1, testCase.path)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %d: unexpected error: %v", i+1, err)
+		} else if string(data) != "config" {
+			t.Errorf("Test %d: unexpected config %q", i+1, data)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"io"
+
+	"github.com/minio/minio/pkg/hash"
+)
+
+// checkContentMD5 returns errMissingContentMD5 for uploads without a
+// Content-MD5 to a bucket requiring one. Uploads are sent to the remotes
+// along with their Content-MD5, so that data corrupted on its way to
+// radio is rejected by every remote instead of being mirrored.
+func (m mirrorConfig) checkContentMD5(md5Base64 string) error {
+	if m.requireContentMD5 && md5Base64 == "" {
+		return errMissingContentMD5
+	}
+	return nil
+}
+
+// digestReader records the checksum mismatch reported at the end of
+// the data of an upload, which then fails on every replica, so that the
+// mismatch is returned rather than the write quorum error it causes.
+type digestReader struct {
+	r   io.Reader
+	err error
+}
+
+func (d *digestReader) Read(p []byte) (int, error) {
+	n, err := d.r.Read(p)
+	switch err.(type) {
+	case hash.BadDigest, hash.SHA256Mismatch:
+		d.err = err
+	}
+	return n, err
+}
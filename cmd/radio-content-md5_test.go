@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/minio/minio/pkg/hash"
+)
+
+// Tests that buckets requiring a Content-MD5 reject uploads without one
+// and uploads whose data does not match it.
+func TestRequireContentMD5(t *testing.T) {
+	remotes, clnts, shutdown := newTestRemotes(t, 3)
+	defer shutdown()
+	l := &radioObjects{
+		nsMutex:       newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts, requireContentMD5: true}},
+	}
+
+	data := []byte("data")
+	testCases := []struct {
+		md5Hex      string
+		expectedErr APIErrorCode
+	}{
+		{"", ErrMissingContentMD5},
+		// Not the MD5 of the data.
+		{"d0e3d4d4c1b1c7d5d2cdc2bc8c8c6d3f", ErrBadDigest},
+		{"8d777f385d3dfec8815d20f7496026dc", ErrNone},
+	}
+	for i, testCase := range testCases {
+		reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), testCase.md5Hex, "", int64(len(data)), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = l.PutObject(context.Background(), "bucket", "object", NewPutObjReader(reader, nil, nil), ObjectOptions{})
+		if code := toAPIErrorCode(context.Background(), err); code != testCase.expectedErr {
+			t.Errorf("Test %d: expected error code %d, got %d (%v)", i+1, testCase.expectedErr, code, err)
+		}
+		for index, remote := range remotes {
+			if data, _, stored := remote.object("object"); stored != (testCase.expectedErr == ErrNone) {
+				t.Errorf("Test %d: replica %d: unexpected object %q", i+1, index, data)
+			}
+		}
+	}
+}
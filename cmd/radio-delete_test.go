@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Tests that the objects deleted by DeleteObjects are journaled for
+// heal to delete them from the replicas which missed the delete.
+func TestDeleteObjectsTombstones(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "radio-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	remotes, clnts, shutdown := newTestRemotes(t, 3)
+	defer shutdown()
+	for _, remote := range remotes {
+		remote.putObject("a", "a", nil)
+		remote.putObject("b", "b", nil)
+	}
+	remotes[2].setHook(func(w http.ResponseWriter, r *http.Request) bool {
+		writeTestRemoteError(w, r, http.StatusForbidden, "AccessDenied")
+		return true
+	})
+	l := &radioObjects{
+		nsMutex:           newNSLock(false),
+		mirrorClients:     map[string]mirrorConfig{"bucket": {clnts: clnts}},
+		deleteParallelism: defaultDeleteParallelism,
+		deleteBatchSize:   defaultDeleteBatchSize,
+	}
+	store := &dirJournalStore{dir: tmpdir}
+	if l.healSys, err = newHealSys(l, store, journalConfig{}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	errs, err := l.DeleteObjects(context.Background(), "bucket", []string{"a", "b", "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("Object %d: unexpected error %v", i, err)
+		}
+	}
+	for i, remote := range remotes[:2] {
+		if keys := remote.keys(); len(keys) != 0 {
+			t.Errorf("Replica %d: expected the objects deleted, got %v", i, keys)
+		}
+	}
+	entries, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var objects []string
+	for _, entry := range entries {
+		if entry.Op != opDeleteObject || len(entry.DstClientIDs) != 1 || entry.DstClientIDs[0] != 2 {
+			t.Errorf("Expected a tombstone for the failing replica, got %+v", entry)
+		}
+		objects = append(objects, entry.Object)
+	}
+	sort.Strings(objects)
+	if len(objects) != 2 || objects[0] != "a" || objects[1] != "b" {
+		t.Errorf("Expected one tombstone per object, got %v", objects)
+	}
+}
+
+// Tests that DeleteObjects sends batches of objects to the remotes by
+// up to the delete parallelism at a time, at the delete rate.
+func TestDeleteObjectsBatches(t *testing.T) {
+	var inflight, maxInflight int64
+	remotes, clnts, shutdown := newTestRemotes(t, 2)
+	defer shutdown()
+	var mu sync.Mutex
+	batches := make([][]int, len(remotes))
+	for i, remote := range remotes {
+		i := i
+		remote.setHook(func(w http.ResponseWriter, r *http.Request) bool {
+			n := atomic.AddInt64(&inflight, 1)
+			defer atomic.AddInt64(&inflight, -1)
+			for {
+				max := atomic.LoadInt64(&maxInflight)
+				if n <= max || atomic.CompareAndSwapInt64(&maxInflight, max, n) {
+					break
+				}
+			}
+			body, _ := ioutil.ReadAll(r.Body)
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+			var req testRemoteDeleteRequest
+			if xml.Unmarshal(body, &req) == nil {
+				mu.Lock()
+				batches[i] = append(batches[i], len(req.Objects))
+				mu.Unlock()
+			}
+			// Leave concurrent requests time to overlap.
+			time.Sleep(10 * time.Millisecond)
+			return false
+		})
+	}
+	objects := []string{"a", "b", "c", "d", "e"}
+
+	testCases := []struct {
+		parallelism int
+		rate        int
+		minDuration time.Duration
+	}{
+		{1, 0, 0},
+		{2, 0, 0},
+		// Each remote is sent an object every 20ms.
+		{2, 50, 90 * time.Millisecond},
+	}
+	for i, testCase := range testCases {
+		atomic.StoreInt64(&maxInflight, 0)
+		for j, remote := range remotes {
+			for _, object := range objects {
+				remote.putObject(object, object, nil)
+			}
+			batches[j] = nil
+		}
+		l := &radioObjects{
+			nsMutex:           newNSLock(false),
+			mirrorClients:     map[string]mirrorConfig{"bucket": {clnts: clnts}},
+			deleteParallelism: testCase.parallelism,
+			deleteBatchSize:   2,
+			deleteRate:        testCase.rate,
+		}
+		start := time.Now()
+		errs, err := l.DeleteObjects(context.Background(), "bucket", objects)
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if elapsed := time.Since(start); elapsed < testCase.minDuration {
+			t.Errorf("Test %d: expected the deletes to take at least %v, took %v", i+1, testCase.minDuration, elapsed)
+		}
+		for j, err := range errs {
+			if err != nil {
+				t.Errorf("Test %d: object %d: unexpected error %v", i+1, j, err)
+			}
+		}
+		for j, remote := range remotes {
+			sort.Ints(batches[j])
+			if len(batches[j]) != 3 || batches[j][0] != 1 || batches[j][1] != 2 || batches[j][2] != 2 {
+				t.Errorf("Test %d: expected batches of 2, 2 and 1 objects sent to remote %d, got %v", i+1, j, batches[j])
+			}
+			if keys := remote.keys(); len(keys) != 0 {
+				t.Errorf("Test %d: expected all objects deleted from remote %d, got %v left", i+1, j, keys)
+			}
+		}
+		if max := atomic.LoadInt64(&maxInflight); max > int64(testCase.parallelism) {
+			t.Errorf("Test %d: expected at most %d requests in flight, got %d", i+1, testCase.parallelism, max)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// Tests that addresses are resolved again once their TTL expires.
+func TestDNSCacheLookup(t *testing.T) {
+	lookups := 0
+	cache := newDNSCache(time.Hour)
+	cache.lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		lookups++
+		return []string{"10.0.0.1"}, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		addrs, err := cache.lookup(context.Background(), "minio1")
+		if err != nil || len(addrs) != 1 || addrs[0] != "10.0.0.1" {
+			t.Fatalf("Test %d: Unexpected addresses %v, error %v", i+1, addrs, err)
+		}
+	}
+	if lookups != 1 {
+		t.Errorf("Expected 1 lookup, got %d", lookups)
+	}
+
+	cache.ttl = 0
+	cache.forget("minio1")
+	cache.lookup(context.Background(), "minio1")
+	cache.lookup(context.Background(), "minio1")
+	if lookups != 3 {
+		t.Errorf("Expected 3 lookups of expired entries, got %d", lookups)
+	}
+}
+
+// Tests that dials try each cached address and resolve hosts again once
+// none of their addresses accept connections.
+func TestDNSCacheDialContext(t *testing.T) {
+	lookups := 0
+	cache := newDNSCache(time.Hour)
+	cache.lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		lookups++
+		return []string{"10.0.0.1", "10.0.0.2"}, nil
+	}
+
+	up := map[string]bool{"10.0.0.2:9000": true}
+	var dialed []string
+	dial := cache.dialContext(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed = append(dialed, addr)
+		if !up[addr] {
+			return nil, errors.New("connection refused")
+		}
+		c1, c2 := net.Pipe()
+		c2.Close()
+		return c1, nil
+	})
+
+	testCases := []struct {
+		addr           string
+		shouldPass     bool
+		expectedDialed []string
+		expectedLookup int
+	}{
+		{"minio1:9000", true, []string{"10.0.0.1:9000", "10.0.0.2:9000"}, 1},
+		{"10.0.0.2:9000", true, []string{"10.0.0.2:9000"}, 1},
+		{"minio1:9001", false, []string{"10.0.0.1:9001", "10.0.0.2:9001"}, 1},
+		{"minio1:9000", true, []string{"10.0.0.1:9000", "10.0.0.2:9000"}, 2},
+	}
+
+	for i, testCase := range testCases {
+		dialed = nil
+		conn, err := dial(context.Background(), "tcp", testCase.addr)
+		if testCase.shouldPass && err != nil {
+			t.Errorf("Test %d: Unexpected error %v", i+1, err)
+		}
+		if !testCase.shouldPass && err == nil {
+			t.Errorf("Test %d: Expected dial to fail", i+1)
+		}
+		if conn != nil {
+			conn.Close()
+		}
+		if len(dialed) != len(testCase.expectedDialed) {
+			t.Errorf("Test %d: Expected dials %v, got %v", i+1, testCase.expectedDialed, dialed)
+			continue
+		}
+		for j := range dialed {
+			if dialed[j] != testCase.expectedDialed[j] {
+				t.Errorf("Test %d: Expected dials %v, got %v", i+1, testCase.expectedDialed, dialed)
+				break
+			}
+		}
+		if lookups != testCase.expectedLookup {
+			t.Errorf("Test %d: Expected %d lookups, got %d", i+1, testCase.expectedLookup, lookups)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+)
+
+// emptyObjectMD5Base64 is the Content-MD5 of empty objects, which every
+// replica stores under the ETag d41d8cd98f00b204e9800998ecf8427e.
+const emptyObjectMD5Base64 = "1B2M2Y8AsgTpgAmY7PhCfg=="
+
+// emptyUploadReaders verifies the empty upload data, returning a reader
+// of no data for each of n replicas. Empty objects are written without
+// duplicating their stream, so that no replica depends on the pipe of
+// another to see the end of the data.
+func emptyUploadReaders(data io.Reader, n int) ([]io.Reader, error) {
+	// Reading the upload verifies its checksums.
+	if _, err := io.Copy(ioutil.Discard, data); err != nil {
+		return nil, err
+	}
+	readers := make([]io.Reader, n)
+	for i := range readers {
+		readers[i] = bytes.NewReader(nil)
+	}
+	return readers, nil
+}
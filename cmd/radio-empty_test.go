@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/minio/minio/pkg/hash"
+)
+
+// Tests that a zero-byte object is written identically to all replicas
+// and read back empty.
+func TestPutObjectEmpty(t *testing.T) {
+	remotes, clnts, shutdown := newTestRemotes(t, 3)
+	defer shutdown()
+	contentMD5s := make([]string, len(remotes))
+	for i, remote := range remotes {
+		i := i
+		remote.setHook(func(w http.ResponseWriter, r *http.Request) bool {
+			if r.Method == http.MethodPut {
+				contentMD5s[i] = r.Header.Get("Content-Md5")
+			}
+			return false
+		})
+	}
+	l := &radioObjects{
+		nsMutex:       newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+	}
+
+	reader, err := hash.NewReader(bytes.NewReader(nil), 0, "", "", 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := l.PutObject(context.Background(), "bucket", "object", NewPutObjReader(reader, nil, nil), ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size != 0 || info.ETag != "d41d8cd98f00b204e9800998ecf8427e" {
+		t.Errorf("Expected an empty object, got size %d and ETag %s", info.Size, info.ETag)
+	}
+	for i, remote := range remotes {
+		data, header, ok := remote.object("object")
+		if !ok || header.Get(globalRadioTagKey) == "" || data != "" || contentMD5s[i] != emptyObjectMD5Base64 {
+			t.Errorf("Replica %d: expected an empty object sent with its Content-MD5, got %q (%q)", i, data, contentMD5s[i])
+		}
+	}
+
+	gr, err := l.GetObjectNInfo(context.Background(), "bucket", "object", nil, nil, ReadLock, ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gr.Close()
+	data, err := ioutil.ReadAll(gr)
+	if err != nil || len(data) != 0 || gr.ObjInfo.Size != 0 {
+		t.Errorf("Expected an empty read, got %q of size %d (%v)", data, gr.ObjInfo.Size, err)
+	}
+
+	// Data sent with a mismatching Content-MD5 is rejected.
+	reader, err = hash.NewReader(bytes.NewReader(nil), 0, "8d777f385d3dfec8815d20f7496026dc", "", 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = l.PutObject(context.Background(), "bucket", "object", NewPutObjReader(reader, nil, nil), ObjectOptions{}); err == nil {
+		t.Error("Expected a digest mismatch")
+	}
+}
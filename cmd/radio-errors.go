@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/radio/cmd/logger"
+)
+
+// replicaError is an error returned by a replica while serving a radio
+// operation. ErrorRespToObjectError converts the error it wraps, so that
+// clients see the S3 error code of the remote, while errors it does not
+// interpret keep the replica they came from.
+type replicaError struct {
+	Op       string
+	Replica  int
+	Endpoint string
+	Err      error
+}
+
+func (e replicaError) Error() string {
+	return fmt.Sprintf("%s: replica %d (%s): %v", e.Op, e.Replica, e.Endpoint, e.Err)
+}
+
+func (e replicaError) Unwrap() error {
+	return e.Err
+}
+
+// replicaErr wraps err returned by the replica index of m in operation
+// op, nil and the errors of skipped replicas are returned as is.
+func (m mirrorConfig) replicaErr(op string, index int, err error) error {
+	if err == nil || IsErrIgnored(err, skippedReplicaErrs...) {
+		return err
+	}
+	var rerr replicaError
+	if errors.As(err, &rerr) {
+		return err
+	}
+	clnt := m.clnts[index]
+	return replicaError{
+		Op:       op,
+		Replica:  index,
+		Endpoint: clnt.EndpointURL().Host + SlashSeparator + clnt.Bucket,
+		Err:      err,
+	}
+}
+
+// toObjectError converts err, reduced from the errors errs of the
+// replicas of m in operation op, into an object layer error, logging
+// the errors of the failed replicas with logReplicaErrs.
+func (m mirrorConfig) toObjectError(ctx context.Context, op string, replicas []int, errs []error, err error, params ...string) error {
+	if err == nil {
+		return nil
+	}
+	m.logReplicaErrs(ctx, op, replicas, errs)
+	return ErrorRespToObjectError(err, params...)
+}
+
+// logReplicaErrs logs the error of every replica of m failing operation
+// op along with the replica it came from, since the error reduced from
+// errs does not tell which remotes the operation failed on. errs[i] is
+// the error of the replica replicas[i], or of the replica i if replicas
+// is nil. Nothing is logged if all replicas failed alike.
+func (m mirrorConfig) logReplicaErrs(ctx context.Context, op string, replicas []int, errs []error) {
+	if uniformErrs(errs) {
+		return
+	}
+	for i, err := range errs {
+		if err == nil || IsErrIgnored(err, skippedReplicaErrs...) {
+			continue
+		}
+		index := i
+		if replicas != nil {
+			index = replicas[i]
+		}
+		logger.LogIf(ctx, m.replicaErr(op, index, err))
+	}
+}
+
+// uniformErrs returns true if all replicas in errs failed with the same
+// error code, the errors of skipped replicas aside.
+func uniformErrs(errs []error) bool {
+	code := ""
+	for _, err := range errs {
+		if IsErrIgnored(err, skippedReplicaErrs...) {
+			continue
+		}
+		if err == nil {
+			return false
+		}
+		c := miniogo.ToErrorResponse(err).Code
+		if c == "" {
+			c = err.Error()
+		}
+		if code != "" && c != code {
+			return false
+		}
+		code = c
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	miniogo "github.com/minio/minio-go/v6"
+)
+
+// Tests that errors of replicas convert to the object layer errors and
+// API errors of the remote errors they wrap, keeping their replica when
+// not interpreted.
+func TestReplicaErrors(t *testing.T) {
+	m := mirrorConfig{clnts: []bucketClient{
+		{Core: newTestCore(t, "http://remote1:9000"), Bucket: "remote"},
+		{Core: newTestCore(t, "http://remote2:9000"), Bucket: "remote"},
+	}}
+	noSuchKey := miniogo.ErrorResponse{Code: "NoSuchKey", StatusCode: http.StatusNotFound}
+	slowDown := miniogo.ErrorResponse{Code: "SlowDown", Message: "Reduce your request rate.", StatusCode: http.StatusServiceUnavailable}
+
+	if err := m.replicaErr("GetObject", 1, nil); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if err := m.replicaErr("PutObject", 0, errReadOnlyReplica); err != errReadOnlyReplica {
+		t.Errorf("Expected skipped replicas left alone, got %v", err)
+	}
+
+	err := m.replicaErr("GetObject", 1, noSuchKey)
+	if !strings.Contains(err.Error(), "GetObject: replica 1 (remote2:9000/remote)") {
+		t.Errorf("Expected the operation and replica in %q", err)
+	}
+	if _, ok := ErrorRespToObjectError(err, "bucket", "object").(ObjectNotFound); !ok {
+		t.Errorf("Expected ObjectNotFound, got %v", ErrorRespToObjectError(err, "bucket", "object"))
+	}
+
+	internalErr := miniogo.ErrorResponse{Code: "InternalError", StatusCode: http.StatusInternalServerError}
+	err = ErrorRespToObjectError(m.replicaErr("PutObject", 0, internalErr), "bucket", "object")
+	var rerr replicaError
+	if !errors.As(err, &rerr) || rerr.Replica != 0 || rerr.Op != "PutObject" {
+		t.Errorf("Expected the replica kept on an uninterpreted error, got %v", err)
+	}
+	apiErr := toAPIError(context.Background(), err)
+	if apiErr.Code != "InternalError" || apiErr.HTTPStatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected InternalError reported to clients, got %+v", apiErr)
+	}
+	err = ErrorRespToObjectError(m.replicaErr("PutObject", 0, slowDown), "bucket", "object")
+	if _, ok := err.(BackendThrottled); !ok {
+		t.Errorf("Expected BackendThrottled, got %v", err)
+	}
+	apiErr = toAPIError(context.Background(), err)
+	if apiErr.Code != "SlowDown" || apiErr.HTTPStatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected SlowDown reported to clients, got %+v", apiErr)
+	}
+
+	testCases := []struct {
+		errs    []error
+		uniform bool
+	}{
+		{[]error{noSuchKey, noSuchKey}, true},
+		{[]error{noSuchKey, errReadOnlyReplica, noSuchKey}, true},
+		{[]error{noSuchKey, nil}, false},
+		{[]error{noSuchKey, slowDown}, false},
+		{[]error{errShortWrite, errShortWrite}, true},
+	}
+	for i, testCase := range testCases {
+		if uniform := uniformErrs(testCase.errs); uniform != testCase.uniform {
+			t.Errorf("Test %d: expected uniform %v, got %v", i+1, testCase.uniform, uniform)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/radio/cmd/logger"
+)
+
+// putETagReplica returns the replica whose ETag is reported for a single
+// PUT accepted by the replicas with a nil error in errs, and whether the
+// replicas reported different ETags. The ETag matching the Content-MD5 of
+// the upload wins, then the ETag reported by most replicas, ties going to
+// the lowest replica index.
+func putETagReplica(md5Base64 string, oinfos []miniogo.ObjectInfo, errs []error) (rindex int, diverged bool) {
+	rindex = firstSucceeded(errs)
+	counts := make(map[string]int)
+	for index, err := range errs {
+		if err == nil {
+			counts[canonicalizeETag(oinfos[index].ETag)]++
+		}
+	}
+	if len(counts) <= 1 {
+		return rindex, false
+	}
+
+	var md5Hex string
+	if md5, err := base64.StdEncoding.DecodeString(md5Base64); err == nil && len(md5) > 0 {
+		md5Hex = hex.EncodeToString(md5)
+	}
+	best := -1
+	for index, err := range errs {
+		if err != nil {
+			continue
+		}
+		etag := canonicalizeETag(oinfos[index].ETag)
+		if md5Hex != "" && strings.EqualFold(etag, md5Hex) {
+			return index, true
+		}
+		if best < 0 || counts[etag] > counts[canonicalizeETag(oinfos[best].ETag)] {
+			best = index
+		}
+	}
+	return best, true
+}
+
+// logETagDivergence reports the replicas of bucket that stored object
+// under different ETags, which usually points at remotes configured
+// differently, e.g. for encryption.
+func logETagDivergence(ctx context.Context, bucket, object string, rs3s mirrorConfig, oinfos []miniogo.ObjectInfo, errs []error, rindex int) {
+	etagDivergence.WithLabelValues(bucket).Inc()
+	var etags []string
+	for index, err := range errs {
+		if err == nil {
+			etags = append(etags, fmt.Sprintf("%s/%s=%s", rs3s.clnts[index].EndpointURL().Host,
+				rs3s.clnts[index].Bucket, canonicalizeETag(oinfos[index].ETag)))
+		}
+	}
+	logger.LogIf(ctx, fmt.Errorf("object %s/%s stored with diverging ETags %s, reporting %s",
+		bucket, object, strings.Join(etags, ", "), canonicalizeETag(oinfos[rindex].ETag)))
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/minio-go/v6/pkg/credentials"
+	"github.com/minio/minio/pkg/hash"
+)
+
+// Tests the choice of the ETag reported for a PUT stored under
+// different ETags on the replicas.
+func TestPutETagReplica(t *testing.T) {
+	etags := func(etags ...string) []miniogo.ObjectInfo {
+		oinfos := make([]miniogo.ObjectInfo, len(etags))
+		for i, etag := range etags {
+			oinfos[i].ETag = etag
+		}
+		return oinfos
+	}
+	errFailed := errors.New("failed")
+
+	testCases := []struct {
+		md5Base64        string
+		oinfos           []miniogo.ObjectInfo
+		errs             []error
+		expectedIndex    int
+		expectedDiverged bool
+	}{
+		// Same ETags.
+		{"", etags("a", `"a"`, "a"), []error{nil, nil, nil}, 0, false},
+		// Same ETags on the replicas that succeeded.
+		{"", etags("b", "a", "a"), []error{errFailed, nil, nil}, 1, false},
+		// Majority ETag.
+		{"", etags("b", "a", "a"), []error{nil, nil, nil}, 1, true},
+		// Tie goes to the lowest index.
+		{"", etags("b", "a"), []error{nil, nil}, 0, true},
+		// ETag matching the Content-MD5 wins over the majority.
+		{"zAs6f0/OJZO+l0ZHtO2M/g==", etags("a", "a", "cc0b3a7f4fce2593be974647b4ed8cfe"), []error{nil, nil, nil}, 2, true},
+		// Shadow replicas are left out.
+		{"", etags("a", "b"), []error{nil, errShadowReplica}, 0, false},
+	}
+	for i, testCase := range testCases {
+		index, diverged := putETagReplica(testCase.md5Base64, testCase.oinfos, testCase.errs)
+		if index != testCase.expectedIndex || diverged != testCase.expectedDiverged {
+			t.Errorf("Test %d: expected %d %v, got %d %v", i+1,
+				testCase.expectedIndex, testCase.expectedDiverged, index, diverged)
+		}
+	}
+}
+
+// Tests that PutObject reports the ETag matching the uploaded content
+// when remotes compute diverging ETags.
+func TestPutObjectDivergentETags(t *testing.T) {
+	data := []byte("radio")
+	sum := md5.Sum(data)
+	md5Hex := hex.EncodeToString(sum[:])
+
+	var clnts []bucketClient
+	for _, etag := range []string{"0123456789abcdef0123456789abcdef", md5Hex} {
+		etag := etag
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("ETag", `"`+etag+`"`)
+		}))
+		defer ts.Close()
+
+		u, err := url.Parse(ts.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clnt, err := miniogo.NewWithOptions(u.Host, &miniogo.Options{
+			Creds:  credentials.NewStaticV4("accesskey", "secretkey", ""),
+			Region: "us-east-1",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		clnts = append(clnts, bucketClient{Core: &miniogo.Core{Client: clnt}, Bucket: "remote"})
+	}
+
+	l := &radioObjects{
+		nsMutex:       newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+	}
+	reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), md5Hex, "", int64(len(data)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	objInfo, err := l.PutObject(context.Background(), "bucket", "object", NewPutObjReader(reader, nil, nil), ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if objInfo.ETag != md5Hex {
+		t.Errorf("expected ETag %s, got %s", md5Hex, objInfo.ETag)
+	}
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/minio/minio/pkg/event"
+	"github.com/minio/radio/cmd/logger"
+)
+
+// Events waiting to be posted per bucket, further events are dropped.
+const eventQueueSize = 10000
+
+// bucketEventsConfig configures the notifications of the writes to a
+// bucket, posted to a webhook once a write reached its write quorum.
+type bucketEventsConfig struct {
+	// Webhook the events are posted to, no events are emitted if
+	// empty.
+	URL string `yaml:"url"`
+	// Names of the emitted events such as s3:ObjectCreated:* or
+	// s3:ObjectRemoved:Delete, all created and removed events if
+	// empty.
+	Events []string `yaml:"events"`
+	// Only objects with this prefix and suffix emit events.
+	Prefix string `yaml:"prefix"`
+	Suffix string `yaml:"suffix"`
+}
+
+// eventNotifier posts the events of a bucket to its webhook in the
+// background, in the S3 notification format of MinIO webhook targets.
+type eventNotifier struct {
+	url    string
+	names  map[event.Name]bool
+	prefix string
+	suffix string
+	client *http.Client
+	events chan event.Log
+}
+
+func newEventNotifier(bucket string, cfg bucketEventsConfig) (*eventNotifier, error) {
+	if cfg.URL == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("bucket %s: invalid events url: %w", bucket, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("bucket %s: invalid events url %q: unsupported scheme", bucket, cfg.URL)
+	}
+	names := cfg.Events
+	if len(names) == 0 {
+		names = []string{event.ObjectCreatedAll.String(), event.ObjectRemovedAll.String()}
+	}
+	n := &eventNotifier{
+		url:    cfg.URL,
+		names:  make(map[event.Name]bool),
+		prefix: cfg.Prefix,
+		suffix: cfg.Suffix,
+		client: &http.Client{Transport: NewCustomHTTPTransport(), Timeout: 10 * time.Second},
+		events: make(chan event.Log, eventQueueSize),
+	}
+	for _, s := range names {
+		name, err := event.ParseName(s)
+		if err != nil {
+			return nil, fmt.Errorf("bucket %s: %w", bucket, err)
+		}
+		for _, name := range name.Expand() {
+			if !strings.HasPrefix(name.String(), "s3:ObjectCreated:") && !strings.HasPrefix(name.String(), "s3:ObjectRemoved:") {
+				return nil, fmt.Errorf("bucket %s: unsupported event %s", bucket, s)
+			}
+			n.names[name] = true
+		}
+	}
+	return n, nil
+}
+
+// notify queues the event name of objInfo if it passes the filters of
+// n, a nil notifier silently drops it. Events are only to be emitted
+// for writes which reached their write quorum.
+func (n *eventNotifier) notify(name event.Name, objInfo ObjectInfo) {
+	if n == nil || !n.names[name] ||
+		!strings.HasPrefix(objInfo.Name, n.prefix) || !strings.HasSuffix(objInfo.Name, n.suffix) {
+		return
+	}
+	now := UTCNow()
+	record := event.Event{
+		EventVersion: "2.0",
+		EventSource:  "minio:s3",
+		AwsRegion:    globalServerRegion,
+		EventTime:    now.Format(event.AMZTimeFormat),
+		EventName:    name,
+		S3: event.Metadata{
+			SchemaVersion: "1.0",
+			Bucket: event.Bucket{
+				Name: objInfo.Bucket,
+				ARN:  "arn:aws:s3:::" + objInfo.Bucket,
+			},
+			Object: event.Object{
+				Key:       url.QueryEscape(objInfo.Name),
+				Size:      objInfo.Size,
+				ETag:      objInfo.ETag,
+				Sequencer: fmt.Sprintf("%X", now.UnixNano()),
+			},
+		},
+	}
+	select {
+	case n.events <- event.Log{EventName: name, Key: objInfo.Bucket + SlashSeparator + objInfo.Name, Records: []event.Event{record}}:
+	default:
+		eventsDropped.WithLabelValues(objInfo.Bucket).Inc()
+	}
+}
+
+// run posts queued events in order until ctx is canceled.
+func (n *eventNotifier) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case log := <-n.events:
+			data, err := json.Marshal(log)
+			if err == nil {
+				err = postJSON(ctx, n.client, n.url, data)
+			}
+			logger.LogIf(ctx, err)
+		}
+	}
+}
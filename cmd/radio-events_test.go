@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/minio/minio/pkg/event"
+	"github.com/minio/minio/pkg/hash"
+)
+
+// Tests that the writes to a bucket reaching their write quorum are
+// posted to its events webhook, filtered by event name and key.
+func TestBucketEvents(t *testing.T) {
+	for _, cfg := range []bucketEventsConfig{
+		{URL: "ftp://events.example.com"},
+		{URL: "http://events.example.com", Events: []string{"s3:ObjectCreated:Unknown"}},
+		{URL: "http://events.example.com", Events: []string{"s3:ObjectAccessed:*"}},
+	} {
+		if _, err := newEventNotifier("bucket", cfg); err == nil {
+			t.Errorf("%+v: expected an invalid configuration", cfg)
+		}
+	}
+
+	logs := make(chan event.Log, 10)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var log event.Log
+		if err := json.NewDecoder(r.Body).Decode(&log); err != nil {
+			t.Error(err)
+		}
+		logs <- log
+	}))
+	defer webhook.Close()
+	events, err := newEventNotifier("bucket", bucketEventsConfig{URL: webhook.URL, Prefix: "logs/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go events.run(ctx)
+
+	remotes, clnts, shutdown := newTestRemotes(t, 3)
+	defer shutdown()
+	// A write failing its quorum emits no event.
+	remotes[1].deny()
+	remotes[2].deny()
+	l := &radioObjects{
+		nsMutex:       newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts, events: events}},
+	}
+	put := func(object string) error {
+		data := []byte("data")
+		reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), "", "", int64(len(data)), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = l.PutObject(context.Background(), "bucket", object, NewPutObjReader(reader, nil, nil), ObjectOptions{})
+		return err
+	}
+	if err = put("logs/failed"); err == nil {
+		t.Fatal("expected the write to fail its quorum")
+	}
+	remotes[1].setHook(nil)
+
+	if err = put("logs/a"); err != nil {
+		t.Fatal(err)
+	}
+	if err = put("other"); err != nil {
+		t.Fatal(err)
+	}
+	if err = l.DeleteObject(context.Background(), "bucket", "logs/a"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []event.Name{event.ObjectCreatedPut, event.ObjectRemovedDelete} {
+		select {
+		case log := <-logs:
+			if log.EventName != expected || log.Key != "bucket/logs/a" || len(log.Records) != 1 ||
+				log.Records[0].S3.Bucket.Name != "bucket" || log.Records[0].S3.Object.Key != "logs%2Fa" {
+				t.Errorf("Expected %s of bucket/logs/a, got %+v", expected, log)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("Expected %s posted", expected)
+		}
+	}
+	select {
+	case log := <-logs:
+		t.Errorf("Unexpected event %+v", log)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
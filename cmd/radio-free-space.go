@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/minio/minio/pkg/madmin"
+	"github.com/minio/radio/cmd/logger"
+)
+
+// defaultFreeSpaceInterval is how often the free space of remotes is
+// polled when no interval is configured.
+const defaultFreeSpaceInterval = time.Minute
+
+// freeSpaceConfig guards a remote against filling up, rejecting writes
+// while it reports less free space than MinFree.
+type freeSpaceConfig struct {
+	// MinFree is a size such as 50GiB, no writes are rejected if
+	// empty.
+	MinFree string `yaml:"min_free"`
+	// Interval between polls of the free space of the remote.
+	Interval time.Duration `yaml:"interval"`
+}
+
+// freeSpaceMonitor polls the free space of a MinIO remote from its admin
+// API, which requires the remote credentials to be admin credentials.
+// Remotes whose free space is unknown are not guarded.
+type freeSpaceMonitor struct {
+	remote   string
+	admin    *madmin.AdminClient
+	minFree  uint64
+	interval time.Duration
+	// low is 1 while the remote reports less than minFree free.
+	low int32
+}
+
+// newFreeSpaceMonitor returns the monitor of the remote at endpoint
+// configured by cfg, nil if no minimum is configured.
+func newFreeSpaceMonitor(endpoint, accessKey, secretKey string, cfg freeSpaceConfig,
+	transport http.RoundTripper) (*freeSpaceMonitor, error) {
+	if cfg.MinFree == "" {
+		return nil, nil
+	}
+	minFree, err := humanize.ParseBytes(cfg.MinFree)
+	if err != nil {
+		return nil, fmt.Errorf("invalid min_free %q: %w", cfg.MinFree, err)
+	}
+	if cfg.Interval < 0 {
+		return nil, fmt.Errorf("free space interval must not be negative")
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	admin, err := madmin.New(u.Host, accessKey, secretKey, u.Scheme == "https")
+	if err != nil {
+		return nil, err
+	}
+	admin.SetCustomTransport(transport)
+	interval := cfg.Interval
+	if interval == 0 {
+		interval = defaultFreeSpaceInterval
+	}
+	return &freeSpaceMonitor{
+		remote:   u.Host,
+		admin:    admin,
+		minFree:  minFree,
+		interval: interval,
+	}, nil
+}
+
+// update polls the free space of the remote, the space available on all
+// its drives.
+func (m *freeSpaceMonitor) update() error {
+	info, err := m.admin.StorageInfo()
+	if err != nil {
+		return err
+	}
+	var free uint64
+	for _, available := range info.Available {
+		free += available
+	}
+	low := int32(0)
+	if free < m.minFree {
+		low = 1
+	}
+	if atomic.SwapInt32(&m.low, low) != low {
+		if low == 1 {
+			logger.Info("WARNING: remote %s has %s free, below %s, rejecting writes",
+				m.remote, humanize.IBytes(free), humanize.IBytes(m.minFree))
+		} else {
+			logger.Info("remote %s has %s free, accepting writes again",
+				m.remote, humanize.IBytes(free))
+		}
+	}
+	return nil
+}
+
+// run polls the free space of the remote until ctx is canceled.
+func (m *freeSpaceMonitor) run(ctx context.Context) {
+	ctx = logger.WithFields(ctx, logger.FieldOp, "FreeSpace", "remote", m.remote)
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		if err := m.update(); err != nil {
+			logger.LogIf(ctx, fmt.Errorf("free space of remote %s: %w", m.remote, err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// lowSpace returns true if the remote was last seen with less free
+// space than its minimum, a nil monitor never reports low space.
+func (m *freeSpaceMonitor) lowSpace() bool {
+	return m != nil && atomic.LoadInt32(&m.low) == 1
+}
+
+// checkFreeSpace rejects writes with StorageFull while a writable replica
+// of m is low on space, so that writes cannot succeed on some replicas
+// only for lack of space on the others.
+func (m mirrorConfig) checkFreeSpace() error {
+	for _, clnt := range m.clnts {
+		if !clnt.ReadOnly && !clnt.Shadow && clnt.freeSpace.lowSpace() {
+			return StorageFull{}
+		}
+	}
+	return nil
+}
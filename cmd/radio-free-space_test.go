@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/minio/minio/pkg/hash"
+)
+
+// Tests that writes are rejected while a writable replica reports less
+// free space than its minimum.
+func TestFreeSpaceGuard(t *testing.T) {
+	for _, cfg := range []freeSpaceConfig{
+		{MinFree: "lots"},
+		{MinFree: "1GiB", Interval: -1},
+	} {
+		if _, err := newFreeSpaceMonitor("http://remote:9000", "accesskey", "secretkey", cfg, http.DefaultTransport); err == nil {
+			t.Errorf("%+v: expected an invalid configuration", cfg)
+		}
+	}
+	if m, err := newFreeSpaceMonitor("http://remote:9000", "accesskey", "secretkey", freeSpaceConfig{}, http.DefaultTransport); m != nil || err != nil {
+		t.Errorf("Expected no monitor without a minimum, got %v (%v)", m, err)
+	}
+
+	var available int64 = 512 << 20
+	admin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/minio/admin/v2/storageinfo" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"Available":[%d,%d]}`, atomic.LoadInt64(&available), atomic.LoadInt64(&available))
+	}))
+	defer admin.Close()
+	monitor, err := newFreeSpaceMonitor(admin.URL, "accesskey", "secretkey", freeSpaceConfig{MinFree: "2GiB"}, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = monitor.update(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, clnts, shutdown := newTestRemotes(t, 3)
+	defer shutdown()
+	l := &radioObjects{
+		nsMutex:       newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+	}
+	put := func() error {
+		data := []byte("data")
+		reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), "", "", int64(len(data)), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = l.PutObject(context.Background(), "bucket", "object", NewPutObjReader(reader, nil, nil), ObjectOptions{})
+		return err
+	}
+
+	// A shadow replica low on space does not hold up writes.
+	clnts[2].Shadow, clnts[2].freeSpace = true, monitor
+	if err = put(); err != nil {
+		t.Errorf("Expected the write accepted, got %v", err)
+	}
+
+	clnts[2].Shadow = false
+	if err = put(); toAPIError(context.Background(), err).Code != "XMinioStorageFull" {
+		t.Errorf("Expected XMinioStorageFull, got %v", err)
+	}
+	if _, err = l.NewMultipartUpload(context.Background(), "bucket", "object", ObjectOptions{}); err != (StorageFull{}) {
+		t.Errorf("Expected multipart uploads rejected, got %v", err)
+	}
+
+	atomic.StoreInt64(&available, 1<<30)
+	if err = monitor.update(); err != nil {
+		t.Fatal(err)
+	}
+	if err = put(); err != nil {
+		t.Errorf("Expected the write accepted once space is freed, got %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	miniogo "github.com/minio/minio-go/v6"
+	xnet "github.com/minio/minio/pkg/net"
+)
+
+// headConfig configures HEAD requests answered by a single replica,
+// rather than by the quorum of the replicas as GetObjectInfo does.
+type headConfig struct {
+	// Fast answers HEAD requests from the first replica reachable,
+	// preferred for the client if any. Objects missing on it, or
+	// whose version on it is not the quorum version while a write to
+	// it is being healed, are reported as they are on that replica.
+	// Ignored with strict_reads.
+	Fast bool `yaml:"fast"`
+	// SkipLock answers fast HEAD requests without taking the object
+	// lock, possibly reporting the previous version of an object being
+	// written.
+	SkipLock bool `yaml:"skip_lock"`
+}
+
+// HeadObject returns the info of object for HEAD requests, stating a
+// single replica if fast heads are enabled for bucket, GetObjectInfo
+// otherwise. Fast heads fall back to GetObjectInfo for objects the
+// replica cannot answer for, such as archived objects. Objects recently
+// written are answered from recentWrites if all replicas are down.
+func (l *radioObjects) HeadObject(ctx context.Context, bucket string, object string, opts ObjectOptions) (objInfo ObjectInfo, err error) {
+	ctx = withRecentWriteRead(ctx)
+	rs3s, ok := l.mirrorClients[bucket]
+	if !ok || !rs3s.head.Fast || rs3s.strictReads || opts.Replica != nil {
+		return l.GetObjectInfo(ctx, bucket, object, opts)
+	}
+	if err := l.operationAllowed(operationHeadObject); err != nil {
+		return objInfo, err
+	}
+	object = l.objectName(bucket, object)
+	rs3s = rs3s.forUser(ctx).forObject(object)
+
+	ctx, cancel := withDeadline(ctx, l.timeouts.Read)
+	defer cancel()
+
+	if !rs3s.head.SkipLock {
+		objectLock := l.NewNSLock(ctx, bucket, object)
+		if err := objectLock.GetRLock(globalObjectTimeout); err != nil {
+			return ObjectInfo{}, err
+		}
+		defer objectLock.RUnlock()
+	}
+
+	slow := l.slowLog.start("HeadObject", bucket, object)
+	defer slow.done(ctx)
+
+	for _, index := range preferReplica(rs3s.readReplicas(), l.affinity.preferred(ctx, nil)) {
+		info, serr := headReplica(ctx, slow, rs3s.clnts[index].reader(), object, opts)
+		if serr == nil && !isArchivedReplica(info) {
+			objInfo = FromMinioClientObjectInfo(bucket, info, index)
+			objInfo.Replicas = []int{index}
+			return objInfo, nil
+		}
+		if serr != nil && miniogo.ToErrorResponse(serr).Code == "NoSuchKey" {
+			return ObjectInfo{}, ErrorRespToObjectError(rs3s.replicaErr("HeadObject", index, serr), bucket, object)
+		}
+		if serr == nil || !xnet.IsNetworkOrHostDown(serr) {
+			// Archived copies and other errors are left to the
+			// quorum of the replicas.
+			break
+		}
+	}
+	return l.getObjectInfo(ctx, bucket, object, opts)
+}
+
+// headReplica stats object on clnt within the time given to replicas
+// to answer stats.
+func headReplica(ctx context.Context, slow *slowRequest, clnt bucketClient, object string, opts ObjectOptions) (miniogo.ObjectInfo, error) {
+	defer slow.replica(clnt)()
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	return statReplica(ctx, clnt, object, opts)
+}
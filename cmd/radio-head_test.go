@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// Tests that fast HEAD requests state a single replica, skipping
+// offline replicas, while other HEAD requests state all replicas.
+func TestHeadObject(t *testing.T) {
+	remotes, clnts, shutdown := newTestReplicas(t, testReplica{"v1", time.Now()}, testReplica{"v1", time.Now()},
+		testReplica{"v1", time.Now()}, testReplica{})
+	defer shutdown()
+	_, offline, shutdownOffline := newTestRemotes(t, 1)
+	shutdownOffline()
+	missing := clnts[3]
+	stats := func() (n int) {
+		for _, remote := range remotes {
+			n += remote.requests(http.MethodHead)
+		}
+		return n
+	}
+
+	testCases := []struct {
+		remotes      []bucketClient
+		head         headConfig
+		strictReads  bool
+		stats        int
+		replicaIndex int
+		notFound     bool
+	}{
+		{clnts[:3], headConfig{}, false, 3, 0, false},
+		{clnts[:3], headConfig{Fast: true}, false, 1, 0, false},
+		{clnts[:3], headConfig{Fast: true, SkipLock: true}, false, 1, 0, false},
+		{clnts[:3], headConfig{Fast: true}, true, 3, 0, false},
+		{[]bucketClient{offline[0], clnts[1], clnts[2]}, headConfig{Fast: true}, false, 1, 1, false},
+		{[]bucketClient{missing, clnts[1], clnts[2]}, headConfig{Fast: true}, false, 1, 0, true},
+	}
+	for i, testCase := range testCases {
+		l := &radioObjects{
+			nsMutex: newNSLock(false),
+			mirrorClients: map[string]mirrorConfig{"bucket": {
+				clnts:       testCase.remotes,
+				head:        testCase.head,
+				strictReads: testCase.strictReads,
+			}},
+		}
+		before := stats()
+		info, err := l.HeadObject(context.Background(), "bucket", "object", ObjectOptions{})
+		if _, ok := err.(ObjectNotFound); ok != testCase.notFound {
+			t.Errorf("Test %d: expected not found %v, got %v", i+1, testCase.notFound, err)
+		}
+		if err == nil && info.ReplicaIndex != testCase.replicaIndex {
+			t.Errorf("Test %d: expected replica %d, got %d", i+1, testCase.replicaIndex, info.ReplicaIndex)
+		}
+		if n := stats() - before; n != testCase.stats {
+			t.Errorf("Test %d: expected %d replicas stated, got %d", i+1, testCase.stats, n)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// validateRemoteHeaders rejects custom headers of a remote which would
+// interfere with request signing, signed headers are never overridden.
+func validateRemoteHeaders(headers map[string]string) error {
+	for name := range headers {
+		canonical := http.CanonicalHeaderKey(name)
+		switch {
+		case name == "" || strings.ContainsAny(name, " :\r\n"):
+			return fmt.Errorf("invalid header name %q", name)
+		case canonical == "Authorization" || canonical == "Host":
+			return fmt.Errorf("header %s cannot be customized", canonical)
+		case strings.HasPrefix(canonical, "X-Amz-"):
+			// Remotes expect all x-amz-* headers to be signed.
+			return fmt.Errorf("header %s cannot be customized", canonical)
+		}
+	}
+	return nil
+}
+
+// newHeaderTransport returns transport adding headers to every request,
+// transport itself if there are none.
+func newHeaderTransport(transport http.RoundTripper, headers map[string]string) http.RoundTripper {
+	if len(headers) == 0 {
+		return transport
+	}
+	h := make(http.Header, len(headers))
+	for name, value := range headers {
+		h.Set(name, value)
+	}
+	return &headerTransport{RoundTripper: transport, headers: h}
+}
+
+// headerTransport adds custom headers to the requests sent to a remote,
+// typically required by proxies or gateways in front of it. Headers
+// already set on a request, as signed by the client, are kept.
+type headerTransport struct {
+	http.RoundTripper
+	headers http.Header
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	for name, values := range t.headers {
+		if _, ok := r.Header[name]; !ok {
+			r.Header[name] = values
+		}
+	}
+	return t.RoundTripper.RoundTrip(r)
+}
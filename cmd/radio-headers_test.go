@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Tests that custom headers interfering with request signing are
+// rejected.
+func TestValidateRemoteHeaders(t *testing.T) {
+	testCases := []struct {
+		headers    map[string]string
+		shouldPass bool
+	}{
+		{nil, true},
+		{map[string]string{"X-Tenant-Id": "tenant1", "x-auth-token": "token"}, true},
+		{map[string]string{"authorization": "Bearer token"}, false},
+		{map[string]string{"Host": "proxy"}, false},
+		{map[string]string{"x-amz-security-token": "token"}, false},
+		{map[string]string{"X Tenant": "tenant1"}, false},
+		{map[string]string{"": "value"}, false},
+	}
+
+	for i, testCase := range testCases {
+		err := validateRemoteHeaders(testCase.headers)
+		if testCase.shouldPass && err != nil {
+			t.Errorf("Test %d: Unexpected error %v", i+1, err)
+		}
+		if !testCase.shouldPass && err == nil {
+			t.Errorf("Test %d: Expected an error", i+1)
+		}
+	}
+}
+
+// Tests that custom headers are added to requests without overriding
+// the headers already set.
+func TestHeaderTransport(t *testing.T) {
+	var received http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header
+	}))
+	defer ts.Close()
+
+	transport := newHeaderTransport(http.DefaultTransport, map[string]string{
+		"x-tenant-id":  "tenant1",
+		"Content-Type": "text/plain",
+	})
+	req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/xml")
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if v := received.Get("X-Tenant-Id"); v != "tenant1" {
+		t.Errorf("Expected X-Tenant-Id tenant1, got %q", v)
+	}
+	if v := received.Get("Content-Type"); v != "application/xml" {
+		t.Errorf("Expected Content-Type application/xml, got %q", v)
+	}
+	if v := req.Header.Get("X-Tenant-Id"); v != "" {
+		t.Errorf("Expected the original request to be left unmodified, got X-Tenant-Id %q", v)
+	}
+	if transport := newHeaderTransport(http.DefaultTransport, nil); transport != http.DefaultTransport {
+		t.Errorf("Expected no wrapping without headers")
+	}
+}
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	humanize "github.com/dustin/go-humanize"
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/radio/cmd/logger"
+)
+
+// Defaults of the checkpointed heal sizes.
+const (
+	defaultHealCheckpointMinSize  = 256 * humanize.MiByte
+	defaultHealCheckpointPartSize = 64 * humanize.MiByte
+)
+
+// healCheckpointConfig configures heals copying large objects part by
+// part, recording the parts copied in the journal entry healed so that
+// a heal interrupted by a restart of radio or of a remote resumes where
+// it stopped rather than copying the object again.
+type healCheckpointConfig struct {
+	Enable bool `yaml:"enable"`
+	// MinSize is the size such as 1GiB of the smallest objects whose
+	// heal is checkpointed, smaller objects are copied in one request.
+	MinSize string `yaml:"min_size"`
+	// PartSize is the size of the parts copied between checkpoints, at
+	// least 5MiB.
+	PartSize string `yaml:"part_size"`
+}
+
+// healCheckpoint records the progress of the copy of the object of a
+// journal entry onto replica DstClientID: the multipart upload of the
+// source copy tagged ETag, split in parts of PartSize bytes, and the
+// parts uploaded so far.
+type healCheckpoint struct {
+	DstClientID int                  `json:"dstClientID"`
+	UploadID    string               `json:"uploadID"`
+	ETag        string               `json:"etag"`
+	PartSize    int64                `json:"partSize"`
+	Parts       []healCheckpointPart `json:"parts,omitempty"`
+}
+
+type healCheckpointPart struct {
+	PartNumber int    `json:"partNumber"`
+	ETag       string `json:"etag"`
+}
+
+// healCheckpointer copies objects of at least minSize bytes by parts of
+// partSize bytes, checkpointing their journal entry after each part.
+type healCheckpointer struct {
+	minSize  int64
+	partSize int64
+}
+
+// newHealCheckpointer returns the checkpointer configured by cfg, nil
+// if checkpointed heals are not enabled.
+func newHealCheckpointer(cfg healCheckpointConfig) (*healCheckpointer, error) {
+	if !cfg.Enable {
+		return nil, nil
+	}
+	c := &healCheckpointer{minSize: defaultHealCheckpointMinSize, partSize: defaultHealCheckpointPartSize}
+	if cfg.MinSize != "" {
+		size, err := humanize.ParseBytes(cfg.MinSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid heal checkpoint min_size %q: %w", cfg.MinSize, err)
+		}
+		c.minSize = int64(size)
+	}
+	if cfg.PartSize != "" {
+		size, err := humanize.ParseBytes(cfg.PartSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid heal checkpoint part_size %q: %w", cfg.PartSize, err)
+		}
+		c.partSize = int64(size)
+	}
+	if c.partSize < minBlockHealBlockSize {
+		return nil, fmt.Errorf("heal checkpoint part_size must be at least %s", humanize.IBytes(minBlockHealBlockSize))
+	}
+	return c, nil
+}
+
+// checkpointed returns true if the heal of an object of the given size
+// is checkpointed, objects fitting in a single part are not.
+func (c *healCheckpointer) checkpointed(size int64) bool {
+	return c != nil && size >= c.minSize && size > c.partSize
+}
+
+// checkpointOf returns the checkpoint of the copy of the object of entry
+// onto replica index, false if there is none.
+func (entry journalEntry) checkpointOf(index int) (healCheckpoint, bool) {
+	for _, cp := range entry.Checkpoints {
+		if cp.DstClientID == index {
+			return cp, true
+		}
+	}
+	return healCheckpoint{}, false
+}
+
+// setCheckpoint records cp in entry, replacing the checkpoint of the
+// same replica if any.
+func (entry *journalEntry) setCheckpoint(cp healCheckpoint) {
+	for i := range entry.Checkpoints {
+		if entry.Checkpoints[i].DstClientID == cp.DstClientID {
+			entry.Checkpoints[i] = cp
+			return
+		}
+	}
+	entry.Checkpoints = append(entry.Checkpoints, cp)
+}
+
+// dropCheckpoint removes the checkpoint of replica index from entry.
+func (entry *journalEntry) dropCheckpoint(index int) {
+	checkpoints := entry.Checkpoints[:0]
+	for _, cp := range entry.Checkpoints {
+		if cp.DstClientID != index {
+			checkpoints = append(checkpoints, cp)
+		}
+	}
+	entry.Checkpoints = checkpoints
+}
+
+// healEntryCopy heals the copy of the object of entry held by replica
+// index, dst, from the copy stated as srcInfo held by src. Objects
+// checkpointed by the checkpointer and not healed block by block are
+// copied part by part, resuming the copy checkpointed in entry if any.
+func (h *healSys) healEntryCopy(ctx context.Context, entry *journalEntry, src, dst bucketClient, index int,
+	srcInfo miniogo.ObjectInfo) error {
+	if entry.sse != nil || !h.checkpoint.checkpointed(srcInfo.Size) {
+		return h.healCopy(ctx, src, dst, entry.Object, entry.sse)
+	}
+	if h.healBlocks(ctx, src, dst, entry.Object, nil) {
+		return nil
+	}
+	return h.checkpointedCopy(ctx, entry, src, dst, index, srcInfo)
+}
+
+// checkpointedCopy copies the object of entry onto dst part by part,
+// saving entry along with the parts copied after each part. The upload
+// is left in place on failures for the next heal to resume it.
+func (h *healSys) checkpointedCopy(ctx context.Context, entry *journalEntry, src, dst bucketClient, index int,
+	srcInfo miniogo.ObjectInfo) error {
+	partSize := healPartSize(h.checkpoint.partSize, srcInfo.Size)
+	cp, resumed := entry.checkpointOf(index)
+	if resumed && (cp.ETag != srcInfo.ETag || cp.PartSize != partSize) {
+		// The parts copied are of another version of the object.
+		abortHealUpload(ctx, dst, entry.Object, cp.UploadID)
+		resumed = false
+	}
+	if !resumed {
+		uploadID, err := newHealUpload(ctx, src, dst, entry.Object, srcInfo)
+		if err != nil {
+			return err
+		}
+		cp = healCheckpoint{DstClientID: index, UploadID: uploadID, ETag: srcInfo.ETag, PartSize: partSize}
+		entry.setCheckpoint(cp)
+		if err = h.store.Save(*entry); err != nil {
+			abortHealUpload(ctx, dst, entry.Object, uploadID)
+			return err
+		}
+	} else {
+		logger.LogInfo(ctx, "resuming heal of %s on %s/%s after %d parts", entry.Object,
+			dst.EndpointURL().Host, dst.Bucket, len(cp.Parts))
+	}
+
+	err := h.copyParts(ctx, entry, src, dst, srcInfo, cp)
+	if resumed && miniogo.ToErrorResponse(err).Code == "NoSuchUpload" {
+		// The upload was aborted or expired since, start over.
+		entry.dropCheckpoint(index)
+		return h.checkpointedCopy(ctx, entry, src, dst, index, srcInfo)
+	}
+	return err
+}
+
+// copyParts copies the parts of the object of entry not yet recorded in
+// cp from src to dst, then completes the upload and drops cp.
+func (h *healSys) copyParts(ctx context.Context, entry *journalEntry, src, dst bucketClient, srcInfo miniogo.ObjectInfo,
+	cp healCheckpoint) error {
+	srcKey, dstKey := src.objectKey(entry.Object), dst.objectKey(entry.Object)
+	for offset := int64(len(cp.Parts)) * cp.PartSize; offset < srcInfo.Size; offset += cp.PartSize {
+		length := cp.PartSize
+		if offset+length > srcInfo.Size {
+			length = srcInfo.Size - offset
+		}
+		part, err := copyBlock(ctx, src, dst, srcKey, dstKey, srcInfo.ETag, cp.UploadID, len(cp.Parts)+1, offset, length)
+		if err != nil {
+			return err
+		}
+		cp.Parts = append(cp.Parts, healCheckpointPart{PartNumber: part.PartNumber, ETag: part.ETag})
+		entry.setCheckpoint(cp)
+		if err = h.store.Save(*entry); err != nil {
+			return err
+		}
+	}
+
+	parts := make([]miniogo.CompletePart, len(cp.Parts))
+	for i, part := range cp.Parts {
+		parts[i] = miniogo.CompletePart{PartNumber: part.PartNumber, ETag: part.ETag}
+	}
+	if _, err := dst.CompleteMultipartUploadWithContext(ctx, dst.Bucket, dstKey, cp.UploadID, parts); err != nil {
+		return err
+	}
+	entry.dropCheckpoint(cp.DstClientID)
+	// The entry is removed once every replica is healed, until then
+	// the heals of the other replicas may resume.
+	logger.LogIf(ctx, h.store.Save(*entry))
+	return nil
+}
+
+// abortCheckpoints aborts the uploads checkpointed in entry, which is
+// no longer healed.
+func (h *healSys) abortCheckpoints(ctx context.Context, entry journalEntry) {
+	rs3s, ok := h.layer.mirrorClients[entry.Bucket]
+	if !ok {
+		return
+	}
+	for _, cp := range entry.Checkpoints {
+		if cp.DstClientID >= 0 && cp.DstClientID < len(rs3s.clnts) {
+			abortHealUpload(ctx, rs3s.clnts[cp.DstClientID], entry.Object, cp.UploadID)
+		}
+	}
+}
+
+// abortHealUpload aborts the multipart upload uploadID of the copy of
+// object held by dst, logging failures other than the upload being gone
+// already.
+func abortHealUpload(ctx context.Context, dst bucketClient, object, uploadID string) {
+	err := dst.AbortMultipartUploadWithContext(ctx, dst.Bucket, dst.objectKey(object), uploadID)
+	if err != nil && miniogo.ToErrorResponse(err).Code != "NoSuchUpload" {
+		logger.LogIf(ctx, err)
+	}
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+
+	humanize "github.com/dustin/go-humanize"
+)
+
+// Tests that heals of large objects interrupted by a failure resume from
+// the last part checkpointed in their journal entry.
+func TestHealCheckpoint(t *testing.T) {
+	for _, cfg := range []healCheckpointConfig{
+		{Enable: true, MinSize: "lots"},
+		{Enable: true, PartSize: "1MiB"},
+	} {
+		if _, err := newHealCheckpointer(cfg); err == nil {
+			t.Errorf("%+v: expected an invalid configuration", cfg)
+		}
+	}
+	c, err := newHealCheckpointer(healCheckpointConfig{Enable: true, MinSize: "10MiB", PartSize: "5MiB"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.checkpointed(9*humanize.MiByte) || !c.checkpointed(10*humanize.MiByte) {
+		t.Errorf("Expected objects of at least min_size checkpointed")
+	}
+
+	tmpdir, err := ioutil.TempDir("", "radio-heal-checkpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	const partSize = 5 * humanize.MiByte
+	data := bytes.Repeat([]byte("0123456789abcdef"), 3*partSize/16)
+	remotes, clnts, shutdown := newTestRemotes(t, 2)
+	defer shutdown()
+	remotes[0].putObject("object", string(data), map[string]string{globalRadioTagKey: "v2"})
+	// The upload of the third part is denied once.
+	failPart := "3"
+	remotes[1].setHook(func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method != http.MethodPut || r.URL.Query().Get("partNumber") != failPart {
+			return false
+		}
+		failPart = ""
+		writeTestRemoteError(w, r, http.StatusForbidden, "AccessDenied")
+		return true
+	})
+	l := &radioObjects{
+		nsMutex:       newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+	}
+	store := &dirJournalStore{dir: tmpdir}
+	l.healSys, err = newHealSys(l, store, journalConfig{
+		Checkpoint: healCheckpointConfig{Enable: true, MinSize: "10MiB", PartSize: "5MiB"},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := journalEntry{ID: "entry", Bucket: "bucket", Object: "object", Op: opPutObject, RadioTag: "v2",
+		DstClientIDs: []int{1}}
+	if err = store.Save(entry); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = l.healEntry(context.Background(), &entry); err == nil {
+		t.Fatal("Expected the heal interrupted")
+	}
+	entries, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || len(entries[0].Checkpoints) != 1 || len(entries[0].Checkpoints[0].Parts) != 2 {
+		t.Fatalf("Expected 2 parts checkpointed, got %+v", entries)
+	}
+
+	if err = l.healEntry(context.Background(), &entries[0]); err != nil {
+		t.Fatal(err)
+	}
+	if got, header, _ := remotes[1].object("object"); got != string(data) || header.Get(globalRadioTagKey) != "v2" {
+		t.Errorf("Expected the copy of the source, got tag %q", header.Get(globalRadioTagKey))
+	}
+	if received := remotes[1].bytesReceived(); received != len(data) {
+		t.Errorf("Expected the parts copied once, got %d bytes transferred", received)
+	}
+	if entries, err = store.List(); err != nil || len(entries) != 1 || len(entries[0].Checkpoints) != 0 {
+		t.Errorf("Expected the checkpoint dropped, got %+v (%v)", entries, err)
+	}
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/minio/pkg/madmin"
+)
+
+// HealObject stats object on all replicas of bucket and copies the
+// authoritative version, picked by the heal policy, onto the replicas
+// missing it or holding another version. Replicas are reported as drives
+// of the result, in their state before and after healing.
+func (l *radioObjects) HealObject(ctx context.Context, bucket, object string, opts madmin.HealOpts) (res madmin.HealResultItem, err error) {
+	rs3s, ok := l.mirrorClients[bucket]
+	if !ok {
+		if _, ok = l.erasureClients[bucket]; ok {
+			return res, NotImplemented{}
+		}
+		return res, BucketNotFound{Bucket: bucket}
+	}
+	object = rs3s.keys.object(object)
+	rs3s = rs3s.forObject(object)
+
+	objectLock := l.NewNSLock(ctx, bucket, object)
+	if err = objectLock.GetLock(globalObjectTimeout); err != nil {
+		return res, err
+	}
+	defer objectLock.Unlock()
+
+	n := len(rs3s.clnts)
+	oinfos := make([]miniogo.ObjectInfo, n)
+	errs := make([]error, n)
+	for index, clnt := range rs3s.clnts {
+		oinfos[index], errs[index] = clnt.StatObjectWithContext(ctx, clnt.Bucket,
+			clnt.objectKey(object), miniogo.StatObjectOptions{})
+	}
+
+	// Shadow replicas are neither heal sources nor healed.
+	sources := make([]error, n)
+	for index, clnt := range rs3s.clnts {
+		sources[index] = errs[index]
+		if clnt.Shadow {
+			sources[index] = errShadowReplica
+		}
+	}
+	src := newestReplica(oinfos, sources)
+	if l.healSys != nil {
+		src = l.healSys.healSource(oinfos, sources, src)
+	}
+	if src < 0 {
+		return res, ObjectNotFound{Bucket: bucket, Object: object}
+	}
+
+	radioTag := oinfos[src].Metadata.Get(globalRadioTagKey)
+	res = madmin.HealResultItem{
+		Type:       madmin.HealItemObject,
+		Bucket:     bucket,
+		Object:     object,
+		DiskCount:  n,
+		SetCount:   1,
+		ObjectSize: oinfos[src].Size,
+	}
+	res.Before.Drives = make([]madmin.HealDriveInfo, n)
+	res.After.Drives = make([]madmin.HealDriveInfo, n)
+	for index, clnt := range rs3s.clnts {
+		res.Before.Drives[index] = madmin.HealDriveInfo{
+			Endpoint: clnt.EndpointURL().Host + SlashSeparator + clnt.Bucket,
+			State:    replicaHealState(oinfos[index], errs[index], radioTag),
+		}
+		res.After.Drives[index] = res.Before.Drives[index]
+	}
+	if opts.DryRun {
+		return res, nil
+	}
+
+	for index, clnt := range rs3s.clnts {
+		if res.Before.Drives[index].State == madmin.DriveStateOk ||
+			res.Before.Drives[index].State == madmin.DriveStateOffline ||
+			clnt.ReadOnly || clnt.Shadow {
+			continue
+		}
+		if herr := l.healSys.healCopy(ctx, rs3s.clnts[src], clnt, object, nil); herr != nil {
+			err = ErrorRespToObjectError(herr, bucket, object)
+			continue
+		}
+		res.After.Drives[index].State = madmin.DriveStateOk
+	}
+	l.listCache.invalidate(bucket, object)
+	return res, err
+}
+
+// replicaHealState returns the heal state of a replica stated as info
+// and err, where radioTag tags the authoritative version of the object.
+func replicaHealState(info miniogo.ObjectInfo, err error, radioTag string) string {
+	switch {
+	case err == nil && info.Metadata.Get(globalRadioTagKey) == radioTag:
+		return madmin.DriveStateOk
+	case err == nil:
+		return madmin.DriveStateCorrupt
+	case miniogo.ToErrorResponse(err).Code == "NoSuchKey":
+		return madmin.DriveStateMissing
+	}
+	return madmin.DriveStateOffline
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/minio/minio/pkg/madmin"
+)
+
+// Tests that HealObject reports the state of each replica and copies
+// the newest version onto the others.
+func TestHealObject(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		replicas       []testReplica
+		opts           madmin.HealOpts
+		shouldPass     bool
+		expectedBefore []string
+		expectedAfter  []string
+		expectedPuts   []int
+	}{
+		// Newest version healed onto a missing and a stale replica.
+		{
+			[]testReplica{{"new", now}, {}, {"old", now.Add(-time.Hour)}},
+			madmin.HealOpts{}, true,
+			[]string{madmin.DriveStateOk, madmin.DriveStateMissing, madmin.DriveStateCorrupt},
+			[]string{madmin.DriveStateOk, madmin.DriveStateOk, madmin.DriveStateOk},
+			[]int{0, 1, 1},
+		},
+		// Dry runs only report.
+		{
+			[]testReplica{{"old", now.Add(-time.Hour)}, {"new", now}},
+			madmin.HealOpts{DryRun: true}, true,
+			[]string{madmin.DriveStateCorrupt, madmin.DriveStateOk},
+			[]string{madmin.DriveStateCorrupt, madmin.DriveStateOk},
+			[]int{0, 0},
+		},
+		// Object missing everywhere.
+		{
+			[]testReplica{{}, {}},
+			madmin.HealOpts{}, false, nil, nil, []int{0, 0},
+		},
+	}
+
+	for i, testCase := range testCases {
+		remotes, clnts, shutdown := newTestReplicas(t, testCase.replicas...)
+		defer shutdown()
+		l := &radioObjects{
+			nsMutex:       newNSLock(false),
+			mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+		}
+
+		res, err := l.HealObject(context.Background(), "bucket", "object", testCase.opts)
+		if testCase.shouldPass && err != nil {
+			t.Errorf("Test %d: Unexpected error %v", i+1, err)
+			continue
+		}
+		if !testCase.shouldPass {
+			if err == nil {
+				t.Errorf("Test %d: Expected an error", i+1)
+			}
+			continue
+		}
+		for index, remote := range remotes {
+			if state := res.Before.Drives[index].State; state != testCase.expectedBefore[index] {
+				t.Errorf("Test %d: Expected replica %d to be %s before heal, got %s", i+1, index, testCase.expectedBefore[index], state)
+			}
+			if state := res.After.Drives[index].State; state != testCase.expectedAfter[index] {
+				t.Errorf("Test %d: Expected replica %d to be %s after heal, got %s", i+1, index, testCase.expectedAfter[index], state)
+			}
+			if puts := len(remote.writtenTags()); puts != testCase.expectedPuts[index] {
+				t.Errorf("Test %d: Expected %d writes to replica %d, got %d", i+1, testCase.expectedPuts[index], index, puts)
+			}
+		}
+	}
+}
@@ -0,0 +1,756 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/minio-go/v6/pkg/encrypt"
+	"github.com/minio/radio/cmd/logger"
+)
+
+// Default interval between heal journal scans.
+const defaultHealInterval = time.Minute
+
+// Default duration a heal may hold the lease of its journal entry.
+const defaultJournalClaimTTL = 5 * time.Minute
+
+// journalClaimWait is how long a heal waits for the lease of an entry
+// held by a peer before moving on to the next entry.
+const journalClaimWait = time.Second
+
+// journalClaimPrefix is the namespace of the journal entry leases below
+// the meta bucket.
+const journalClaimPrefix = "radio/journal"
+
+// healPolicy decides which replica holds the authoritative copy of a
+// journaled object.
+type healPolicy string
+
+// Supported heal policies.
+const (
+	// The replica that succeeded the journaled write.
+	healPolicySucceeded healPolicy = "succeeded"
+	// The replica with the most recently modified copy.
+	healPolicyNewest healPolicy = "newest"
+	// A designated primary replica.
+	healPolicyPrimary healPolicy = "primary"
+)
+
+func parseHealPolicy(policy string) (healPolicy, error) {
+	switch p := healPolicy(policy); p {
+	case "":
+		return healPolicySucceeded, nil
+	case healPolicySucceeded, healPolicyNewest, healPolicyPrimary:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown heal policy %q", policy)
+}
+
+// healSys replays the heal journal, copying the authoritative version of
+// each journaled object onto the replicas that missed the write.
+type healSys struct {
+	layer    *radioObjects
+	store    journalStore
+	interval time.Duration
+	policy   healPolicy
+	primary  int
+	webhook  *webhookNotifier
+	// Heal attempts and age after which entries are given up.
+	maxRetries int
+	ttl        time.Duration
+	claimTTL   time.Duration
+	// Heal the entries of each bucket separately, by up to
+	// bucketConcurrency heals at a time.
+	isolateBuckets    bool
+	bucketConcurrency int
+	// Journaled deletes win over the copies they missed, see
+	// tombstoned.
+	deleteWins bool
+	// Heals replicas block by block if not nil.
+	blockHeal *blockHealer
+	// Checkpoints the heals of large objects if not nil.
+	checkpoint *healCheckpointer
+
+	mu sync.Mutex
+	// Customer keys of the SSE-C encrypted objects journaled by this
+	// process, by entry ID.
+	keys map[string]encrypt.ServerSide
+	// Radio tags of the quarantined objects by quarantineKey, see
+	// refreshQuarantine.
+	quarantine map[string]string
+}
+
+func newHealSys(layer *radioObjects, store journalStore, cfg journalConfig, webhook *webhookNotifier) (*healSys, error) {
+	policy, err := parseHealPolicy(cfg.Policy)
+	if err != nil {
+		return nil, err
+	}
+	blockHeal, err := newBlockHealer(cfg.BlockHeal)
+	if err != nil {
+		return nil, err
+	}
+	checkpoint, err := newHealCheckpointer(cfg.Checkpoint)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MaxRetries < 0 || cfg.TTL < 0 || cfg.ClaimTTL < 0 || cfg.BucketConcurrency < 0 {
+		return nil, fmt.Errorf("journal max_retries, ttl, claim_ttl and bucket_concurrency must not be negative")
+	}
+	bucketConcurrency := cfg.BucketConcurrency
+	if bucketConcurrency == 0 {
+		bucketConcurrency = 1
+	}
+	claimTTL := cfg.ClaimTTL
+	if claimTTL == 0 {
+		claimTTL = defaultJournalClaimTTL
+	}
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = defaultHealInterval
+	}
+	return &healSys{
+		layer:      layer,
+		store:      store,
+		interval:   interval,
+		policy:     policy,
+		primary:    cfg.Primary,
+		webhook:    webhook,
+		maxRetries: cfg.MaxRetries,
+		ttl:        cfg.TTL,
+		claimTTL:   claimTTL,
+
+		isolateBuckets:    cfg.IsolateBuckets,
+		bucketConcurrency: bucketConcurrency,
+		deleteWins:        cfg.DeleteWins,
+		blockHeal:         blockHeal,
+		checkpoint:        checkpoint,
+
+		keys:       make(map[string]encrypt.ServerSide),
+		quarantine: make(map[string]string),
+	}, nil
+}
+
+// queue journals entry for healing, a nil healSys silently drops it.
+func (h *healSys) queue(ctx context.Context, entry journalEntry) {
+	if h == nil || len(entry.DstClientIDs) == 0 {
+		return
+	}
+	if entry.RadioTag != "" && h.quarantinedVersion(entry) {
+		// Heals of this version were given up already.
+		return
+	}
+	entry.ID = mustGetUUID()
+	entry.Timestamp = UTCNow()
+	entry.sse = ssecKey(entry.sse)
+	entry.SSEC = entry.sse != nil
+	if entry.SSEC {
+		h.mu.Lock()
+		h.keys[entry.ID] = entry.sse
+		h.mu.Unlock()
+	}
+	if err := h.store.Save(entry); err != nil {
+		h.forget(entry.ID)
+		logger.LogIf(ctx, err)
+		return
+	}
+	h.webhook.notify(webhookEvent{
+		Event:    webhookHealQueued,
+		Bucket:   entry.Bucket,
+		Object:   entry.Object,
+		Op:       entry.Op,
+		RadioTag: entry.RadioTag,
+	})
+}
+
+// run heals journaled entries every interval until ctx is canceled.
+func (h *healSys) run(ctx context.Context) {
+	h.refreshQuarantine(ctx)
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.healAll(ctx)
+		}
+	}
+}
+
+func (h *healSys) healAll(ctx context.Context) {
+	h.refreshQuarantine(ctx)
+	entries, err := h.store.List()
+	if err != nil {
+		logger.LogIf(ctx, err)
+		return
+	}
+	h.meterBacklog(entries)
+	tombstones := journalTombstones(entries)
+	if h.isolateBuckets {
+		h.healBuckets(ctx, entries, tombstones)
+		return
+	}
+	for _, entry := range entries {
+		h.heal(ctx, entry, tombstones)
+	}
+}
+
+// healBuckets heals the entries of each bucket separately, by up to
+// bucketConcurrency heals at a time. No heal is started on a bucket
+// after the heal interval, the entries left are healed by the next
+// passes, so that a bucket whose remotes fail or hang cannot hold up
+// the heals of the other buckets.
+func (h *healSys) healBuckets(ctx context.Context, entries []journalEntry, tombstones tombstones) {
+	buckets := make(map[string][]journalEntry)
+	for _, entry := range entries {
+		buckets[entry.Bucket] = append(buckets[entry.Bucket], entry)
+	}
+	// Heals started in time run to completion, bounded by the claim
+	// TTL.
+	dctx, cancel := context.WithTimeout(ctx, h.interval)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, entries := range buckets {
+		work := make(chan journalEntry)
+		for i := 0; i < h.bucketConcurrency && i < len(entries); i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for entry := range work {
+					h.heal(ctx, entry, tombstones)
+				}
+			}()
+		}
+		wg.Add(1)
+		go func(entries []journalEntry) {
+			defer wg.Done()
+			defer close(work)
+			for _, entry := range entries {
+				select {
+				case work <- entry:
+				case <-dctx.Done():
+					return
+				}
+			}
+		}(entries)
+	}
+	wg.Wait()
+}
+
+// meterBacklog reports the journaled entries of each bucket.
+func (h *healSys) meterBacklog(entries []journalEntry) {
+	backlog := make(map[string]int)
+	for bucket := range h.layer.mirrorClients {
+		backlog[bucket] = 0
+	}
+	for _, entry := range entries {
+		backlog[entry.Bucket]++
+	}
+	for bucket, n := range backlog {
+		healBacklog.WithLabelValues(bucket).Set(float64(n))
+	}
+}
+
+// heal claims and heals entry, leaving it to the peer holding its lease
+// if any.
+func (h *healSys) heal(ctx context.Context, entry journalEntry, tombstones tombstones) {
+	release, ok := h.claim(ctx, entry.ID)
+	if !ok {
+		return
+	}
+	hctx, cancel := context.WithTimeout(ctx, h.claimTTL)
+	hctx = logger.WithFields(hctx, logger.FieldOp, "Heal", logger.FieldBucket, entry.Bucket,
+		logger.FieldObject, entry.Object, "entry", entry.ID)
+	h.healClaimed(hctx, entry, tombstones)
+	cancel()
+	release()
+}
+
+// claim leases entry id to this process for the duration of its heal,
+// so that radio peers sharing the journal never heal an entry twice.
+// The lease is a lock on the namespace of the entry, distributed among
+// the peers like object locks and freed by their lock maintenance if
+// the holder dies. It returns false if a peer holds the lease or healed
+// the entry meanwhile.
+func (h *healSys) claim(ctx context.Context, id string) (release func(), ok bool) {
+	lock := h.layer.NewNSLock(ctx, minioMetaBucket, pathJoin(journalClaimPrefix, id))
+	if err := lock.GetLock(newDynamicTimeout(journalClaimWait, journalClaimWait)); err != nil {
+		return nil, false
+	}
+	if ok, err := h.store.Contains(id); err != nil || !ok {
+		logger.LogIf(ctx, err)
+		lock.Unlock()
+		return nil, false
+	}
+	return lock.Unlock, true
+}
+
+// healClaimed heals entry, whose lease is held by this process.
+func (h *healSys) healClaimed(ctx context.Context, entry journalEntry, tombstones tombstones) {
+	if tombstones.supersedes(entry) {
+		// Object was deleted since, healing the write would
+		// resurrect it on the replicas the delete reached.
+		h.abortCheckpoints(ctx, entry)
+		logger.LogIf(ctx, h.store.Remove(entry.ID))
+		h.forget(entry.ID)
+		return
+	}
+	h.mu.Lock()
+	entry.sse = h.keys[entry.ID]
+	h.mu.Unlock()
+	op := entry.Op.metricLabel()
+	start := time.Now()
+	err := h.layer.healEntry(ctx, &entry)
+	healDuration.WithLabelValues(op).Observe(time.Since(start).Seconds())
+	if err != nil {
+		healFailed.WithLabelValues(op).Inc()
+		logger.LogIf(ctx, err)
+		h.healFailed(ctx, &entry, err)
+		if h.expired(entry) {
+			h.bury(ctx, entry, err)
+		}
+		return
+	}
+	healCompleted.WithLabelValues(op).Inc()
+	// Uploads checkpointed by previous heals of an object superseded
+	// since are left over.
+	h.abortCheckpoints(ctx, entry)
+	logger.LogIf(ctx, h.store.Remove(entry.ID))
+	h.forget(entry.ID)
+}
+
+// flush makes the journaled entries durable, returning their count. It
+// is safe to call at any time and fails with NotImplemented if the
+// journal is disabled.
+func (h *healSys) flush() (int, error) {
+	if h == nil {
+		return 0, NotImplemented{}
+	}
+	return h.store.Flush()
+}
+
+// forget drops the customer key of entry id if any.
+func (h *healSys) forget(id string) {
+	h.mu.Lock()
+	delete(h.keys, id)
+	h.mu.Unlock()
+}
+
+// healFailed counts a failed heal of entry in the journal, so that its
+// retries survive restarts and are shared by the peers, reporting
+// entries failing repeatedly once to the webhook.
+func (h *healSys) healFailed(ctx context.Context, entry *journalEntry, err error) {
+	entry.Failures++
+	if entry.FirstFailure.IsZero() {
+		entry.FirstFailure = UTCNow()
+	}
+	logger.LogIf(ctx, h.store.Save(*entry))
+	if h.webhook == nil || entry.Failures != h.webhook.failures {
+		return
+	}
+	h.webhook.notify(webhookEvent{
+		Event:    webhookHealFailed,
+		Bucket:   entry.Bucket,
+		Object:   entry.Object,
+		Op:       entry.Op,
+		RadioTag: entry.RadioTag,
+		Failures: entry.Failures,
+		Error:    err.Error(),
+	})
+}
+
+// healEntry brings the destination replicas of entry in line with its
+// source replica, recording in entry the checkpoints of interrupted
+// copies.
+func (l *radioObjects) healEntry(ctx context.Context, entry *journalEntry) error {
+	rs3s, ok := l.mirrorClients[entry.Bucket]
+	if !ok {
+		// Bucket is no longer configured, nothing to heal.
+		return nil
+	}
+	if !entry.replicasConfigured(len(rs3s.clnts)) {
+		// Remotes were removed from the bucket since, the replicas of
+		// the entry are gone.
+		logger.LogIf(ctx, fmt.Errorf("heal entry %s of %s/%s refers to replicas no longer configured, dropping it",
+			entry.ID, entry.Bucket, entry.Object))
+		return nil
+	}
+
+	objectLock := l.NewNSLock(ctx, entry.Bucket, entry.Object)
+	if err := objectLock.GetLock(globalObjectTimeout); err != nil {
+		return err
+	}
+	defer objectLock.Unlock()
+
+	src := rs3s.clnts[entry.SrcClientID]
+	if entry.Op == opDeleteObject {
+		for _, index := range entry.DstClientIDs {
+			dst := rs3s.clnts[index]
+			info, err := dst.StatObjectWithContext(ctx, dst.Bucket, dst.objectKey(entry.Object), miniogo.StatObjectOptions{})
+			if err != nil {
+				if miniogo.ToErrorResponse(err).Code == "NoSuchKey" {
+					continue
+				}
+				return ErrorRespToObjectError(err, entry.Bucket, entry.Object)
+			}
+			if !entry.tombstoneCovers(info.Metadata.Get(globalRadioTagKey), info.LastModified) {
+				// Object was written again since, a later write supersedes this entry.
+				continue
+			}
+			if err = dst.RemoveObject(dst.Bucket, dst.objectKey(entry.Object)); err != nil {
+				return ErrorRespToObjectError(err, entry.Bucket, entry.Object)
+			}
+		}
+		return nil
+	}
+
+	if entry.SSEC && entry.sse == nil {
+		return errHealKeyUnavailable
+	}
+
+	if l.healSys.policy != healPolicySucceeded {
+		return l.healByPolicy(ctx, rs3s, *entry)
+	}
+
+	srcInfo, err := src.StatObject(src.Bucket, src.objectKey(entry.Object), miniogo.StatObjectOptions{
+		GetObjectOptions: miniogo.GetObjectOptions{ServerSideEncryption: entry.sse},
+	})
+	if err != nil {
+		if miniogo.ToErrorResponse(err).Code == "NoSuchKey" {
+			// Object was removed since, a later delete supersedes this entry.
+			return nil
+		}
+		return ErrorRespToObjectError(err, entry.Bucket, entry.Object)
+	}
+	if srcInfo.Metadata.Get(globalRadioTagKey) != entry.RadioTag {
+		// Object was overwritten since, a later write supersedes this entry.
+		return nil
+	}
+
+	for _, index := range entry.DstClientIDs {
+		if err = l.healSys.healEntryCopy(ctx, entry, src, rs3s.clnts[index], index, srcInfo); err != nil {
+			return ErrorRespToObjectError(err, entry.Bucket, entry.Object)
+		}
+	}
+	return nil
+}
+
+// replicasConfigured returns true if the source and destination
+// replicas of entry are among the n replicas of its bucket.
+func (entry journalEntry) replicasConfigured(n int) bool {
+	if entry.SrcClientID < 0 || entry.SrcClientID >= n {
+		return false
+	}
+	for _, index := range entry.DstClientIDs {
+		if index < 0 || index >= n {
+			return false
+		}
+	}
+	return true
+}
+
+// tombstones maps journaled objects to the time of their latest
+// journaled delete.
+type tombstones map[string]time.Time
+
+// journalTombstones returns the tombstones recorded by the delete
+// entries among entries.
+func journalTombstones(entries []journalEntry) tombstones {
+	t := make(tombstones)
+	for _, entry := range entries {
+		if entry.Op != opDeleteObject {
+			continue
+		}
+		key := pathJoin(entry.Bucket, entry.Object)
+		if entry.Timestamp.After(t[key]) {
+			t[key] = entry.Timestamp
+		}
+	}
+	return t
+}
+
+// supersedes returns true if entry journals a write followed by a
+// journaled delete of the same object.
+func (t tombstones) supersedes(entry journalEntry) bool {
+	if entry.Op == opDeleteObject {
+		return false
+	}
+	deleted, ok := t[pathJoin(entry.Bucket, entry.Object)]
+	return ok && !entry.Timestamp.After(deleted)
+}
+
+// tombstoneCovers returns true if the delete journaled by entry applies
+// to a replica holding the version radioTag modified at modTime, that
+// is the deleted version or any version written before the delete.
+// Content derived tags are shared by rewrites of the deleted content,
+// only their modification time tells them apart.
+func (entry journalEntry) tombstoneCovers(radioTag string, modTime time.Time) bool {
+	if entry.RadioTag != "" && radioTag == entry.RadioTag && !isContentRadioTag(radioTag) {
+		return true
+	}
+	return !modTime.After(entry.Timestamp)
+}
+
+// healByPolicy stats all replicas of the journaled object, picks the
+// authoritative copy according to the configured heal policy and
+// copies it onto every replica holding a different version.
+func (l *radioObjects) healByPolicy(ctx context.Context, rs3s mirrorConfig, entry journalEntry) error {
+	n := len(rs3s.clnts)
+	oinfos := make([]miniogo.ObjectInfo, n)
+	errs := make([]error, n)
+	for index, clnt := range rs3s.clnts {
+		if clnt.Shadow {
+			// Shadow replicas are neither heal sources nor healed.
+			errs[index] = errShadowReplica
+			continue
+		}
+		oinfos[index], errs[index] = clnt.StatObjectWithContext(ctx, clnt.Bucket,
+			clnt.objectKey(entry.Object), miniogo.StatObjectOptions{
+				GetObjectOptions: miniogo.GetObjectOptions{ServerSideEncryption: entry.sse},
+			})
+		if errs[index] != nil && miniogo.ToErrorResponse(errs[index]).Code != "NoSuchKey" {
+			return ErrorRespToObjectError(errs[index], entry.Bucket, entry.Object)
+		}
+	}
+
+	src := l.healSys.healSource(oinfos, errs, entry.SrcClientID)
+	if src < 0 {
+		// No replica holds the object anymore, nothing to heal.
+		return nil
+	}
+
+	radioTag := oinfos[src].Metadata.Get(globalRadioTagKey)
+	for index := range oinfos {
+		if index == src || rs3s.clnts[index].ReadOnly || rs3s.clnts[index].Shadow ||
+			(errs[index] == nil && oinfos[index].Metadata.Get(globalRadioTagKey) == radioTag) {
+			continue
+		}
+		if err := l.healSys.healCopy(ctx, rs3s.clnts[src], rs3s.clnts[index], entry.Object, entry.sse); err != nil {
+			return ErrorRespToObjectError(err, entry.Bucket, entry.Object)
+		}
+	}
+	return nil
+}
+
+// healSource returns the replica holding the authoritative copy of an
+// object stated in oinfos and errs according to the heal policy, or -1
+// if no replica holds it. Replica fallback is the source of policies
+// designating no replica.
+func (h *healSys) healSource(oinfos []miniogo.ObjectInfo, errs []error, fallback int) int {
+	holds := func(index int) bool {
+		return index >= 0 && index < len(errs) && errs[index] == nil
+	}
+	switch h.policy {
+	case healPolicyNewest:
+		return newestReplica(oinfos, errs)
+	case healPolicyPrimary:
+		if holds(h.primary) {
+			return h.primary
+		}
+	}
+	if holds(fallback) {
+		return fallback
+	}
+	return -1
+}
+
+// pendingWrite returns the latest journaled write of object still
+// pending heal. A nil healSys has no pending writes.
+func (h *healSys) pendingWrite(ctx context.Context, bucket, object string) (journalEntry, bool) {
+	if h == nil {
+		return journalEntry{}, false
+	}
+	entries, err := h.store.List()
+	if err != nil {
+		logger.LogIf(ctx, err)
+		return journalEntry{}, false
+	}
+	tombstones := journalTombstones(entries)
+	var pending *journalEntry
+	for i, entry := range entries {
+		if entry.Bucket != bucket || entry.Object != object || entry.Op == opDeleteObject ||
+			tombstones.supersedes(entry) {
+			continue
+		}
+		if pending == nil || entry.Timestamp.After(pending.Timestamp) {
+			pending = &entries[i]
+		}
+	}
+	if pending == nil {
+		return journalEntry{}, false
+	}
+	return *pending, true
+}
+
+// tombstoned returns true if object, stated in oinfos and errs, is
+// missing on some replicas while the latest journaled delete of object
+// still pending heal covers the copies held by the others. These copies
+// are stragglers of the delete, which heal removes, and object is then
+// deleted rather than served. Always false unless deletes win.
+func (h *healSys) tombstoned(ctx context.Context, bucket, object string, oinfos []miniogo.ObjectInfo, errs []error) bool {
+	if h == nil || !h.deleteWins {
+		return false
+	}
+	var missing, present bool
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			present = true
+		case miniogo.ToErrorResponse(err).Code == "NoSuchKey":
+			missing = true
+		}
+	}
+	if !missing || !present {
+		return false
+	}
+
+	entries, err := h.store.List()
+	if err != nil {
+		logger.LogIf(ctx, err)
+		return false
+	}
+	var tombstone *journalEntry
+	for i, entry := range entries {
+		if entry.Bucket != bucket || entry.Object != object || entry.Op != opDeleteObject {
+			continue
+		}
+		if tombstone == nil || entry.Timestamp.After(tombstone.Timestamp) {
+			tombstone = &entries[i]
+		}
+	}
+	if tombstone == nil {
+		return false
+	}
+	for i, err := range errs {
+		if err == nil && !tombstone.tombstoneCovers(oinfos[i].Metadata.Get(globalRadioTagKey), oinfos[i].LastModified) {
+			// Object was written again since the delete.
+			return false
+		}
+	}
+	return true
+}
+
+// pendingSource returns the position in replicas of the source replica
+// of the latest journaled write of object still pending heal, if it
+// holds the journaled version according to oinfos and errs, -1
+// otherwise.
+func (h *healSys) pendingSource(ctx context.Context, bucket, object string, replicas []int,
+	oinfos []miniogo.ObjectInfo, errs []error) int {
+	pending, ok := h.pendingWrite(ctx, bucket, object)
+	if !ok {
+		return -1
+	}
+	for i, index := range replicas {
+		if index != pending.SrcClientID || errs[i] != nil {
+			continue
+		}
+		if pending.RadioTag == "" || oinfos[i].Metadata.Get(globalRadioTagKey) == pending.RadioTag {
+			return i
+		}
+	}
+	return -1
+}
+
+// newestReplica returns the replica holding the most recently modified
+// copy of an object stated in oinfos and errs, or -1 if no replica
+// holds it.
+func newestReplica(oinfos []miniogo.ObjectInfo, errs []error) int {
+	src := -1
+	for index := range oinfos {
+		if errs[index] == nil && (src < 0 || oinfos[index].LastModified.After(oinfos[src].LastModified)) {
+			src = index
+		}
+	}
+	return src
+}
+
+// healObjectCopy streams object from src to dst along with its metadata,
+// storage class and ACL, encrypted with the customer key sse if not nil.
+func healObjectCopy(ctx context.Context, src, dst bucketClient, object string, sse encrypt.ServerSide) error {
+	reader, info, header, err := src.GetObjectWithContext(ctx, src.Bucket, src.objectKey(object),
+		miniogo.GetObjectOptions{ServerSideEncryption: sse})
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	// Unlike StatObject, GetObject does not parse Expires.
+	if expires, perr := time.Parse(http.TimeFormat, header.Get("Expires")); perr == nil {
+		info.Expires = expires.UTC()
+	}
+
+	metadata, err := healObjectMetadata(ctx, src, object, info)
+	if err != nil {
+		return err
+	}
+	metadata = dst.storageClassMetadata(metadata)
+	_, err = dst.PutObjectWithContext(ctx, dst.Bucket, dst.objectKey(object), reader, info.Size,
+		"", "", metadata, sse)
+	dst.downgradeStorageClass(ctx, metadata, err)
+	return err
+}
+
+// healObjectMetadata returns the metadata and ACL of the copy of object
+// stated as info held by src, to be reproduced on a healed replica.
+func healObjectMetadata(ctx context.Context, src bucketClient, object string, info miniogo.ObjectInfo) (map[string]string, error) {
+	metadata := healMetadata(info)
+	acl, err := src.GetObjectACLWithContext(ctx, src.Bucket, src.objectKey(object))
+	if err != nil && miniogo.ToErrorResponse(err).Code != "NotImplemented" {
+		return nil, err
+	}
+	if err == nil {
+		for k, v := range aclMetadata(acl) {
+			metadata[k] = v
+		}
+	}
+	return metadata, nil
+}
+
+// healMetadata returns the metadata of info to be reproduced on a
+// healed replica. Content-Type and Expires are left out of the stated
+// metadata by minio-go and taken from their own fields.
+func healMetadata(info miniogo.ObjectInfo) map[string]string {
+	metadata := make(map[string]string)
+	for k, v := range info.Metadata {
+		if key := http.CanonicalHeaderKey(k); isObjectMetadataKey(key) {
+			metadata[key] = v[0]
+		}
+	}
+	if info.ContentType != "" {
+		metadata["Content-Type"] = info.ContentType
+	}
+	if !info.Expires.IsZero() {
+		metadata["Expires"] = info.Expires.UTC().Format(http.TimeFormat)
+	}
+	return metadata
+}
+
+// copyableMetadata returns the object metadata in userDefined to be set
+// on a new object written with its content, the radio tag is left out
+// to be regenerated.
+func copyableMetadata(userDefined map[string]string) map[string]string {
+	metadata := make(map[string]string)
+	for k, v := range userDefined {
+		key := http.CanonicalHeaderKey(k)
+		if isObjectMetadataKey(key) && key != globalRadioTagKey {
+			metadata[key] = v
+		}
+	}
+	return metadata
+}
+
+// isObjectMetadataKey returns true if the canonical header key is part
+// of the metadata stored along with an object.
+func isObjectMetadataKey(key string) bool {
+	switch key {
+	case "Content-Type", "Content-Encoding", "Content-Disposition",
+		"Content-Language", "Cache-Control", "Expires", "X-Amz-Storage-Class":
+		return true
+	}
+	return strings.HasPrefix(strings.ToLower(key), "x-amz-meta-")
+}
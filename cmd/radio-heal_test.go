@@ -0,0 +1,580 @@
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// Tests that a delete journaled after a partially failed write keeps
+// heal from resurrecting the object, while later writes still heal.
+func TestHealTombstones(t *testing.T) {
+	now := time.Now().UTC()
+
+	// Write of v1 reached replica 1 only, the delete then reached
+	// replica 0 only: replica 1 still holds v1.
+	put := journalEntry{
+		Bucket: "bucket", Object: "object", Op: opPutObject, RadioTag: "v1",
+		SrcClientID: 1, DstClientIDs: []int{0}, Timestamp: now,
+	}
+	del := journalEntry{
+		Bucket: "bucket", Object: "object", Op: opDeleteObject, RadioTag: "v1",
+		SrcClientID: 0, DstClientIDs: []int{1}, Timestamp: now.Add(time.Second),
+	}
+	later := journalEntry{
+		Bucket: "bucket", Object: "object", Op: opPutObject, RadioTag: "v2",
+		SrcClientID: 0, DstClientIDs: []int{1}, Timestamp: now.Add(2 * time.Second),
+	}
+	other := journalEntry{
+		Bucket: "bucket", Object: "other", Op: opPutObject, RadioTag: "v1",
+		SrcClientID: 1, DstClientIDs: []int{0}, Timestamp: now,
+	}
+
+	tombstones := journalTombstones([]journalEntry{put, del, later, other})
+	testCases := []struct {
+		entry      journalEntry
+		superseded bool
+	}{
+		{put, true},
+		{del, false},
+		{later, false},
+		{other, false},
+	}
+	for i, testCase := range testCases {
+		if got := tombstones.supersedes(testCase.entry); got != testCase.superseded {
+			t.Errorf("Test %d: expected superseded %v, got %v", i+1, testCase.superseded, got)
+		}
+	}
+
+	coverCases := []struct {
+		radioTag string
+		modTime  time.Time
+		covered  bool
+	}{
+		// The deleted version is removed by heal.
+		{"v1", now, true},
+		// So is any version written before the delete.
+		{"v0", now.Add(-time.Minute), true},
+		// A version written after the delete is kept.
+		{"v2", now.Add(2 * time.Second), false},
+	}
+	for i, testCase := range coverCases {
+		if got := del.tombstoneCovers(testCase.radioTag, testCase.modTime); got != testCase.covered {
+			t.Errorf("Test %d: expected covered %v, got %v", i+1, testCase.covered, got)
+		}
+	}
+}
+
+// Tests that objects whose replicas diverge are read from the source
+// replica of a write pending heal.
+func TestGetObjectHealPending(t *testing.T) {
+	now := time.Now()
+	dir, err := ioutil.TempDir("", "radio-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	testCases := []struct {
+		entries    []journalEntry
+		shouldPass bool
+	}{
+		// Replica 1 missed the write of v2, pending heal.
+		{[]journalEntry{{ID: "1", Bucket: "bucket", Object: "object", Op: opPutObject, RadioTag: "v2",
+			SrcClientID: 0, DstClientIDs: []int{1}, Timestamp: now}}, true},
+		// Nothing journaled, replicas disagree.
+		{nil, false},
+		// Journaled version is on neither replica.
+		{[]journalEntry{{ID: "2", Bucket: "bucket", Object: "object", Op: opPutObject, RadioTag: "v3",
+			SrcClientID: 0, DstClientIDs: []int{1}, Timestamp: now}}, false},
+		// Write was deleted since.
+		{[]journalEntry{
+			{ID: "3", Bucket: "bucket", Object: "object", Op: opPutObject, RadioTag: "v2",
+				SrcClientID: 0, DstClientIDs: []int{1}, Timestamp: now},
+			{ID: "4", Bucket: "bucket", Object: "object", Op: opDeleteObject, RadioTag: "v2",
+				SrcClientID: 1, DstClientIDs: []int{0}, Timestamp: now.Add(time.Second)},
+		}, false},
+	}
+
+	for i, testCase := range testCases {
+		store := &dirJournalStore{dir: dir}
+		for _, entry := range testCase.entries {
+			if err = store.Save(entry); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		_, clnts, shutdown := newTestReplicas(t, testReplica{"v2", now}, testReplica{"v1", now.Add(-time.Hour)})
+		defer shutdown()
+		l := &radioObjects{
+			nsMutex:       newNSLock(false),
+			mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+		}
+		if l.healSys, err = newHealSys(l, store, journalConfig{}, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		gr, err := l.GetObjectNInfo(context.Background(), "bucket", "object", nil, nil, ReadLock, ObjectOptions{})
+		if testCase.shouldPass && err != nil {
+			t.Errorf("Test %d: Unexpected error %v", i+1, err)
+		}
+		if !testCase.shouldPass && err == nil {
+			t.Errorf("Test %d: Expected an error", i+1)
+		}
+		if err == nil {
+			data, err := ioutil.ReadAll(gr)
+			gr.Close()
+			if err != nil || string(data) != "data" {
+				t.Errorf("Test %d: Unexpected read %q, error %v", i+1, data, err)
+			}
+			if gr.ObjInfo.ReplicaIndex != 0 {
+				t.Errorf("Test %d: Expected read from replica 0, got %d", i+1, gr.ObjInfo.ReplicaIndex)
+			}
+		}
+
+		for _, entry := range testCase.entries {
+			if err = store.Remove(entry.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+// Tests that flushing the journal reports the entries saved, and fails
+// if the journal is disabled.
+func TestHealSysFlush(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "radio-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	var h *healSys
+	if _, err = h.flush(); err == nil {
+		t.Errorf("Expected an error flushing a disabled journal")
+	}
+
+	store := &dirJournalStore{dir: tmpdir}
+	if h, err = newHealSys(&radioObjects{}, store, journalConfig{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"1", "2"} {
+		if err = store.Save(journalEntry{ID: id, Bucket: "bucket", Object: "object"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, expected := range []int{2, 2} {
+		n, err := h.flush()
+		if err != nil {
+			t.Fatalf("Test %d: Unexpected error %v", i+1, err)
+		}
+		if n != expected {
+			t.Errorf("Test %d: Expected %d entries flushed, got %d", i+1, expected, n)
+		}
+	}
+	if err = store.Remove("1"); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := h.flush(); err != nil || n != 1 {
+		t.Errorf("Expected 1 entry flushed, got %d (%v)", n, err)
+	}
+}
+
+// Tests that entries exceeding the journal max retries or TTL are moved
+// to the dead letter area, from where they are requeued or discarded.
+func TestHealDeadLetter(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "radio-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	// Heals of SSE-C encrypted objects whose key is unknown always fail.
+	l := &radioObjects{
+		nsMutex:       newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: []bucketClient{{}, {}}}},
+	}
+	store := &dirJournalStore{dir: tmpdir}
+	h, err := newHealSys(l, store, journalConfig{MaxRetries: 2, TTL: time.Hour}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := func() (live, dead int) {
+		entries, err := store.List()
+		if err != nil {
+			t.Fatal(err)
+		}
+		deadEntries, err := h.deadLetters()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(entries), len(deadEntries)
+	}
+
+	buried := testutil.ToFloat64(healDeadLettered.WithLabelValues("put"))
+	entry := journalEntry{ID: "1", Bucket: "bucket", Object: "object", Op: opPutObject,
+		DstClientIDs: []int{1}, Timestamp: UTCNow(), SSEC: true}
+	if err = store.Save(entry); err != nil {
+		t.Fatal(err)
+	}
+	h.healAll(context.Background())
+	if live, dead := count(); live != 1 || dead != 0 {
+		t.Fatalf("Expected 1 live entry after the first failure, got %d live and %d dead entries", live, dead)
+	}
+	entries, err := store.List()
+	if err != nil || len(entries) != 1 || entries[0].Failures != 1 || entries[0].FirstFailure.IsZero() {
+		t.Fatalf("Expected the failure recorded in the journaled entry, got %+v (%v)", entries, err)
+	}
+	// Failures are counted in the journal, across restarts.
+	if h, err = newHealSys(l, store, journalConfig{MaxRetries: 2, TTL: time.Hour}, nil); err != nil {
+		t.Fatal(err)
+	}
+	h.healAll(context.Background())
+	if live, dead := count(); live != 0 || dead != 1 {
+		t.Errorf("Expected the entry dead lettered after a restart, got %d live and %d dead entries", live, dead)
+	}
+	dead, err := h.deadLetters()
+	if err != nil || len(dead) != 1 || dead[0].Failures != 2 || dead[0].Error != errHealKeyUnavailable.Error() {
+		t.Fatalf("Unexpected dead letter entries %+v (%v)", dead, err)
+	}
+	if got := testutil.ToFloat64(healDeadLettered.WithLabelValues("put")) - buried; got != 1 {
+		t.Errorf("Expected 1 dead lettered entry counted, got %v", got)
+	}
+
+	if err = h.requeue("unknown"); err != errDeadLetterNotFound {
+		t.Errorf("Expected %v requeuing an unknown entry, got %v", errDeadLetterNotFound, err)
+	}
+	if err = h.requeue("1"); err != nil {
+		t.Fatal(err)
+	}
+	if live, dead := count(); live != 1 || dead != 0 {
+		t.Errorf("Expected the requeued entry back in the journal, got %d live and %d dead entries", live, dead)
+	}
+	if entries, err = store.List(); err != nil || len(entries) != 1 || entries[0].Failures != 0 ||
+		!entries[0].FirstFailure.IsZero() {
+		t.Errorf("Expected the failures of the requeued entry reset, got %+v (%v)", entries, err)
+	}
+	if err = store.Remove("1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Entries failing for longer than the TTL are given up on their next
+	// failure, however old they are.
+	if h, err = newHealSys(l, store, journalConfig{TTL: time.Hour}, nil); err != nil {
+		t.Fatal(err)
+	}
+	entry.ID, entry.Timestamp = "2", UTCNow().Add(-2*time.Hour)
+	if err = store.Save(entry); err != nil {
+		t.Fatal(err)
+	}
+	h.healAll(context.Background())
+	if live, dead := count(); live != 1 || dead != 0 {
+		t.Errorf("Expected an old entry retried, got %d live and %d dead entries", live, dead)
+	}
+	entry.Failures, entry.FirstFailure = 1, UTCNow().Add(-2*time.Hour)
+	if err = store.Save(entry); err != nil {
+		t.Fatal(err)
+	}
+	h.healAll(context.Background())
+	if live, dead := count(); live != 0 || dead != 1 {
+		t.Errorf("Expected the expired entry dead lettered, got %d live and %d dead entries", live, dead)
+	}
+	if err = h.discard("2"); err != nil {
+		t.Fatal(err)
+	}
+	if live, dead := count(); live != 0 || dead != 0 {
+		t.Errorf("Expected the discarded entry dropped, got %d live and %d dead entries", live, dead)
+	}
+}
+
+// Tests that two heal workers sharing a journal contend on the lease of
+// an entry, healing it exactly once.
+func TestHealClaim(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "radio-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	remotes, clnts, shutdown := newTestReplicas(t, testReplica{"v1", time.Now()}, testReplica{})
+	defer shutdown()
+	// Slow heals down so that the second worker contends on the lease.
+	remotes[1].setHook(func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method == http.MethodPut {
+			time.Sleep(200 * time.Millisecond)
+		}
+		return false
+	})
+
+	// Workers share the namespace lock like peers share their lockers.
+	l := &radioObjects{
+		nsMutex:       newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+	}
+	store := &dirJournalStore{dir: tmpdir}
+	workers := make([]*healSys, 2)
+	for i := range workers {
+		if workers[i], err = newHealSys(l, store, journalConfig{}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l.healSys = workers[0]
+
+	entry := journalEntry{ID: "1", Bucket: "bucket", Object: "object", Op: opPutObject,
+		RadioTag: "v1", SrcClientID: 0, DstClientIDs: []int{1}, Timestamp: UTCNow()}
+	if err = store.Save(entry); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for _, h := range workers {
+		wg.Add(1)
+		go func(h *healSys) {
+			defer wg.Done()
+			h.healAll(context.Background())
+		}(h)
+	}
+	wg.Wait()
+
+	if puts := len(remotes[1].writtenTags()); puts != 1 {
+		t.Errorf("Expected the entry healed once, got %d heals", puts)
+	}
+	if entries, err := store.List(); err != nil || len(entries) != 0 {
+		t.Errorf("Expected the healed entry removed, got %v (%v)", entries, err)
+	}
+	if release, ok := workers[1].claim(context.Background(), entry.ID); ok {
+		release()
+		t.Errorf("Expected no lease granted on a healed entry")
+	}
+}
+
+// Tests that with isolated buckets a bucket whose heals hang does not
+// hold up the heals of the other buckets, and that the backlog of each
+// bucket is metered.
+func TestHealIsolateBuckets(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "radio-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	var shutdowns []func()
+	defer func() {
+		for _, shutdown := range shutdowns {
+			shutdown()
+		}
+	}()
+	newClients := func(delay time.Duration) []bucketClient {
+		remotes, clnts, shutdown := newTestReplicas(t, testReplica{"v1", time.Now()}, testReplica{})
+		shutdowns = append(shutdowns, shutdown)
+		remotes[1].setHook(func(w http.ResponseWriter, r *http.Request) bool {
+			if r.Method == http.MethodPut {
+				time.Sleep(delay)
+			}
+			return false
+		})
+		return clnts
+	}
+	l := &radioObjects{
+		nsMutex: newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{
+			"slow":    {clnts: newClients(300 * time.Millisecond)},
+			"healthy": {clnts: newClients(0)},
+		},
+	}
+	store := &dirJournalStore{dir: tmpdir}
+	if l.healSys, err = newHealSys(l, store, journalConfig{Interval: 100 * time.Millisecond, IsolateBuckets: true}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, bucket := range []string{"slow", "slow", "slow", "healthy"} {
+		if err = store.Save(journalEntry{ID: strconv.Itoa(i), Bucket: bucket, Object: "object", Op: opPutObject,
+			RadioTag: "v1", SrcClientID: 0, DstClientIDs: []int{1}, Timestamp: UTCNow()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	start := time.Now()
+	l.healSys.healAll(context.Background())
+	if elapsed := time.Since(start); elapsed > 600*time.Millisecond {
+		t.Errorf("Expected the pass over the slow bucket cut short, took %v", elapsed)
+	}
+	entries, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	left := make(map[string]int)
+	for _, entry := range entries {
+		left[entry.Bucket]++
+	}
+	if left["healthy"] != 0 || left["slow"] != 2 {
+		t.Errorf("Expected the healthy bucket healed and 2 slow entries left, got %v", left)
+	}
+	if n := testutil.ToFloat64(healBacklog.WithLabelValues("slow")); n != 3 {
+		t.Errorf("Expected a backlog of 3 slow entries, got %v", n)
+	}
+}
+
+// Tests that with deletes winning, copies missed by a journaled delete
+// are neither served nor healed back, and are removed by heal.
+func TestHealDeleteWins(t *testing.T) {
+	now := time.Now()
+	dir, err := ioutil.TempDir("", "radio-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store := &dirJournalStore{dir: dir}
+	if err = store.Save(journalEntry{ID: "1", Bucket: "bucket", Object: "object", Op: opDeleteObject,
+		RadioTag: "v1", SrcClientID: 1, DstClientIDs: []int{0}, Timestamp: now}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Replica 0 missed the delete of v1.
+	remotes, clnts, shutdown := newTestReplicas(t, testReplica{"v1", now.Add(-time.Hour)}, testReplica{})
+	defer shutdown()
+	rs3s := mirrorConfig{clnts: clnts}
+	l := &radioObjects{
+		nsMutex:       newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{"bucket": rs3s},
+	}
+	if l.healSys, err = newHealSys(l, store, journalConfig{DeleteWins: true}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = l.GetObjectInfo(context.Background(), "bucket", "object", ObjectOptions{})
+	if _, ok := err.(ObjectNotFound); !ok {
+		t.Errorf("Expected the object deleted, got %v", err)
+	}
+	if err = newDivergenceScanner(l, time.Hour, 0).heal(context.Background(), "bucket", rs3s, "object"); err != nil {
+		t.Fatal(err)
+	}
+	if entries, err := store.List(); err != nil || len(entries) != 1 {
+		t.Errorf("Expected the scanner to leave the delete pending alone, got %d entries (%v)", len(entries), err)
+	}
+
+	// A version written after the delete is served.
+	remotes[0].putTaggedObject("object", "data", "v2", now.Add(time.Hour))
+	_, err = l.GetObjectInfo(context.Background(), "bucket", "object", ObjectOptions{})
+	if _, ok := err.(ObjectNotFound); ok {
+		t.Error("Expected a later write served")
+	}
+
+	remotes[0].putTaggedObject("object", "data", "v1", now.Add(-time.Hour))
+	l.healSys.healAll(context.Background())
+	if deletes, puts := remotes[0].requests(http.MethodDelete), remotes[0].writtenTags(); deletes != 1 || len(puts) != 0 {
+		t.Errorf("Expected the copy missed by the delete removed, got %d deletes and puts %v", deletes, puts)
+	}
+	if keys := remotes[0].keys(); len(keys) != 0 {
+		t.Errorf("Expected no copy left, got %v", keys)
+	}
+}
+
+// Tests that journaled writes are healed onto the replicas which missed
+// them, and that entries journaled for replicas no longer configured
+// are dropped.
+func TestHealJournal(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "radio-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	remotes, clnts, shutdown := newTestReplicas(t, testReplica{"v1", time.Now()}, testReplica{})
+	defer shutdown()
+	l := &radioObjects{
+		nsMutex:       newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+	}
+	store := &dirJournalStore{dir: tmpdir}
+	if l.healSys, err = newHealSys(l, store, journalConfig{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	h := l.healSys
+
+	for _, entry := range []journalEntry{
+		{Bucket: "bucket", Object: "object", Op: opPutObject, RadioTag: "v1", SrcClientID: 0, DstClientIDs: []int{1}},
+		{Bucket: "bucket", Object: "object", Op: opPutObject, RadioTag: "v1", SrcClientID: 0, DstClientIDs: []int{2}},
+		{Bucket: "bucket", Object: "object", Op: opPutObject, RadioTag: "v1", SrcClientID: 3, DstClientIDs: []int{0}},
+		{Bucket: "bucket", Object: "other", Op: opDeleteObject, RadioTag: "v1", SrcClientID: -1, DstClientIDs: []int{1}},
+	} {
+		h.queue(context.Background(), entry)
+	}
+	if entries, err := store.List(); err != nil || len(entries) != 4 {
+		t.Fatalf("Expected 4 entries journaled, got %d (%v)", len(entries), err)
+	}
+	h.healAll(context.Background())
+
+	if data, header, ok := remotes[1].object("object"); !ok || data != "data" || header.Get(globalRadioTagKey) != "v1" {
+		t.Errorf("Expected the object healed onto the second replica, got %q %v", data, header)
+	}
+	if entries, err := store.List(); err != nil || len(entries) != 0 {
+		t.Errorf("Expected all entries healed or dropped, got %+v (%v)", entries, err)
+	}
+}
+
+// Tests that each heal policy heals the replicas from the right source.
+func TestHealPolicies(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "radio-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	now := time.Now()
+	testCases := []struct {
+		policy       healPolicy
+		primary      int
+		replicas     []testReplica
+		expectedPuts [][]string
+	}{
+		// The replica that succeeded the write.
+		{healPolicySucceeded, 0,
+			[]testReplica{{"old", now.Add(-time.Hour)}, {"new", now}, {"mid", now.Add(-time.Minute)}},
+			[][]string{{"mid"}, {"mid"}, nil}},
+		// The most recently modified replica.
+		{healPolicyNewest, 0,
+			[]testReplica{{"old", now.Add(-time.Hour)}, {"new", now}, {"mid", now.Add(-time.Minute)}},
+			[][]string{{"new"}, nil, {"new"}}},
+		// The primary replica, even if older.
+		{healPolicyPrimary, 0,
+			[]testReplica{{"old", now.Add(-time.Hour)}, {"new", now}, {"mid", now.Add(-time.Minute)}},
+			[][]string{nil, {"old"}, {"old"}}},
+		// The replica that succeeded the write if the primary misses
+		// the object.
+		{healPolicyPrimary, 0,
+			[]testReplica{{}, {"new", now}, {"mid", now.Add(-time.Minute)}},
+			[][]string{{"mid"}, {"mid"}, nil}},
+	}
+
+	for i, testCase := range testCases {
+		remotes, clnts, shutdown := newTestReplicas(t, testCase.replicas...)
+		defer shutdown()
+		l := &radioObjects{
+			nsMutex:       newNSLock(false),
+			mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+		}
+		if l.healSys, err = newHealSys(l, &dirJournalStore{dir: tmpdir}, journalConfig{}, nil); err != nil {
+			t.Fatal(err)
+		}
+		l.healSys.policy, l.healSys.primary = testCase.policy, testCase.primary
+
+		l.healSys.queue(context.Background(), journalEntry{Bucket: "bucket", Object: "object", Op: opPutObject,
+			RadioTag: "mid", SrcClientID: 2, DstClientIDs: []int{0, 1}})
+		l.healSys.healAll(context.Background())
+
+		for index, remote := range remotes {
+			if puts := remote.writtenTags(); !reflect.DeepEqual(puts, testCase.expectedPuts[index]) {
+				t.Errorf("Test %d: expected replica %d healed with %v, got %v", i+1, index, testCase.expectedPuts[index], puts)
+			}
+		}
+	}
+}
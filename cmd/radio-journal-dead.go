@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/minio/minio/pkg/policy"
+	"github.com/minio/radio/cmd/logger"
+)
+
+// journalDeadLetterDir is the directory, or key prefix below the journal
+// prefix, holding the entries given up healing.
+const journalDeadLetterDir = "dead"
+
+// errDeadLetterNotFound is returned when resolving a dead letter entry
+// which does not exist.
+var errDeadLetterNotFound = errors.New("dead letter entry not found")
+
+func (d *dirJournalStore) deadDir() string {
+	return filepath.Join(d.dir, journalDeadLetterDir)
+}
+
+func (d *dirJournalStore) Bury(entry journalEntry) error {
+	if err := os.MkdirAll(d.deadDir(), 0700); err != nil {
+		return err
+	}
+	if err := saveEntryFile(d.deadDir(), entry); err != nil {
+		return err
+	}
+	return d.Remove(entry.ID)
+}
+
+func (d *dirJournalStore) ListDead() ([]journalEntry, error) {
+	return listEntryFiles(d.deadDir())
+}
+
+func (d *dirJournalStore) RemoveDead(id string) error {
+	return removeEntryFile(d.deadDir(), id)
+}
+
+func (o *objectJournalStore) deadPrefix() string {
+	return path.Join(o.prefix, journalDeadLetterDir) + "/"
+}
+
+// isDead returns true if key holds a dead letter entry.
+func (o *objectJournalStore) isDead(key string) bool {
+	return strings.HasPrefix(key, o.deadPrefix())
+}
+
+func (o *objectJournalStore) Bury(entry journalEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = o.clnt.PutObject(o.bucket, o.deadPrefix()+entry.ID+journalEntrySuffix, bytes.NewReader(data),
+		int64(len(data)), "", "", map[string]string{"Content-Type": "application/json"}, nil)
+	if err != nil {
+		return err
+	}
+	return o.Remove(entry.ID)
+}
+
+func (o *objectJournalStore) ListDead() ([]journalEntry, error) {
+	return o.listEntries(o.deadPrefix(), false)
+}
+
+func (o *objectJournalStore) RemoveDead(id string) error {
+	return o.clnt.RemoveObject(o.bucket, o.deadPrefix()+id+journalEntrySuffix)
+}
+
+// expired returns true if entry failed to heal as often, or has been
+// failing for as long, as the journal allows.
+func (h *healSys) expired(entry journalEntry) bool {
+	if h.maxRetries > 0 && entry.Failures >= h.maxRetries {
+		return true
+	}
+	return h.ttl > 0 && !entry.FirstFailure.IsZero() && UTCNow().Sub(entry.FirstFailure) > h.ttl
+}
+
+// bury moves entry, failing to heal with err, to the dead letter area
+// where it is no longer retried, quarantining its object.
+func (h *healSys) bury(ctx context.Context, entry journalEntry, err error) {
+	entry.Error = err.Error()
+	// Requeued entries start their heals over.
+	h.abortCheckpoints(ctx, entry)
+	entry.Checkpoints = nil
+	if err = h.store.Bury(entry); err != nil {
+		logger.LogIf(ctx, err)
+		return
+	}
+	healDeadLettered.WithLabelValues(entry.Op.metricLabel()).Inc()
+	h.forget(entry.ID)
+	h.setQuarantine(entry)
+}
+
+// deadLetters returns the entries given up healing, failing with
+// NotImplemented if the journal is disabled.
+func (h *healSys) deadLetters() ([]journalEntry, error) {
+	if h == nil {
+		return nil, NotImplemented{}
+	}
+	return h.store.ListDead()
+}
+
+// requeue moves the dead letter entry id back to the journal, where it
+// is retried for another max retries or TTL.
+func (h *healSys) requeue(id string) error {
+	entries, err := h.deadLetters()
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.ID != id {
+			continue
+		}
+		entry.Failures, entry.FirstFailure, entry.Error = 0, time.Time{}, ""
+		entry.Requeued = UTCNow()
+		if err = h.store.Save(entry); err != nil {
+			return err
+		}
+		if err = h.store.RemoveDead(id); err != nil {
+			return err
+		}
+		h.clearQuarantine(entry)
+		return nil
+	}
+	return errDeadLetterNotFound
+}
+
+// discard drops the dead letter entry id, for objects resolved by the
+// operator, lifting the quarantine of its object.
+func (h *healSys) discard(id string) error {
+	entries, err := h.deadLetters()
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.ID != id {
+			continue
+		}
+		if err = h.store.RemoveDead(id); err != nil {
+			return err
+		}
+		h.clearQuarantine(entry)
+		return nil
+	}
+	return errDeadLetterNotFound
+}
+
+// JournalDeadLettersHandler lists the heal journal entries given up
+// healing.
+func JournalDeadLettersHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "JournalDeadLetters")
+
+	if s3Error := checkRequestAuthType(ctx, r, policy.ListAllMyBucketsAction, "", ""); s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+		return
+	}
+
+	l, ok := newObjectLayerFn().(*radioObjects)
+	if !ok {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	entries, err := l.healSys.deadLetters()
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	if entries == nil {
+		entries = []journalEntry{}
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	writeResponse(w, http.StatusOK, data, mimeJSON)
+}
+
+// JournalRequeueHandler moves a dead letter entry back to the heal
+// journal.
+func JournalRequeueHandler(w http.ResponseWriter, r *http.Request) {
+	resolveDeadLetter(w, r, "JournalRequeue", (*healSys).requeue)
+}
+
+// JournalDiscardHandler drops a dead letter entry once the operator
+// resolved its object.
+func JournalDiscardHandler(w http.ResponseWriter, r *http.Request) {
+	resolveDeadLetter(w, r, "JournalDiscard", (*healSys).discard)
+}
+
+func resolveDeadLetter(w http.ResponseWriter, r *http.Request, api string, resolve func(*healSys, string) error) {
+	ctx := newContext(r, w, api)
+
+	if s3Error := checkRequestAuthType(ctx, r, policy.PutObjectAction, "", ""); s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+		return
+	}
+
+	l, ok := newObjectLayerFn().(*radioObjects)
+	if !ok {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	if err := resolve(l.healSys, r.URL.Query().Get("id")); err != nil {
+		if err == errDeadLetterNotFound {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrNoSuchKey), r.URL)
+			return
+		}
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	writeSuccessNoContent(w)
+}
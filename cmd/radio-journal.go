@@ -0,0 +1,354 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/minio-go/v6/pkg/encrypt"
+	"github.com/minio/minio/pkg/policy"
+)
+
+// journalOp is the operation that left replicas out of sync.
+type journalOp string
+
+// Operations recorded in the heal journal.
+const (
+	opPutObject    journalOp = "PutObject"
+	opCopyObject   journalOp = "CopyObject"
+	opDeleteObject journalOp = "DeleteObject"
+)
+
+// metricLabel returns the op label used by heal metrics.
+func (op journalOp) metricLabel() string {
+	switch op {
+	case opPutObject:
+		return "put"
+	case opCopyObject:
+		return "copy"
+	case opDeleteObject:
+		return "delete"
+	}
+	return string(op)
+}
+
+// journalEntry records an object whose write did not reach every
+// replica, SrcClientID is the replica holding the authoritative copy
+// and DstClientIDs are the replicas to be healed from it. Delete entries
+// are tombstones, RadioTag then is the deleted version if known.
+type journalEntry struct {
+	ID           string    `json:"id"`
+	Bucket       string    `json:"bucket"`
+	Object       string    `json:"object"`
+	Op           journalOp `json:"op"`
+	RadioTag     string    `json:"radioTag,omitempty"`
+	SrcClientID  int       `json:"srcClientID"`
+	DstClientIDs []int     `json:"dstClientIDs"`
+	Timestamp    time.Time `json:"timestamp"`
+	// SSEC is set for objects encrypted with a customer key, sse holds
+	// the key while known. Keys are never persisted.
+	SSEC bool `json:"ssec,omitempty"`
+	sse  encrypt.ServerSide
+	// Failures counts the failed heals of the entry since FirstFailure,
+	// bounded by the journal max retries and TTL. Error records why a
+	// dead letter entry was given up, Requeued is when the operator last
+	// moved it back to the journal.
+	Failures     int       `json:"failures,omitempty"`
+	FirstFailure time.Time `json:"firstFailure,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	Requeued     time.Time `json:"requeued,omitempty"`
+	// Checkpoints record the progress of interrupted heals of large
+	// objects, see healCheckpointer.
+	Checkpoints []healCheckpoint `json:"checkpoints,omitempty"`
+}
+
+// journalStore persists heal journal entries.
+type journalStore interface {
+	Save(entry journalEntry) error
+	Remove(id string) error
+	List() ([]journalEntry, error)
+	// Contains returns true if entry id is journaled.
+	Contains(id string) (bool, error)
+	// Flush makes the saved entries durable, returning their count.
+	Flush() (int, error)
+	// Bury moves an entry given up healing to the dead letter area.
+	Bury(entry journalEntry) error
+	ListDead() ([]journalEntry, error)
+	RemoveDead(id string) error
+}
+
+const journalEntrySuffix = ".json"
+
+// newJournalStore returns the journal store configured in cfg, nil if
+// journaling is disabled.
+func newJournalStore(cfg journalConfig) (journalStore, error) {
+	if cfg.Remote.Endpoint != "" {
+		if err := validateRemoteHeaders(cfg.Remote.Headers); err != nil {
+			return nil, err
+		}
+		if err := cfg.Remote.Retries.validate(); err != nil {
+			return nil, err
+		}
+		clnt, err := newS3(cfg.Remote.Bucket, cfg.Remote.Endpoint, "", cfg.Remote.AccessKey,
+			cfg.Remote.SecretKey, cfg.Remote.SessionToken,
+			newRetryTransport(newHeaderTransport(NewCustomHTTPTransport(), cfg.Remote.Headers), cfg.Remote.Retries))
+		if err != nil {
+			return nil, err
+		}
+		return &objectJournalStore{
+			clnt:   clnt,
+			bucket: cfg.Remote.Bucket,
+			prefix: cfg.Remote.KeyPrefix,
+		}, nil
+	}
+	if cfg.Dir != "" {
+		if err := os.MkdirAll(cfg.Dir, 0700); err != nil {
+			return nil, err
+		}
+		return &dirJournalStore{dir: cfg.Dir}, nil
+	}
+	return nil, nil
+}
+
+// dirJournalStore keeps one file per entry in a local directory.
+type dirJournalStore struct {
+	dir string
+}
+
+func (d *dirJournalStore) Save(entry journalEntry) error {
+	return saveEntryFile(d.dir, entry)
+}
+
+func (d *dirJournalStore) Remove(id string) error {
+	return removeEntryFile(d.dir, id)
+}
+
+func (d *dirJournalStore) Contains(id string) (bool, error) {
+	_, err := os.Stat(filepath.Join(d.dir, id+journalEntrySuffix))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Flush syncs the entry files and the directory to disk, entries are
+// otherwise written back at the cadence of the filesystem.
+func (d *dirJournalStore) Flush() (int, error) {
+	files, err := ioutil.ReadDir(d.dir)
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	for _, fi := range files {
+		if !strings.HasSuffix(fi.Name(), journalEntrySuffix) {
+			continue
+		}
+		if err = syncFile(filepath.Join(d.dir, fi.Name())); err != nil {
+			if os.IsNotExist(err) {
+				// Healed meanwhile.
+				continue
+			}
+			return n, err
+		}
+		n++
+	}
+	return n, syncFile(d.dir)
+}
+
+func syncFile(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Sync()
+}
+
+func (d *dirJournalStore) List() ([]journalEntry, error) {
+	return listEntryFiles(d.dir)
+}
+
+// saveEntryFile atomically writes entry to its file in dir.
+func saveEntryFile(dir string, entry journalEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	tmpFile := filepath.Join(dir, entry.ID+".tmp")
+	if err = ioutil.WriteFile(tmpFile, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, filepath.Join(dir, entry.ID+journalEntrySuffix))
+}
+
+func removeEntryFile(dir, id string) error {
+	err := os.Remove(filepath.Join(dir, id+journalEntrySuffix))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// listEntryFiles returns the entries saved in dir, a missing dir holds
+// no entries.
+func listEntryFiles(dir string) ([]journalEntry, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var entries []journalEntry
+	for _, fi := range files {
+		if !strings.HasSuffix(fi.Name(), journalEntrySuffix) {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, fi.Name()))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		var entry journalEntry
+		if err = json.Unmarshal(data, &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// objectJournalStore keeps one object per entry in a dedicated bucket,
+// so the journal survives restarts of stateless deployments and is
+// shared by all radio peers.
+type objectJournalStore struct {
+	clnt   *miniogo.Core
+	bucket string
+	prefix string
+}
+
+func (o *objectJournalStore) entryKey(id string) string {
+	return path.Join(o.prefix, id+journalEntrySuffix)
+}
+
+func (o *objectJournalStore) Save(entry journalEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = o.clnt.PutObject(o.bucket, o.entryKey(entry.ID), bytes.NewReader(data),
+		int64(len(data)), "", "", map[string]string{"Content-Type": "application/json"}, nil)
+	return err
+}
+
+func (o *objectJournalStore) Remove(id string) error {
+	return o.clnt.RemoveObject(o.bucket, o.entryKey(id))
+}
+
+func (o *objectJournalStore) Contains(id string) (bool, error) {
+	_, err := o.clnt.StatObject(o.bucket, o.entryKey(id), miniogo.StatObjectOptions{})
+	if err != nil {
+		if miniogo.ToErrorResponse(err).Code == "NoSuchKey" {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Flush counts the saved entries, which the remote made durable when
+// they were written.
+func (o *objectJournalStore) Flush() (int, error) {
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+
+	var n int
+	for oi := range o.clnt.Client.ListObjectsV2(o.bucket, o.prefix, true, doneCh) {
+		if oi.Err != nil {
+			return n, oi.Err
+		}
+		if strings.HasSuffix(oi.Key, journalEntrySuffix) && !o.isDead(oi.Key) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (o *objectJournalStore) List() ([]journalEntry, error) {
+	return o.listEntries(o.prefix, true)
+}
+
+// listEntries returns the entries saved under prefix, leaving out dead
+// letter entries if skipDead is set.
+func (o *objectJournalStore) listEntries(prefix string, skipDead bool) ([]journalEntry, error) {
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+
+	var entries []journalEntry
+	for oi := range o.clnt.Client.ListObjectsV2(o.bucket, prefix, true, doneCh) {
+		if oi.Err != nil {
+			return nil, oi.Err
+		}
+		if !strings.HasSuffix(oi.Key, journalEntrySuffix) || (skipDead && o.isDead(oi.Key)) {
+			continue
+		}
+		reader, _, _, err := o.clnt.GetObject(o.bucket, oi.Key, miniogo.GetObjectOptions{})
+		if err != nil {
+			if miniogo.ToErrorResponse(err).Code == "NoSuchKey" {
+				continue
+			}
+			return nil, err
+		}
+		var entry journalEntry
+		err = json.NewDecoder(reader).Decode(&entry)
+		reader.Close()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// journalFlushResult is the response of the journal flush handler.
+type journalFlushResult struct {
+	Flushed int `json:"flushed"`
+}
+
+// JournalFlushHandler makes all journaled heal entries durable before
+// returning their count, for use before a planned restart.
+func JournalFlushHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "JournalFlush")
+
+	if s3Error := checkRequestAuthType(ctx, r, policy.PutObjectAction, "", ""); s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+		return
+	}
+
+	l, ok := newObjectLayerFn().(*radioObjects)
+	if !ok {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+
+	n, err := l.healSys.flush()
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	data, err := json.Marshal(journalFlushResult{Flushed: n})
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	writeResponse(w, http.StatusOK, data, mimeJSON)
+}
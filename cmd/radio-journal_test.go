@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/minio/minio/pkg/hash"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func testJournalStore(t *testing.T, store journalStore) {
+	entries := []journalEntry{
+		{ID: "1", Bucket: "bucket", Object: "a", Op: opPutObject, RadioTag: "tag", SrcClientID: 0, DstClientIDs: []int{1, 2}},
+		{ID: "2", Bucket: "bucket", Object: "b", Op: opDeleteObject, SrcClientID: 1, DstClientIDs: []int{0}},
+	}
+	for _, entry := range entries {
+		entry.Timestamp = UTCNow().Truncate(0)
+		if err := store.Save(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Remove("1"); err != nil {
+		t.Fatal(err)
+	}
+	// Removing an entry removed by a peer is not an error.
+	if err := store.Remove("1"); err != nil {
+		t.Fatal(err)
+	}
+
+	listed, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(listed))
+	}
+	listed[0].Timestamp = entries[1].Timestamp
+	if !reflect.DeepEqual(listed[0], entries[1]) {
+		t.Errorf("Expected %+v, got %+v", entries[1], listed[0])
+	}
+}
+
+// Tests that journal entries are saved, listed and removed by the local
+// directory store.
+func TestDirJournalStore(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "radio-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	testJournalStore(t, &dirJournalStore{dir: tmpdir})
+}
+
+// Tests that journal entries are saved, listed and removed by the
+// object store, ignoring other objects of its bucket.
+func TestObjectJournalStore(t *testing.T) {
+	remotes, clnts, shutdown := newTestRemotes(t, 1)
+	defer shutdown()
+	remotes[0].putObject("journal/other", "", nil)
+
+	testJournalStore(t, &objectJournalStore{clnt: clnts[0].Core, bucket: testRemoteBucket, prefix: "journal"})
+	for _, key := range remotes[0].keys() {
+		if key != "journal/2.json" && key != "journal/other" {
+			t.Errorf("Unexpected journal object %s", key)
+		}
+	}
+}
+
+// Tests that writes missed by a replica are journaled, and healed from
+// the replica journaled as the source.
+func TestHealJournaledWrites(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "radio-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	remotes, clnts, shutdown := newTestRemotes(t, 3)
+	defer shutdown()
+	l := &radioObjects{
+		nsMutex:       newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+	}
+	store := &dirJournalStore{dir: tmpdir}
+	if l.healSys, err = newHealSys(l, store, journalConfig{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	remotes[2].putObject("deleted", "data", nil)
+	remotes[2].setHook(func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			return false
+		}
+		writeTestRemoteError(w, r, http.StatusForbidden, "AccessDenied")
+		return true
+	})
+
+	data := []byte("hello")
+	reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), "", "", int64(len(data)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expires := "Thu, 01 Jan 2099 00:00:00 GMT"
+	if _, err = l.PutObject(ctx, "bucket", "object", NewPutObjReader(reader, nil, nil),
+		ObjectOptions{UserDefined: map[string]string{"Content-Type": "text/plain", "Expires": expires}}); err != nil {
+		t.Fatal(err)
+	}
+	if err = l.DeleteObject(ctx, "bucket", "deleted"); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 journal entries, got %+v", entries)
+	}
+	for _, entry := range entries {
+		if entry.SrcClientID != 0 || !reflect.DeepEqual(entry.DstClientIDs, []int{2}) {
+			t.Errorf("Expected %s to be healed from replica 0 onto replica 2, got %+v", entry.Op, entry)
+		}
+	}
+
+	// Entries are kept while the replica is still failing.
+	l.healSys.healAll(ctx)
+	if entries, _ = store.List(); len(entries) != 2 {
+		t.Fatalf("Expected the 2 journal entries to be kept, got %+v", entries)
+	}
+
+	remotes[2].setHook(nil)
+	l.healSys.healAll(ctx)
+	if entries, _ = store.List(); len(entries) != 0 {
+		t.Errorf("Expected the journal to be empty, got %+v", entries)
+	}
+	if _, _, ok := remotes[2].object("deleted"); ok {
+		t.Error("Expected the delete to be healed")
+	}
+	src, srcHeader, _ := remotes[0].object("object")
+	dst, dstHeader, ok := remotes[2].object("object")
+	if !ok || dst != src {
+		t.Fatalf("Expected the object to be healed, got %q", dst)
+	}
+	for key, value := range map[string]string{
+		"X-Amz-Meta-Radio-Tag": srcHeader.Get("X-Amz-Meta-Radio-Tag"),
+		"Content-Type":         "text/plain",
+		"Expires":              expires,
+	} {
+		if dstHeader.Get(key) != value {
+			t.Errorf("Expected healed %s %q, got %q", key, value, dstHeader.Get(key))
+		}
+	}
+}
+
+// Tests that healed and failed journal entries are counted by op.
+func TestHealMetrics(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "radio-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	remotes, clnts, shutdown := newTestRemotes(t, 3)
+	defer shutdown()
+	l := &radioObjects{
+		nsMutex:       newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+	}
+	store := &dirJournalStore{dir: tmpdir}
+	if l.healSys, err = newHealSys(l, store, journalConfig{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	remotes[2].setHook(func(w http.ResponseWriter, r *http.Request) bool {
+		writeTestRemoteError(w, r, http.StatusForbidden, "AccessDenied")
+		return true
+	})
+	for i, dst := range []int{1, 2} {
+		entry := journalEntry{ID: strconv.Itoa(i), Bucket: "bucket", Object: "object", Op: opDeleteObject, DstClientIDs: []int{dst}}
+		if err = store.Save(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	completed := testutil.ToFloat64(healCompleted.WithLabelValues("delete"))
+	failed := testutil.ToFloat64(healFailed.WithLabelValues("delete"))
+	l.healSys.healAll(context.Background())
+	if n := testutil.ToFloat64(healCompleted.WithLabelValues("delete")) - completed; n != 1 {
+		t.Errorf("Expected 1 completed heal, got %v", n)
+	}
+	if n := testutil.ToFloat64(healFailed.WithLabelValues("delete")) - failed; n != 1 {
+		t.Errorf("Expected 1 failed heal, got %v", n)
+	}
+}
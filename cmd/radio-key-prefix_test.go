@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/minio/minio/pkg/hash"
+)
+
+// newKeyPrefixTestLayer returns a layer mirroring bucket onto two test
+// remotes, each storing the objects under its own key prefix.
+func newKeyPrefixTestLayer(t *testing.T) (*radioObjects, []*testRemote, func()) {
+	remotes, clnts, shutdown := newTestRemotes(t, 2)
+	clnts[0].Prefix = "one/"
+	clnts[1].Prefix = "two/"
+	return &radioObjects{
+		nsMutex:              newNSLock(false),
+		mirrorClients:        map[string]mirrorConfig{"bucket": {clnts: clnts}},
+		multipartUploadIDMap: make(map[string][]string),
+		deleteParallelism:    defaultDeleteParallelism,
+		deleteBatchSize:      defaultDeleteBatchSize,
+		completedUploads:     newCompletedUploads(),
+	}, remotes, shutdown
+}
+
+func newKeyPrefixTestReader(t *testing.T, data string) *PutObjReader {
+	reader, err := hash.NewReader(bytes.NewReader([]byte(data)), int64(len(data)), "", "", int64(len(data)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewPutObjReader(reader, nil, nil)
+}
+
+func putKeyPrefixTestObject(t *testing.T, l *radioObjects, object, data string) ObjectInfo {
+	info, err := l.PutObject(context.Background(), "bucket", object, newKeyPrefixTestReader(t, data),
+		ObjectOptions{UserDefined: map[string]string{}})
+	if err != nil {
+		t.Fatalf("PutObject %s: %v", object, err)
+	}
+	return info
+}
+
+func getKeyPrefixTestObject(t *testing.T, l *radioObjects, object string) string {
+	gr, err := l.GetObjectNInfo(context.Background(), "bucket", object, nil, nil, ReadLock, ObjectOptions{})
+	if err != nil {
+		t.Fatalf("GetObjectNInfo %s: %v", object, err)
+	}
+	defer gr.Close()
+	data, err := ioutil.ReadAll(gr)
+	if err != nil {
+		t.Fatalf("GetObjectNInfo %s: %v", object, err)
+	}
+	return string(data)
+}
+
+// checkKeyPrefixTestKeys checks that each remote stores exactly keys
+// under its prefix.
+func checkKeyPrefixTestKeys(t *testing.T, remotes []*testRemote, keys ...string) {
+	t.Helper()
+	for i, prefix := range []string{"one/", "two/"} {
+		want := []string{}
+		for _, key := range keys {
+			want = append(want, prefix+key)
+		}
+		if got := remotes[i].keys(); !reflect.DeepEqual(got, want) {
+			t.Errorf("Remote %d: expected keys %v, got %v", i, want, got)
+		}
+	}
+}
+
+// Tests that objects are put, read, copied and deleted under the key
+// prefix of each remote.
+func TestKeyPrefixObjects(t *testing.T) {
+	l, remotes, shutdown := newKeyPrefixTestLayer(t)
+	defer shutdown()
+	ctx := context.Background()
+
+	putKeyPrefixTestObject(t, l, "dir/object", "hello")
+	checkKeyPrefixTestKeys(t, remotes, "dir/object")
+	if data := getKeyPrefixTestObject(t, l, "dir/object"); data != "hello" {
+		t.Errorf("Expected hello, got %q", data)
+	}
+	info, err := l.GetObjectInfo(ctx, "bucket", "dir/object", ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Name != "dir/object" || info.Size != 5 {
+		t.Errorf("Expected dir/object of 5 bytes, got %s of %d", info.Name, info.Size)
+	}
+
+	if _, err = l.CopyObject(ctx, "bucket", "dir/object", "bucket", "copy", info,
+		ObjectOptions{}, ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	checkKeyPrefixTestKeys(t, remotes, "copy", "dir/object")
+	if data := getKeyPrefixTestObject(t, l, "copy"); data != "hello" {
+		t.Errorf("Expected the copy to read hello, got %q", data)
+	}
+
+	if err = l.DeleteObject(ctx, "bucket", "copy"); err != nil {
+		t.Fatal(err)
+	}
+	checkKeyPrefixTestKeys(t, remotes, "dir/object")
+
+	putKeyPrefixTestObject(t, l, "other", "world")
+	errs, err := l.DeleteObjects(ctx, "bucket", []string{"dir/object", "other"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("Object %d: unexpected error %v", i, err)
+		}
+	}
+	checkKeyPrefixTestKeys(t, remotes)
+}
+
+// Tests that listings are made under the key prefix of the remote, and
+// that it is stripped from the keys, prefixes and markers returned.
+func TestKeyPrefixList(t *testing.T) {
+	l, remotes, shutdown := newKeyPrefixTestLayer(t)
+	defer shutdown()
+	ctx := context.Background()
+
+	for _, object := range []string{"a/1", "a/2", "b", "c"} {
+		putKeyPrefixTestObject(t, l, object, object)
+	}
+	// Keys outside of the prefix of the remote are not listed.
+	remotes[0].putObject("zzz", "", nil)
+
+	var objects, prefixes []string
+	var marker string
+	for {
+		loi, err := l.ListObjects(ctx, "bucket", "", marker, "/", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, obj := range loi.Objects {
+			objects = append(objects, obj.Name)
+		}
+		prefixes = append(prefixes, loi.Prefixes...)
+		if !loi.IsTruncated {
+			break
+		}
+		if loi.NextMarker == marker {
+			t.Fatalf("Listing did not advance past %q", marker)
+		}
+		marker = loi.NextMarker
+	}
+	if !reflect.DeepEqual(objects, []string{"b", "c"}) || !reflect.DeepEqual(prefixes, []string{"a/"}) {
+		t.Errorf("Expected objects [b c] and prefixes [a/], got %v and %v", objects, prefixes)
+	}
+
+	loi, err := l.ListObjects(ctx, "bucket", "a/", "a/1", "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loi.Objects) != 1 || loi.Objects[0].Name != "a/2" {
+		t.Errorf("Expected a/2 after the marker a/1, got %v", loi.Objects)
+	}
+
+	objects = nil
+	var token string
+	for {
+		lov2, err := l.ListObjectsV2(ctx, "bucket", "", token, "", 2, false, "a/1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, obj := range lov2.Objects {
+			objects = append(objects, obj.Name)
+		}
+		if !lov2.IsTruncated {
+			break
+		}
+		token = lov2.NextContinuationToken
+	}
+	if !reflect.DeepEqual(objects, []string{"a/2", "b", "c"}) {
+		t.Errorf("Expected [a/2 b c] after a/1, got %v", objects)
+	}
+}
+
+// Tests that multipart uploads are made and listed under the key
+// prefix of each remote.
+func TestKeyPrefixMultipart(t *testing.T) {
+	l, remotes, shutdown := newKeyPrefixTestLayer(t)
+	defer shutdown()
+	ctx := context.Background()
+
+	uploadID, err := l.NewMultipartUpload(ctx, "bucket", "dir/object", ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lmi, err := l.ListMultipartUploads(ctx, "bucket", "dir/", "", "", "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lmi.Uploads) != 1 || lmi.Uploads[0].Object != "dir/object" {
+		t.Errorf("Expected an upload of dir/object, got %+v", lmi.Uploads)
+	}
+
+	part, err := l.PutObjectPart(ctx, "bucket", "dir/object", uploadID, 1, newKeyPrefixTestReader(t, "hello"), ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = l.CompleteMultipartUpload(ctx, "bucket", "dir/object", uploadID,
+		[]CompletePart{{PartNumber: 1, ETag: part.ETag}}, ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	checkKeyPrefixTestKeys(t, remotes, "dir/object")
+	for i, key := range []string{"one/dir/object", "two/dir/object"} {
+		if data, _, _ := remotes[i].object(key); data != "hello" {
+			t.Errorf("Remote %d: expected hello, got %q", i, data)
+		}
+	}
+}
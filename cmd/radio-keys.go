@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Handling of object names ending with a slash, which some remotes
+// store as folder markers while others reject them.
+const (
+	// Fail writes of such names.
+	trailingSlashReject = "reject"
+	// Store such names without their trailing slashes.
+	trailingSlashStrip = "strip"
+)
+
+// Case normalization of object names.
+const (
+	// Store object names lowercased.
+	keyCaseLower = "lower"
+)
+
+// keyNormalization rewrites the object names of a bucket before they
+// reach the remotes, so that all replicas store edge-case names alike.
+// Names are kept as is by default.
+type keyNormalization struct {
+	TrailingSlash string `yaml:"trailing_slash"`
+	Case          string `yaml:"case"`
+}
+
+func (k keyNormalization) validate(bucket string) error {
+	switch k.TrailingSlash {
+	case "", trailingSlashReject, trailingSlashStrip:
+	default:
+		return fmt.Errorf("bucket %s: unknown trailing slash handling %q", bucket, k.TrailingSlash)
+	}
+	switch k.Case {
+	case "", keyCaseLower:
+	default:
+		return fmt.Errorf("bucket %s: unknown key case %q", bucket, k.Case)
+	}
+	return nil
+}
+
+// prefix returns the normalized listing prefix or marker p, trailing
+// slashes delimit listings and are always kept.
+func (k keyNormalization) prefix(p string) string {
+	if k.Case == keyCaseLower {
+		return strings.ToLower(p)
+	}
+	return p
+}
+
+// object returns the normalized name of object.
+func (k keyNormalization) object(object string) string {
+	if k.TrailingSlash == trailingSlashStrip {
+		object = strings.TrimRight(object, SlashSeparator)
+	}
+	return k.prefix(object)
+}
+
+// newObject returns the normalized name of a new object, failing for
+// names the bucket rejects.
+func (k keyNormalization) newObject(bucket, object string) (string, error) {
+	if k.TrailingSlash == trailingSlashReject && strings.HasSuffix(object, SlashSeparator) {
+		return "", ObjectNameInvalid{Bucket: bucket, Object: object}
+	}
+	name := k.object(object)
+	if name == "" {
+		return "", ObjectNameInvalid{Bucket: bucket, Object: object}
+	}
+	return name, nil
+}
+
+// objectName returns the name under which object of bucket is stored.
+func (l *radioObjects) objectName(bucket, object string) string {
+	return l.mirrorClients[bucket].keys.object(object)
+}
+
+// newObjectName returns the name under which a new object of bucket is
+// written, failing for names the bucket rejects.
+func (l *radioObjects) newObjectName(bucket, object string) (string, error) {
+	return l.mirrorClients[bucket].keys.newObject(bucket, object)
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"testing"
+)
+
+// Tests the normalization of object names and listing prefixes.
+func TestKeyNormalization(t *testing.T) {
+	testCases := []struct {
+		keys           keyNormalization
+		object         string
+		shouldPass     bool
+		expectedObject string
+		expectedPrefix string
+	}{
+		// Names are kept as is by default.
+		{keyNormalization{}, "Dir/", true, "Dir/", "Dir/"},
+		{keyNormalization{TrailingSlash: trailingSlashReject}, "dir/obj", true, "dir/obj", "dir/obj"},
+		{keyNormalization{TrailingSlash: trailingSlashReject}, "dir/", false, "dir/", "dir/"},
+		{keyNormalization{TrailingSlash: trailingSlashStrip}, "dir//", true, "dir", "dir//"},
+		{keyNormalization{TrailingSlash: trailingSlashStrip}, "/", false, "", "/"},
+		{keyNormalization{Case: keyCaseLower}, "Dir/Obj", true, "dir/obj", "dir/obj"},
+		{keyNormalization{TrailingSlash: trailingSlashStrip, Case: keyCaseLower}, "Dir/", true, "dir", "dir/"},
+	}
+
+	for i, testCase := range testCases {
+		object, err := testCase.keys.newObject("bucket", testCase.object)
+		if testCase.shouldPass && err != nil {
+			t.Errorf("Test %d: Unexpected error %v", i+1, err)
+		}
+		if !testCase.shouldPass {
+			if _, ok := err.(ObjectNameInvalid); !ok {
+				t.Errorf("Test %d: Expected ObjectNameInvalid, got %v", i+1, err)
+			}
+		}
+		if testCase.shouldPass && object != testCase.expectedObject {
+			t.Errorf("Test %d: Expected new object %q, got %q", i+1, testCase.expectedObject, object)
+		}
+		// Existing names are normalized but never rejected.
+		if object = testCase.keys.object(testCase.object); object != testCase.expectedObject {
+			t.Errorf("Test %d: Expected object %q, got %q", i+1, testCase.expectedObject, object)
+		}
+		if prefix := testCase.keys.prefix(testCase.object); prefix != testCase.expectedPrefix {
+			t.Errorf("Test %d: Expected prefix %q, got %q", i+1, testCase.expectedPrefix, prefix)
+		}
+	}
+}
+
+// Tests validation of the normalization options.
+func TestKeyNormalizationValidate(t *testing.T) {
+	testCases := []struct {
+		keys       keyNormalization
+		shouldPass bool
+	}{
+		{keyNormalization{}, true},
+		{keyNormalization{TrailingSlash: "strip", Case: "lower"}, true},
+		{keyNormalization{TrailingSlash: "keep"}, false},
+		{keyNormalization{Case: "upper"}, false},
+	}
+
+	for i, testCase := range testCases {
+		if err := testCase.keys.validate("bucket"); (err == nil) != testCase.shouldPass {
+			t.Errorf("Test %d: Unexpected error %v", i+1, err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/radio/cmd/logger"
+)
+
+// Default number of objects stat'ed per bucket by each lag sample.
+const defaultLagSampleSize = 100
+
+// lagSampler periodically compares the radio tags of a sample of
+// objects across the replicas of each mirrored bucket, exporting the
+// number of divergent objects found.
+type lagSampler struct {
+	layer      *radioObjects
+	interval   time.Duration
+	sampleSize int
+	// Key after which the next sample of each bucket starts, so that
+	// successive samples walk the whole bucket.
+	startAfter map[string]string
+}
+
+func newLagSampler(layer *radioObjects, interval time.Duration, sampleSize int) *lagSampler {
+	if sampleSize <= 0 {
+		sampleSize = defaultLagSampleSize
+	}
+	return &lagSampler{
+		layer:      layer,
+		interval:   interval,
+		sampleSize: sampleSize,
+		startAfter: make(map[string]string),
+	}
+}
+
+// run samples all mirrored buckets every interval until ctx is canceled.
+func (s *lagSampler) run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for bucket, rs3s := range s.layer.mirrorClients {
+				divergent, sampled, err := s.sample(ctx, bucket, rs3s)
+				if err != nil {
+					logger.LogIf(logger.WithFields(ctx, logger.FieldOp, "SampleLag", logger.FieldBucket, bucket), err)
+					continue
+				}
+				replicaDivergentObjects.WithLabelValues(bucket).Set(float64(divergent))
+				ratio := 0.0
+				if sampled > 0 {
+					ratio = float64(divergent) / float64(sampled)
+				}
+				replicaDivergentRatio.WithLabelValues(bucket).Set(ratio)
+			}
+		}
+	}
+}
+
+// sample stats the next sampleSize objects of bucket on all replicas,
+// returns the number of objects whose replicas are missing or do not
+// agree on the radio tag along with the number of objects sampled.
+func (s *lagSampler) sample(ctx context.Context, bucket string, rs3s mirrorConfig) (divergent, sampled int, err error) {
+	var result miniogo.ListBucketV2Result
+	for _, clnt := range rs3s.clnts {
+		if clnt.Shadow {
+			continue
+		}
+		result, err = clnt.listObjectsV2(clnt.Prefix, "", false, "",
+			s.sampleSize, clnt.markerKey(s.startAfter[bucket]))
+		if err == nil {
+			result = clnt.trimListBucketV2Result(result)
+			break
+		}
+	}
+	if err != nil {
+		return 0, 0, ErrorRespToObjectError(err, bucket)
+	}
+	if result.IsTruncated && len(result.Contents) > 0 {
+		s.startAfter[bucket] = result.Contents[len(result.Contents)-1].Key
+	} else {
+		delete(s.startAfter, bucket)
+	}
+
+	for _, obj := range result.Contents {
+		if ctx.Err() != nil {
+			return 0, 0, ctx.Err()
+		}
+		if rs3s.singleReplica(obj.Key) {
+			continue
+		}
+		var radioTag string
+		var compared int
+		for _, clnt := range rs3s.clnts {
+			if clnt.ReadOnly || clnt.Shadow {
+				// Read-only and shadow replicas are not expected to
+				// follow writes.
+				continue
+			}
+			info, serr := clnt.StatObjectWithContext(ctx, clnt.Bucket, clnt.objectKey(obj.Key),
+				miniogo.StatObjectOptions{})
+			tag := info.Metadata.Get(globalRadioTagKey)
+			if compared == 0 {
+				radioTag = tag
+			}
+			compared++
+			if serr != nil || tag != radioTag {
+				divergent++
+				break
+			}
+		}
+		sampled++
+	}
+	return divergent, sampled, nil
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+)
+
+// Tests that successive samples walk the bucket, counting the objects
+// missing on a replica or tagged differently.
+func TestLagSample(t *testing.T) {
+	remotes, clnts, shutdown := newTestRemotes(t, 3)
+	defer shutdown()
+	for i, remote := range remotes {
+		for _, key := range []string{"a", "b", "c", "d", "e"} {
+			tag := "tag"
+			if key == "b" && i == 2 {
+				tag = "other"
+			}
+			if key != "c" || i != 1 {
+				remote.putObject(key, key, map[string]string{"X-Amz-Meta-Radio-Tag": tag})
+			}
+		}
+	}
+	l := &radioObjects{mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}}}
+	s := newLagSampler(l, 0, 2)
+
+	// The third sample reaches the end of the bucket, the fourth starts
+	// over.
+	for i, want := range [][2]int{{1, 2}, {1, 2}, {0, 1}, {1, 2}} {
+		divergent, sampled, err := s.sample(context.Background(), "bucket", l.mirrorClients["bucket"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if divergent != want[0] || sampled != want[1] {
+			t.Errorf("Sample %d: expected %d of %d objects divergent, got %d of %d",
+				i, want[0], want[1], divergent, sampled)
+		}
+	}
+}
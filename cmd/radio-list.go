@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"context"
+
+	miniogo "github.com/minio/minio-go/v6"
+)
+
+// listPage is one page of the listing of a remote, with keys and
+// prefixes as seen by clients.
+type listPage struct {
+	contents []miniogo.ObjectInfo
+	prefixes []miniogo.CommonPrefix
+	// next resumes the listing after this page, a marker or a
+	// continuation token depending on the listing.
+	next      string
+	truncated bool
+	err       error
+}
+
+// listPageFunc lists up to n entries of clnt after marker.
+type listPageFunc func(clnt bucketClient, marker string, n int) (listPage, error)
+
+// listBatch returns the number of entries to request from a remote with
+// maxKeys left to list, at most batchSize if batchSize is positive.
+func listBatch(maxKeys, batchSize int) int {
+	if batchSize <= 0 || batchSize > maxKeys {
+		return maxKeys
+	}
+	return batchSize
+}
+
+// streamList lists up to maxKeys entries after marker in pages of at
+// most batchSize entries, sending each page as it arrives so that the
+// next one is requested while the previous is consumed. The first
+// remote serving the first page serves the following ones, since
+// markers and continuation tokens returned by a remote are only
+// meaningful to it. The channel is closed after the last page, or after
+// a page carrying the error which ended the listing.
+func streamList(ctx context.Context, clnts []bucketClient, marker string, maxKeys, batchSize int, list listPageFunc) <-chan listPage {
+	pages := make(chan listPage, 1)
+	go func() {
+		defer close(pages)
+		send := func(page listPage) bool {
+			select {
+			case pages <- page:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		var clnt bucketClient
+		var page listPage
+		var err error
+		for _, c := range clnts {
+			if c.Shadow {
+				continue
+			}
+			clnt = c.reader()
+			if page, err = list(clnt, marker, listBatch(maxKeys, batchSize)); err == nil {
+				break
+			}
+		}
+		for {
+			if err != nil {
+				send(listPage{err: err})
+				return
+			}
+			if !send(page) {
+				return
+			}
+			maxKeys -= len(page.contents) + len(page.prefixes)
+			if !page.truncated || page.next == "" || maxKeys <= 0 {
+				return
+			}
+			page, err = list(clnt, page.next, listBatch(maxKeys, batchSize))
+		}
+	}()
+	return pages
+}
+
+// listObjectsPages streams the pages of a ListObjects listing of rs3.
+func (l *radioObjects) listObjectsPages(ctx context.Context, rs3 mirrorConfig, prefix, marker, delimiter string, maxKeys int) <-chan listPage {
+	return streamList(ctx, rs3.clnts, marker, maxKeys, l.listBatchSize,
+		func(clnt bucketClient, marker string, n int) (listPage, error) {
+			result, err := clnt.ListObjects(clnt.Bucket, clnt.objectKey(prefix), clnt.markerKey(marker), delimiter, n)
+			if err != nil {
+				return listPage{}, err
+			}
+			result = clnt.trimListBucketResult(result)
+			next := result.NextMarker
+			if result.IsTruncated && next == "" && len(result.Contents) > 0 {
+				// NextMarker is only returned along with a delimiter.
+				next = result.Contents[len(result.Contents)-1].Key
+			}
+			return listPage{
+				contents:  result.Contents,
+				prefixes:  result.CommonPrefixes,
+				next:      next,
+				truncated: result.IsTruncated,
+			}, nil
+		})
+}
+
+// listObjectsV2Pages streams the pages of a ListObjectsV2 listing of
+// rs3, the first page resuming from continuationToken.
+func (l *radioObjects) listObjectsV2Pages(ctx context.Context, rs3 mirrorConfig, prefix, continuationToken, delimiter string,
+	maxKeys int, fetchOwner bool, startAfter string) <-chan listPage {
+	return streamList(ctx, rs3.clnts, continuationToken, maxKeys, l.listBatchSize,
+		func(clnt bucketClient, token string, n int) (listPage, error) {
+			result, err := clnt.listObjectsV2(clnt.objectKey(prefix), token, fetchOwner, delimiter,
+				n, clnt.markerKey(startAfter))
+			if err != nil {
+				return listPage{}, err
+			}
+			result = clnt.trimListBucketV2Result(result)
+			return listPage{
+				contents:  result.Contents,
+				prefixes:  result.CommonPrefixes,
+				next:      result.NextContinuationToken,
+				truncated: result.IsTruncated,
+			}, nil
+		})
+}
+
+// collectListPages gathers the pages of a listing of bucket, returning
+// the listed objects and prefixes and the last page, which tells
+// whether and where the listing continues.
+func collectListPages(bucket string, pages <-chan listPage) (objects []ObjectInfo, prefixes []string, last listPage, err error) {
+	for page := range pages {
+		if page.err != nil {
+			err = page.err
+			continue
+		}
+		for _, oi := range page.contents {
+			objects = append(objects, FromMinioClientObjectInfo(bucket, oi, 0))
+		}
+		for _, p := range page.prefixes {
+			prefixes = append(prefixes, p.Prefix)
+		}
+		last = page
+	}
+	return objects, prefixes, last, err
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// Tests that listings are paged from the remote in batches and resume
+// where the previous client page ended.
+func TestListObjectsBatches(t *testing.T) {
+	remotes, clnts, shutdown := newTestRemotes(t, 1)
+	defer shutdown()
+	// Listings of the remote url encode the keys.
+	for _, key := range []string{"a", "b", "c+%2F", "d", "e", "f", "g"} {
+		remotes[0].putObject(key, key, nil)
+	}
+	var mu sync.Mutex
+	var maxKeys []int
+	remotes[0].setHook(func(w http.ResponseWriter, r *http.Request) bool {
+		n, _ := strconv.Atoi(r.URL.Query().Get("max-keys"))
+		mu.Lock()
+		maxKeys = append(maxKeys, n)
+		mu.Unlock()
+		return false
+	})
+	batches := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		batches := fmt.Sprint(maxKeys)
+		maxKeys = nil
+		return batches
+	}
+	shadow := clnts[0]
+	shadow.Shadow = true
+	l := &radioObjects{
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: []bucketClient{shadow, clnts[0]}}},
+		listBatchSize: 2,
+	}
+	keys := func(objects []ObjectInfo) (keys []string) {
+		for _, oi := range objects {
+			keys = append(keys, oi.Name)
+		}
+		return keys
+	}
+
+	loi, err := l.ListObjects(context.Background(), "bucket", "", "", "", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(keys(loi.Objects)) != "[a b c+%2F d e]" || !loi.IsTruncated || loi.NextMarker != "e" {
+		t.Errorf("Expected a to e truncated at e, got %v %t %q", keys(loi.Objects), loi.IsTruncated, loi.NextMarker)
+	}
+	if got := batches(); got != "[2 2 1]" {
+		t.Errorf("Expected batches of 2, 2 and 1 keys, got %v", got)
+	}
+	loi, err = l.ListObjects(context.Background(), "bucket", "", loi.NextMarker, "", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(keys(loi.Objects)) != "[f g]" || loi.IsTruncated {
+		t.Errorf("Expected f and g, got %v %t", keys(loi.Objects), loi.IsTruncated)
+	}
+
+	batches()
+	loi2, err := l.ListObjectsV2(context.Background(), "bucket", "", "", "", 3, false, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The continuation token is the one of the remote.
+	if fmt.Sprint(keys(loi2.Objects)) != "[b c+%2F d]" || !loi2.IsTruncated || loi2.NextContinuationToken == "" {
+		t.Errorf("Expected b to d truncated, got %v %t %q", keys(loi2.Objects), loi2.IsTruncated, loi2.NextContinuationToken)
+	}
+	loi2, err = l.ListObjectsV2(context.Background(), "bucket", "", loi2.NextContinuationToken, "", 10, false, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(keys(loi2.Objects)) != "[e f g]" || loi2.IsTruncated {
+		t.Errorf("Expected e to g, got %v %t", keys(loi2.Objects), loi2.IsTruncated)
+	}
+	if got := batches(); got != "[2 1 2 2]" {
+		t.Errorf("Expected batches of at most 2 keys, got %v", got)
+	}
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	xhttp "github.com/minio/radio/cmd/http"
+)
+
+type listNoCacheKeyType struct{}
+
+// listNoCacheKey marks contexts of listings that must bypass the cache.
+var listNoCacheKey listNoCacheKeyType
+
+// withListCacheControl returns a context whose listings bypass the list
+// cache if the request carries Cache-Control: no-cache.
+func withListCacheControl(ctx context.Context, r *http.Request) context.Context {
+	if !strings.Contains(r.Header.Get(xhttp.CacheControl), "no-cache") {
+		return ctx
+	}
+	return context.WithValue(ctx, listNoCacheKey, true)
+}
+
+func isListNoCache(ctx context.Context) bool {
+	noCache, _ := ctx.Value(listNoCacheKey).(bool)
+	return noCache
+}
+
+// listCacheKey identifies a cached listing by its parameters and by the
+// access key of the caller the replicas were listed as, empty for the
+// credentials of the remotes, see mappedUser.
+type listCacheKey struct {
+	user       string
+	v2         bool
+	bucket     string
+	prefix     string
+	marker     string
+	delimiter  string
+	maxKeys    int
+	fetchOwner bool
+	startAfter string
+}
+
+type listCacheEntry struct {
+	expires time.Time
+	result  interface{}
+}
+
+// listCache keeps listing results for a short TTL, entries of a bucket
+// are dropped on writes to objects under their prefix. A nil listCache
+// caches nothing.
+type listCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[listCacheKey]listCacheEntry
+}
+
+func newListCache(ttl time.Duration) *listCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &listCache{
+		ttl:     ttl,
+		entries: make(map[listCacheKey]listCacheEntry),
+	}
+}
+
+// get returns the cached result for key unless ctx bypasses the cache.
+func (c *listCache) get(ctx context.Context, key listCacheKey) (interface{}, bool) {
+	if c == nil || isListNoCache(ctx) {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || UTCNow().After(entry.expires) {
+		return nil, false
+	}
+	return entry.result, true
+}
+
+// set caches result for key.
+func (c *listCache) set(key listCacheKey, result interface{}) {
+	if c == nil {
+		return
+	}
+	now := UTCNow()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = listCacheEntry{
+		expires: now.Add(c.ttl),
+		result:  result,
+	}
+}
+
+// invalidate drops the cached listings of bucket which may include object.
+func (c *listCache) invalidate(bucket, object string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k := range c.entries {
+		if k.bucket == bucket && strings.HasPrefix(object, k.prefix) {
+			delete(c.entries, k)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/minio/minio/pkg/hash"
+)
+
+// Tests that listings are cached for the TTL unless bypassed, and
+// dropped on writes under their prefix.
+func TestListCache(t *testing.T) {
+	remotes, clnts, shutdown := newTestRemotes(t, 1)
+	defer shutdown()
+	remotes[0].putTaggedObject("a/1", "1", "v1", time.Now())
+	var lists int32
+	remotes[0].setHook(func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method == http.MethodGet && strings.Trim(r.URL.Path, "/") == testRemoteBucket {
+			atomic.AddInt32(&lists, 1)
+		}
+		return false
+	})
+
+	ttl := 500 * time.Millisecond
+	l := &radioObjects{
+		nsMutex:       newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+		listCache:     newListCache(ttl),
+	}
+	put := func(object string) {
+		data := []byte("data")
+		reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), "", "", int64(len(data)), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = l.PutObject(context.Background(), "bucket", object, NewPutObjReader(reader, nil, nil), ObjectOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	noCache := context.WithValue(context.Background(), listNoCacheKey, true)
+
+	testCases := []struct {
+		ctx             context.Context
+		before          func()
+		expectedLists   int32
+		expectedObjects int
+	}{
+		// Listed from the remote.
+		{context.Background(), nil, 1, 1},
+		// Cached.
+		{context.Background(), nil, 1, 1},
+		// Cache bypassed.
+		{noCache, nil, 2, 1},
+		// Writes outside the prefix keep the cache.
+		{context.Background(), func() { put("b/1") }, 2, 1},
+		// Writes under the prefix drop it.
+		{context.Background(), func() { put("a/2") }, 3, 2},
+		// Expired.
+		{context.Background(), func() { time.Sleep(ttl + 100*time.Millisecond) }, 4, 2},
+		// Shared by the callers not mapped to users of the remote.
+		{withClientAccessKey(context.Background(), "user"), nil, 4, 2},
+	}
+	for i, testCase := range testCases {
+		if testCase.before != nil {
+			testCase.before()
+		}
+		loi, err := l.ListObjects(testCase.ctx, "bucket", "a/", "", "", 1000)
+		if err != nil {
+			t.Fatalf("Test %d: unexpected error: %v", i+1, err)
+		}
+		if n := atomic.LoadInt32(&lists); n != testCase.expectedLists {
+			t.Errorf("Test %d: expected %d listings of the remote, got %d", i+1, testCase.expectedLists, n)
+		}
+		if len(loi.Objects) != testCase.expectedObjects {
+			t.Errorf("Test %d: expected %d objects, got %d", i+1, testCase.expectedObjects, len(loi.Objects))
+		}
+	}
+}
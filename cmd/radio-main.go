@@ -32,7 +32,7 @@ var radioFlags = []cli.Flag{
 	},
 	cli.StringFlag{
 		Name:  "config, c",
-		Usage: "path to radio configuration",
+		Usage: "path or http(s) URL of radio configuration, \"-\" reads it from stdin",
 	},
 }
 
@@ -85,6 +85,16 @@ func startRadio(ctx *cli.Context, radio *Radio) {
 	globalRootCAs, err = config.GetRootCAs(radio.rconfig.Distribute.Certs.CAPath)
 	logger.FatalIf(err, "Failed to read root CAs (%v)", err)
 
+	globalTLSPolicy, err = parseTLSPolicy(radio.rconfig.TLS)
+	logger.FatalIf(err, "Invalid TLS configuration")
+
+	if ttl := radio.rconfig.Transport.DNSCacheTTL; ttl > 0 {
+		globalDNSCache = newDNSCache(ttl)
+	}
+
+	logger.FatalIf(radio.rconfig.Admission.validate(), "Invalid admission configuration")
+	globalAdmission = newAdmissionControl(radio.rconfig.Admission)
+
 	// Set system resources to maximum.
 	logger.LogIf(context.Background(), setMaxResources())
 
@@ -117,6 +127,14 @@ func startRadio(ctx *cli.Context, radio *Radio) {
 	// Add server metrics router
 	registerMetricsRouter(router)
 
+	// Add radio admin router
+	registerRadioRouter(router)
+
+	// Serve profiling handlers on their own address, if enabled
+	if radio.rconfig.Debug.Pprof {
+		startPprofServer(radio.rconfig.Debug.Address)
+	}
+
 	for bucket := range radio.rconfig.Buckets {
 		registerAPIRouter(router, bucket)
 	}
@@ -132,6 +150,7 @@ func startRadio(ctx *cli.Context, radio *Radio) {
 
 	httpServer := xhttp.NewServer([]string{globalCLIContext.Addr},
 		criticalErrorHandler{registerHandlers(router, globalHandlers...)}, getCert)
+	globalTLSPolicy.apply(httpServer.TLSConfig)
 	go func() {
 		globalHTTPServerErrorCh <- httpServer.Start()
 	}()
@@ -172,6 +191,11 @@ func startRadio(ctx *cli.Context, radio *Radio) {
 
 		// Print radio startup message.
 		printRadioStartupMessage(getAPIEndpoints())
+
+		// Print the effective configuration.
+		if l, ok := newObject.(*radioObjects); ok {
+			printConfigSummary(newConfigSummary(radio.rconfig, l))
+		}
 	}
 
 	handleSignals()
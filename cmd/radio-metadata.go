@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"net/http"
+	"strings"
+)
+
+// metadataFilter restricts the user metadata forwarded to the remotes of
+// a bucket by key prefix. Keys matching a Deny prefix, or no Allow prefix
+// when Allow is set, are dropped, or rejected if Reject is set. The
+// radio tag is managed by radio and never filtered.
+type metadataFilter struct {
+	Allow  []string `yaml:"allow"`
+	Deny   []string `yaml:"deny"`
+	Reject bool     `yaml:"reject"`
+}
+
+// allowed returns true if the user metadata key passes the filter.
+func (f metadataFilter) allowed(key string) bool {
+	key = strings.ToLower(key)
+	for _, prefix := range f.Deny {
+		if strings.HasPrefix(key, strings.ToLower(prefix)) {
+			return false
+		}
+	}
+	if len(f.Allow) == 0 {
+		return true
+	}
+	for _, prefix := range f.Allow {
+		if strings.HasPrefix(key, strings.ToLower(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// apply returns metadata without the user metadata keys refused by the
+// filter, UnsupportedMetadata if any key is refused and Reject is set.
+func (f metadataFilter) apply(metadata map[string]string) (map[string]string, error) {
+	if len(f.Allow) == 0 && len(f.Deny) == 0 {
+		return metadata, nil
+	}
+	filtered := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		key := http.CanonicalHeaderKey(k)
+		if strings.HasPrefix(strings.ToLower(key), "x-amz-meta-") &&
+			key != globalRadioTagKey && !f.allowed(key) {
+			if f.Reject {
+				return nil, UnsupportedMetadata{}
+			}
+			continue
+		}
+		filtered[k] = v
+	}
+	return filtered, nil
+}
+
+// withRadioTag returns a copy of metadata tagged with radioTag, any
+// client supplied radio tag is dropped whatever the case of its key.
+func withRadioTag(metadata map[string]string, radioTag string) map[string]string {
+	tagged := make(map[string]string, len(metadata)+1)
+	for k, v := range metadata {
+		if http.CanonicalHeaderKey(k) == globalRadioTagKey {
+			continue
+		}
+		tagged[k] = v
+	}
+	tagged[globalRadioTagKey] = radioTag
+	return tagged
+}
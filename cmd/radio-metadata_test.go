@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/minio/minio/pkg/hash"
+)
+
+// Tests that client supplied radio tags never survive tagging.
+func TestWithRadioTag(t *testing.T) {
+	testCases := []struct {
+		metadata map[string]string
+	}{
+		{nil},
+		{map[string]string{"Content-Type": "text/plain"}},
+		{map[string]string{"x-amz-meta-radio-tag": "client"}},
+		{map[string]string{"X-Amz-Meta-Radio-Tag": "client"}},
+		{map[string]string{"X-AMZ-META-RADIO-TAG": "client", "x-amz-meta-radio-tag": "client"}},
+	}
+
+	for i, testCase := range testCases {
+		tagged := withRadioTag(testCase.metadata, "radio")
+		for k, v := range ToMinioClientMetadata(tagged) {
+			if k == http.CanonicalHeaderKey("x-amz-meta-radio-tag") && v != "radio" {
+				t.Errorf("Test %d: Expected radio tag %s, got %s", i+1, "radio", v)
+			}
+		}
+		var tags int
+		for k := range tagged {
+			if http.CanonicalHeaderKey(k) == http.CanonicalHeaderKey("x-amz-meta-radio-tag") {
+				tags++
+			}
+		}
+		if tags != 1 {
+			t.Errorf("Test %d: Expected a single radio tag, got %d", i+1, tags)
+		}
+		if v, ok := testCase.metadata["Content-Type"]; ok && tagged["Content-Type"] != v {
+			t.Errorf("Test %d: Expected content type %s, got %s", i+1, v, tagged["Content-Type"])
+		}
+	}
+}
+
+// Tests that the metadata filter drops or rejects user metadata by prefix
+// and leaves the radio tag alone.
+func TestMetadataFilterApply(t *testing.T) {
+	metadata := map[string]string{
+		"Content-Type":            "text/plain",
+		"X-Amz-Meta-Owner":        "radio",
+		"X-Amz-Meta-Internal-Foo": "bar",
+		"x-amz-meta-radio-tag":    "radio",
+	}
+	testCases := []struct {
+		filter     metadataFilter
+		expected   []string
+		shouldFail bool
+	}{
+		{metadataFilter{}, []string{"Content-Type", "X-Amz-Meta-Owner", "X-Amz-Meta-Internal-Foo", "x-amz-meta-radio-tag"}, false},
+		{metadataFilter{Deny: []string{"x-amz-meta-internal-"}}, []string{"Content-Type", "X-Amz-Meta-Owner", "x-amz-meta-radio-tag"}, false},
+		{metadataFilter{Allow: []string{"X-Amz-Meta-Owner"}}, []string{"Content-Type", "X-Amz-Meta-Owner", "x-amz-meta-radio-tag"}, false},
+		{metadataFilter{Deny: []string{"x-amz-meta-radio-tag"}}, []string{"Content-Type", "X-Amz-Meta-Owner", "X-Amz-Meta-Internal-Foo", "x-amz-meta-radio-tag"}, false},
+		{metadataFilter{Deny: []string{"x-amz-meta-internal-"}, Reject: true}, nil, true},
+	}
+
+	for i, testCase := range testCases {
+		filtered, err := testCase.filter.apply(metadata)
+		if err != nil && !testCase.shouldFail {
+			t.Errorf("Test %d: Expected to pass, failed with %s", i+1, err)
+		}
+		if err == nil && testCase.shouldFail {
+			t.Errorf("Test %d: Expected to fail, passed instead", i+1)
+		}
+		if len(filtered) != len(testCase.expected) {
+			t.Errorf("Test %d: Expected %d keys, got %d", i+1, len(testCase.expected), len(filtered))
+		}
+		for _, k := range testCase.expected {
+			if _, ok := filtered[k]; !ok {
+				t.Errorf("Test %d: Expected key %s to be kept", i+1, k)
+			}
+		}
+	}
+}
+
+// Tests that the user metadata refused by the filter of a bucket is not
+// written to its remotes.
+func TestMetadataFilterWrites(t *testing.T) {
+	remotes, clnts, shutdown := newTestRemotes(t, 2)
+	defer shutdown()
+	l := &radioObjects{
+		nsMutex: newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{
+			"bucket": {clnts: clnts, metadata: metadataFilter{Deny: []string{"x-amz-meta-internal-"}}},
+			"strict": {clnts: clnts, metadata: metadataFilter{Deny: []string{"x-amz-meta-internal-"}, Reject: true}},
+		},
+		multipartUploadIDMap: make(map[string][]string),
+	}
+	ctx := context.Background()
+	metadata := func() map[string]string {
+		return map[string]string{"X-Amz-Meta-Owner": "radio", "X-Amz-Meta-Internal-Foo": "bar"}
+	}
+
+	data := []byte("hello")
+	reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), "", "", int64(len(data)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = l.PutObject(ctx, "bucket", "object", NewPutObjReader(reader, nil, nil),
+		ObjectOptions{UserDefined: metadata()}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = l.NewMultipartUpload(ctx, "bucket", "upload", ObjectOptions{UserDefined: metadata()}); err != nil {
+		t.Fatal(err)
+	}
+	for i, remote := range remotes {
+		_, header, _ := remote.object("object")
+		if header.Get("X-Amz-Meta-Owner") != "radio" || header.Get("X-Amz-Meta-Internal-Foo") != "" {
+			t.Errorf("Remote %d: expected only the allowed metadata to be written, got %v", i, header)
+		}
+		for _, upload := range remote.uploads {
+			if upload.header.Get("X-Amz-Meta-Owner") != "radio" || upload.header.Get("X-Amz-Meta-Internal-Foo") != "" {
+				t.Errorf("Remote %d: expected only the allowed metadata to be uploaded, got %v", i, upload.header)
+			}
+		}
+	}
+
+	if _, err = l.NewMultipartUpload(ctx, "strict", "upload", ObjectOptions{UserDefined: metadata()}); err != (UnsupportedMetadata{}) {
+		t.Errorf("Expected UnsupportedMetadata, got %v", err)
+	}
+}
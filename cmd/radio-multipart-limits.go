@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// multipartLimitsConfig limits the multipart uploads to a bucket, zero
+// leaves them unlimited.
+type multipartLimitsConfig struct {
+	// MaxUploads limits the multipart uploads in progress, from their
+	// creation until they are completed or aborted.
+	MaxUploads int `yaml:"max_uploads"`
+	// MaxParts limits the parts uploaded or copied concurrently.
+	MaxParts int `yaml:"max_parts"`
+}
+
+// multipartLimiter rejects the multipart uploads and parts to a bucket
+// beyond its limits with SlowDown, since every part is sent to all
+// replicas. A nil multipartLimiter only tracks the uploads in progress.
+type multipartLimiter struct {
+	maxUploads int64
+	uploads    int64
+	parts      chan struct{}
+}
+
+// newMultipartLimiter returns the limiter of the multipart uploads to
+// bucket configured by cfg, nil if no limit is configured.
+func newMultipartLimiter(bucket string, cfg multipartLimitsConfig) (*multipartLimiter, error) {
+	if cfg.MaxUploads < 0 || cfg.MaxParts < 0 {
+		return nil, fmt.Errorf("bucket %s: multipart limits must not be negative", bucket)
+	}
+	if cfg.MaxUploads == 0 && cfg.MaxParts == 0 {
+		return nil, nil
+	}
+	m := &multipartLimiter{maxUploads: int64(cfg.MaxUploads)}
+	if cfg.MaxParts > 0 {
+		m.parts = make(chan struct{}, cfg.MaxParts)
+	}
+	return m, nil
+}
+
+// startUpload reserves an upload to bucket, returning SlowDown if the
+// uploads in progress reached the limit. Callers release reserved
+// uploads with endUpload once completed, aborted or failed.
+func (m *multipartLimiter) startUpload(bucket string) error {
+	if m != nil && m.maxUploads > 0 {
+		if atomic.AddInt64(&m.uploads, 1) > m.maxUploads {
+			atomic.AddInt64(&m.uploads, -1)
+			rejectedMultipart.WithLabelValues(bucket, "upload").Inc()
+			return SlowDown{}
+		}
+	}
+	multipartUploadsInProgress.WithLabelValues(bucket).Inc()
+	return nil
+}
+
+func (m *multipartLimiter) endUpload(bucket string) {
+	multipartUploadsInProgress.WithLabelValues(bucket).Dec()
+	if m != nil && m.maxUploads > 0 {
+		atomic.AddInt64(&m.uploads, -1)
+	}
+}
+
+// startPart reserves a part upload to bucket, returning SlowDown if the
+// parts in flight reached the limit. Callers release reserved parts with
+// endPart.
+func (m *multipartLimiter) startPart(bucket string) error {
+	if m == nil || m.parts == nil {
+		return nil
+	}
+	select {
+	case m.parts <- struct{}{}:
+		return nil
+	default:
+		rejectedMultipart.WithLabelValues(bucket, "part").Inc()
+		return SlowDown{}
+	}
+}
+
+func (m *multipartLimiter) endPart() {
+	if m == nil || m.parts == nil {
+		return
+	}
+	<-m.parts
+}
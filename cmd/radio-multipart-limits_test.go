@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/minio/minio/pkg/hash"
+)
+
+// Tests that multipart uploads and parts beyond the limits of a bucket
+// are rejected with SlowDown until others finish.
+func TestMultipartLimits(t *testing.T) {
+	if _, err := newMultipartLimiter("bucket", multipartLimitsConfig{MaxParts: -1}); err == nil {
+		t.Error("Expected negative limits rejected")
+	}
+	if m, err := newMultipartLimiter("bucket", multipartLimitsConfig{}); m != nil || err != nil {
+		t.Errorf("Expected no limiter without limits, got %v (%v)", m, err)
+	}
+	multipart, err := newMultipartLimiter("bucket", multipartLimitsConfig{MaxUploads: 1, MaxParts: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remotes, clnts, shutdown := newTestRemotes(t, 1)
+	defer shutdown()
+	l := &radioObjects{
+		nsMutex:              newNSLock(false),
+		multipartUploadIDMap: make(map[string][]string),
+		mirrorClients: map[string]mirrorConfig{"bucket": {
+			clnts:     clnts,
+			multipart: multipart,
+		}},
+	}
+
+	// Uploads failing to initiate release their reservation.
+	denyTestUploads(remotes[0])
+	if _, err = l.NewMultipartUpload(context.Background(), "bucket", "object", ObjectOptions{}); err == nil {
+		t.Fatal("Expected the upload to fail")
+	}
+	remotes[0].setHook(nil)
+	uploadID, err := l.NewMultipartUpload(context.Background(), "bucket", "object", ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = l.NewMultipartUpload(context.Background(), "bucket", "object", ObjectOptions{}); err != (SlowDown{}) {
+		t.Fatalf("Expected SlowDown beyond max_uploads, got %v", err)
+	}
+	if apiErr := toAPIError(context.Background(), err); apiErr.Code != "SlowDown" || apiErr.HTTPStatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected SlowDown reported to clients, got %+v", apiErr)
+	}
+
+	if err = multipart.startPart("bucket"); err != nil {
+		t.Fatal(err)
+	}
+	reader, err := hash.NewReader(bytes.NewReader([]byte("part")), 4, "", "", 4, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = l.PutObjectPart(context.Background(), "bucket", "object", uploadID, 1, NewPutObjReader(reader, nil, nil), ObjectOptions{}); err != (SlowDown{}) {
+		t.Errorf("Expected SlowDown beyond max_parts, got %v", err)
+	}
+	multipart.endPart()
+
+	if err = l.AbortMultipartUpload(context.Background(), "bucket", "object", uploadID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = l.NewMultipartUpload(context.Background(), "bucket", "object", ObjectOptions{}); err != nil {
+		t.Errorf("Expected an upload once the other was aborted, got %v", err)
+	}
+}
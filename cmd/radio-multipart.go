@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/radio/cmd/logger"
+)
+
+// Time completed multipart uploads are remembered for, to answer
+// retried CompleteMultipartUpload requests.
+const completedUploadTTL = 15 * time.Minute
+
+// Attempts at aborting each remote upload of a multipart upload whose
+// initiation failed.
+const abortUploadAttempts = 3
+
+type completedUpload struct {
+	bucket  string
+	object  string
+	etag    string
+	expires time.Time
+}
+
+// completedUploads remembers recently completed multipart uploads.
+type completedUploads struct {
+	mu      sync.Mutex
+	uploads map[string]completedUpload
+}
+
+func newCompletedUploads() *completedUploads {
+	return &completedUploads{uploads: make(map[string]completedUpload)}
+}
+
+// add records the completion of uploadID into object with etag.
+func (c *completedUploads) add(uploadID, bucket, object, etag string) {
+	now := UTCNow()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, upload := range c.uploads {
+		if now.After(upload.expires) {
+			delete(c.uploads, id)
+		}
+	}
+	c.uploads[uploadID] = completedUpload{
+		bucket:  bucket,
+		object:  object,
+		etag:    etag,
+		expires: now.Add(completedUploadTTL),
+	}
+}
+
+// get returns the result of the recently completed uploadID into object.
+func (c *completedUploads) get(uploadID, bucket, object string) (ObjectInfo, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	upload, ok := c.uploads[uploadID]
+	if !ok || upload.bucket != bucket || upload.object != object || UTCNow().After(upload.expires) {
+		return ObjectInfo{}, false
+	}
+	return ObjectInfo{Bucket: bucket, Name: object, ETag: upload.etag}, true
+}
+
+// abortUploads aborts the uploads initiated on the replicas of rs3s for
+// a multipart upload that failed to initiate on the others, ids being
+// indexed like the replicas. Aborts are retried so that no upload is
+// left holding storage on a remote, the uploads that could not be
+// aborted are logged.
+func abortUploads(ctx context.Context, bucket, object string, rs3s mirrorConfig, ids []string) {
+	for index, id := range ids {
+		if id == "" {
+			continue
+		}
+		clnt := rs3s.clnts[index]
+		var err error
+		for attempt := 0; attempt < abortUploadAttempts; attempt++ {
+			if attempt > 0 {
+				time.Sleep(retryBackoffUnit << uint(attempt-1))
+			}
+			err = clnt.AbortMultipartUploadWithContext(ctx, clnt.Bucket, clnt.objectKey(object), id)
+			if err == nil || miniogo.ToErrorResponse(err).Code == "NoSuchUpload" {
+				err = nil
+				break
+			}
+		}
+		if err != nil {
+			logger.LogIf(ctx, fmt.Errorf("multipart upload %s of %s/%s left on %s/%s: %w",
+				id, bucket, object, clnt.EndpointURL().Host, clnt.Bucket, err))
+		}
+	}
+}
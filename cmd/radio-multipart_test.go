@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minio/minio/pkg/hash"
+)
+
+// denyTestUploads fails the multipart uploads initiated on remote.
+func denyTestUploads(remote *testRemote) {
+	remote.setHook(func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method != http.MethodPost || r.URL.Query()["uploads"] == nil {
+			return false
+		}
+		writeTestRemoteError(w, r, http.StatusForbidden, "AccessDenied")
+		return true
+	})
+}
+
+// Tests that the uploads initiated on some replicas are aborted when
+// the multipart upload fails to initiate on another.
+func TestNewMultipartUploadCleanup(t *testing.T) {
+	remotes, clnts, shutdown := newTestRemotes(t, 3)
+	defer shutdown()
+	// The first abort on the second replica fails.
+	var mu sync.Mutex
+	aborts := make([]int, len(remotes))
+	abortFailures := 1
+	for i, remote := range remotes[:2] {
+		i := i
+		remote.setHook(func(w http.ResponseWriter, r *http.Request) bool {
+			if r.Method != http.MethodDelete {
+				return false
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			aborts[i]++
+			if i == 1 && abortFailures > 0 {
+				abortFailures--
+				writeTestRemoteError(w, r, http.StatusConflict, "OperationAborted")
+				return true
+			}
+			return false
+		})
+	}
+	denyTestUploads(remotes[2])
+	l := &radioObjects{
+		nsMutex:              newNSLock(false),
+		multipartUploadIDMap: make(map[string][]string),
+		mirrorClients:        map[string]mirrorConfig{"bucket": {clnts: clnts}},
+	}
+
+	uploadID, err := l.NewMultipartUpload(context.Background(), "bucket", "object", ObjectOptions{})
+	if err == nil {
+		t.Fatal("expected the upload to fail on the last replica")
+	}
+	if _, ok := l.multipartUploadIDMap[uploadID]; ok {
+		t.Error("expected the failed upload forgotten")
+	}
+	for i, remote := range remotes {
+		if ids := remote.uploadIDs(); len(ids) != 0 {
+			t.Errorf("expected no upload left on replica %d, got %v", i, ids)
+		}
+	}
+	// The failed abort is retried, and there is no upload to abort on
+	// the failing replica.
+	mu.Lock()
+	if aborts[0] != 1 || aborts[1] != 2 || aborts[2] != 0 {
+		t.Errorf("expected 1, 2 and 0 aborts, got %v", aborts)
+	}
+	mu.Unlock()
+}
+
+// Tests that parts are uploaded to the replicas without waiting for
+// shadow replicas which failed to initiate the upload.
+func TestShadowReplicaParts(t *testing.T) {
+	remotes, clnts, shutdown := newTestRemotes(t, 3)
+	defer shutdown()
+	clnts[2].Shadow = true
+	denyTestUploads(remotes[2])
+	l := &radioObjects{
+		nsMutex:              newNSLock(false),
+		multipartUploadIDMap: make(map[string][]string),
+		mirrorClients:        map[string]mirrorConfig{"bucket": {clnts: clnts}},
+	}
+
+	uploadID, err := l.NewMultipartUpload(context.Background(), "bucket", "object", ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The upload blocks until every replica reads it.
+	data := bytes.Repeat([]byte("data"), 1<<18)
+	reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), "", "", int64(len(data)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err = l.PutObjectPart(ctx, "bucket", "object", uploadID, 1, NewPutObjReader(reader, nil, nil), ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for i, remote := range remotes {
+		want := 1
+		if clnts[i].Shadow {
+			want = 0
+		}
+		if parts := remote.requests(http.MethodPut); parts != want {
+			t.Errorf("Expected %d parts uploaded to replica %d, got %d", want, i, parts)
+		}
+	}
+}
+
+// Tests that retries of a completed multipart upload are answered with
+// the result of the completion, without completing it again.
+func TestCompleteMultipartUploadRetry(t *testing.T) {
+	remotes, clnts, shutdown := newTestRemotes(t, 2)
+	defer shutdown()
+	var mu sync.Mutex
+	completes := make([]int, len(remotes))
+	for i, remote := range remotes {
+		i := i
+		remote.setHook(func(w http.ResponseWriter, r *http.Request) bool {
+			if r.Method == http.MethodPost && r.URL.Query().Get("uploadId") != "" {
+				mu.Lock()
+				completes[i]++
+				mu.Unlock()
+			}
+			return false
+		})
+	}
+	l := &radioObjects{
+		nsMutex:              newNSLock(false),
+		multipartUploadIDMap: make(map[string][]string),
+		completedUploads:     newCompletedUploads(),
+		mirrorClients:        map[string]mirrorConfig{"bucket": {clnts: clnts}},
+	}
+	uploadID, err := l.NewMultipartUpload(context.Background(), "bucket", "object", ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader, err := hash.NewReader(bytes.NewReader([]byte("part")), 4, "", "", 4, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part, err := l.PutObjectPart(context.Background(), "bucket", "object", uploadID, 1, NewPutObjReader(reader, nil, nil), ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := []CompletePart{{PartNumber: 1, ETag: part.ETag}}
+	var completed ObjectInfo
+
+	testCases := []struct {
+		object, uploadID string
+		shouldPass       bool
+	}{
+		// Completion.
+		{"object", uploadID, true},
+		// Retried completion.
+		{"object", uploadID, true},
+		// Upload of another object.
+		{"other", uploadID, false},
+		// Unknown upload.
+		{"object", "unknown", false},
+	}
+	for i, testCase := range testCases {
+		oi, err := l.CompleteMultipartUpload(context.Background(), "bucket", testCase.object, testCase.uploadID, parts, ObjectOptions{})
+		if !testCase.shouldPass {
+			if _, ok := err.(InvalidUploadID); !ok {
+				t.Errorf("Test %d: expected InvalidUploadID, got %v", i+1, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Test %d: unexpected error: %v", i+1, err)
+		}
+		if i == 0 {
+			completed = oi
+		}
+		if oi.ETag == "" || oi.ETag != completed.ETag || oi.Name != testCase.object {
+			t.Errorf("Test %d: expected the result of the completion %+v, got %+v", i+1, completed, oi)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for index, remote := range remotes {
+		if completes[index] != 1 {
+			t.Errorf("expected the upload completed once on replica %d, got %d", index, completes[index])
+		}
+		if data, _, ok := remote.object("object"); !ok || data != "part" {
+			t.Errorf("expected the object completed on replica %d, got %q", index, data)
+		}
+	}
+}
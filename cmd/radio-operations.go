@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Client operations that can be disabled, each covering the object
+// layer methods implementing it.
+const (
+	operationGetObject     = "GetObject"
+	operationHeadObject    = "HeadObject"
+	operationPutObject     = "PutObject"
+	operationAppendObject  = "AppendObject"
+	operationCopyObject    = "CopyObject"
+	operationDeleteObject  = "DeleteObject"
+	operationListObjects   = "ListObjects"
+	operationSelectObject  = "SelectObjectContent"
+	operationRestoreObject = "RestoreObject"
+	operationMultipart     = "Multipart"
+)
+
+var disableableOperations = map[string]bool{
+	operationGetObject:     true,
+	operationHeadObject:    true,
+	operationPutObject:     true,
+	operationAppendObject:  true,
+	operationCopyObject:    true,
+	operationDeleteObject:  true,
+	operationListObjects:   true,
+	operationSelectObject:  true,
+	operationRestoreObject: true,
+	operationMultipart:     true,
+}
+
+// parseDisabledOperations validates the operations disabled in the
+// configuration, matched regardless of case.
+func parseDisabledOperations(ops []string) (map[string]bool, error) {
+	disabled := make(map[string]bool, len(ops))
+	for _, op := range ops {
+		name, ok := operationName(op)
+		if !ok {
+			var valid []string
+			for name := range disableableOperations {
+				valid = append(valid, name)
+			}
+			sort.Strings(valid)
+			return nil, fmt.Errorf("unknown disabled operation %q, expected one of %s",
+				op, strings.Join(valid, ", "))
+		}
+		disabled[name] = true
+	}
+	return disabled, nil
+}
+
+func operationName(op string) (string, bool) {
+	for name := range disableableOperations {
+		if strings.EqualFold(name, op) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// operationAllowed returns OperationNotAllowed if op is disabled.
+func (l *radioObjects) operationAllowed(op string) error {
+	if l.disabledOperations[op] {
+		return OperationNotAllowed{Operation: op}
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+)
+
+// Tests that disabled operations are validated and rejected.
+func TestDisabledOperations(t *testing.T) {
+	if _, err := parseDisabledOperations([]string{"Multipart", "Rename"}); err == nil {
+		t.Fatal("expected an error for an unknown operation")
+	}
+	disabled, err := parseDisabledOperations([]string{"multipart", "DeleteObject"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := &radioObjects{nsMutex: newNSLock(false), disabledOperations: disabled}
+	if _, err = l.NewMultipartUpload(context.Background(), "bucket", "object", ObjectOptions{}); err != (OperationNotAllowed{operationMultipart}) {
+		t.Errorf("expected multipart to be disabled, got %v", err)
+	}
+	if err = l.DeleteObject(context.Background(), "bucket", "object"); err != (OperationNotAllowed{operationDeleteObject}) {
+		t.Errorf("expected delete to be disabled, got %v", err)
+	}
+	if _, err = l.GetObjectInfo(context.Background(), "bucket", "object", ObjectOptions{}); err == (OperationNotAllowed{operationHeadObject}) {
+		t.Error("expected head to be allowed")
+	}
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/minio/minio/pkg/hash"
+)
+
+// Tests that uploads to https remotes configured with unsigned payloads
+// are not signed with the payload SHA256.
+func TestUnsignedPayload(t *testing.T) {
+	remotes, clnts, shutdown := newTLSTestRemotes(t, 2)
+	defer shutdown()
+	clnts[0].UnsignedPayload = true
+
+	var mu sync.Mutex
+	payloads := make([]string, len(remotes))
+	for i, remote := range remotes {
+		i := i
+		remote.setHook(func(w http.ResponseWriter, r *http.Request) bool {
+			if r.Method == http.MethodPut {
+				mu.Lock()
+				payloads[i] = r.Header.Get("X-Amz-Content-Sha256")
+				mu.Unlock()
+			}
+			return false
+		})
+	}
+	l := &radioObjects{
+		nsMutex:              newNSLock(false),
+		mirrorClients:        map[string]mirrorConfig{"bucket": {clnts: clnts}},
+		multipartUploadIDMap: make(map[string][]string),
+	}
+
+	data := []byte("hello")
+	sum := sha256.Sum256(data)
+	sha256Hex := hex.EncodeToString(sum[:])
+	newReader := func() *PutObjReader {
+		reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), "", sha256Hex, int64(len(data)), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return NewPutObjReader(reader, nil, nil)
+	}
+	checkPayloads := func(op string) {
+		t.Helper()
+		mu.Lock()
+		defer mu.Unlock()
+		if payloads[0] != unsignedPayload {
+			t.Errorf("%s: expected an unsigned payload upload, got %q", op, payloads[0])
+		}
+		if payloads[1] != sha256Hex {
+			t.Errorf("%s: expected an upload signed with the payload SHA256 %s, got %q", op, sha256Hex, payloads[1])
+		}
+	}
+
+	ctx := context.Background()
+	if _, err := l.PutObject(ctx, "bucket", "object", newReader(), ObjectOptions{UserDefined: map[string]string{}}); err != nil {
+		t.Fatal(err)
+	}
+	checkPayloads("PutObject")
+	for i, remote := range remotes {
+		if got, _, _ := remote.object("object"); got != string(data) {
+			t.Errorf("Replica %d: expected %q, got %q", i, data, got)
+		}
+	}
+
+	uploadID, err := l.NewMultipartUpload(ctx, "bucket", "multipart", ObjectOptions{UserDefined: map[string]string{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = l.PutObjectPart(ctx, "bucket", "multipart", uploadID, 1, newReader(), ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	checkPayloads("PutObjectPart")
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"net"
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/minio/radio/cmd/logger"
+)
+
+const radioPprofPath = "/debug/pprof/"
+
+// newPprofMux returns the net/http/pprof handlers served under
+// radioPprofPath.
+func newPprofMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc(radioPprofPath, pprof.Index)
+	mux.HandleFunc(radioPprofPath+"cmdline", pprof.Cmdline)
+	mux.HandleFunc(radioPprofPath+"profile", pprof.Profile)
+	mux.HandleFunc(radioPprofPath+"symbol", pprof.Symbol)
+	mux.HandleFunc(radioPprofPath+"trace", pprof.Trace)
+	return mux
+}
+
+// startPprofServer serves pprof handlers on addr, unauthenticated, and
+// is meant for addresses reachable by operators only. Profiles are never
+// served on the client listener, as any client credentials would grant
+// access to them.
+func startPprofServer(addr string) {
+	l, err := net.Listen("tcp", addr)
+	logger.FatalIf(err, "Unable to start the pprof listener")
+	go func() {
+		logger.FatalIf(http.Serve(l, newPprofMux()), "pprof listener failed")
+	}()
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Tests that pprof handlers require their own address and are not
+// served on the client listener.
+func TestPprofAddress(t *testing.T) {
+	var rconfig radioConfig
+	rconfig.Debug.Pprof = true
+	if err := lookupConfigEnv(rconfig); err == nil {
+		t.Fatal("Expected pprof without an address to be rejected")
+	}
+
+	defer setRadioAdminTestLayer(&radioObjects{})()
+	rec := serveRadioAdminTestRequest(newRadioAdminTestRequest(t, http.MethodGet, radioPprofPath+"cmdline", nil, radioAdminTestSecretKey))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected pprof not to be served on the client listener, got status %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	newPprofMux().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, radioPprofPath+"cmdline", nil))
+	if rec.Code != http.StatusOK || rec.Body.Len() == 0 {
+		t.Errorf("Expected the command line to be served, got status %d", rec.Code)
+	}
+}
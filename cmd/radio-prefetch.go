@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/minio/pkg/policy"
+	xhttp "github.com/minio/radio/cmd/http"
+)
+
+// prefetchEntry reports the progress of a prefetch, one entry is sent
+// for every key copied to the target replica or failing to be copied,
+// followed by a final entry with Done set summarizing the run.
+type prefetchEntry struct {
+	Key     string `json:"key,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Done    bool   `json:"done,omitempty"`
+	Scanned int    `json:"scanned"`
+	Copied  int    `json:"copied"`
+	Failed  int    `json:"failed"`
+}
+
+// PrefetchHandler - copies the objects of a bucket prefix missing on
+// the target replica from the source replica, streaming the progress
+// as newline delimited JSON. The source replica defaults to the heal
+// primary when configured, the first replica otherwise. The optional
+// rate parameter limits the number of objects copied per second.
+func PrefetchHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "Prefetch")
+
+	query := r.URL.Query()
+	bucket := query.Get("bucket")
+	prefix := query.Get("prefix")
+
+	if s3Error := checkRequestAuthType(ctx, r, policy.PutObjectAction, bucket, ""); s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+		return
+	}
+
+	l, ok := newObjectLayerFn().(*radioObjects)
+	if !ok {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+	rs3s, ok := l.mirrorClients[bucket]
+	if !ok {
+		writeErrorResponse(ctx, w, toAPIError(ctx, BucketNotFound{Bucket: bucket}), r.URL)
+		return
+	}
+
+	source := 0
+	if l.healSys != nil && l.healSys.policy == healPolicyPrimary {
+		source = l.healSys.primary
+	}
+	var err error
+	if v := query.Get("source"); v != "" {
+		if source, err = strconv.Atoi(v); err != nil {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidQueryParams), r.URL)
+			return
+		}
+	}
+	target, err := strconv.Atoi(query.Get("target"))
+	if err != nil {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidQueryParams), r.URL)
+		return
+	}
+	n := len(rs3s.clnts)
+	if source < 0 || source >= n || target < 0 || target >= n || source == target ||
+		rs3s.clnts[source].Shadow || rs3s.clnts[target].ReadOnly {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidQueryParams), r.URL)
+		return
+	}
+	var rate int
+	if v := query.Get("rate"); v != "" {
+		if rate, err = strconv.Atoi(v); err != nil || rate < 0 {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidQueryParams), r.URL)
+			return
+		}
+	}
+
+	setCommonHeaders(w)
+	w.Header().Set(xhttp.ContentType, string(mimeNDJSON))
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	send := func(entry prefetchEntry) error {
+		if err := enc.Encode(entry); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	}
+	summary, err := l.prefetchPrefix(ctx, bucket, prefix, rs3s.clnts[source], rs3s.clnts[target], rate, send)
+	summary.Done = true
+	if err != nil {
+		// Headers are already sent, report the failure in the summary.
+		summary.Error = err.Error()
+	}
+	send(summary)
+}
+
+// prefetchPrefix copies every object under prefix present on src but
+// missing on dst, at most rate objects per second when rate is
+// positive. Failures to copy an object are reported and do not stop
+// the prefetch.
+func (l *radioObjects) prefetchPrefix(ctx context.Context, bucket, prefix string, src, dst bucketClient,
+	rate int, report func(prefetchEntry) error) (summary prefetchEntry, err error) {
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+
+	var ticker *time.Ticker
+	if rate > 0 {
+		ticker = time.NewTicker(time.Second / time.Duration(rate))
+		defer ticker.Stop()
+	}
+
+	for oi := range src.listObjects(src.objectKey(prefix), doneCh) {
+		if oi.Err != nil {
+			return summary, oi.Err
+		}
+		if err = ctx.Err(); err != nil {
+			return summary, err
+		}
+		summary.Scanned++
+
+		object := src.listKey(oi.Key)
+		_, err = dst.StatObjectWithContext(ctx, dst.Bucket, dst.objectKey(object), miniogo.StatObjectOptions{})
+		if err == nil {
+			continue
+		}
+		if miniogo.ToErrorResponse(err).Code != "NoSuchKey" {
+			return summary, err
+		}
+
+		if ticker != nil {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return summary, ctx.Err()
+			}
+		}
+
+		entry := prefetchEntry{Key: object}
+		if err = l.prefetchObject(ctx, bucket, object, src, dst); err != nil {
+			summary.Failed++
+			entry.Error = err.Error()
+		} else {
+			summary.Copied++
+		}
+		entry.Scanned, entry.Copied, entry.Failed = summary.Scanned, summary.Copied, summary.Failed
+		if err = report(entry); err != nil {
+			return summary, err
+		}
+	}
+	return summary, nil
+}
+
+// prefetchObject copies object from src to dst under the object lock,
+// unless it was written to dst in the meantime.
+func (l *radioObjects) prefetchObject(ctx context.Context, bucket, object string, src, dst bucketClient) error {
+	objectLock := l.NewNSLock(ctx, bucket, object)
+	if err := objectLock.GetLock(globalObjectTimeout); err != nil {
+		return err
+	}
+	defer objectLock.Unlock()
+
+	_, err := dst.StatObjectWithContext(ctx, dst.Bucket, dst.objectKey(object), miniogo.StatObjectOptions{})
+	if err == nil {
+		return nil
+	}
+	if miniogo.ToErrorResponse(err).Code != "NoSuchKey" {
+		return err
+	}
+	if err = healObjectCopy(ctx, src, dst, object, nil); err != nil {
+		if miniogo.ToErrorResponse(err).Code == "NoSuchKey" {
+			// Object was removed from the source since it was listed.
+			return nil
+		}
+		return err
+	}
+	return nil
+}
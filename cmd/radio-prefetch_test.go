@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// Tests that the prefetch copies the objects missing on the target
+// replica, streaming the progress as newline delimited JSON.
+func TestPrefetchHandler(t *testing.T) {
+	remotes, clnts, shutdown := newTestRemotes(t, 2)
+	defer shutdown()
+	remotes[0].putObject("dir/a", "a", map[string]string{"X-Amz-Meta-Radio-Tag": "a"})
+	remotes[0].putObject("dir/b", "b", map[string]string{"X-Amz-Meta-Radio-Tag": "b"})
+	remotes[0].putObject("other", "other", map[string]string{"X-Amz-Meta-Radio-Tag": "other"})
+	remotes[1].putObject("dir/a", "a", map[string]string{"X-Amz-Meta-Radio-Tag": "a"})
+	l := &radioObjects{
+		nsMutex:       newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+	}
+	defer setRadioAdminTestLayer(l)()
+
+	query := url.Values{"bucket": {"bucket"}, "prefix": {"dir/"}, "target": {"1"}}
+	testRadioAdminAuth(t, http.MethodPost, radioPrefetchPath, query)
+
+	for _, invalid := range []url.Values{
+		{"bucket": {"bucket"}, "target": {"0"}},
+		{"bucket": {"bucket"}, "target": {"2"}},
+		{"bucket": {"bucket"}, "target": {"1"}, "rate": {"-1"}},
+	} {
+		rec := serveRadioAdminTestRequest(newRadioAdminTestRequest(t, http.MethodPost, radioPrefetchPath, invalid, radioAdminTestSecretKey))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%v: expected status %d, got %d", invalid, http.StatusBadRequest, rec.Code)
+		}
+	}
+	rec := serveRadioAdminTestRequest(newRadioAdminTestRequest(t, http.MethodPost, radioPrefetchPath,
+		url.Values{"bucket": {"missing"}, "target": {"1"}}, radioAdminTestSecretKey))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for a missing bucket, got %d", http.StatusNotFound, rec.Code)
+	}
+
+	rec = serveRadioAdminTestRequest(newRadioAdminTestRequest(t, http.MethodPost, radioPrefetchPath, query, radioAdminTestSecretKey))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body)
+	}
+	if contentType := rec.Header().Get("Content-Type"); contentType != string(mimeNDJSON) {
+		t.Errorf("Expected content type %s, got %s", mimeNDJSON, contentType)
+	}
+	var entries []prefetchEntry
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var entry prefetchEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("Invalid progress line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	expected := []prefetchEntry{
+		{Key: "dir/b", Scanned: 2, Copied: 1},
+		{Done: true, Scanned: 2, Copied: 1},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("Expected progress %+v, got %+v", expected, entries)
+	}
+
+	if data, header, ok := remotes[1].object("dir/b"); !ok || data != "b" || header.Get("X-Amz-Meta-Radio-Tag") != "b" {
+		t.Errorf("Expected dir/b to be prefetched, got %q %v", data, header)
+	}
+	if _, _, ok := remotes[1].object("other"); ok {
+		t.Error("Expected objects outside of the prefix not to be prefetched")
+	}
+}
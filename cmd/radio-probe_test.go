@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/minio-go/v6/pkg/credentials"
+)
+
+// Tests that remote probes retry transient errors only.
+func TestProbeS3(t *testing.T) {
+	defer func(backoff time.Duration) { probeBackoff = backoff }(probeBackoff)
+	probeBackoff = time.Millisecond
+
+	testCases := []struct {
+		statuses         []int
+		shouldErr        bool
+		expectedAttempts int32
+	}{
+		// Bucket exists.
+		{[]int{http.StatusOK}, false, 1},
+		// Server recovering.
+		{[]int{http.StatusServiceUnavailable, http.StatusInternalServerError, http.StatusOK}, false, 3},
+		// Server failing persistently.
+		{[]int{http.StatusServiceUnavailable}, true, maxProbeRetry + 1},
+		// Invalid credentials.
+		{[]int{http.StatusForbidden}, true, 1},
+		// Missing bucket.
+		{[]int{http.StatusNotFound}, true, 1},
+	}
+	for i, testCase := range testCases {
+		var attempts int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := int(atomic.AddInt32(&attempts, 1))
+			if n > len(testCase.statuses) {
+				n = len(testCase.statuses)
+			}
+			w.WriteHeader(testCase.statuses[n-1])
+		}))
+
+		u, err := url.Parse(ts.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clnt, err := miniogo.NewWithOptions(u.Host, &miniogo.Options{
+			Creds:  credentials.NewStaticV4("accesskey", "secretkey", ""),
+			Region: "us-east-1",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = probeS3(clnt, "bucket")
+		ts.Close()
+
+		if (err != nil) != testCase.shouldErr {
+			t.Errorf("Test %d: expected error %v, got %v", i+1, testCase.shouldErr, err)
+		}
+		if attempts != testCase.expectedAttempts {
+			t.Errorf("Test %d: expected %d attempts, got %d", i+1, testCase.expectedAttempts, attempts)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/radio/cmd/logger"
+)
+
+// Objects whose heal was given up, the dead letter entries, are
+// quarantined until an operator requeues or discards their entries:
+// further heals of the version given up are not journaled, and reads
+// fail with ObjectQuarantined while the replicas of the object diverge
+// rather than serving either version.
+
+// quarantineKey is the key of object of bucket in the quarantine.
+func quarantineKey(bucket, object string) string {
+	return pathJoin(bucket, object)
+}
+
+// refreshQuarantine reloads the quarantine from the dead letter entries,
+// which radio peers sharing the journal bury too.
+func (h *healSys) refreshQuarantine(ctx context.Context) {
+	entries, err := h.store.ListDead()
+	if err != nil {
+		logger.LogIf(ctx, err)
+		return
+	}
+	quarantine := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		quarantine[quarantineKey(entry.Bucket, entry.Object)] = entry.RadioTag
+	}
+	h.mu.Lock()
+	h.quarantine = quarantine
+	h.mu.Unlock()
+}
+
+// quarantined returns true if the heal of object of bucket was given
+// up, false for a nil healSys.
+func (h *healSys) quarantined(bucket, object string) bool {
+	if h == nil {
+		return false
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, ok := h.quarantine[quarantineKey(bucket, object)]
+	return ok
+}
+
+// quarantinedVersion returns true if the heal of the version of entry
+// was given up.
+func (h *healSys) quarantinedVersion(entry journalEntry) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	radioTag, ok := h.quarantine[quarantineKey(entry.Bucket, entry.Object)]
+	return ok && radioTag == entry.RadioTag
+}
+
+func (h *healSys) setQuarantine(entry journalEntry) {
+	h.mu.Lock()
+	h.quarantine[quarantineKey(entry.Bucket, entry.Object)] = entry.RadioTag
+	h.mu.Unlock()
+}
+
+func (h *healSys) clearQuarantine(entry journalEntry) {
+	h.mu.Lock()
+	delete(h.quarantine, quarantineKey(entry.Bucket, entry.Object))
+	h.mu.Unlock()
+}
+
+// replicasDiverge returns true if the replicas stated as oinfos and errs
+// hold different versions of an object, or only some of them hold it.
+// Replicas failing otherwise are left out.
+func replicasDiverge(oinfos []miniogo.ObjectInfo, errs []error) bool {
+	radioTag, found, missing := "", false, false
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			tag := oinfos[i].Metadata.Get(globalRadioTagKey)
+			if found && tag != radioTag {
+				return true
+			}
+			radioTag, found = tag, true
+		case miniogo.ToErrorResponse(err).Code == "NoSuchKey":
+			missing = true
+		}
+	}
+	return found && missing
+}
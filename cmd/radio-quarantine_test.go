@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+// Tests that objects whose heal was given up are quarantined until the
+// operator resolves their dead letter entry: their reads fail while
+// their replicas diverge and their version is not journaled again.
+func TestQuarantine(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "radio-quarantine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	remotes, clnts, shutdown := newTestReplicas(t, testReplica{"v2", time.Now()}, testReplica{"v1", time.Now()},
+		testReplica{"v1", time.Now()})
+	defer shutdown()
+	for _, remote := range remotes {
+		remote.putTaggedObject("other", "o", "v1", time.Now())
+	}
+	l := &radioObjects{
+		nsMutex:       newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+	}
+	store := &dirJournalStore{dir: tmpdir}
+	if l.healSys, err = newHealSys(l, store, journalConfig{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	h := l.healSys
+
+	if _, err = l.getObjectInfo(context.Background(), "bucket", "object", ObjectOptions{}); err != nil {
+		t.Fatalf("Expected reads served before the quarantine, got %v", err)
+	}
+	entry := journalEntry{ID: "1", Bucket: "bucket", Object: "object", Op: opPutObject, RadioTag: "v2",
+		DstClientIDs: []int{1, 2}}
+	if err = store.Save(entry); err != nil {
+		t.Fatal(err)
+	}
+	h.bury(context.Background(), entry, errors.New("source unreadable"))
+
+	if _, err = l.getObjectInfo(context.Background(), "bucket", "object", ObjectOptions{}); err != (ObjectQuarantined{Bucket: "bucket", Object: "object"}) {
+		t.Errorf("Expected ObjectQuarantined, got %v", err)
+	}
+	if _, err = l.getObjectInfo(context.Background(), "bucket", "other", ObjectOptions{}); err != nil {
+		t.Errorf("Expected other objects read, got %v", err)
+	}
+
+	// Only new versions of a quarantined object are journaled.
+	h.queue(context.Background(), journalEntry{Bucket: "bucket", Object: "object", Op: opPutObject, RadioTag: "v2", DstClientIDs: []int{1, 2}})
+	h.queue(context.Background(), journalEntry{Bucket: "bucket", Object: "object", Op: opPutObject, RadioTag: "v3", DstClientIDs: []int{1, 2}})
+	entries, err := store.List()
+	if err != nil || len(entries) != 1 || entries[0].RadioTag != "v3" {
+		t.Errorf("Expected only v3 journaled, got %+v (%v)", entries, err)
+	}
+
+	// Peers sharing the journal learn of the quarantine.
+	peer, err := newHealSys(l, store, journalConfig{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	peer.refreshQuarantine(context.Background())
+	if !peer.quarantined("bucket", "object") {
+		t.Error("Expected the quarantine loaded from the dead letter entries")
+	}
+
+	if err = h.discard("1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = l.getObjectInfo(context.Background(), "bucket", "object", ObjectOptions{}); err != nil {
+		t.Errorf("Expected reads served once the entry is discarded, got %v", err)
+	}
+	if err = h.discard("1"); err != errDeadLetterNotFound {
+		t.Errorf("Expected %v discarding an unknown entry, got %v", errDeadLetterNotFound, err)
+	}
+}
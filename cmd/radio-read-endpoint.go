@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/minio-go/v6/pkg/s3utils"
+	"github.com/minio/radio/cmd/logger"
+)
+
+// validateEndpointURL checks that urlStr is an http or https URL.
+func validateEndpointURL(urlStr string) error {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid endpoint %q, expected an http or https URL", urlStr)
+	}
+	return nil
+}
+
+// validateReadEndpoint checks the endpoints of a remote serving reads
+// from a separate endpoint.
+func validateReadEndpoint(cfg remoteConfig) error {
+	if err := validateEndpointURL(cfg.Endpoint); err != nil {
+		return err
+	}
+	if err := validateEndpointURL(cfg.ReadEndpoint); err != nil {
+		return fmt.Errorf("read endpoint: %v", err)
+	}
+	if (cfg.ReadAccessKey == "") != (cfg.ReadSecretKey == "") {
+		return fmt.Errorf("read endpoint: both read_access_key and read_secret_key must be set")
+	}
+	return nil
+}
+
+// newReadClients returns the clients reading objects, stats and
+// listings of the remote of cfg from its read endpoint. Requests are
+// signed with the credentials of the remote unless read credentials are
+// set, and for the region of the read endpoint, falling back to the one
+// of the remote.
+func newReadClients(cfg remoteConfig, transport http.RoundTripper, allowDegraded bool) (*miniogo.Core, *versionsClient, error) {
+	accessKey, secretKey, sessionToken := cfg.AccessKey, cfg.SecretKey, cfg.SessionToken
+	if cfg.ReadAccessKey != "" {
+		accessKey, secretKey, sessionToken = cfg.ReadAccessKey, cfg.ReadSecretKey, ""
+	}
+	region := cfg.ReadRegion
+	if region == "" {
+		if u, err := url.Parse(cfg.ReadEndpoint); err == nil {
+			region = s3utils.GetRegionFromURL(*u)
+		}
+	}
+	if region == "" {
+		if u, err := url.Parse(cfg.Endpoint); err == nil {
+			region = s3utils.GetRegionFromURL(*u)
+		}
+	}
+
+	clnt, err := newS3(cfg.Bucket, cfg.ReadEndpoint, region, accessKey, secretKey, sessionToken, transport)
+	if err != nil {
+		if clnt == nil || !allowDegraded || !isRemoteOffline(err) {
+			return nil, nil, fmt.Errorf("read endpoint %s: %v", cfg.ReadEndpoint, err)
+		}
+		logger.Info("WARNING: read endpoint %s/%s is offline, starting degraded: %v",
+			cfg.ReadEndpoint, cfg.Bucket, err)
+		go waitRemoteOnline(clnt.Client, cfg.ReadEndpoint, cfg.Bucket)
+	}
+	versions, err := newVersionsClient(cfg.ReadEndpoint, accessKey, secretKey, sessionToken, transport)
+	if err != nil {
+		return nil, nil, err
+	}
+	if region != "" {
+		versions.region = region
+	}
+	return clnt, versions, nil
+}
+
+// reader returns c serving reads from its read endpoint if configured.
+func (c bucketClient) reader() bucketClient {
+	if c.readCore != nil {
+		c.Core, c.versions = c.readCore, c.readVersions
+	}
+	return c
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/minio-go/v6/pkg/credentials"
+	"github.com/minio/minio/pkg/hash"
+)
+
+func TestValidateReadEndpoint(t *testing.T) {
+	testCases := []struct {
+		cfg        remoteConfig
+		shouldPass bool
+	}{
+		{remoteConfig{Endpoint: "https://s3.example.com", ReadEndpoint: "https://cdn.example.com"}, true},
+		{remoteConfig{Endpoint: "https://s3.example.com", ReadEndpoint: "cdn.example.com"}, false},
+		{remoteConfig{Endpoint: "s3.example.com", ReadEndpoint: "https://cdn.example.com"}, false},
+		{remoteConfig{Endpoint: "https://s3.example.com", ReadEndpoint: "ftp://cdn.example.com"}, false},
+		{remoteConfig{Endpoint: "https://s3.example.com", ReadEndpoint: "https://cdn.example.com",
+			ReadAccessKey: "accesskey", ReadSecretKey: "secretkey"}, true},
+		{remoteConfig{Endpoint: "https://s3.example.com", ReadEndpoint: "https://cdn.example.com",
+			ReadAccessKey: "accesskey"}, false},
+	}
+	for i, testCase := range testCases {
+		err := validateReadEndpoint(testCase.cfg)
+		if testCase.shouldPass && err != nil {
+			t.Errorf("Test %d: Unexpected error %v", i+1, err)
+		}
+		if !testCase.shouldPass && err == nil {
+			t.Errorf("Test %d: Expected an error", i+1)
+		}
+	}
+}
+
+func newTestCore(t *testing.T, rawURL string) *miniogo.Core {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clnt, err := miniogo.NewWithOptions(u.Host, &miniogo.Options{
+		Creds:  credentials.NewStaticV4("accesskey", "secretkey", ""),
+		Region: "us-east-1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &miniogo.Core{Client: clnt}
+}
+
+// Tests that reads are served by the read endpoint and writes by the
+// endpoint of a remote.
+func TestReadEndpointRouting(t *testing.T) {
+	// The read endpoint serves a copy of the objects written.
+	remotes, clnts, shutdown := newTestReplicas(t, testReplica{}, testReplica{"tag", time.Now()})
+	defer shutdown()
+	write, read := remotes[0], remotes[1]
+	l := &radioObjects{
+		nsMutex: newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: []bucketClient{{
+			Core:     clnts[0].Core,
+			readCore: clnts[1].Core,
+			Bucket:   testRemoteBucket,
+		}}}},
+	}
+
+	data := []byte("data")
+	reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), "", "", int64(len(data)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = l.PutObject(context.Background(), "bucket", "object", NewPutObjReader(reader, nil, nil), ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	gr, err := l.GetObjectNInfo(context.Background(), "bucket", "object", nil, nil, ReadLock, ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadAll(gr)
+	gr.Close()
+	if err != nil || string(got) != "data" {
+		t.Errorf("Expected data %q, got %q (%v)", "data", got, err)
+	}
+
+	if write.requests(http.MethodPut) != 1 || write.requests(http.MethodGet)+write.requests(http.MethodHead) != 0 {
+		t.Errorf("Expected only the write served by the endpoint")
+	}
+	if read.requests(http.MethodPut) != 0 || read.requests(http.MethodHead) == 0 || read.requests(http.MethodGet) == 0 {
+		t.Errorf("Expected only the reads served by the read endpoint")
+	}
+}
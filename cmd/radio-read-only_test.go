@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/minio/minio/pkg/hash"
+)
+
+// Tests that objects and parts are written to the writable replicas
+// only, without waiting for read-only replicas to read the upload.
+func TestReadOnlyReplicaWrites(t *testing.T) {
+	remotes, clnts, shutdown := newTestRemotes(t, 3)
+	defer shutdown()
+	clnts[2].ReadOnly = true
+	l := &radioObjects{
+		nsMutex:              newNSLock(false),
+		multipartUploadIDMap: make(map[string][]string),
+		mirrorClients:        map[string]mirrorConfig{"bucket": {clnts: clnts}},
+	}
+
+	// The upload blocks until every replica reads it.
+	data := bytes.Repeat([]byte("data"), 1<<18)
+	newReader := func() *PutObjReader {
+		reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), "", "", int64(len(data)), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return NewPutObjReader(reader, nil, nil)
+	}
+	// Writes blocked on the read-only replica fail once ctx expires.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := l.PutObject(ctx, "bucket", "object", newReader(), ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for i, remote := range remotes[:2] {
+		if got, _, _ := remote.object("object"); got != string(data) {
+			t.Errorf("Expected the object written to replica %d", i)
+		}
+	}
+
+	uploadID, err := l.NewMultipartUpload(context.Background(), "bucket", "object", ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = l.PutObjectPart(ctx, "bucket", "object", uploadID, 1, newReader(), ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for i, remote := range remotes[:2] {
+		if ids := remote.uploadIDs(); len(ids) != 1 || remote.requests(http.MethodPut) != 2 {
+			t.Errorf("Expected the object and the part uploaded to replica %d, got uploads %v", i, ids)
+		}
+	}
+	if n := remotes[2].requests(http.MethodPut) + remotes[2].requests(http.MethodPost); n != 0 {
+		t.Errorf("Expected no write sent to the read-only replica, got %d requests", n)
+	}
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	xnet "github.com/minio/minio/pkg/net"
+)
+
+const (
+	defaultRecentWritesSize = 10000
+	defaultRecentWritesTTL  = 5 * time.Minute
+)
+
+// recentWritesConfig configures the cache of the objects recently
+// written, whose info is served by HEAD requests when all replicas are
+// unreachable rather than failing with BackendDown.
+type recentWritesConfig struct {
+	Enable bool `yaml:"enable"`
+	// Size is the number of objects kept, 10000 by default.
+	Size int `yaml:"size"`
+	// TTL is how long the info of an object is kept after it was
+	// written, 5m by default.
+	TTL time.Duration `yaml:"ttl"`
+}
+
+type recentWriteKey struct {
+	bucket, object string
+}
+
+type recentWrite struct {
+	key     recentWriteKey
+	info    ObjectInfo
+	expires time.Time
+}
+
+// recentWrites keeps the info of the objects last written through radio
+// until they expire, are overwritten or deleted, evicting the least
+// recently written objects beyond its size. A nil recentWrites keeps
+// nothing.
+type recentWrites struct {
+	size int
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[recentWriteKey]*list.Element
+}
+
+// newRecentWrites returns the cache configured by cfg, nil if disabled.
+func newRecentWrites(cfg recentWritesConfig) (*recentWrites, error) {
+	if !cfg.Enable {
+		return nil, nil
+	}
+	if cfg.Size < 0 || cfg.TTL < 0 {
+		return nil, fmt.Errorf("recent writes size and ttl must not be negative")
+	}
+	c := &recentWrites{
+		size:    cfg.Size,
+		ttl:     cfg.TTL,
+		order:   list.New(),
+		entries: make(map[recentWriteKey]*list.Element),
+	}
+	if c.size == 0 {
+		c.size = defaultRecentWritesSize
+	}
+	if c.ttl == 0 {
+		c.ttl = defaultRecentWritesTTL
+	}
+	return c, nil
+}
+
+// add records info of an object written with metadata, whose content
+// type and modification time remotes do not return on writes.
+func (c *recentWrites) add(info ObjectInfo, metadata map[string]string) {
+	if c == nil {
+		return
+	}
+	if info.ContentType == "" {
+		info.ContentType = metadata["content-type"]
+	}
+	if info.ModTime.IsZero() {
+		info.ModTime = UTCNow()
+	}
+	key := recentWriteKey{info.Bucket, info.Name}
+	entry := &recentWrite{key: key, info: info, expires: UTCNow().Add(c.ttl)}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.order.Remove(e)
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*recentWrite).key)
+	}
+}
+
+// forget drops object of bucket, overwritten or deleted.
+func (c *recentWrites) forget(bucket, object string) {
+	if c == nil {
+		return
+	}
+	key := recentWriteKey{bucket, object}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.order.Remove(e)
+		delete(c.entries, key)
+	}
+}
+
+// get returns the info of object of bucket if recently written, marked
+// as possibly stale.
+func (c *recentWrites) get(bucket, object string) (ObjectInfo, bool) {
+	if c == nil {
+		return ObjectInfo{}, false
+	}
+	key := recentWriteKey{bucket, object}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return ObjectInfo{}, false
+	}
+	entry := e.Value.(*recentWrite)
+	if UTCNow().After(entry.expires) {
+		c.order.Remove(e)
+		delete(c.entries, key)
+		return ObjectInfo{}, false
+	}
+	info := entry.info
+	info.Stale = true
+	return info, true
+}
+
+type recentWriteReadKeyType struct{}
+
+// recentWriteReadKey marks contexts of reads which may be served from
+// the recent writes.
+var recentWriteReadKey recentWriteReadKeyType
+
+// withRecentWriteRead returns a context whose object info reads are
+// served from the recent writes when all replicas are down. Only HEAD
+// requests are, GET requests and the reads of radio itself need the
+// replicas anyway.
+func withRecentWriteRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, recentWriteReadKey, true)
+}
+
+func isRecentWriteRead(ctx context.Context) bool {
+	ok, _ := ctx.Value(recentWriteReadKey).(bool)
+	return ok
+}
+
+// allReplicasDown returns true if every replica in errs failed with a
+// network error, as opposed to failures of some replicas or errors
+// returned by the remotes.
+func allReplicasDown(errs []error) bool {
+	for _, err := range errs {
+		if err == nil || !xnet.IsNetworkOrHostDown(err) {
+			return false
+		}
+	}
+	return len(errs) > 0
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/minio/minio/pkg/hash"
+)
+
+// Tests that HEAD requests are served the info of objects recently
+// written, marked stale, while all replicas are down.
+func TestRecentWrites(t *testing.T) {
+	if _, err := newRecentWrites(recentWritesConfig{Enable: true, Size: -1}); err == nil {
+		t.Error("Expected a negative size rejected")
+	}
+	if c, err := newRecentWrites(recentWritesConfig{Size: 1}); c != nil || err != nil {
+		t.Errorf("Expected no cache unless enabled, got %v (%v)", c, err)
+	}
+
+	c, err := newRecentWrites(recentWritesConfig{Enable: true, Size: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, object := range []string{"a", "b", "c"} {
+		c.add(ObjectInfo{Bucket: "bucket", Name: object}, nil)
+	}
+	if _, ok := c.get("bucket", "a"); ok {
+		t.Error("Expected the oldest write evicted")
+	}
+	c.forget("bucket", "b")
+	if _, ok := c.get("bucket", "b"); ok {
+		t.Error("Expected the forgotten write dropped")
+	}
+	c.ttl = -time.Second
+	c.add(ObjectInfo{Bucket: "bucket", Name: "d"}, nil)
+	if _, ok := c.get("bucket", "d"); ok {
+		t.Error("Expected the expired write dropped")
+	}
+
+	_, clnts, shutdown := newTestRemotes(t, 2)
+	defer shutdown()
+	l := &radioObjects{
+		nsMutex:       newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+	}
+	if l.recentWrites, err = newRecentWrites(recentWritesConfig{Enable: true}); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("data")
+	reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), "", "", int64(len(data)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata := map[string]string{"content-type": "text/plain"}
+	put, err := l.PutObject(context.Background(), "bucket", "object", NewPutObjReader(reader, nil, nil),
+		ObjectOptions{UserDefined: metadata})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Replicas answer while up.
+	info, err := l.HeadObject(context.Background(), "bucket", "object", ObjectOptions{})
+	if err != nil || info.Stale {
+		t.Fatalf("Expected the info of the replicas, got %+v (%v)", info, err)
+	}
+	shutdown()
+	info, err = l.HeadObject(context.Background(), "bucket", "object", ObjectOptions{})
+	if err != nil || !info.Stale || info.ETag != put.ETag || info.Size != int64(len(data)) || info.ContentType != "text/plain" {
+		t.Errorf("Expected the stale info of the recent write, got %+v (%v)", info, err)
+	}
+	if _, err = l.GetObjectInfo(context.Background(), "bucket", "object", ObjectOptions{}); err == nil {
+		t.Error("Expected reads other than HEAD to fail while all replicas are down")
+	}
+	if _, err = l.HeadObject(context.Background(), "bucket", "other", ObjectOptions{}); err == nil {
+		t.Error("Expected objects not written recently to fail")
+	}
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/minio/pkg/policy"
+	xhttp "github.com/minio/radio/cmd/http"
+)
+
+// Reconciliation statuses of a key.
+const (
+	// Key is missing on some replicas.
+	reconcilePartial = "partial"
+	// Key is present on all replicas with different versions.
+	reconcileDivergent = "divergent"
+)
+
+// reconcileEntry reports a key whose replicas are out of sync, Present
+// lists the replicas holding the key and Tags their radio tags when
+// the replicas disagree on the checksum of the key.
+type reconcileEntry struct {
+	Key     string   `json:"key"`
+	Status  string   `json:"status"`
+	Present []int    `json:"present"`
+	Tags    []string `json:"tags,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// ReconcileReportHandler - streams the keys of a bucket prefix whose
+// replicas are out of sync as newline delimited JSON, without healing
+// them.
+func ReconcileReportHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "ReconcileReport")
+
+	bucket := r.URL.Query().Get("bucket")
+	prefix := r.URL.Query().Get("prefix")
+
+	if s3Error := checkRequestAuthType(ctx, r, policy.ListBucketAction, bucket, ""); s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+		return
+	}
+
+	l, ok := newObjectLayerFn().(*radioObjects)
+	if !ok {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+	rs3s, ok := l.mirrorClients[bucket]
+	if !ok {
+		writeErrorResponse(ctx, w, toAPIError(ctx, BucketNotFound{Bucket: bucket}), r.URL)
+		return
+	}
+
+	setCommonHeaders(w)
+	w.Header().Set(xhttp.ContentType, string(mimeNDJSON))
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	err := reconcilePrefix(ctx, rs3s, prefix, 0, func(entry reconcileEntry) error {
+		if err := enc.Encode(entry); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		// Headers are already sent, report the failure as a last entry.
+		enc.Encode(reconcileEntry{Error: err.Error()})
+	}
+}
+
+// reconcilePrefix walks the sorted listings of prefix on all replicas
+// in lockstep, calling report for each key the replicas disagree on.
+// Keys present everywhere with matching ETags are considered in sync,
+// replicas with differing ETags are compared by radio tag. At most rate
+// keys are visited per second if rate is positive.
+func reconcilePrefix(ctx context.Context, rs3s mirrorConfig, prefix string, rate int, report func(reconcileEntry) error) error {
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+
+	n := len(rs3s.clnts)
+	listings := make([]<-chan miniogo.ObjectInfo, n)
+	heads := make([]*miniogo.ObjectInfo, n)
+	next := func(index int) error {
+		oi, ok := <-listings[index]
+		if !ok {
+			heads[index] = nil
+			return nil
+		}
+		if oi.Err != nil {
+			return oi.Err
+		}
+		oi.Key = rs3s.clnts[index].listKey(oi.Key)
+		heads[index] = &oi
+		return nil
+	}
+	for index, clnt := range rs3s.clnts {
+		listings[index] = clnt.listObjects(clnt.objectKey(prefix), doneCh)
+		if err := next(index); err != nil {
+			return err
+		}
+	}
+
+	start := time.Now()
+	for visited := 0; ; visited++ {
+		if rate > 0 {
+			due := start.Add(time.Duration(float64(visited) / float64(rate) * float64(time.Second)))
+			if wait := time.Until(due); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+				}
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Smallest key not yet visited on any replica.
+		var key string
+		found := false
+		for _, head := range heads {
+			if head != nil && (!found || head.Key < key) {
+				key, found = head.Key, true
+			}
+		}
+		if !found {
+			return nil
+		}
+
+		var present []int
+		etagsMatch := true
+		for index, head := range heads {
+			if head == nil || head.Key != key {
+				continue
+			}
+			if len(present) > 0 && head.ETag != heads[present[0]].ETag {
+				etagsMatch = false
+			}
+			present = append(present, index)
+		}
+
+		entry := reconcileEntry{Key: key, Present: present}
+		switch {
+		case rs3s.singleReplica(key):
+			// Stored on the primary replica only.
+		case len(present) < n:
+			entry.Status = reconcilePartial
+		case !etagsMatch:
+			tags := make([]string, n)
+			for _, index := range present {
+				clnt := rs3s.clnts[index]
+				info, err := clnt.StatObjectWithContext(ctx, clnt.Bucket, clnt.objectKey(key), miniogo.StatObjectOptions{})
+				if err != nil {
+					return err
+				}
+				tags[index] = info.Metadata.Get(globalRadioTagKey)
+			}
+			for _, tag := range tags {
+				if tag != tags[0] {
+					entry.Status = reconcileDivergent
+					entry.Tags = tags
+					break
+				}
+			}
+		}
+		if entry.Status != "" {
+			if err := report(entry); err != nil {
+				return err
+			}
+		}
+
+		for _, index := range present {
+			if err := next(index); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+// Tests that the reconciliation report streams the keys missing on
+// some replicas or tagged differently as newline delimited JSON.
+func TestReconcileReportHandler(t *testing.T) {
+	remotes, clnts, shutdown := newTestRemotes(t, 3)
+	defer shutdown()
+	for i, remote := range remotes {
+		remote.putObject("dir/a", "a", map[string]string{"X-Amz-Meta-Radio-Tag": "a"})
+		if i != 1 {
+			remote.putObject("dir/b", "b", map[string]string{"X-Amz-Meta-Radio-Tag": "b"})
+		}
+		if i == 2 {
+			remote.putObject("dir/c", "other", map[string]string{"X-Amz-Meta-Radio-Tag": "other"})
+		} else {
+			remote.putObject("dir/c", "c", map[string]string{"X-Amz-Meta-Radio-Tag": "c"})
+		}
+		// Same tag with different ETags, as if uploaded in different
+		// parts.
+		remote.putObject("dir/d", "d"+string(rune('0'+i)), map[string]string{"X-Amz-Meta-Radio-Tag": "d"})
+		remote.putObject("other", "other", nil)
+	}
+	l := &radioObjects{mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}}}
+	defer setRadioAdminTestLayer(l)()
+
+	query := url.Values{"bucket": {"bucket"}, "prefix": {"dir/"}}
+	testRadioAdminAuth(t, http.MethodGet, radioReconcilePath, query)
+
+	rec := serveRadioAdminTestRequest(newRadioAdminTestRequest(t, http.MethodGet, radioReconcilePath,
+		url.Values{"bucket": {"missing"}}, radioAdminTestSecretKey))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for a missing bucket, got %d", http.StatusNotFound, rec.Code)
+	}
+
+	rec = serveRadioAdminTestRequest(newRadioAdminTestRequest(t, http.MethodGet, radioReconcilePath, query, radioAdminTestSecretKey))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body)
+	}
+	if contentType := rec.Header().Get("Content-Type"); contentType != string(mimeNDJSON) {
+		t.Errorf("Expected content type %s, got %s", mimeNDJSON, contentType)
+	}
+	var entries []reconcileEntry
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var entry reconcileEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("Invalid report line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	expected := []reconcileEntry{
+		{Key: "dir/b", Status: reconcilePartial, Present: []int{0, 2}},
+		{Key: "dir/c", Status: reconcileDivergent, Present: []int{0, 1, 2}, Tags: []string{"c", "c", "other"}},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("Expected report %+v, got %+v", expected, entries)
+	}
+}
@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/minio/pkg/policy"
+)
+
+// Operations the errors of remotes are counted by.
+const (
+	remoteOpGetObject = iota
+	remoteOpHeadObject
+	remoteOpPutObject
+	remoteOpCopyObject
+	remoteOpUploadPart
+	remoteOpMultipart
+	remoteOpDeleteObject
+	remoteOpDeleteObjects
+	remoteOpListObjects
+	remoteOpBucket
+	remoteOpOther
+	numRemoteOps
+)
+
+var remoteOpNames = [numRemoteOps]string{
+	remoteOpGetObject:     "GetObject",
+	remoteOpHeadObject:    "HeadObject",
+	remoteOpPutObject:     "PutObject",
+	remoteOpCopyObject:    "CopyObject",
+	remoteOpUploadPart:    "UploadPart",
+	remoteOpMultipart:     "Multipart",
+	remoteOpDeleteObject:  "DeleteObject",
+	remoteOpDeleteObjects: "DeleteObjects",
+	remoteOpListObjects:   "ListObjects",
+	remoteOpBucket:        "Bucket",
+	remoteOpOther:         "Other",
+}
+
+// remoteErrorStats counts the requests to a remote failing with network
+// or server errors by operation, since the counters were last reset.
+// Requests abandoned by radio, such as those of clients hanging up, are
+// not counted. Counters are only updated on failures.
+type remoteErrorStats struct {
+	errors [numRemoteOps]uint64
+	// Unix times in nanoseconds of the last reset and error.
+	since     int64
+	lastError int64
+}
+
+func newRemoteErrorStats() *remoteErrorStats {
+	return &remoteErrorStats{since: UTCNow().UnixNano()}
+}
+
+// count counts a failure of operation op.
+func (s *remoteErrorStats) count(op int) {
+	atomic.AddUint64(&s.errors[op], 1)
+	atomic.StoreInt64(&s.lastError, UTCNow().UnixNano())
+}
+
+// reset zeroes the counters of s, a nil remoteErrorStats is never
+// counted.
+func (s *remoteErrorStats) reset() {
+	if s == nil {
+		return
+	}
+	for op := range s.errors {
+		atomic.StoreUint64(&s.errors[op], 0)
+	}
+	atomic.StoreInt64(&s.lastError, 0)
+	atomic.StoreInt64(&s.since, UTCNow().UnixNano())
+}
+
+// remoteErrorReport reports the errors of the replica of a bucket.
+type remoteErrorReport struct {
+	Bucket   string            `json:"bucket"`
+	Replica  int               `json:"replica"`
+	Endpoint string            `json:"endpoint"`
+	Errors   map[string]uint64 `json:"errors"`
+	Total    uint64            `json:"total"`
+	// Since is when the counters were last reset, or radio started.
+	Since     time.Time  `json:"since"`
+	LastError *time.Time `json:"lastError,omitempty"`
+}
+
+// report returns the counters of s, zero for a nil remoteErrorStats.
+func (s *remoteErrorStats) report() remoteErrorReport {
+	stats := remoteErrorReport{Errors: make(map[string]uint64)}
+	if s == nil {
+		return stats
+	}
+	for op := range s.errors {
+		if n := atomic.LoadUint64(&s.errors[op]); n > 0 {
+			stats.Errors[remoteOpNames[op]] = n
+			stats.Total += n
+		}
+	}
+	stats.Since = time.Unix(0, atomic.LoadInt64(&s.since)).UTC()
+	if last := atomic.LoadInt64(&s.lastError); last != 0 {
+		t := time.Unix(0, last).UTC()
+		stats.LastError = &t
+	}
+	return stats
+}
+
+// errorStatsTransport counts the failed requests to the remote bucket
+// in stats.
+type errorStatsTransport struct {
+	http.RoundTripper
+	bucket string
+	stats  *remoteErrorStats
+}
+
+func newErrorStatsTransport(transport http.RoundTripper, bucket string, stats *remoteErrorStats) http.RoundTripper {
+	return &errorStatsTransport{RoundTripper: transport, bucket: bucket, stats: stats}
+}
+
+func (t *errorStatsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.RoundTripper.RoundTrip(req)
+	if isRemoteError(req, resp, err) {
+		t.stats.count(remoteOpOf(req, t.bucket))
+	}
+	return resp, err
+}
+
+// isRemoteError returns true for network errors and server errors other
+// than unimplemented features, unless the request was canceled.
+func isRemoteError(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode >= http.StatusInternalServerError && resp.StatusCode != http.StatusNotImplemented
+}
+
+// remoteOpOf returns the operation of req to the remote bucket, whose
+// name is either the first label of the host or the first element of
+// the path.
+func remoteOpOf(req *http.Request, bucket string) int {
+	path := strings.TrimPrefix(req.URL.Path, SlashSeparator)
+	if strings.HasPrefix(path, minioReservedBucket+SlashSeparator) {
+		// Admin requests such as those of free space monitors.
+		return remoteOpOther
+	}
+	key := path
+	if !strings.HasPrefix(req.URL.Host, bucket+".") {
+		key = strings.TrimPrefix(strings.TrimPrefix(path, bucket), SlashSeparator)
+	}
+	query := req.URL.Query()
+	switch {
+	case query["uploadId"] != nil && req.Method == http.MethodPut:
+		return remoteOpUploadPart
+	case query["uploadId"] != nil || query["uploads"] != nil:
+		return remoteOpMultipart
+	case key == "" && req.Method == http.MethodGet && isListQuery(query):
+		return remoteOpListObjects
+	case key == "" && req.Method == http.MethodPost && query["delete"] != nil:
+		return remoteOpDeleteObjects
+	case key == "":
+		return remoteOpBucket
+	}
+	switch req.Method {
+	case http.MethodGet:
+		return remoteOpGetObject
+	case http.MethodHead:
+		return remoteOpHeadObject
+	case http.MethodPut:
+		if req.Header.Get("X-Amz-Copy-Source") != "" {
+			return remoteOpCopyObject
+		}
+		return remoteOpPutObject
+	case http.MethodDelete:
+		return remoteOpDeleteObject
+	}
+	return remoteOpOther
+}
+
+// isListQuery returns true for the query of bucket listings, as opposed
+// to the queries of bucket subresources such as ?location.
+func isListQuery(query url.Values) bool {
+	if len(query) == 0 {
+		return true
+	}
+	for _, param := range []string{"list-type", "versions", "prefix", "delimiter", "marker", "max-keys"} {
+		if query[param] != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteErrors returns the error reports of the replicas of bucket, of
+// all buckets if empty, sorted by bucket and replica.
+func (l *radioObjects) remoteErrors(bucket string) []remoteErrorReport {
+	var buckets []string
+	for b := range l.mirrorClients {
+		if bucket == "" || b == bucket {
+			buckets = append(buckets, b)
+		}
+	}
+	sort.Strings(buckets)
+	reports := []remoteErrorReport{}
+	for _, b := range buckets {
+		for index, clnt := range l.mirrorClients[b].clnts {
+			r := clnt.errStats.report()
+			r.Bucket, r.Replica = b, index
+			r.Endpoint = clnt.EndpointURL().Host + SlashSeparator + clnt.Bucket
+			reports = append(reports, r)
+		}
+	}
+	return reports
+}
+
+// RemoteStatsHandler reports the errors of the replicas of the bucket
+// query parameter, of all buckets if missing.
+func RemoteStatsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "RemoteStats")
+
+	if s3Error := checkRequestAuthType(ctx, r, policy.ListAllMyBucketsAction, "", ""); s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+		return
+	}
+
+	l, ok := newObjectLayerFn().(*radioObjects)
+	if !ok {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+	bucket := r.URL.Query().Get("bucket")
+	if _, ok = l.mirrorClients[bucket]; bucket != "" && !ok {
+		writeErrorResponse(ctx, w, toAPIError(ctx, BucketNotFound{Bucket: bucket}), r.URL)
+		return
+	}
+
+	data, err := json.Marshal(l.remoteErrors(bucket))
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	writeResponse(w, http.StatusOK, data, mimeJSON)
+}
+
+// RemoteStatsResetHandler resets the error counters of the replicas of
+// the bucket query parameter, of all buckets if missing.
+func RemoteStatsResetHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "RemoteStatsReset")
+
+	if s3Error := checkRequestAuthType(ctx, r, policy.PutObjectAction, "", ""); s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+		return
+	}
+
+	l, ok := newObjectLayerFn().(*radioObjects)
+	if !ok {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+	bucket := r.URL.Query().Get("bucket")
+	if _, ok = l.mirrorClients[bucket]; bucket != "" && !ok {
+		writeErrorResponse(ctx, w, toAPIError(ctx, BucketNotFound{Bucket: bucket}), r.URL)
+		return
+	}
+
+	for b, rs3s := range l.mirrorClients {
+		if bucket != "" && b != bucket {
+			continue
+		}
+		for _, clnt := range rs3s.clnts {
+			clnt.errStats.reset()
+		}
+	}
+	writeSuccessNoContent(w)
+}
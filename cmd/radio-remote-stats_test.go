@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Tests that the network and server errors of the requests to a remote
+// are counted by operation until reset.
+func TestRemoteErrorStats(t *testing.T) {
+	testCases := []struct {
+		method, url string
+		copySource  string
+		op          int
+	}{
+		{http.MethodGet, "http://host/remote/object", "", remoteOpGetObject},
+		{http.MethodGet, "http://remote.host/object", "", remoteOpGetObject},
+		{http.MethodHead, "http://host/remote/dir/object", "", remoteOpHeadObject},
+		{http.MethodPut, "http://host/remote/object", "", remoteOpPutObject},
+		{http.MethodPut, "http://host/remote/object", "remote/other", remoteOpCopyObject},
+		{http.MethodPut, "http://host/remote/object?partNumber=1&uploadId=u", "", remoteOpUploadPart},
+		{http.MethodPost, "http://host/remote/object?uploads", "", remoteOpMultipart},
+		{http.MethodDelete, "http://host/remote/object?uploadId=u", "", remoteOpMultipart},
+		{http.MethodDelete, "http://host/remote/object", "", remoteOpDeleteObject},
+		{http.MethodPost, "http://host/remote/?delete", "", remoteOpDeleteObjects},
+		{http.MethodGet, "http://host/remote/?list-type=2&prefix=a", "", remoteOpListObjects},
+		{http.MethodGet, "http://remote.host/", "", remoteOpListObjects},
+		{http.MethodGet, "http://host/remote/?location", "", remoteOpBucket},
+		{http.MethodHead, "http://host/remote", "", remoteOpBucket},
+		{http.MethodGet, "http://host/minio/admin/v2/info", "", remoteOpOther},
+	}
+	for i, testCase := range testCases {
+		req, err := http.NewRequest(testCase.method, testCase.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if testCase.copySource != "" {
+			req.Header.Set("X-Amz-Copy-Source", testCase.copySource)
+		}
+		if op := remoteOpOf(req, "remote"); op != testCase.op {
+			t.Errorf("Test %d: expected %s, got %s", i+1, remoteOpNames[testCase.op], remoteOpNames[op])
+		}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Query()["acl"] != nil:
+			w.WriteHeader(http.StatusNotImplemented)
+		case r.Method == http.MethodPut:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	offline := httptest.NewServer(http.NotFoundHandler())
+	offline.Close()
+
+	stats := newRemoteErrorStats()
+	clnt := &http.Client{Transport: newErrorStatsTransport(http.DefaultTransport, "remote", stats)}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	requests := []struct {
+		method, url string
+		ctx         context.Context
+	}{
+		{http.MethodPut, ts.URL + "/remote/object", context.Background()},
+		{http.MethodPut, ts.URL + "/remote/object", context.Background()},
+		{http.MethodGet, ts.URL + "/remote/object", context.Background()},
+		{http.MethodGet, ts.URL + "/remote/object?acl", context.Background()},
+		{http.MethodGet, offline.URL + "/remote/object", context.Background()},
+		{http.MethodGet, offline.URL + "/remote/object", canceled},
+	}
+	for _, r := range requests {
+		req, err := http.NewRequest(r.method, r.url, strings.NewReader(""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp, err := clnt.Do(req.WithContext(r.ctx)); err == nil {
+			resp.Body.Close()
+		}
+	}
+
+	report := stats.report()
+	if report.Total != 3 || report.Errors["PutObject"] != 2 || report.Errors["GetObject"] != 1 || report.LastError == nil {
+		t.Errorf("Expected 2 PutObject and 1 GetObject errors, got %+v", report)
+	}
+	stats.reset()
+	if report = stats.report(); report.Total != 0 || len(report.Errors) != 0 || report.LastError != nil {
+		t.Errorf("Expected the counters reset, got %+v", report)
+	}
+	if report := (*remoteErrorStats)(nil).report(); report.Total != 0 {
+		t.Errorf("Expected no errors counted without stats, got %+v", report)
+	}
+
+	l := &radioObjects{mirrorClients: map[string]mirrorConfig{
+		"b": {clnts: []bucketClient{{Core: newTestCore(t, ts.URL), Bucket: "remote", errStats: stats}}},
+		"a": {clnts: []bucketClient{{Core: newTestCore(t, ts.URL), Bucket: "remote"}}},
+	}}
+	reports := l.remoteErrors("")
+	if len(reports) != 2 || reports[0].Bucket != "a" || reports[1].Bucket != "b" ||
+		reports[1].Endpoint != strings.TrimPrefix(ts.URL, "http://")+"/remote" {
+		t.Errorf("Unexpected reports %+v", reports)
+	}
+	if reports = l.remoteErrors("b"); len(reports) != 1 || reports[0].Bucket != "b" {
+		t.Errorf("Unexpected reports of bucket b %+v", reports)
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	xhttp "github.com/minio/radio/cmd/http"
+)
+
+// operationReplicaRead names reads forced to a replica when they are
+// not enabled.
+const operationReplicaRead = "ReplicaRead"
+
+// replicaReadOptions sets the replica a GET or HEAD request is forced
+// to read from with the x-radio-replica header in opts.
+//
+// Forced reads are meant for diagnosing diverging replicas only: they
+// bypass the read quorum, the version selection and the cache, and
+// serve whatever the replica holds, which may be a stale version, a
+// version being healed or an object deleted from the other replicas.
+// They are rejected unless debug.replica_reads is enabled.
+func replicaReadOptions(h http.Header, bucket, object string, opts ObjectOptions) (ObjectOptions, error) {
+	value, ok := h[http.CanonicalHeaderKey(xhttp.RadioReplica)]
+	if !ok {
+		return opts, nil
+	}
+	index, err := strconv.Atoi(value[0])
+	if err != nil || index < 0 {
+		return opts, InvalidReplica{Bucket: bucket, Object: object, Replica: value[0]}
+	}
+	opts.Replica = &index
+	return opts, nil
+}
+
+// replicaObjectInfo stats object on the replica of rs3s forced by opts,
+// reporting it as the only replica holding the object.
+func (l *radioObjects) replicaObjectInfo(ctx context.Context, bucket, object string, rs3s mirrorConfig, opts ObjectOptions) (ObjectInfo, error) {
+	if !l.replicaReads {
+		return ObjectInfo{}, OperationNotAllowed{Operation: operationReplicaRead}
+	}
+	index := *opts.Replica
+	if index >= len(rs3s.clnts) {
+		return ObjectInfo{}, InvalidReplica{Bucket: bucket, Object: object, Replica: strconv.Itoa(index)}
+	}
+	info, err := statReplica(ctx, rs3s.clnts[index].reader(), object, opts)
+	if err != nil {
+		return ObjectInfo{}, ErrorRespToObjectError(err, bucket, object)
+	}
+	objInfo := FromMinioClientObjectInfo(bucket, info, index)
+	objInfo.Replicas = []int{index}
+	return objInfo, nil
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	xhttp "github.com/minio/radio/cmd/http"
+)
+
+// Tests that reads forced to a replica are served from that replica
+// alone, and only when enabled.
+func TestReplicaReads(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	_, clnts, shutdown := newTestReplicas(t, testReplica{"v2", now}, testReplica{"v1", now.Add(-time.Hour)})
+	defer shutdown()
+	l := &radioObjects{
+		nsMutex:       newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+	}
+
+	h := http.Header{}
+	h.Set(xhttp.RadioReplica, "one")
+	if _, err := replicaReadOptions(h, "bucket", "object", ObjectOptions{}); err == nil {
+		t.Fatal("expected an error for an invalid replica")
+	}
+	h.Set(xhttp.RadioReplica, "1")
+	opts, err := replicaReadOptions(h, "bucket", "object", ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = l.GetObjectInfo(context.Background(), "bucket", "object", opts); err != (OperationNotAllowed{operationReplicaRead}) {
+		t.Errorf("expected replica reads to be disabled, got %v", err)
+	}
+
+	l.replicaReads = true
+	info, err := l.GetObjectInfo(context.Background(), "bucket", "object", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ReplicaIndex != 1 || !info.ModTime.Equal(now.Add(-time.Hour)) {
+		t.Errorf("expected the stale replica read, got replica %d modified %v", info.ReplicaIndex, info.ModTime)
+	}
+	gr, err := l.GetObjectNInfo(context.Background(), "bucket", "object", nil, nil, ReadLock, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(gr)
+	gr.Close()
+	if err != nil || string(data) != "data" {
+		t.Errorf("expected the replica data, got %q (%v)", data, err)
+	}
+
+	two := 2
+	opts.Replica = &two
+	if _, err = l.GetObjectInfo(context.Background(), "bucket", "object", opts); err == nil {
+		t.Error("expected an error for a missing replica")
+	}
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/minio/pkg/sync/errgroup"
+	xhttp "github.com/minio/radio/cmd/http"
+)
+
+// isArchived returns true for objects of the given storage class and
+// x-amz-restore status which are archived without a restored copy, and
+// cannot be read until restored.
+func isArchived(storageClass, restore string) bool {
+	switch storageClass {
+	case "GLACIER", "DEEP_ARCHIVE":
+		return !strings.Contains(restore, `ongoing-request="false"`)
+	}
+	return false
+}
+
+// isArchivedReplica returns true if the replica reporting info holds an
+// archived copy without a restored copy.
+func isArchivedReplica(info miniogo.ObjectInfo) bool {
+	return isArchived(info.Metadata.Get(xhttp.AmzStorageClass), info.Metadata.Get(xhttp.AmzRestore))
+}
+
+// isArchivedObject returns true if objInfo reports an archived object
+// without a restored copy on any replica.
+func isArchivedObject(objInfo ObjectInfo) bool {
+	return isArchived(objInfo.UserDefined[http.CanonicalHeaderKey(xhttp.AmzStorageClass)],
+		objInfo.UserDefined[http.CanonicalHeaderKey(xhttp.AmzRestore)])
+}
+
+// RestoreObject forwards restoreRequest to all readable replicas, for
+// the replicas holding an archived copy of the object to retrieve it.
+// Replicas holding a copy which is not archived, or already restored,
+// serve reads meanwhile, so the object is reported restored as soon as
+// one replica can be read. Replicas restoring the object report their
+// progress in the x-amz-restore header of HEAD requests.
+func (l *radioObjects) RestoreObject(ctx context.Context, bucket, object string, restoreRequest []byte) (restored bool, err error) {
+	if err := l.operationAllowed(operationRestoreObject); err != nil {
+		return false, err
+	}
+	object = l.objectName(bucket, object)
+
+	rs3s, ok := l.mirrorClients[bucket]
+	if !ok {
+		return false, BucketNotFound{Bucket: bucket}
+	}
+	rs3s = rs3s.forObject(object)
+
+	ctx, cancel := withDeadline(ctx, l.timeouts.Write)
+	defer cancel()
+
+	readable := rs3s.readReplicas()
+	statuses := make([]int, len(readable))
+	g := errgroup.WithNErrs(len(readable))
+	for i, index := range readable {
+		i, clnt := i, rs3s.clnts[index]
+		g.Go(func() error {
+			var rerr error
+			statuses[i], rerr = clnt.versions.restoreObject(ctx, clnt.Bucket, clnt.objectKey(object), restoreRequest)
+			return rerr
+		}, i)
+	}
+
+	errs := g.Wait()
+	var accepted, readableCopy bool
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			accepted = true
+			readableCopy = readableCopy || statuses[i] == http.StatusOK
+		case miniogo.ToErrorResponse(err).Code == "RestoreAlreadyInProgress":
+			accepted = true
+		case miniogo.ToErrorResponse(err).Code == "InvalidObjectState":
+			// The copy of this replica is not archived.
+			readableCopy = true
+		}
+	}
+	if !accepted {
+		// No replica holds an archived copy of the object.
+		_, maxErr := reduceErrs(errs, nil)
+		return false, rs3s.toObjectError(ctx, "RestoreObject", readable, errs, maxErr, bucket, object)
+	}
+	return readableCopy, nil
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+// Tests that restores are forwarded to the replicas holding archived
+// copies, and that reads are served by the replicas which can serve
+// them.
+func TestRestoreObject(t *testing.T) {
+	const restoreRequest = `<RestoreRequest><Days>1</Days></RestoreRequest>`
+	var shutdowns []func()
+	defer func() {
+		for _, shutdown := range shutdowns {
+			shutdown()
+		}
+	}()
+	// newObjects returns replicas of the object stored in the given
+	// storage classes.
+	newObjects := func(storageClasses ...string) ([]*testRemote, *radioObjects) {
+		remotes, clnts, shutdown := newTestRemotes(t, len(storageClasses))
+		shutdowns = append(shutdowns, shutdown)
+		for i, storageClass := range storageClasses {
+			metadata := map[string]string{globalRadioTagKey: "v1"}
+			if storageClass != "" {
+				metadata["X-Amz-Storage-Class"] = storageClass
+			}
+			remotes[i].putObject("object", "data", metadata)
+			versions, err := newVersionsClient(clnts[i].EndpointURL().String(), "accesskey", "secretkey", "", http.DefaultTransport)
+			if err != nil {
+				t.Fatal(err)
+			}
+			clnts[i].versions = versions
+		}
+		return remotes, &radioObjects{
+			nsMutex:       newNSLock(false),
+			mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+		}
+	}
+	read := func(l *radioObjects) (string, error) {
+		gr, err := l.GetObjectNInfo(context.Background(), "bucket", "object", nil, http.Header{}, NoLock, ObjectOptions{})
+		if err != nil {
+			return "", err
+		}
+		defer gr.Close()
+		data, err := ioutil.ReadAll(gr)
+		return string(data), err
+	}
+
+	// All replicas archived.
+	remotes, l := newObjects("GLACIER", "GLACIER")
+	if _, err := read(l); toAPIError(context.Background(), err).Code != "InvalidObjectState" {
+		t.Errorf("Expected InvalidObjectState reading an archived object, got %v", err)
+	}
+	for i, expected := range []bool{false, false} {
+		restored, err := l.RestoreObject(context.Background(), "bucket", "object", []byte(restoreRequest))
+		if err != nil || restored != expected {
+			t.Errorf("Restore %d: expected restored %v, got %v (%v)", i+1, expected, restored, err)
+		}
+	}
+	info, err := l.GetObjectInfo(context.Background(), "bucket", "object", ObjectOptions{})
+	if err != nil || info.UserDefined["X-Amz-Restore"] != `ongoing-request="true"` {
+		t.Errorf("Expected the restore reported in progress, got %v (%v)", info.UserDefined, err)
+	}
+	remotes[1].putObject("object", "data", map[string]string{
+		globalRadioTagKey:     "v1",
+		"X-Amz-Storage-Class": "GLACIER",
+		"X-Amz-Restore":       `ongoing-request="false", expiry-date="Fri, 23 Dec 2022 00:00:00 GMT"`,
+	})
+	if data, err := read(l); err != nil || data != "data" {
+		t.Errorf("Expected the restored replica read, got %q (%v)", data, err)
+	}
+	if restored, err := l.RestoreObject(context.Background(), "bucket", "object", []byte(restoreRequest)); err != nil || !restored {
+		t.Errorf("Expected the object restored, got %v (%v)", restored, err)
+	}
+
+	// Only one replica archived.
+	_, l = newObjects("DEEP_ARCHIVE", "")
+	info, err = l.GetObjectInfo(context.Background(), "bucket", "object", ObjectOptions{})
+	if err != nil || info.ReplicaIndex != 1 || fmt.Sprint(info.Replicas) != "[1]" {
+		t.Errorf("Expected the object reported by replica 1 only, got %d %v (%v)", info.ReplicaIndex, info.Replicas, err)
+	}
+	if data, err := read(l); err != nil || data != "data" {
+		t.Errorf("Expected the unarchived replica read, got %q (%v)", data, err)
+	}
+	if restored, err := l.RestoreObject(context.Background(), "bucket", "object", []byte(restoreRequest)); err != nil || !restored {
+		t.Errorf("Expected the object readable while restored, got %v (%v)", restored, err)
+	}
+
+	// No replica archived.
+	_, l = newObjects("", "STANDARD_IA")
+	_, err = l.RestoreObject(context.Background(), "bucket", "object", []byte(restoreRequest))
+	if apiErr := toAPIError(context.Background(), err); apiErr.Code != "InvalidObjectState" {
+		t.Errorf("Expected InvalidObjectState restoring an object not archived, got %v", err)
+	}
+}
@@ -0,0 +1,296 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/minio/pkg/policy"
+	xhttp "github.com/minio/radio/cmd/http"
+)
+
+// Actions taken by a resync on a key of the target replica.
+const (
+	// Key was missing or differed on the target and was copied.
+	resyncCopy = "copy"
+	// Key was missing on the source and was deleted from the target.
+	resyncDelete = "delete"
+)
+
+// resyncEntry reports the progress of a resync, one entry is sent for
+// every key copied to or deleted from the target replica, or failing
+// to be, followed by a final entry with Done set summarizing the run.
+type resyncEntry struct {
+	Key     string `json:"key,omitempty"`
+	Action  string `json:"action,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Done    bool   `json:"done,omitempty"`
+	Scanned int    `json:"scanned"`
+	Copied  int    `json:"copied"`
+	Deleted int    `json:"deleted"`
+	Failed  int    `json:"failed"`
+}
+
+// ResyncHandler - rebuilds the target replica of a bucket prefix from
+// the source replica, copying the objects missing or differing on the
+// target and deleting the objects missing on the source, streaming the
+// progress as newline delimited JSON. Unlike heals, which only repair
+// journaled writes, a resync brings a replica back in sync after it
+// missed more than the journal retained. The source replica defaults
+// to the heal primary when configured, the first replica otherwise.
+// The optional rate parameter limits the number of objects copied or
+// deleted per second.
+func ResyncHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "Resync")
+
+	query := r.URL.Query()
+	bucket := query.Get("bucket")
+	prefix := query.Get("prefix")
+
+	if s3Error := checkRequestAuthType(ctx, r, policy.PutObjectAction, bucket, ""); s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+		return
+	}
+	if s3Error := checkRequestAuthType(ctx, r, policy.DeleteObjectAction, bucket, ""); s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+		return
+	}
+
+	l, ok := newObjectLayerFn().(*radioObjects)
+	if !ok {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+	rs3s, ok := l.mirrorClients[bucket]
+	if !ok {
+		writeErrorResponse(ctx, w, toAPIError(ctx, BucketNotFound{Bucket: bucket}), r.URL)
+		return
+	}
+
+	source := 0
+	if l.healSys != nil && l.healSys.policy == healPolicyPrimary {
+		source = l.healSys.primary
+	}
+	var err error
+	if v := query.Get("source"); v != "" {
+		if source, err = strconv.Atoi(v); err != nil {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidQueryParams), r.URL)
+			return
+		}
+	}
+	target, err := strconv.Atoi(query.Get("target"))
+	if err != nil {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidQueryParams), r.URL)
+		return
+	}
+	n := len(rs3s.clnts)
+	if source < 0 || source >= n || target < 0 || target >= n || source == target ||
+		rs3s.clnts[source].Shadow || rs3s.clnts[target].ReadOnly {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidQueryParams), r.URL)
+		return
+	}
+	var rate int
+	if v := query.Get("rate"); v != "" {
+		if rate, err = strconv.Atoi(v); err != nil || rate < 0 {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidQueryParams), r.URL)
+			return
+		}
+	}
+
+	setCommonHeaders(w)
+	w.Header().Set(xhttp.ContentType, string(mimeNDJSON))
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	send := func(entry resyncEntry) error {
+		if err := enc.Encode(entry); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	}
+	summary, err := l.resyncPrefix(ctx, bucket, prefix, rs3s, source, target, rate, send)
+	summary.Done = true
+	if err != nil {
+		// Headers are already sent, report the failure in the summary.
+		summary.Error = err.Error()
+	}
+	send(summary)
+}
+
+// resyncPrefix walks the sorted listings of prefix on the source and
+// target replicas in lockstep, copying to the target the keys it misses
+// or holds another version of and deleting from it the keys missing on
+// the source, at most rate objects per second when rate is positive.
+// Keys stored on the primary replica only are left alone. Failures to
+// resync a key are reported and do not stop the resync.
+func (l *radioObjects) resyncPrefix(ctx context.Context, bucket, prefix string, rs3s mirrorConfig, source, target int,
+	rate int, report func(resyncEntry) error) (summary resyncEntry, err error) {
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+
+	src, dst := rs3s.clnts[source], rs3s.clnts[target]
+	srcList := src.listObjects(src.objectKey(prefix), doneCh)
+	dstList := dst.listObjects(dst.objectKey(prefix), doneCh)
+	next := func(clnt bucketClient, listing <-chan miniogo.ObjectInfo) (*miniogo.ObjectInfo, error) {
+		oi, ok := <-listing
+		if !ok {
+			return nil, nil
+		}
+		if oi.Err != nil {
+			return nil, oi.Err
+		}
+		oi.Key = clnt.listKey(oi.Key)
+		return &oi, nil
+	}
+	srcHead, err := next(src, srcList)
+	if err != nil {
+		return summary, err
+	}
+	dstHead, err := next(dst, dstList)
+	if err != nil {
+		return summary, err
+	}
+
+	var ticker *time.Ticker
+	if rate > 0 {
+		ticker = time.NewTicker(time.Second / time.Duration(rate))
+		defer ticker.Stop()
+	}
+
+	for srcHead != nil || dstHead != nil {
+		if err = ctx.Err(); err != nil {
+			return summary, err
+		}
+		summary.Scanned++
+
+		var key, action string
+		switch {
+		case dstHead == nil || (srcHead != nil && srcHead.Key < dstHead.Key):
+			key, action = srcHead.Key, resyncCopy
+			if srcHead, err = next(src, srcList); err != nil {
+				return summary, err
+			}
+		case srcHead == nil || dstHead.Key < srcHead.Key:
+			key, action = dstHead.Key, resyncDelete
+			if dstHead, err = next(dst, dstList); err != nil {
+				return summary, err
+			}
+		default:
+			key = srcHead.Key
+			if srcHead.ETag != dstHead.ETag {
+				// Multipart and encrypted objects may have differing
+				// ETags, the radio tags are compared when copying.
+				action = resyncCopy
+			}
+			if srcHead, err = next(src, srcList); err != nil {
+				return summary, err
+			}
+			if dstHead, err = next(dst, dstList); err != nil {
+				return summary, err
+			}
+		}
+		if action == "" || rs3s.singleReplica(key) {
+			continue
+		}
+
+		if ticker != nil {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return summary, ctx.Err()
+			}
+		}
+
+		entry := resyncEntry{Key: key, Action: action}
+		var changed bool
+		if action == resyncCopy {
+			changed, err = l.resyncObject(ctx, bucket, key, src, dst)
+		} else {
+			changed, err = l.resyncDelete(ctx, bucket, key, src, dst)
+		}
+		switch {
+		case err != nil:
+			summary.Failed++
+			entry.Error = err.Error()
+		case !changed:
+			continue
+		case action == resyncCopy:
+			summary.Copied++
+		default:
+			summary.Deleted++
+		}
+		entry.Scanned, entry.Copied, entry.Deleted, entry.Failed =
+			summary.Scanned, summary.Copied, summary.Deleted, summary.Failed
+		if err = report(entry); err != nil {
+			return summary, err
+		}
+	}
+	return summary, nil
+}
+
+// resyncObject copies object from src to dst under the object lock,
+// unless dst holds the same version of it, returning true if it was
+// copied.
+func (l *radioObjects) resyncObject(ctx context.Context, bucket, object string, src, dst bucketClient) (bool, error) {
+	objectLock := l.NewNSLock(ctx, bucket, object)
+	if err := objectLock.GetLock(globalObjectTimeout); err != nil {
+		return false, err
+	}
+	defer objectLock.Unlock()
+
+	srcInfo, err := src.StatObjectWithContext(ctx, src.Bucket, src.objectKey(object), miniogo.StatObjectOptions{})
+	if err != nil {
+		if miniogo.ToErrorResponse(err).Code == "NoSuchKey" {
+			// Object was removed from the source since it was listed,
+			// the delete is journaled for the target.
+			return false, nil
+		}
+		return false, err
+	}
+	dstInfo, err := dst.StatObjectWithContext(ctx, dst.Bucket, dst.objectKey(object), miniogo.StatObjectOptions{})
+	switch {
+	case err == nil:
+		radioTag := srcInfo.Metadata.Get(globalRadioTagKey)
+		if radioTag != "" && radioTag == dstInfo.Metadata.Get(globalRadioTagKey) {
+			return false, nil
+		}
+	case miniogo.ToErrorResponse(err).Code != "NoSuchKey":
+		return false, err
+	}
+	if err = healObjectCopy(ctx, src, dst, object, nil); err != nil {
+		if miniogo.ToErrorResponse(err).Code == "NoSuchKey" {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// resyncDelete removes object from dst under the object lock, unless it
+// was written to src in the meantime, returning true if it was removed.
+func (l *radioObjects) resyncDelete(ctx context.Context, bucket, object string, src, dst bucketClient) (bool, error) {
+	objectLock := l.NewNSLock(ctx, bucket, object)
+	if err := objectLock.GetLock(globalObjectTimeout); err != nil {
+		return false, err
+	}
+	defer objectLock.Unlock()
+
+	_, err := src.StatObjectWithContext(ctx, src.Bucket, src.objectKey(object), miniogo.StatObjectOptions{})
+	if err == nil {
+		return false, nil
+	}
+	if miniogo.ToErrorResponse(err).Code != "NoSuchKey" {
+		return false, err
+	}
+	if err = dst.RemoveObject(dst.Bucket, dst.objectKey(object)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// Tests that a resync copies the objects missing or differing on the
+// target and deletes the objects missing on the source.
+func TestResyncPrefix(t *testing.T) {
+	remotes, clnts, shutdown := newTestRemotes(t, 2)
+	defer shutdown()
+	src, dst := remotes[0], remotes[1]
+	for key, object := range map[string][2]string{"a": {"a", "v1"}, "b": {"b", "v1"}, "c": {"new", "v2"}, "s/x": {"x", "v1"}} {
+		src.putTaggedObject(key, object[0], object[1], time.Now())
+	}
+	for key, object := range map[string][2]string{"b": {"b", "v1"}, "c": {"old", "v1"}, "d": {"d", "v1"}, "s/y": {"y", "v1"}} {
+		dst.putTaggedObject(key, object[0], object[1], time.Now())
+	}
+	rs3s := mirrorConfig{clnts: clnts, singleReplicaPrefixes: []string{"s/"}}
+	l := &radioObjects{
+		nsMutex:       newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{"bucket": rs3s},
+	}
+
+	var reported []string
+	summary, err := l.resyncPrefix(context.Background(), "bucket", "", rs3s, 0, 1, 0, func(entry resyncEntry) error {
+		if entry.Error != "" {
+			t.Errorf("%s: unexpected error %s", entry.Key, entry.Error)
+		}
+		reported = append(reported, entry.Action+" "+entry.Key)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(reported) != "[copy a copy c delete d]" {
+		t.Errorf("Expected a and c copied and d deleted, got %v", reported)
+	}
+	if summary.Scanned != 6 || summary.Copied != 2 || summary.Deleted != 1 || summary.Failed != 0 {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+
+	for _, key := range dst.keys() {
+		data, header, _ := dst.object(key)
+		if wantData, want, ok := src.object(key); ok && (data != wantData || header.Get(globalRadioTagKey) != want.Get(globalRadioTagKey)) {
+			t.Errorf("%s: expected %q tagged %s, got %q tagged %s", key, wantData, want.Get(globalRadioTagKey),
+				data, header.Get(globalRadioTagKey))
+		}
+	}
+	if keys := dst.keys(); fmt.Sprint(keys) != "[a b c s/y]" {
+		t.Errorf("Expected the target to hold a, b, c and s/y, got %v", keys)
+	}
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// defaultRetryBudget is the retries per second of a remote when retries
+// are enabled without a budget.
+const defaultRetryBudget = 10
+
+// retryBackoffUnit is the delay before the first retry of a request,
+// doubled for each further retry.
+const retryBackoffUnit = 50 * time.Millisecond
+
+// retryConfig bounds the retries of failed requests to a remote.
+type retryConfig struct {
+	// Max retries of a single request, zero disables retries.
+	Max int `yaml:"max"`
+	// Budget is the retries per second shared by all requests to the
+	// remote, once spent requests fail right away and are left to
+	// heal rather than adding load to an unhealthy remote.
+	Budget float64 `yaml:"budget"`
+	// Burst of retries allowed at once, defaults to the budget.
+	Burst int `yaml:"burst"`
+}
+
+func (c retryConfig) validate() error {
+	if c.Max < 0 || c.Budget < 0 || c.Burst < 0 {
+		return fmt.Errorf("retries must not be negative")
+	}
+	return nil
+}
+
+// retryBudget is a token bucket of the retries allowed to a remote.
+type retryBudget struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newRetryBudget(rate float64, burst int) *retryBudget {
+	if rate <= 0 {
+		rate = defaultRetryBudget
+	}
+	if burst <= 0 {
+		burst = int(rate)
+		if burst < 1 {
+			burst = 1
+		}
+	}
+	b := &retryBudget{rate: rate, burst: float64(burst), now: time.Now}
+	b.tokens, b.last = b.burst, b.now()
+	return b
+}
+
+// take spends a retry, returning false if the budget is exhausted.
+func (b *retryBudget) take() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// newRetryTransport returns transport retrying failed requests as
+// allowed by cfg, transport itself if retries are disabled.
+func newRetryTransport(transport http.RoundTripper, cfg retryConfig) http.RoundTripper {
+	if cfg.Max <= 0 {
+		return transport
+	}
+	return &retryTransport{
+		RoundTripper: transport,
+		max:          cfg.Max,
+		budget:       newRetryBudget(cfg.Budget, cfg.Burst),
+	}
+}
+
+// retryTransport retries requests to a remote failing with network or
+// server errors. Requests whose body cannot be replayed are sent once.
+type retryTransport struct {
+	http.RoundTripper
+	max    int
+	budget *retryBudget
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.RoundTripper.RoundTrip(req)
+	for attempt := 0; attempt < t.max && isRetryableResponse(resp, err); attempt++ {
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			break
+		}
+		if req.Context().Err() != nil {
+			break
+		}
+		if !t.budget.take() {
+			remoteRetryBudgetExhausted.WithLabelValues(req.URL.Host).Inc()
+			break
+		}
+
+		timer := time.NewTimer(retryBackoffUnit << uint(attempt))
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return resp, err
+		}
+
+		r := req
+		if req.GetBody != nil {
+			body, berr := req.GetBody()
+			if berr != nil {
+				break
+			}
+			r = req.Clone(req.Context())
+			r.Body = body
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		remoteRetries.WithLabelValues(req.URL.Host).Inc()
+		resp, err = t.RoundTripper.RoundTrip(r)
+	}
+	return resp, err
+}
+
+// isRetryableResponse returns true for network errors and server errors
+// which may succeed when retried.
+func isRetryableResponse(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
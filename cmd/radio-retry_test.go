@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// Tests that failed requests are retried within the retry budget.
+func TestRetryTransport(t *testing.T) {
+	testCases := []struct {
+		cfg              retryConfig
+		failures         int
+		body             func() io.Reader
+		expectedStatus   int
+		expectedAttempts int
+	}{
+		// Retried until it succeeds.
+		{retryConfig{Max: 2}, 1, nil, http.StatusOK, 2},
+		// Retried at most max times.
+		{retryConfig{Max: 2}, 5, nil, http.StatusServiceUnavailable, 3},
+		// Fails right away once the budget is spent.
+		{retryConfig{Max: 2, Budget: 0.001, Burst: 1}, 5, nil, http.StatusServiceUnavailable, 2},
+		// Retries disabled.
+		{retryConfig{}, 1, nil, http.StatusServiceUnavailable, 1},
+		// Replayable bodies are sent again.
+		{retryConfig{Max: 2}, 1, func() io.Reader { return bytes.NewReader([]byte("data")) }, http.StatusOK, 2},
+		// Streamed bodies are sent once.
+		{retryConfig{Max: 2}, 1, func() io.Reader { return io.MultiReader(bytes.NewReader([]byte("data"))) },
+			http.StatusServiceUnavailable, 1},
+	}
+
+	for i, testCase := range testCases {
+		remotes, clnts, shutdown := newTestRemotes(t, 1)
+		remote := remotes[0]
+		remote.putObject("object", "stale", nil)
+		var bodies []string
+		remote.setHook(func(w http.ResponseWriter, r *http.Request) bool {
+			body, _ := ioutil.ReadAll(r.Body)
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+			bodies = append(bodies, string(body))
+			if len(bodies) <= testCase.failures {
+				writeTestRemoteError(w, r, http.StatusServiceUnavailable, "SlowDown")
+				return true
+			}
+			return false
+		})
+		clnt := &http.Client{Transport: newRetryTransport(http.DefaultTransport, testCase.cfg)}
+
+		method, body := http.MethodGet, io.Reader(nil)
+		if testCase.body != nil {
+			method, body = http.MethodPut, testCase.body()
+		}
+		req, err := http.NewRequest(method, clnts[0].EndpointURL().String()+"/"+testRemoteBucket+"/object", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := clnt.Do(req)
+		if err != nil {
+			t.Errorf("Test %d: Unexpected error %v", i+1, err)
+			shutdown()
+			continue
+		}
+		resp.Body.Close()
+		shutdown()
+
+		if resp.StatusCode != testCase.expectedStatus {
+			t.Errorf("Test %d: Expected status %d, got %d", i+1, testCase.expectedStatus, resp.StatusCode)
+		}
+		if len(bodies) != testCase.expectedAttempts {
+			t.Errorf("Test %d: Expected %d attempts, got %d", i+1, testCase.expectedAttempts, len(bodies))
+		}
+		for _, b := range bodies {
+			if testCase.body != nil && b != "data" {
+				t.Errorf("Test %d: Expected body %q, got %q", i+1, "data", b)
+			}
+		}
+		if data, _, _ := remote.object("object"); testCase.body != nil && resp.StatusCode == http.StatusOK && data != "data" {
+			t.Errorf("Test %d: Expected %q stored, got %q", i+1, "data", data)
+		}
+	}
+}
+
+// Tests that the retry budget refills at its rate up to its burst.
+func TestRetryBudget(t *testing.T) {
+	now := time.Now()
+	b := newRetryBudget(2, 2)
+	b.now = func() time.Time { return now }
+	b.last = now
+
+	for i, expected := range []bool{true, true, false} {
+		if b.take() != expected {
+			t.Errorf("Test %d: Expected %v", i+1, expected)
+		}
+	}
+	now = now.Add(time.Second / 2)
+	if !b.take() || b.take() {
+		t.Errorf("Expected a single retry refilled after half a second")
+	}
+	now = now.Add(time.Hour)
+	if !b.take() || !b.take() || b.take() {
+		t.Errorf("Expected the budget refilled up to its burst")
+	}
+}
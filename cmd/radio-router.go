@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	radioAdminVersion     = "/v1"
+	radioAdminPathPrefix  = minioReservedBucketPath + "/radio" + radioAdminVersion
+	radioReconcilePath    = "/reconcile"
+	radioPrefetchPath     = "/prefetch"
+	radioResyncPath       = "/resync"
+	radioStatsPath        = "/stats"
+	radioVerifyPath       = "/verify"
+	radioJournalFlushPath = "/journal/flush"
+	radioJournalDeadPath  = "/journal/dead"
+	radioRemoteStatsPath  = "/remote/stats"
+)
+
+// registerRadioRouter - add handler functions for radio admin routes.
+func registerRadioRouter(router *mux.Router) {
+	radioRouter := router.PathPrefix(radioAdminPathPrefix).Subrouter()
+
+	// Reconciliation report handler
+	radioRouter.Methods(http.MethodGet).Path(radioReconcilePath).
+		HandlerFunc(httpTraceAll(ReconcileReportHandler)).Queries("bucket", "{bucket:.*}")
+
+	// Prefetch handler
+	radioRouter.Methods(http.MethodPost).Path(radioPrefetchPath).
+		HandlerFunc(httpTraceAll(PrefetchHandler)).Queries("bucket", "{bucket:.*}", "target", "{target:.*}")
+
+	// Replica resync handler
+	radioRouter.Methods(http.MethodPost).Path(radioResyncPath).
+		HandlerFunc(httpTraceAll(ResyncHandler)).Queries("bucket", "{bucket:.*}", "target", "{target:.*}")
+
+	// Bucket stats handler
+	radioRouter.Methods(http.MethodGet).Path(radioStatsPath).
+		HandlerFunc(httpTraceAll(BucketStatsHandler)).Queries("bucket", "{bucket:.*}")
+
+	// Object content verification handler
+	radioRouter.Methods(http.MethodGet).Path(radioVerifyPath).
+		HandlerFunc(httpTraceAll(VerifyHandler)).Queries("bucket", "{bucket:.*}", "object", "{object:.*}")
+
+	// Heal journal flush handler
+	radioRouter.Methods(http.MethodPost).Path(radioJournalFlushPath).
+		HandlerFunc(httpTraceAll(JournalFlushHandler))
+
+	// Heal journal dead letter handlers
+	radioRouter.Methods(http.MethodGet).Path(radioJournalDeadPath).
+		HandlerFunc(httpTraceAll(JournalDeadLettersHandler))
+	radioRouter.Methods(http.MethodPost).Path(radioJournalDeadPath).
+		HandlerFunc(httpTraceAll(JournalRequeueHandler)).Queries("id", "{id:.*}")
+	radioRouter.Methods(http.MethodDelete).Path(radioJournalDeadPath).
+		HandlerFunc(httpTraceAll(JournalDiscardHandler)).Queries("id", "{id:.*}")
+
+	// Remote error stats handlers
+	radioRouter.Methods(http.MethodGet).Path(radioRemoteStatsPath).
+		HandlerFunc(httpTraceAll(RemoteStatsHandler))
+	radioRouter.Methods(http.MethodPost).Path(radioRemoteStatsPath).
+		HandlerFunc(httpTraceAll(RemoteStatsResetHandler))
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/minio/minio-go/v6/pkg/s3signer"
+	"github.com/minio/minio/pkg/auth"
+)
+
+// Credentials of the client of radio admin API tests.
+const (
+	radioAdminTestAccessKey = "radioclient"
+	radioAdminTestSecretKey = "radioclientsecret"
+)
+
+// setRadioAdminTestLayer serves the radio admin API test requests by l,
+// returning a function restoring the globals it replaces.
+func setRadioAdminTestLayer(l ObjectLayer) func() {
+	globalObjLayerMutex.Lock()
+	objAPI := globalObjectAPI
+	globalObjectAPI = l
+	globalObjLayerMutex.Unlock()
+	cred, ok := globalLocalCreds[radioAdminTestAccessKey]
+	globalLocalCreds[radioAdminTestAccessKey] = auth.Credentials{
+		AccessKey: radioAdminTestAccessKey,
+		SecretKey: radioAdminTestSecretKey,
+	}
+	return func() {
+		globalObjLayerMutex.Lock()
+		globalObjectAPI = objAPI
+		globalObjLayerMutex.Unlock()
+		if ok {
+			globalLocalCreds[radioAdminTestAccessKey] = cred
+		} else {
+			delete(globalLocalCreds, radioAdminTestAccessKey)
+		}
+	}
+}
+
+// newRadioAdminTestRequest returns a request to the radio admin API
+// path, signed with secretKey unless empty.
+func newRadioAdminTestRequest(t *testing.T, method, path string, query url.Values, secretKey string) *http.Request {
+	req, err := http.NewRequest(method, "http://radio"+radioAdminPathPrefix+path+"?"+query.Encode(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secretKey == "" {
+		return req
+	}
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+	return s3signer.SignV4(*req, radioAdminTestAccessKey, secretKey, "", "us-east-1")
+}
+
+// serveRadioAdminTestRequest serves req by the radio admin router.
+func serveRadioAdminTestRequest(req *http.Request) *httptest.ResponseRecorder {
+	router := mux.NewRouter().SkipClean(true)
+	registerRadioRouter(router)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+// testRadioAdminAuth checks that the radio admin API path refuses
+// unsigned requests, requests signed with a wrong secret key and
+// methods other than method.
+func testRadioAdminAuth(t *testing.T, method, path string, query url.Values) {
+	t.Helper()
+	for _, secretKey := range []string{"", "wrongsecret"} {
+		rec := serveRadioAdminTestRequest(newRadioAdminTestRequest(t, method, path, query, secretKey))
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s %s signed with %q: expected status %d, got %d", method, path, secretKey, http.StatusForbidden, rec.Code)
+		}
+	}
+	for _, other := range []string{http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete} {
+		if other == method {
+			continue
+		}
+		rec := serveRadioAdminTestRequest(newRadioAdminTestRequest(t, other, path, query, radioAdminTestSecretKey))
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s %s: expected status %d, got %d", other, path, http.StatusMethodNotAllowed, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/radio/cmd/logger"
+)
+
+// Default number of keys visited per second by the divergence scanner.
+const defaultScannerRate = 10
+
+// divergenceScanner periodically walks all mirrored buckets, journaling
+// a heal of the objects whose replicas are missing or disagree on the
+// radio tag. It catches divergence the journal cannot see, such as
+// objects changed directly on a remote.
+type divergenceScanner struct {
+	layer    *radioObjects
+	interval time.Duration
+	rate     int
+}
+
+func newDivergenceScanner(layer *radioObjects, interval time.Duration, rate int) *divergenceScanner {
+	if rate <= 0 {
+		rate = defaultScannerRate
+	}
+	return &divergenceScanner{
+		layer:    layer,
+		interval: interval,
+		rate:     rate,
+	}
+}
+
+// run scans all mirrored buckets every interval until ctx is canceled.
+func (s *divergenceScanner) run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for bucket, rs3s := range s.layer.mirrorClients {
+				sctx := logger.WithFields(ctx, logger.FieldOp, "Scan", logger.FieldBucket, bucket)
+				logger.LogIf(sctx, s.scan(sctx, bucket, rs3s))
+			}
+		}
+	}
+}
+
+// scan walks bucket at the configured rate, journaling a heal of each
+// object found out of sync.
+func (s *divergenceScanner) scan(ctx context.Context, bucket string, rs3s mirrorConfig) error {
+	err := reconcilePrefix(ctx, rs3s, "", s.rate, func(entry reconcileEntry) error {
+		// Errors on single objects are logged without ending the scan.
+		logger.LogIf(logger.WithFields(ctx, logger.FieldObject, entry.Key), s.heal(ctx, bucket, rs3s, entry.Key))
+		return nil
+	})
+	return ErrorRespToObjectError(err, bucket)
+}
+
+// heal journals a heal of the newest copy of object onto the writable
+// replicas not holding it. Replicas are stated again under the object
+// lock, since the listing may have raced with a write, and objects
+// already pending heal are left alone.
+func (s *divergenceScanner) heal(ctx context.Context, bucket string, rs3s mirrorConfig, object string) error {
+	objectLock := s.layer.NewNSLock(ctx, bucket, object)
+	if err := objectLock.GetRLock(globalObjectTimeout); err != nil {
+		return err
+	}
+	defer objectLock.RUnlock()
+
+	oinfos := make([]miniogo.ObjectInfo, len(rs3s.clnts))
+	errs := make([]error, len(rs3s.clnts))
+	for index, clnt := range rs3s.clnts {
+		if clnt.Shadow {
+			// Shadow replicas are neither heal sources nor healed.
+			errs[index] = errShadowReplica
+			continue
+		}
+		oinfos[index], errs[index] = clnt.StatObjectWithContext(ctx, clnt.Bucket, clnt.objectKey(object),
+			miniogo.StatObjectOptions{})
+		if errs[index] != nil && miniogo.ToErrorResponse(errs[index]).Code != "NoSuchKey" {
+			return ErrorRespToObjectError(errs[index], bucket, object)
+		}
+	}
+
+	src := newestReplica(oinfos, errs)
+	if src < 0 {
+		// Object was removed since.
+		return nil
+	}
+	if s.layer.healSys.tombstoned(ctx, bucket, object, oinfos, errs) {
+		// The pending delete removes the copies left.
+		return nil
+	}
+	radioTag := oinfos[src].Metadata.Get(globalRadioTagKey)
+	var dsts []int
+	for index, clnt := range rs3s.clnts {
+		if clnt.ReadOnly || clnt.Shadow {
+			continue
+		}
+		if errs[index] != nil || oinfos[index].Metadata.Get(globalRadioTagKey) != radioTag {
+			dsts = append(dsts, index)
+		}
+	}
+	if len(dsts) == 0 {
+		return nil
+	}
+	if _, ok := s.layer.healSys.pendingWrite(ctx, bucket, object); ok {
+		return nil
+	}
+	s.layer.healSys.queue(ctx, journalEntry{
+		Bucket:       bucket,
+		Object:       object,
+		Op:           opPutObject,
+		RadioTag:     radioTag,
+		SrcClientID:  src,
+		DstClientIDs: dsts,
+	})
+	scannerHealsQueued.WithLabelValues(bucket).Inc()
+	return nil
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+// Tests that the scanner journals a heal of the newest copy onto the
+// writable replicas missing it.
+func TestScannerHeal(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		radioTags    []string
+		readOnly     []bool
+		pending      bool
+		expectedSrc  int
+		expectedDsts []int
+	}{
+		// In sync.
+		{[]string{"v2", "v2"}, []bool{false, false}, false, -1, nil},
+		// Missing and stale replicas.
+		{[]string{"v1", "v2", ""}, []bool{false, false, false}, false, 1, []int{0, 2}},
+		// Read-only replicas are not healed.
+		{[]string{"v2", "v1"}, []bool{false, true}, false, -1, nil},
+		// A heal is already pending.
+		{[]string{"v2", "v1"}, []bool{false, false}, true, 0, []int{1}},
+		// Object removed since listed.
+		{[]string{"", ""}, []bool{false, false}, false, -1, nil},
+	}
+
+	for i, testCase := range testCases {
+		dir, err := ioutil.TempDir("", "radio-journal")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		store := &dirJournalStore{dir: dir}
+		if testCase.pending {
+			if err = store.Save(journalEntry{ID: "1", Bucket: "bucket", Object: "object", Op: opPutObject,
+				RadioTag: "v2", SrcClientID: 0, DstClientIDs: []int{1}, Timestamp: now}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		var replicas []testReplica
+		for _, radioTag := range testCase.radioTags {
+			// Replicas holding later versions were modified later.
+			replica := testReplica{radioTag, now.Add(-time.Hour)}
+			if radioTag == "v2" {
+				replica.modTime = now
+			}
+			replicas = append(replicas, replica)
+		}
+		_, clnts, shutdown := newTestReplicas(t, replicas...)
+		defer shutdown()
+		for index := range clnts {
+			clnts[index].ReadOnly = testCase.readOnly[index]
+		}
+		rs3s := mirrorConfig{clnts: clnts}
+		l := &radioObjects{
+			nsMutex:       newNSLock(false),
+			mirrorClients: map[string]mirrorConfig{"bucket": rs3s},
+		}
+		if l.healSys, err = newHealSys(l, store, journalConfig{}, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		s := newDivergenceScanner(l, time.Hour, 0)
+		if err = s.heal(context.Background(), "bucket", rs3s, "object"); err != nil {
+			t.Errorf("Test %d: Unexpected error %v", i+1, err)
+			continue
+		}
+		entries, err := store.List()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if testCase.pending || testCase.expectedSrc < 0 {
+			expected := 0
+			if testCase.pending {
+				expected = 1
+			}
+			if len(entries) != expected {
+				t.Errorf("Test %d: Expected %d journal entries, got %d", i+1, expected, len(entries))
+			}
+			continue
+		}
+		if len(entries) != 1 {
+			t.Errorf("Test %d: Expected 1 journal entry, got %d", i+1, len(entries))
+			continue
+		}
+		entry := entries[0]
+		if entry.SrcClientID != testCase.expectedSrc || entry.RadioTag != testCase.radioTags[testCase.expectedSrc] {
+			t.Errorf("Test %d: Expected heal from replica %d, got %+v", i+1, testCase.expectedSrc, entry)
+		}
+		if len(entry.DstClientIDs) != len(testCase.expectedDsts) {
+			t.Errorf("Test %d: Expected heal onto %v, got %v", i+1, testCase.expectedDsts, entry.DstClientIDs)
+			continue
+		}
+		for j := range entry.DstClientIDs {
+			if entry.DstClientIDs[j] != testCase.expectedDsts[j] {
+				t.Errorf("Test %d: Expected heal onto %v, got %v", i+1, testCase.expectedDsts, entry.DstClientIDs)
+			}
+		}
+	}
+}
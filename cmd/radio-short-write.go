@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/minio/radio/pkg/streamdup"
+)
+
+// errShortWrite is returned for a replica whose copy of an upload ended
+// before the upload size, the replica may hold a truncated object.
+var errShortWrite = errors.New("replica received fewer bytes than the upload size")
+
+// errWriteStalled is returned for a replica abandoned by the upload for
+// reading none of it within the write stall timeout.
+var errWriteStalled = errors.New("replica stalled reading the upload")
+
+// newStreamDup duplicates uploads for the replicas, abandoning replicas
+// reading none of an upload for stallTimeout.
+var newStreamDup = func(r io.Reader, dupN int, stallTimeout time.Duration) ([]io.Reader, error) {
+	return streamdup.NewWithWatchdog(r, dupN, stallTimeout)
+}
+
+// deliveryReader counts the bytes of an upload read by a replica.
+type deliveryReader struct {
+	r   io.Reader
+	n   int64
+	eof bool
+}
+
+func (d *deliveryReader) Read(p []byte) (int, error) {
+	n, err := d.r.Read(p)
+	d.n += int64(n)
+	if err == io.EOF {
+		d.eof = true
+	}
+	return n, err
+}
+
+// stallNotifier is implemented by the upload readers of replicas which
+// may be abandoned for stalling.
+type stallNotifier interface {
+	Stalled() <-chan struct{}
+}
+
+// stalled returns true if the replica was abandoned by the upload.
+func (d *deliveryReader) stalled() bool {
+	s, ok := d.r.(stallNotifier)
+	if !ok {
+		return false
+	}
+	select {
+	case <-s.Stalled():
+		return true
+	default:
+		return false
+	}
+}
+
+// withStallCancel returns a context of ctx canceled once the replica
+// reading the upload r is abandoned, aborting its request which may be
+// blocked sending data the replica does not read.
+func withStallCancel(ctx context.Context, r io.Reader) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	if s, ok := r.(stallNotifier); ok {
+		go func() {
+			select {
+			case <-s.Stalled():
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+	}
+	return ctx, cancel
+}
+
+// short returns true if the upload ended before size bytes, as opposed
+// to the replica giving up reading it. Uploads of unknown size are
+// never short.
+func (d *deliveryReader) short(size int64) bool {
+	return size >= 0 && d.eof && d.n < size
+}
+
+// checkDelivered returns errShortWrite if the upload read through d
+// was short, errWriteStalled if the replica was abandoned, err
+// otherwise.
+func checkDelivered(bucket string, d *deliveryReader, size int64, err error) error {
+	if d.stalled() {
+		stalledWrites.WithLabelValues(bucket).Inc()
+		return errWriteStalled
+	}
+	if d.short(size) {
+		shortWrites.WithLabelValues(bucket).Inc()
+		return errShortWrite
+	}
+	return err
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/minio/minio/pkg/hash"
+	"github.com/minio/radio/pkg/streamdup"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// truncatingReader delivers the first n bytes of r only, while still
+// draining r as a stream duplicator with a lost buffer would.
+type truncatingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (t *truncatingReader) Read(p []byte) (int, error) {
+	if t.n <= 0 {
+		io.Copy(ioutil.Discard, t.r)
+		return 0, io.EOF
+	}
+	if int64(len(p)) > t.n {
+		p = p[:t.n]
+	}
+	n, err := t.r.Read(p)
+	t.n -= int64(n)
+	return n, err
+}
+
+// Tests that a replica sent a truncated upload fails the write to it,
+// which is journaled for heal.
+func TestPutObjectShortWrite(t *testing.T) {
+	defer func(f func(io.Reader, int, time.Duration) ([]io.Reader, error)) { newStreamDup = f }(newStreamDup)
+	newStreamDup = func(r io.Reader, dupN int, stallTimeout time.Duration) ([]io.Reader, error) {
+		readers, err := streamdup.NewWithWatchdog(r, dupN, stallTimeout)
+		if err != nil {
+			return nil, err
+		}
+		readers[2] = &truncatingReader{r: readers[2], n: 2}
+		return readers, nil
+	}
+
+	tmpdir, err := ioutil.TempDir("", "radio-short-write")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	_, clnts, shutdown := newTestRemotes(t, 3)
+	defer shutdown()
+	l := &radioObjects{
+		nsMutex:       newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+	}
+	store := &dirJournalStore{dir: tmpdir}
+	if l.healSys, err = newHealSys(l, store, journalConfig{}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	before := testutil.ToFloat64(shortWrites.WithLabelValues("bucket"))
+	data := []byte("data")
+	reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), "", "", int64(len(data)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = l.PutObject(context.Background(), "bucket", "object", NewPutObjReader(reader, nil, nil), ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := testutil.ToFloat64(shortWrites.WithLabelValues("bucket")) - before; n != 1 {
+		t.Errorf("Expected 1 short write, got %v", n)
+	}
+	entries, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || len(entries[0].DstClientIDs) != 1 || entries[0].DstClientIDs[0] != 2 {
+		t.Errorf("Expected the short replica journaled for heal, got %+v", entries)
+	}
+}
+
+// Tests that a replica reading none of an upload within the write stall
+// timeout is abandoned, the write completing on the other replicas and
+// journaling the stalled replica for heal.
+func TestPutObjectWriteStall(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "radio-write-stall")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	remotes, clnts, shutdown := newTestRemotes(t, 3)
+	defer shutdown()
+	// The hung replica never reads its uploads.
+	release := make(chan struct{})
+	defer close(release)
+	remotes[2].setHook(func(w http.ResponseWriter, r *http.Request) bool {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+		return true
+	})
+	l := &radioObjects{
+		nsMutex:       newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+		timeouts:      timeoutsConfig{WriteStall: 200 * time.Millisecond},
+	}
+	store := &dirJournalStore{dir: tmpdir}
+	if l.healSys, err = newHealSys(l, store, journalConfig{}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Large enough not to fit in the socket buffers of the hung replica.
+	before := testutil.ToFloat64(stalledWrites.WithLabelValues("bucket"))
+	data := bytes.Repeat([]byte("data"), 8<<20)
+	reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), "", "", int64(len(data)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = l.PutObject(context.Background(), "bucket", "object", NewPutObjReader(reader, nil, nil), ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := testutil.ToFloat64(stalledWrites.WithLabelValues("bucket")) - before; n != 1 {
+		t.Errorf("Expected 1 stalled write, got %v", n)
+	}
+	entries, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || len(entries[0].DstClientIDs) != 1 || entries[0].DstClientIDs[0] != 2 {
+		t.Errorf("Expected the stalled replica journaled for heal, got %+v", entries)
+	}
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// validateSingleReplicaPrefixes checks the single replica prefixes of
+// bucket, whose objects are only stored on the first remote, which must
+// then be writable.
+func validateSingleReplicaPrefixes(bucket string, prefixes []string, remotes []remoteConfig) error {
+	if len(prefixes) == 0 {
+		return nil
+	}
+	if len(remotes) == 0 || remotes[0].ReadOnly || remotes[0].Shadow || remotes[0].AsyncWrites {
+		return fmt.Errorf("bucket %s: single replica prefixes require a writable first remote", bucket)
+	}
+	for _, prefix := range prefixes {
+		if prefix == "" || strings.HasPrefix(prefix, SlashSeparator) {
+			return fmt.Errorf("bucket %s: invalid single replica prefix %q", bucket, prefix)
+		}
+	}
+	return nil
+}
+
+// singleReplica returns true if object is stored on the primary replica
+// only.
+func (m mirrorConfig) singleReplica(object string) bool {
+	for _, prefix := range m.singleReplicaPrefixes {
+		if strings.HasPrefix(object, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// forObject returns m restricted to its primary replica, the first one,
+// for objects stored on it only. Replica indexes are unchanged.
+func (m mirrorConfig) forObject(object string) mirrorConfig {
+	if m.singleReplica(object) {
+		m.clnts = m.clnts[:1]
+	}
+	return m
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/minio/minio/pkg/hash"
+)
+
+// Tests validation of single replica prefixes.
+func TestValidateSingleReplicaPrefixes(t *testing.T) {
+	testCases := []struct {
+		prefixes   []string
+		remotes    []remoteConfig
+		shouldPass bool
+	}{
+		{nil, nil, true},
+		{[]string{"temp/"}, []remoteConfig{{}, {}}, true},
+		{[]string{"temp/"}, []remoteConfig{{ReadOnly: true}, {}}, false},
+		{[]string{"temp/"}, []remoteConfig{{Shadow: true}, {}}, false},
+		{[]string{"temp/"}, nil, false},
+		{[]string{""}, []remoteConfig{{}, {}}, false},
+		{[]string{"/temp/"}, []remoteConfig{{}, {}}, false},
+	}
+
+	for i, testCase := range testCases {
+		err := validateSingleReplicaPrefixes("bucket", testCase.prefixes, testCase.remotes)
+		if testCase.shouldPass && err != nil {
+			t.Errorf("Test %d: Unexpected error %v", i+1, err)
+		}
+		if !testCase.shouldPass && err == nil {
+			t.Errorf("Test %d: Expected an error", i+1)
+		}
+	}
+}
+
+// Tests that objects under single replica prefixes are written to and
+// read from the primary replica only.
+func TestSingleReplicaPrefixes(t *testing.T) {
+	remotes, clnts, shutdown := newTestRemotes(t, 2)
+	defer shutdown()
+	l := &radioObjects{
+		nsMutex: newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{
+			"bucket": {clnts: clnts, singleReplicaPrefixes: []string{"temp/"}},
+		},
+	}
+
+	data := []byte("data")
+	reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), "", "", int64(len(data)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = l.PutObject(context.Background(), "bucket", "temp/object", NewPutObjReader(reader, nil, nil), ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if keys0, keys1 := remotes[0].keys(), remotes[1].keys(); len(keys0) != 1 || len(keys1) != 0 {
+		t.Fatalf("Expected a single write to the primary replica, got %v and %v", keys0, keys1)
+	}
+
+	// The other replica does not hold the object, yet it is read
+	// without quorum.
+	objInfo, err := l.GetObjectInfo(context.Background(), "bucket", "temp/object", ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if objInfo.ReplicaIndex != 0 || len(objInfo.Replicas) != 1 {
+		t.Errorf("Expected object read from the primary replica, got replica %d of %v", objInfo.ReplicaIndex, objInfo.Replicas)
+	}
+
+	reader, err = hash.NewReader(bytes.NewReader(data), int64(len(data)), "", "", int64(len(data)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = l.PutObject(context.Background(), "bucket", "object", NewPutObjReader(reader, nil, nil), ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if keys0, keys1 := remotes[0].keys(), remotes[1].keys(); len(keys0) != 2 || len(keys1) != 1 {
+		t.Errorf("Expected mirrored writes, got %v and %v", keys0, keys1)
+	}
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/radio/cmd/logger"
+)
+
+// Object layer operations timed by the slow request log.
+var slowLoggedOperations = []string{
+	"GetObjectInfo",
+	"PutObject",
+	"CopyObject",
+	"DeleteObject",
+	"PutObjectPart",
+	"CopyObjectPart",
+}
+
+// slowRequestConfig logs the object layer operations taking longer
+// than a threshold, zero disables the log.
+type slowRequestConfig struct {
+	Threshold time.Duration `yaml:"threshold"`
+	// Operations overrides the threshold of the operations it names,
+	// see slowLoggedOperations. Zero disables the log of an operation.
+	Operations map[string]time.Duration `yaml:"operations"`
+}
+
+// slowLog holds the thresholds of the operations it logs.
+type slowLog struct {
+	thresholds map[string]time.Duration
+}
+
+// newSlowLog returns the slow request log of cfg, nil if no operation
+// is logged. Operation names are matched regardless of case.
+func newSlowLog(cfg slowRequestConfig) (*slowLog, error) {
+	if cfg.Threshold < 0 {
+		return nil, fmt.Errorf("slow request threshold must not be negative")
+	}
+	s := &slowLog{thresholds: make(map[string]time.Duration)}
+	for _, op := range slowLoggedOperations {
+		if cfg.Threshold > 0 {
+			s.thresholds[op] = cfg.Threshold
+		}
+	}
+	for op, threshold := range cfg.Operations {
+		name, ok := slowLoggedOperation(op)
+		if !ok {
+			return nil, fmt.Errorf("unknown slow request operation %q, expected one of %s",
+				op, strings.Join(slowLoggedOperations, ", "))
+		}
+		if threshold < 0 {
+			return nil, fmt.Errorf("slow request threshold of %s must not be negative", name)
+		}
+		if threshold == 0 {
+			delete(s.thresholds, name)
+			continue
+		}
+		s.thresholds[name] = threshold
+	}
+	if len(s.thresholds) == 0 {
+		return nil, nil
+	}
+	return s, nil
+}
+
+func slowLoggedOperation(op string) (string, bool) {
+	for _, name := range slowLoggedOperations {
+		if strings.EqualFold(name, op) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// start times op on object, returning nil if op is not logged.
+func (s *slowLog) start(op, bucket, object string) *slowRequest {
+	if s == nil {
+		return nil
+	}
+	threshold, ok := s.thresholds[op]
+	if !ok {
+		return nil
+	}
+	return &slowRequest{
+		op:        op,
+		bucket:    bucket,
+		object:    object,
+		threshold: threshold,
+		start:     time.Now(),
+		replicas:  make(map[string]time.Duration),
+	}
+}
+
+// slowRequest is an operation timed by the slow request log, along with
+// the calls to the replicas taking part in it. A nil slowRequest times
+// nothing.
+type slowRequest struct {
+	op        string
+	bucket    string
+	object    string
+	threshold time.Duration
+	start     time.Time
+
+	mu       sync.Mutex
+	replicas map[string]time.Duration
+}
+
+// replica times the call to clnt, until the returned function is
+// called.
+func (r *slowRequest) replica(clnt bucketClient) func() {
+	if r == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		elapsed := time.Since(start)
+		r.mu.Lock()
+		r.replicas[clnt.EndpointURL().Host+SlashSeparator+clnt.Bucket] = elapsed
+		r.mu.Unlock()
+	}
+}
+
+// done logs the operation if it took longer than its threshold.
+func (r *slowRequest) done(ctx context.Context) {
+	if r == nil {
+		return
+	}
+	if err := r.check(time.Since(r.start)); err != nil {
+		logger.LogIf(ctx, err)
+	}
+}
+
+// check returns an error reporting the operation and its replicas,
+// slowest first, if elapsed exceeds the threshold.
+func (r *slowRequest) check(elapsed time.Duration) error {
+	if elapsed <= r.threshold {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	remotes := make([]string, 0, len(r.replicas))
+	for remote := range r.replicas {
+		remotes = append(remotes, remote)
+	}
+	sort.Slice(remotes, func(i, j int) bool {
+		return r.replicas[remotes[i]] > r.replicas[remotes[j]]
+	})
+	timings := make([]string, len(remotes))
+	for i, remote := range remotes {
+		timings[i] = fmt.Sprintf("%s=%s", remote, r.replicas[remote].Round(time.Millisecond))
+	}
+	return fmt.Errorf("slow %s of %s/%s took %s, threshold %s, replicas %s",
+		r.op, r.bucket, r.object, elapsed.Round(time.Millisecond), r.threshold,
+		strings.Join(timings, ", "))
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Tests that slow request thresholds are resolved per operation and that
+// slow operations are reported with their slowest replica first.
+func TestSlowLog(t *testing.T) {
+	if _, err := newSlowLog(slowRequestConfig{Operations: map[string]time.Duration{"ListBuckets": time.Second}}); err == nil {
+		t.Fatal("expected an error for an unknown operation")
+	}
+	if s, err := newSlowLog(slowRequestConfig{}); err != nil || s != nil {
+		t.Fatalf("expected no slow log without thresholds, got %v (%v)", s, err)
+	}
+
+	s, err := newSlowLog(slowRequestConfig{
+		Threshold:  time.Second,
+		Operations: map[string]time.Duration{"putobject": time.Minute, "DeleteObject": 0},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := s.start("DeleteObject", "bucket", "object"); r != nil {
+		t.Error("expected DeleteObject not to be logged")
+	}
+	if r := s.start("PutObject", "bucket", "object"); r == nil || r.threshold != time.Minute {
+		t.Errorf("expected PutObject threshold overridden, got %v", r)
+	}
+
+	r := s.start("GetObjectInfo", "bucket", "object")
+	fast := bucketClient{Core: newTestCore(t, "http://fast:9000"), Bucket: "remote1"}
+	r.replicas["slow:9000/remote2"] = 2 * time.Second
+	r.replica(fast)()
+	if err = r.check(500 * time.Millisecond); err != nil {
+		t.Errorf("expected no report below the threshold, got %v", err)
+	}
+	err = r.check(2 * time.Second)
+	if err == nil || !strings.Contains(err.Error(), "replicas slow:9000/remote2=2s, fast:9000/remote1=") {
+		t.Errorf("expected the slow replica reported first, got %v", err)
+	}
+
+	// Operations not logged time nothing.
+	var none *slowRequest
+	none.replica(fast)()
+	none.done(context.Background())
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	humanize "github.com/dustin/go-humanize"
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/minio-go/v6/pkg/encrypt"
+	"github.com/minio/minio/pkg/sync/errgroup"
+	"github.com/minio/radio/cmd/logger"
+)
+
+// defaultSpoolRetries is the retries of a replica failing a spooled
+// upload when spooling is enabled without retries.
+const defaultSpoolRetries = 1
+
+// spoolConfig configures the spooling of large uploads to a temporary
+// file while they are streamed to the replicas, for replicas failing
+// the upload to be retried from the file rather than healed.
+type spoolConfig struct {
+	// Threshold is the size such as 1GiB of the smallest uploads
+	// spooled, uploads are never spooled if empty. Smaller uploads are
+	// only streamed to the replicas.
+	Threshold string `yaml:"threshold"`
+	// Dir holds the spooled uploads, the journal dir by default.
+	Dir string `yaml:"dir"`
+	// Retries of each replica failing a spooled upload.
+	Retries int `yaml:"retries"`
+}
+
+// uploadSpooler spools uploads of at least threshold bytes in dir.
+type uploadSpooler struct {
+	threshold int64
+	dir       string
+	retries   int
+}
+
+// newUploadSpooler returns the spooler configured by cfg, spooling in
+// journalDir unless cfg sets a dir, nil if spooling is disabled.
+func newUploadSpooler(cfg spoolConfig, journalDir string) (*uploadSpooler, error) {
+	if cfg.Threshold == "" {
+		return nil, nil
+	}
+	threshold, err := humanize.ParseBytes(cfg.Threshold)
+	if err != nil {
+		return nil, fmt.Errorf("invalid spool threshold %q: %w", cfg.Threshold, err)
+	}
+	if cfg.Retries < 0 {
+		return nil, fmt.Errorf("spool retries must not be negative")
+	}
+	s := &uploadSpooler{threshold: int64(threshold), dir: cfg.Dir, retries: cfg.Retries}
+	if s.dir == "" {
+		s.dir = journalDir
+	}
+	if s.dir == "" {
+		return nil, fmt.Errorf("spool dir is required without a journal dir")
+	}
+	if s.retries == 0 {
+		s.retries = defaultSpoolRetries
+	}
+	if err = os.MkdirAll(s.dir, 0700); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// spool returns r spooled to a temporary file if an upload of size
+// bytes is spooled, nil otherwise. Uploads are streamed unspooled if the
+// file cannot be created.
+func (s *uploadSpooler) spool(ctx context.Context, r io.Reader, size int64) *uploadSpool {
+	if s == nil || size < s.threshold || size <= 0 {
+		return nil
+	}
+	f, err := ioutil.TempFile(s.dir, "spool-")
+	if err != nil {
+		logger.LogIf(ctx, err)
+		return nil
+	}
+	return &uploadSpool{r: r, f: f, retries: s.retries}
+}
+
+// uploadSpool copies the data read from an upload to a temporary file.
+// Failures to write the file only leave the upload unspooled.
+type uploadSpool struct {
+	r       io.Reader
+	f       *os.File
+	retries int
+
+	mu  sync.Mutex
+	n   int64
+	err error
+}
+
+func (s *uploadSpool) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n, err := s.r.Read(p)
+	if n > 0 && s.err == nil {
+		if _, werr := s.f.Write(p[:n]); werr != nil {
+			s.err = werr
+		}
+	}
+	s.n += int64(n)
+	if err != nil && err != io.EOF && s.err == nil {
+		s.err = err
+	}
+	return n, err
+}
+
+// spooled returns true if the file holds all size bytes of the upload.
+func (s *uploadSpool) spooled(size int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err == nil && s.n == size
+}
+
+// remove removes the file of s, a nil uploadSpool is a no-op.
+func (s *uploadSpool) remove(ctx context.Context) {
+	if s == nil {
+		return
+	}
+	s.f.Close()
+	logger.LogIf(ctx, os.Remove(s.f.Name()))
+}
+
+// retry uploads object again from the spool file to the writable
+// replicas of m which failed the upload as reported in errs, updating
+// errs and oinfos with the results. The readers of the failed replicas
+// are drained first for the upload to be spooled whole.
+func (s *uploadSpool) retry(ctx context.Context, m mirrorConfig, bucket, object string, readers []io.Reader,
+	size int64, md5Base64, sha256Hex string, metadata map[string]string, sse encrypt.ServerSide,
+	oinfos []miniogo.ObjectInfo, errs []error) {
+	var failed []int
+	for index, err := range errs {
+		clnt := m.clnts[index]
+		if err == nil || IsErrIgnored(err, skippedReplicaErrs...) || clnt.ReadOnly || clnt.Shadow || clnt.asyncWrites {
+			continue
+		}
+		failed = append(failed, index)
+	}
+	if len(failed) == 0 {
+		return
+	}
+	var wg sync.WaitGroup
+	for _, index := range failed {
+		wg.Add(1)
+		go func(r io.Reader) {
+			defer wg.Done()
+			io.Copy(ioutil.Discard, r)
+		}(readers[index])
+	}
+	wg.Wait()
+	if !s.spooled(size) {
+		return
+	}
+
+	g := errgroup.WithNErrs(len(failed))
+	for i, index := range failed {
+		index := index
+		g.Go(func() error {
+			clnt := m.clnts[index]
+			replicaMetadata := clnt.storageClassMetadata(metadata)
+			var err error
+			for attempt := 0; attempt < s.retries; attempt++ {
+				spoolRetries.WithLabelValues(bucket).Inc()
+				oinfos[index], err = clnt.PutObjectWithContext(ctx, clnt.Bucket, clnt.objectKey(object),
+					io.NewSectionReader(s.f, 0, size), size, md5Base64, clnt.payloadSHA256(sha256Hex),
+					ToMinioClientMetadata(replicaMetadata), sse)
+				if err == nil {
+					oinfos[index].Key = object
+					oinfos[index].Metadata = ToMinioClientObjectInfoMetadata(replicaMetadata)
+					errs[index] = nil
+					return nil
+				}
+				if ctx.Err() != nil {
+					break
+				}
+			}
+			return err
+		}, i)
+	}
+	g.Wait()
+}
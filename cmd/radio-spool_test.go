@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/minio/minio/pkg/hash"
+)
+
+// Tests that replicas failing uploads above the spool threshold are
+// retried from the spooled upload, which is removed afterwards.
+func TestPutObjectSpool(t *testing.T) {
+	for _, cfg := range []spoolConfig{
+		{Threshold: "lots", Dir: "spool"},
+		{Threshold: "1MiB", Dir: "spool", Retries: -1},
+		{Threshold: "1MiB"},
+	} {
+		if _, err := newUploadSpooler(cfg, ""); err == nil {
+			t.Errorf("%+v: expected an invalid configuration", cfg)
+		}
+	}
+	if s, err := newUploadSpooler(spoolConfig{}, "journal"); s != nil || err != nil {
+		t.Errorf("Expected no spooler without a threshold, got %v (%v)", s, err)
+	}
+
+	tmpdir, err := ioutil.TempDir("", "radio-spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+	spooler, err := newUploadSpooler(spoolConfig{Threshold: "1MiB"}, tmpdir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Large enough for the uploads of replicas failing without reading
+	// them not to fit the buffers of their connections.
+	data := bytes.Repeat([]byte("data"), 1<<23)
+	testCases := []struct {
+		size int
+		hook func(w http.ResponseWriter, r *http.Request) bool
+		put  bool
+	}{
+		// Spooled uploads are retried.
+		{2 << 20, nil, true},
+		// Retries are bounded.
+		{2 << 20, func(w http.ResponseWriter, r *http.Request) bool {
+			ioutil.ReadAll(r.Body)
+			writeTestRemoteError(w, r, http.StatusForbidden, "AccessDenied")
+			return true
+		}, false},
+		// Replicas failing without reading the upload do not hold up
+		// the others.
+		{len(data), func(w http.ResponseWriter, r *http.Request) bool {
+			writeTestRemoteError(w, r, http.StatusForbidden, "AccessDenied")
+			return true
+		}, false},
+		// Uploads below the threshold are not spooled.
+		{1 << 10, nil, false},
+	}
+	for i, testCase := range testCases {
+		remotes, clnts, shutdown := newTestRemotes(t, 2)
+		failTestPuts(remotes[1], 1)
+		if testCase.hook != nil {
+			remotes[1].setHook(testCase.hook)
+		}
+		l := &radioObjects{
+			nsMutex:       newNSLock(false),
+			mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+			spooler:       spooler,
+		}
+		size := int64(testCase.size)
+		reader, err := hash.NewReader(bytes.NewReader(data[:size]), size, "", "", size, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		_, err = l.PutObject(ctx, "bucket", "object", NewPutObjReader(reader, nil, nil), ObjectOptions{})
+		if ctx.Err() != nil {
+			t.Fatalf("Test %d: PutObject blocked", i+1)
+		}
+		cancel()
+		if (err == nil) != testCase.put {
+			t.Errorf("Test %d: expected put %v, got %v", i+1, testCase.put, err)
+		}
+		if stored, _, _ := remotes[1].object("object"); testCase.put && stored != string(data[:size]) {
+			t.Errorf("Test %d: expected the flaky replica written", i+1)
+		}
+		if files, _ := ioutil.ReadDir(tmpdir); len(files) != 0 {
+			t.Errorf("Test %d: expected the spooled upload removed, got %d files", i+1, len(files))
+		}
+		shutdown()
+	}
+}
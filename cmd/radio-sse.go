@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"errors"
+	"net/http"
+
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/minio-go/v6/pkg/encrypt"
+)
+
+// errEncryptedObject is returned when reading an SSE-C encrypted object
+// without its customer key.
+var errEncryptedObject = errors.New("object is encrypted with a customer key, which the request does not provide")
+
+// errHealKeyUnavailable is returned when healing an SSE-C encrypted
+// object whose customer key is no longer known. Customer keys are only
+// kept in memory and are lost on restart, such objects are healed by
+// writing them again.
+var errHealKeyUnavailable = errors.New("customer key of the SSE-C encrypted object is not available to heal it")
+
+// ssecObjectOptions returns opts with the SSE-C customer key of the
+// request headers h if any.
+func ssecObjectOptions(h http.Header, opts ObjectOptions) (ObjectOptions, error) {
+	if !SSEC.IsRequested(h) {
+		return opts, nil
+	}
+	key, err := SSEC.ParseHTTP(h)
+	if err != nil {
+		return opts, err
+	}
+	opts.ServerSideEncryption, err = encrypt.NewSSEC(key[:])
+	return opts, err
+}
+
+// ssecCopySourceOptions returns the options to read the source object of
+// a copy with, holding the SSE-C customer key of the copy source headers
+// of h if any.
+func ssecCopySourceOptions(h http.Header) (opts ObjectOptions, err error) {
+	if !SSECopy.IsRequested(h) {
+		return opts, nil
+	}
+	key, err := SSECopy.ParseHTTP(h)
+	if err != nil {
+		return opts, err
+	}
+	opts.ServerSideEncryption, err = encrypt.NewSSEC(key[:])
+	return opts, err
+}
+
+// ssecKey returns sse if it holds a customer key, nil otherwise.
+func ssecKey(sse encrypt.ServerSide) encrypt.ServerSide {
+	if sse != nil && sse.Type() == encrypt.SSEC {
+		return sse
+	}
+	return nil
+}
+
+// isEncryptedObjectErr returns true if err is a remote refusing to read
+// an object without its customer key, remotes answer with a bad request.
+func isEncryptedObjectErr(err error) bool {
+	return err != nil && miniogo.ToErrorResponse(err).StatusCode == http.StatusBadRequest
+}
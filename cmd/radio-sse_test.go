@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/minio/minio-go/v6/pkg/encrypt"
+	"github.com/minio/minio/pkg/hash"
+)
+
+func putSSETestObject(t *testing.T, l *radioObjects, sse encrypt.ServerSide) {
+	data := []byte("data")
+	reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), "", "", int64(len(data)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = l.PutObject(context.Background(), "bucket", "object", NewPutObjReader(reader, nil, nil),
+		ObjectOptions{ServerSideEncryption: sse}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Tests that SSE-C encrypted objects written through the mirror are
+// only read back with their customer key.
+func TestSSECRoundTrip(t *testing.T) {
+	key, err := encrypt.NewSSEC(bytes.Repeat([]byte("k"), 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := encrypt.NewSSEC(bytes.Repeat([]byte("o"), 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, clnts, shutdown := newTestRemotes(t, 2)
+	defer shutdown()
+	l := &radioObjects{
+		nsMutex:       newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+	}
+	putSSETestObject(t, l, key)
+
+	testCases := []struct {
+		sse         encrypt.ServerSide
+		expectedErr APIErrorCode
+	}{
+		{key, ErrNone},
+		{nil, ErrSSEEncryptedObject},
+		{otherKey, ErrAccessDenied},
+	}
+
+	for i, testCase := range testCases {
+		gr, err := l.GetObjectNInfo(context.Background(), "bucket", "object", nil, nil, ReadLock,
+			ObjectOptions{ServerSideEncryption: testCase.sse})
+		if code := toAPIErrorCode(context.Background(), err); code != testCase.expectedErr {
+			t.Errorf("Test %d: Expected error code %v, got %v (%v)", i+1, testCase.expectedErr, code, err)
+		}
+		if err != nil {
+			continue
+		}
+		data, err := ioutil.ReadAll(gr)
+		gr.Close()
+		if err != nil {
+			t.Errorf("Test %d: Unexpected error %v", i+1, err)
+		}
+		if string(data) != "data" {
+			t.Errorf("Test %d: Expected data %q, got %q", i+1, "data", data)
+		}
+	}
+}
+
+// Tests that heals of SSE-C encrypted objects write them with the
+// customer key, which is not persisted in the journal.
+func TestHealSSEC(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "radio-sse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	key, err := encrypt.NewSSEC(bytes.Repeat([]byte("k"), 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	remotes, clnts, shutdown := newTestRemotes(t, 3)
+	defer shutdown()
+	remotes[2].setHook(func(w http.ResponseWriter, r *http.Request) bool {
+		writeTestRemoteError(w, r, http.StatusForbidden, "AccessDenied")
+		return true
+	})
+	l := &radioObjects{
+		nsMutex:       newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+	}
+	store := &dirJournalStore{dir: tmpdir}
+	if l.healSys, err = newHealSys(l, store, journalConfig{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	putSSETestObject(t, l, key)
+
+	entries, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || !entries[0].SSEC || entries[0].sse != nil {
+		t.Fatalf("Expected a single SSE-C entry without its key, got %+v", entries)
+	}
+
+	remotes[2].setHook(nil)
+	l.healSys.healAll(context.Background())
+
+	_, expected, _ := remotes[0].object("object")
+	_, healed, ok := remotes[2].object("object")
+	if !ok || healed.Get(globalRadioTagKey) != expected.Get(globalRadioTagKey) ||
+		healed.Get(testRemoteSSECKeyMD5) == "" || healed.Get(testRemoteSSECKeyMD5) != expected.Get(testRemoteSSECKeyMD5) {
+		t.Errorf("Expected the object healed with its customer key, got %v", healed)
+	}
+}
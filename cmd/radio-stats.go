@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/minio/minio/pkg/policy"
+)
+
+// Default lifetime of cached bucket stats.
+const defaultStatsTTL = 5 * time.Minute
+
+// Relative difference in object count or size between replicas above
+// which bucket stats are reported divergent.
+const statsDivergenceRatio = 0.01
+
+// remoteStats is the object count and total size of a replica.
+type remoteStats struct {
+	Endpoint string `json:"endpoint"`
+	Bucket   string `json:"bucket"`
+	Objects  int64  `json:"objects"`
+	Size     int64  `json:"size"`
+	Error    string `json:"error,omitempty"`
+}
+
+// bucketStats summarizes the contents of all replicas of a bucket.
+type bucketStats struct {
+	Bucket    string        `json:"bucket"`
+	Updated   time.Time     `json:"updated"`
+	Divergent bool          `json:"divergent"`
+	Remotes   []remoteStats `json:"remotes"`
+}
+
+// statsCache keeps bucket stats for a TTL, as computing them requires
+// listing every replica.
+type statsCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]bucketStats
+}
+
+func newStatsCache(ttl time.Duration) *statsCache {
+	if ttl <= 0 {
+		ttl = defaultStatsTTL
+	}
+	return &statsCache{
+		ttl:     ttl,
+		entries: make(map[string]bucketStats),
+	}
+}
+
+// BucketStatsHandler - reports the object count and total size of each
+// replica of a bucket, computed by listing the replicas and cached.
+// Requests with Cache-Control: no-cache recompute the stats.
+func BucketStatsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "BucketStats")
+
+	bucket := r.URL.Query().Get("bucket")
+
+	if s3Error := checkRequestAuthType(ctx, r, policy.ListBucketAction, bucket, ""); s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+		return
+	}
+
+	l, ok := newObjectLayerFn().(*radioObjects)
+	if !ok {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+	rs3s, ok := l.mirrorClients[bucket]
+	if !ok {
+		writeErrorResponse(ctx, w, toAPIError(ctx, BucketNotFound{Bucket: bucket}), r.URL)
+		return
+	}
+
+	stats := l.statsCache.get(withListCacheControl(ctx, r), bucket, rs3s)
+	data, err := json.Marshal(stats)
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	writeResponse(w, http.StatusOK, data, mimeJSON)
+}
+
+// get returns the cached stats of bucket, computing them if missing,
+// expired or if ctx bypasses the cache.
+func (c *statsCache) get(ctx context.Context, bucket string, rs3s mirrorConfig) bucketStats {
+	c.mu.Lock()
+	stats, ok := c.entries[bucket]
+	c.mu.Unlock()
+	if ok && !isListNoCache(ctx) && UTCNow().Before(stats.Updated.Add(c.ttl)) {
+		return stats
+	}
+
+	stats = computeBucketStats(ctx, bucket, rs3s)
+	c.mu.Lock()
+	c.entries[bucket] = stats
+	c.mu.Unlock()
+	return stats
+}
+
+// computeBucketStats lists all replicas of bucket in parallel.
+func computeBucketStats(ctx context.Context, bucket string, rs3s mirrorConfig) bucketStats {
+	stats := bucketStats{
+		Bucket:  bucket,
+		Updated: UTCNow(),
+		Remotes: make([]remoteStats, len(rs3s.clnts)),
+	}
+
+	var wg sync.WaitGroup
+	for index, clnt := range rs3s.clnts {
+		wg.Add(1)
+		go func(index int, clnt bucketClient) {
+			defer wg.Done()
+			rstats := remoteStats{
+				Endpoint: clnt.EndpointURL().Host,
+				Bucket:   clnt.Bucket,
+			}
+			doneCh := make(chan struct{})
+			defer close(doneCh)
+			for oi := range clnt.listObjects(clnt.Prefix, doneCh) {
+				if oi.Err == nil {
+					oi.Err = ctx.Err()
+				}
+				if oi.Err != nil {
+					rstats.Error = oi.Err.Error()
+					break
+				}
+				rstats.Objects++
+				rstats.Size += oi.Size
+			}
+			stats.Remotes[index] = rstats
+		}(index, clnt)
+	}
+	wg.Wait()
+
+	stats.Divergent = statsDiverge(stats.Remotes)
+	return stats
+}
+
+// statsDiverge returns true if the object counts or sizes of the
+// listed replicas differ by more than statsDivergenceRatio.
+func statsDiverge(remotes []remoteStats) bool {
+	var minObjects, maxObjects, minSize, maxSize int64
+	first := true
+	for _, r := range remotes {
+		if r.Error != "" {
+			continue
+		}
+		if first {
+			minObjects, maxObjects, minSize, maxSize = r.Objects, r.Objects, r.Size, r.Size
+			first = false
+			continue
+		}
+		minObjects, maxObjects = minInt64(minObjects, r.Objects), maxInt64(maxObjects, r.Objects)
+		minSize, maxSize = minInt64(minSize, r.Size), maxInt64(maxSize, r.Size)
+	}
+	return float64(maxObjects-minObjects) > statsDivergenceRatio*float64(maxObjects) ||
+		float64(maxSize-minSize) > statsDivergenceRatio*float64(maxSize)
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
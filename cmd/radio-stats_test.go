@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+// Tests that bucket stats report the object count and size of each
+// replica, and are served from the cache unless bypassed.
+func TestBucketStatsHandler(t *testing.T) {
+	remotes, clnts, shutdown := newTestRemotes(t, 2)
+	defer shutdown()
+	remotes[0].putObject("a", "a", nil)
+	remotes[0].putObject("b", "bb", nil)
+	remotes[1].putObject("a", "a", nil)
+	l := &radioObjects{
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+		statsCache:    newStatsCache(0),
+	}
+	defer setRadioAdminTestLayer(l)()
+
+	query := url.Values{"bucket": {"bucket"}}
+	testRadioAdminAuth(t, http.MethodGet, radioStatsPath, query)
+
+	rec := serveRadioAdminTestRequest(newRadioAdminTestRequest(t, http.MethodGet, radioStatsPath,
+		url.Values{"bucket": {"missing"}}, radioAdminTestSecretKey))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for a missing bucket, got %d", http.StatusNotFound, rec.Code)
+	}
+
+	getStats := func(noCache bool) bucketStats {
+		t.Helper()
+		req := newRadioAdminTestRequest(t, http.MethodGet, radioStatsPath, query, radioAdminTestSecretKey)
+		if noCache {
+			req.Header.Set("Cache-Control", "no-cache")
+		}
+		rec := serveRadioAdminTestRequest(req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body)
+		}
+		if contentType := rec.Header().Get("Content-Type"); contentType != string(mimeJSON) {
+			t.Errorf("Expected content type %s, got %s", mimeJSON, contentType)
+		}
+		var stats bucketStats
+		if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+			t.Fatalf("Invalid stats %q: %v", rec.Body, err)
+		}
+		return stats
+	}
+
+	stats := getStats(false)
+	if stats.Bucket != "bucket" || !stats.Divergent || len(stats.Remotes) != 2 {
+		t.Fatalf("Expected divergent stats of 2 replicas, got %+v", stats)
+	}
+	for i, expected := range []remoteStats{{Objects: 2, Size: 3}, {Objects: 1, Size: 1}} {
+		r := stats.Remotes[i]
+		if r.Objects != expected.Objects || r.Size != expected.Size || r.Error != "" ||
+			r.Bucket != testRemoteBucket || r.Endpoint != clnts[i].EndpointURL().Host {
+			t.Errorf("Replica %d: expected %d objects of %d bytes, got %+v", i, expected.Objects, expected.Size, r)
+		}
+	}
+
+	remotes[1].putObject("b", "bb", nil)
+	if stats = getStats(false); !stats.Divergent {
+		t.Errorf("Expected cached stats, got %+v", stats)
+	}
+	if stats = getStats(true); stats.Divergent || stats.Remotes[1].Objects != 2 {
+		t.Errorf("Expected recomputed stats, got %+v", stats)
+	}
+}
+
+// Tests that replicas differing by more than the divergence ratio are
+// reported divergent, ignoring replicas which failed to be listed.
+func TestStatsDiverge(t *testing.T) {
+	testCases := []struct {
+		remotes   []remoteStats
+		divergent bool
+	}{
+		{[]remoteStats{{Objects: 1000, Size: 1000}, {Objects: 1000, Size: 1000}}, false},
+		{[]remoteStats{{Objects: 1000, Size: 1000}, {Objects: 995, Size: 995}}, false},
+		{[]remoteStats{{Objects: 1000, Size: 1000}, {Objects: 980, Size: 1000}}, true},
+		{[]remoteStats{{Objects: 1000, Size: 1000}, {Objects: 1000, Size: 900}}, true},
+		{[]remoteStats{{Objects: 1000, Size: 1000}, {Error: "AccessDenied"}}, false},
+		{nil, false},
+	}
+	for i, testCase := range testCases {
+		if divergent := statsDiverge(testCase.remotes); divergent != testCase.divergent {
+			t.Errorf("Test %d: expected divergent %v, got %v", i+1, testCase.divergent, divergent)
+		}
+	}
+}
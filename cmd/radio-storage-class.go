@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	miniogo "github.com/minio/minio-go/v6"
+	xhttp "github.com/minio/radio/cmd/http"
+	"github.com/minio/radio/cmd/logger"
+)
+
+// Storage classes accepted on writes and as bucket defaults.
+var storageClasses = map[string]bool{
+	"STANDARD":            true,
+	"REDUCED_REDUNDANCY":  true,
+	"STANDARD_IA":         true,
+	"ONEZONE_IA":          true,
+	"INTELLIGENT_TIERING": true,
+	"GLACIER":             true,
+	"DEEP_ARCHIVE":        true,
+}
+
+// What to do when a remote rejects the storage class of a write.
+const (
+	// Fail the write to that remote.
+	storageClassFallbackError = "error"
+	// Write to that remote in its default storage class instead.
+	storageClassFallbackDowngrade = "downgrade"
+)
+
+// parseStorageClass validates the default storage class of bucket and
+// the fallback used by remotes rejecting a storage class.
+func parseStorageClass(bucket, class, fallback string) (string, bool, error) {
+	if class != "" && !storageClasses[class] {
+		return "", false, fmt.Errorf("bucket %s: unknown storage class %q", bucket, class)
+	}
+	switch fallback {
+	case "", storageClassFallbackError:
+		return class, false, nil
+	case storageClassFallbackDowngrade:
+		return class, true, nil
+	}
+	return "", false, fmt.Errorf("bucket %s: unknown storage class fallback %q", bucket, fallback)
+}
+
+// isStorageClassKey returns true if the metadata key sets the storage
+// class of an object.
+func isStorageClassKey(key string) bool {
+	return strings.EqualFold(key, xhttp.AmzStorageClass)
+}
+
+// checkStorageClass returns an error if metadata sets an unknown
+// storage class.
+func checkStorageClass(metadata map[string]string) error {
+	for k, v := range metadata {
+		if isStorageClassKey(k) && !storageClasses[v] {
+			return InvalidStorageClass{StorageClass: v}
+		}
+	}
+	return nil
+}
+
+// extractStorageClassHeader copies the storage class header of h into
+// metadata, dropping any storage class already set there.
+func extractStorageClassHeader(h http.Header, metadata map[string]string) error {
+	for k := range metadata {
+		if isStorageClassKey(k) {
+			delete(metadata, k)
+		}
+	}
+	if v := h.Get(xhttp.AmzStorageClass); v != "" {
+		metadata[xhttp.AmzStorageClass] = v
+	}
+	return checkStorageClass(metadata)
+}
+
+// withDefaultStorageClass returns metadata with the storage class set,
+// unless class is empty or metadata already sets a storage class.
+func withDefaultStorageClass(metadata map[string]string, class string) map[string]string {
+	if class == "" {
+		return metadata
+	}
+	for k := range metadata {
+		if isStorageClassKey(k) {
+			return metadata
+		}
+	}
+	withClass := make(map[string]string, len(metadata)+1)
+	for k, v := range metadata {
+		withClass[k] = v
+	}
+	withClass[xhttp.AmzStorageClass] = class
+	return withClass
+}
+
+// storageClassDowngrades records the storage classes a remote rejected,
+// writes in these classes land in the default class of the remote.
+type storageClassDowngrades struct {
+	mu       sync.RWMutex
+	rejected map[string]bool
+}
+
+func newStorageClassDowngrades() *storageClassDowngrades {
+	return &storageClassDowngrades{rejected: make(map[string]bool)}
+}
+
+// storageClassMetadata returns the metadata of a write to this remote,
+// without the storage class if the remote rejected it before.
+func (c bucketClient) storageClassMetadata(metadata map[string]string) map[string]string {
+	if c.downgrades == nil {
+		return metadata
+	}
+	c.downgrades.mu.RLock()
+	defer c.downgrades.mu.RUnlock()
+	m := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		if !isStorageClassKey(k) || !c.downgrades.rejected[v] {
+			m[k] = v
+		}
+	}
+	return m
+}
+
+// downgradeStorageClass records the storage class of metadata as
+// unsupported by this remote when err rejects it, it returns true if
+// writes in that class are to be downgraded from now on.
+func (c bucketClient) downgradeStorageClass(ctx context.Context, metadata map[string]string, err error) bool {
+	if c.downgrades == nil || miniogo.ToErrorResponse(err).Code != "InvalidStorageClass" {
+		return false
+	}
+	for k, v := range metadata {
+		if !isStorageClassKey(k) {
+			continue
+		}
+		c.downgrades.mu.Lock()
+		defer c.downgrades.mu.Unlock()
+		if !c.downgrades.rejected[v] {
+			c.downgrades.rejected[v] = true
+			logger.LogIf(ctx, fmt.Errorf("remote %s/%s rejected storage class %s, downgrading to its default class",
+				c.EndpointURL().Host, c.Bucket, v))
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	miniogo "github.com/minio/minio-go/v6"
+)
+
+// Tests that the bucket default storage class only applies to objects
+// written without a storage class.
+func TestWithDefaultStorageClass(t *testing.T) {
+	testCases := []struct {
+		metadata      map[string]string
+		class         string
+		expectedClass string
+	}{
+		{nil, "", ""},
+		{nil, "STANDARD_IA", "STANDARD_IA"},
+		{map[string]string{"x-amz-storage-class": "GLACIER"}, "STANDARD_IA", "GLACIER"},
+		{map[string]string{"Content-Type": "text/plain"}, "ONEZONE_IA", "ONEZONE_IA"},
+	}
+
+	for i, testCase := range testCases {
+		metadata := ToMinioClientMetadata(withDefaultStorageClass(testCase.metadata, testCase.class))
+		if metadata["X-Amz-Storage-Class"] != testCase.expectedClass {
+			t.Errorf("Test %d: Expected storage class %q, got %q", i+1, testCase.expectedClass, metadata["X-Amz-Storage-Class"])
+		}
+	}
+}
+
+// Tests that unknown storage classes are rejected.
+func TestCheckStorageClass(t *testing.T) {
+	testCases := []struct {
+		metadata    map[string]string
+		expectedErr error
+	}{
+		{map[string]string{}, nil},
+		{map[string]string{"x-amz-storage-class": "STANDARD"}, nil},
+		{map[string]string{"X-Amz-Storage-Class": "DEEP_ARCHIVE"}, nil},
+		{map[string]string{"x-amz-storage-class": "glacier"}, InvalidStorageClass{StorageClass: "glacier"}},
+		{map[string]string{"x-amz-storage-class": "COLD"}, InvalidStorageClass{StorageClass: "COLD"}},
+	}
+
+	for i, testCase := range testCases {
+		if err := checkStorageClass(testCase.metadata); err != testCase.expectedErr {
+			t.Errorf("Test %d: Expected error %v, got %v", i+1, testCase.expectedErr, err)
+		}
+	}
+}
+
+// Tests that the storage class of a copy is the one of the request.
+func TestExtractStorageClassHeader(t *testing.T) {
+	testCases := []struct {
+		header        http.Header
+		expectedClass string
+	}{
+		{http.Header{}, ""},
+		{http.Header{"X-Amz-Storage-Class": []string{"STANDARD_IA"}}, "STANDARD_IA"},
+	}
+
+	for i, testCase := range testCases {
+		metadata := map[string]string{"x-amz-storage-class": "GLACIER"}
+		if err := extractStorageClassHeader(testCase.header, metadata); err != nil {
+			t.Errorf("Test %d: Unexpected error %v", i+1, err)
+		}
+		metadata = ToMinioClientMetadata(metadata)
+		if metadata["X-Amz-Storage-Class"] != testCase.expectedClass {
+			t.Errorf("Test %d: Expected storage class %q, got %q", i+1, testCase.expectedClass, metadata["X-Amz-Storage-Class"])
+		}
+	}
+}
+
+// Tests that storage classes rejected by a remote are only dropped from
+// later writes when the bucket downgrades them.
+func TestDowngradeStorageClass(t *testing.T) {
+	metadata := map[string]string{"X-Amz-Storage-Class": "GLACIER", "Content-Type": "text/plain"}
+	rejected := miniogo.ErrorResponse{Code: "InvalidStorageClass"}
+
+	core, err := miniogo.NewCore("localhost:9000", "minio", "minio123", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clnt := bucketClient{Core: core, Bucket: "remote"}
+	if clnt.downgradeStorageClass(context.Background(), metadata, rejected) {
+		t.Errorf("Expected no downgrade without storage class fallback")
+	}
+
+	clnt.downgrades = newStorageClassDowngrades()
+	if clnt.downgradeStorageClass(context.Background(), metadata, miniogo.ErrorResponse{Code: "AccessDenied"}) {
+		t.Errorf("Expected no downgrade of other errors")
+	}
+	if _, ok := clnt.storageClassMetadata(metadata)["X-Amz-Storage-Class"]; !ok {
+		t.Errorf("Expected storage class to be kept before rejection")
+	}
+	if !clnt.downgradeStorageClass(context.Background(), metadata, rejected) {
+		t.Errorf("Expected rejected storage class to be downgraded")
+	}
+	downgraded := clnt.storageClassMetadata(metadata)
+	if _, ok := downgraded["X-Amz-Storage-Class"]; ok {
+		t.Errorf("Expected storage class to be dropped after rejection")
+	}
+	if downgraded["Content-Type"] != "text/plain" {
+		t.Errorf("Expected content type to be kept, got %q", downgraded["Content-Type"])
+	}
+	metadata["X-Amz-Storage-Class"] = "STANDARD_IA"
+	if _, ok := clnt.storageClassMetadata(metadata)["X-Amz-Storage-Class"]; !ok {
+		t.Errorf("Expected other storage classes to be kept")
+	}
+}
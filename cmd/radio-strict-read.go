@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"context"
+
+	miniogo "github.com/minio/minio-go/v6"
+)
+
+// checkReplicaConflict returns ReplicaConflict if the replicas stated in
+// oinfos and errs, indexed like replicas, hold different versions of
+// object. The newest version is then journaled for heal onto the other
+// replicas, unless a heal of object is already pending. Replicas not
+// holding the object are left to the read quorum.
+func (l *radioObjects) checkReplicaConflict(ctx context.Context, bucket, object string, replicas []int,
+	oinfos []miniogo.ObjectInfo, errs []error) error {
+	src := newestReplica(oinfos, errs)
+	if src < 0 {
+		return nil
+	}
+	radioTag := oinfos[src].Metadata.Get(globalRadioTagKey)
+	conflict := false
+	var dsts []int
+	for i := range oinfos {
+		if errs[i] != nil || oinfos[i].Metadata.Get(globalRadioTagKey) == radioTag {
+			continue
+		}
+		conflict = true
+		if !l.mirrorClients[bucket].clnts[replicas[i]].ReadOnly {
+			dsts = append(dsts, replicas[i])
+		}
+	}
+	if !conflict {
+		return nil
+	}
+	if _, ok := l.healSys.pendingWrite(ctx, bucket, object); !ok {
+		l.healSys.queue(ctx, journalEntry{
+			Bucket:       bucket,
+			Object:       object,
+			Op:           opPutObject,
+			RadioTag:     radioTag,
+			SrcClientID:  replicas[src],
+			DstClientIDs: dsts,
+		})
+	}
+	return ReplicaConflict{Bucket: bucket, Object: object}
+}
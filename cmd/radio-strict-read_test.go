@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+// Tests that strict reads fail on replicas holding different versions,
+// journaling a heal of the newest version.
+func TestStrictReads(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		radioTags       []string
+		strictReads     bool
+		pending         bool
+		expectedErr     error
+		expectedEntries int
+	}{
+		{[]string{"v2", "v2"}, true, false, nil, 0},
+		{[]string{"v2", "v1"}, true, false, ReplicaConflict{Bucket: "bucket", Object: "object"}, 1},
+		// A heal is already pending, nothing more is journaled.
+		{[]string{"v2", "v1"}, true, true, ReplicaConflict{Bucket: "bucket", Object: "object"}, 1},
+		// Replicas missing the object are left to the read quorum.
+		{[]string{"v2", "v2", ""}, true, false, nil, 0},
+		{[]string{"v2", "v2", "v1"}, false, false, nil, 0},
+	}
+
+	for i, testCase := range testCases {
+		dir, err := ioutil.TempDir("", "radio-journal")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		store := &dirJournalStore{dir: dir}
+		if testCase.pending {
+			if err = store.Save(journalEntry{ID: "1", Bucket: "bucket", Object: "object", Op: opPutObject,
+				RadioTag: "v2", SrcClientID: 0, DstClientIDs: []int{1}, Timestamp: now}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		var replicas []testReplica
+		for index, radioTag := range testCase.radioTags {
+			replicas = append(replicas, testReplica{radioTag, now.Add(-time.Duration(index) * time.Hour)})
+		}
+		_, clnts, shutdown := newTestReplicas(t, replicas...)
+		defer shutdown()
+		l := &radioObjects{
+			nsMutex:       newNSLock(false),
+			mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts, strictReads: testCase.strictReads}},
+		}
+		if l.healSys, err = newHealSys(l, store, journalConfig{}, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = l.GetObjectInfo(context.Background(), "bucket", "object", ObjectOptions{})
+		if err != testCase.expectedErr {
+			t.Errorf("Test %d: Expected error %v, got %v", i+1, testCase.expectedErr, err)
+		}
+		entries, err := store.List()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != testCase.expectedEntries {
+			t.Errorf("Test %d: Expected %d journal entries, got %d", i+1, testCase.expectedEntries, len(entries))
+			continue
+		}
+		for _, entry := range entries {
+			if entry.RadioTag != "v2" || entry.SrcClientID != 0 || len(entry.DstClientIDs) != 1 || entry.DstClientIDs[0] != 1 {
+				t.Errorf("Test %d: Unexpected journal entry %+v", i+1, entry)
+			}
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/minio/minio-go/v6/pkg/encrypt"
+	xnet "github.com/minio/minio/pkg/net"
+	"github.com/minio/radio/cmd/logger"
+)
+
+// syncReplicas copies object from the replica src onto the writable
+// replicas whose write failed although they were reachable, for buckets
+// with synchronous writes, so that the write returns once every online
+// replica holds the object. The errors of the replicas copied to are
+// cleared, replicas failing on the network or failing the copy, and
+// async replicas, are left to heal.
+func (m mirrorConfig) syncReplicas(ctx context.Context, bucket, object string, src int, errs []error, sse encrypt.ServerSide) {
+	if !m.syncWrites {
+		return
+	}
+	for _, index := range failedReplicas(errs) {
+		if m.clnts[index].Shadow || errs[index] == errAsyncReplica || xnet.IsNetworkOrHostDown(errs[index]) {
+			continue
+		}
+		if err := healObjectCopy(ctx, m.clnts[src], m.clnts[index], object, sse); err != nil {
+			logger.LogIf(ctx, err)
+			continue
+		}
+		errs[index] = nil
+	}
+}
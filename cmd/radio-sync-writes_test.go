@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/minio/minio/pkg/hash"
+)
+
+// failTestPuts fails the first n uploads of objects to remote with an
+// access denied error.
+func failTestPuts(remote *testRemote, n int32) {
+	remote.setHook(func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method != http.MethodPut || atomic.AddInt32(&n, -1) < 0 {
+			return false
+		}
+		ioutil.ReadAll(r.Body)
+		writeTestRemoteError(w, r, http.StatusForbidden, "AccessDenied")
+		return true
+	})
+}
+
+// Tests that synchronous writes copy the object onto the reachable
+// replicas which failed the write instead of journaling them.
+func TestSyncWrites(t *testing.T) {
+	for _, syncWrites := range []bool{false, true} {
+		dir, err := ioutil.TempDir("", "radio-journal")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		store := &dirJournalStore{dir: dir}
+
+		remotes, clnts, shutdown := newTestRemotes(t, 4)
+		defer shutdown()
+		failTestPuts(remotes[3], 1)
+
+		l := &radioObjects{
+			nsMutex:       newNSLock(false),
+			mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts, syncWrites: syncWrites}},
+		}
+		if l.healSys, err = newHealSys(l, store, journalConfig{}, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		data := []byte("data")
+		reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), "", "", int64(len(data)), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = l.PutObject(context.Background(), "bucket", "object", NewPutObjReader(reader, nil, nil), ObjectOptions{}); err != nil {
+			t.Fatal(err)
+		}
+
+		if data, _, _ := remotes[3].object("object"); (data == "data") != syncWrites {
+			t.Errorf("Sync writes %v: expected the failed replica synced %v, got %q", syncWrites, syncWrites, data)
+		}
+		entries, err := store.List()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var healed []int
+		for _, entry := range entries {
+			healed = append(healed, entry.DstClientIDs...)
+		}
+		expected := "[3]"
+		if syncWrites {
+			expected = "[]"
+		}
+		if got := fmt.Sprint(healed); got != expected {
+			t.Errorf("Sync writes %v: expected heals onto %s, got %s", syncWrites, expected, got)
+		}
+	}
+}
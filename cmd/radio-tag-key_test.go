@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/minio/minio/pkg/hash"
+)
+
+// Tests that radio tags are stamped under the configured metadata key,
+// and that keys outside of the x-amz-meta- namespace are rejected.
+func TestRadioTagKey(t *testing.T) {
+	defer func(key string) { globalRadioTagKey = key }(globalRadioTagKey)
+
+	if err := lookupConfigEnv(radioConfig{RadioTag: "radio-tag"}); err == nil {
+		t.Fatal("Expected a radio tag key without the x-amz-meta- prefix to be rejected")
+	}
+	if globalRadioTagKey != globalRadioDefaultTagKey {
+		t.Fatalf("Expected the radio tag key %s to be kept, got %s", globalRadioDefaultTagKey, globalRadioTagKey)
+	}
+
+	remotes, clnts, shutdown := newTestRemotes(t, 2)
+	defer shutdown()
+	l := &radioObjects{
+		nsMutex:       newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+	}
+	globalRadioTagKey = "X-Amz-Meta-Custom-Tag"
+
+	data := []byte("hello")
+	reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), "", "", int64(len(data)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = l.PutObject(context.Background(), "bucket", "object", NewPutObjReader(reader, nil, nil),
+		ObjectOptions{UserDefined: map[string]string{}}); err != nil {
+		t.Fatal(err)
+	}
+	var tags []string
+	for i, remote := range remotes {
+		_, header, _ := remote.object("object")
+		if header.Get(globalRadioDefaultTagKey) != "" {
+			t.Errorf("Replica %d: unexpected radio tag under the default key", i)
+		}
+		tags = append(tags, header.Get("X-Amz-Meta-Custom-Tag"))
+	}
+	if tags[0] == "" || tags[0] != tags[1] {
+		t.Fatalf("Expected the replicas to share a radio tag under the configured key, got %q", tags)
+	}
+
+	info, err := l.GetObjectInfo(context.Background(), "bucket", "object", ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size != int64(len(data)) {
+		t.Errorf("Expected size %d, got %d", len(data), info.Size)
+	}
+}
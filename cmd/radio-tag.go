@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Sources of the radio tags of written objects.
+const (
+	// A random UUID for each write, the default.
+	radioTagSourceUUID = "uuid"
+	// The SHA256 of the data and metadata of each write.
+	radioTagSourceContent = "content"
+)
+
+// contentRadioTagPrefix marks radio tags derived from the content of an
+// object, which unlike random tags are shared by all writes of the same
+// content.
+const contentRadioTagPrefix = "sha256-"
+
+// parseRadioTagSource returns true if radio tags are derived from the
+// content of objects.
+func parseRadioTagSource(source string) (bool, error) {
+	switch source {
+	case "", radioTagSourceUUID:
+		return false, nil
+	case radioTagSourceContent:
+		return true, nil
+	}
+	return false, fmt.Errorf("unknown radio tag source %q, expected %s or %s",
+		source, radioTagSourceUUID, radioTagSourceContent)
+}
+
+// newRadioTag returns the radio tag of an object written with metadata
+// and data of SHA256 sha256Hex. Tags are random unless derived from the
+// content of objects, which requires the SHA256 of the data: server side
+// copies and multipart uploads, whose data radio does not read before
+// writing the replicas, are always tagged randomly.
+func (l *radioObjects) newRadioTag(sha256Hex string, metadata map[string]string) string {
+	if !l.contentRadioTags || sha256Hex == "" {
+		return mustGetUUID()
+	}
+	return contentRadioTag(sha256Hex, metadata)
+}
+
+// contentRadioTag derives a radio tag from the SHA256 of the data of an
+// object and its metadata, so that rewriting an object with the same
+// data but different metadata still changes its tag.
+func contentRadioTag(sha256Hex string, metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	values := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		key := strings.ToLower(k)
+		if http.CanonicalHeaderKey(k) == globalRadioTagKey {
+			continue
+		}
+		keys = append(keys, key)
+		values[key] = v
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n", strings.ToLower(sha256Hex))
+	for _, key := range keys {
+		fmt.Fprintf(h, "%s:%s\n", key, values[key])
+	}
+	return contentRadioTagPrefix + hex.EncodeToString(h.Sum(nil))
+}
+
+// isContentRadioTag returns true if radioTag was derived from the
+// content of an object rather than identifying a single write.
+func isContentRadioTag(radioTag string) bool {
+	return strings.HasPrefix(radioTag, contentRadioTagPrefix)
+}
+
+// bufferSHA256 copies r to a temporary file in dir, returning the file
+// rewound to its start along with the SHA256 of its content. Callers
+// close and remove the file.
+func bufferSHA256(r io.Reader, dir string) (*os.File, string, error) {
+	f, err := ioutil.TempFile(dir, "copy-")
+	if err != nil {
+		return nil, "", err
+	}
+	h := sha256.New()
+	if _, err = io.Copy(io.MultiWriter(f, h), r); err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, "", err
+	}
+	return f, hex.EncodeToString(h.Sum(nil)), nil
+}
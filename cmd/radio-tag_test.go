@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/minio/minio/pkg/hash"
+)
+
+// Tests that content derived radio tags are shared by identical uploads
+// only, and that tombstones tell them apart by modification time.
+func TestContentRadioTags(t *testing.T) {
+	if _, err := parseRadioTagSource("sha1"); err == nil {
+		t.Fatal("expected an error for an unknown radio tag source")
+	}
+	contentRadioTags, err := parseRadioTagSource(radioTagSourceContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remotes, clnts, shutdown := newTestRemotes(t, 1)
+	defer shutdown()
+	l := &radioObjects{
+		nsMutex:          newNSLock(false),
+		mirrorClients:    map[string]mirrorConfig{"bucket": {clnts: clnts}},
+		contentRadioTags: contentRadioTags,
+	}
+
+	data := []byte("data")
+	put := func(sha256Hex string, metadata map[string]string) {
+		reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), "", sha256Hex, int64(len(data)), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = l.PutObject(context.Background(), "bucket", "object", NewPutObjReader(reader, nil, nil),
+			ObjectOptions{UserDefined: metadata}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	const sha256Hex = "3a6eb0790f39ac87c94f3856b2dd2c5d110e6811602261a9a923d3bb23adc8b7"
+	put(sha256Hex, map[string]string{"X-Amz-Meta-Color": "red"})
+	put(sha256Hex, map[string]string{"x-amz-meta-color": "red"})
+	put(sha256Hex, map[string]string{"X-Amz-Meta-Color": "blue"})
+	put("", map[string]string{"X-Amz-Meta-Color": "red"})
+
+	tags := remotes[0].writtenTags()
+	if len(tags) != 4 || !isContentRadioTag(tags[0]) || tags[0] != tags[1] {
+		t.Fatalf("Expected identical uploads to share a content tag, got %v", tags)
+	}
+	if tags[2] == tags[0] {
+		t.Error("Expected a metadata change to change the tag")
+	}
+	if isContentRadioTag(tags[3]) {
+		t.Error("Expected a random tag without the data SHA256")
+	}
+
+	// A rewrite of the deleted content after the delete is not covered.
+	now := time.Now()
+	del := journalEntry{Op: opDeleteObject, RadioTag: tags[0], Timestamp: now}
+	if del.tombstoneCovers(tags[0], now.Add(time.Minute)) {
+		t.Error("Expected a later rewrite of the deleted content not to be covered")
+	}
+	if !del.tombstoneCovers(tags[0], now.Add(-time.Minute)) {
+		t.Error("Expected the deleted content to be covered")
+	}
+}
+
+// Tests that copies streamed through radio are tagged by content, while
+// server side copies and multipart uploads fall back to random tags.
+func TestContentRadioTagsCopy(t *testing.T) {
+	remotes, clnts, shutdown := newTestRemotes(t, 3)
+	defer shutdown()
+	l := &radioObjects{
+		nsMutex: newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{
+			"src":    {clnts: clnts[:1]},
+			"bucket": {clnts: clnts[1:]},
+		},
+		multipartUploadIDMap: make(map[string][]string),
+		contentRadioTags:     true,
+	}
+	ctx := context.Background()
+	data := []byte("data")
+	const sha256Hex = "3a6eb0790f39ac87c94f3856b2dd2c5d110e6811602261a9a923d3bb23adc8b7"
+	newReader := func(sha256Hex string) *PutObjReader {
+		reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), "", sha256Hex, int64(len(data)), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return NewPutObjReader(reader, nil, nil)
+	}
+	tag := func(object string) string {
+		t.Helper()
+		_, header, ok := remotes[1].object(object)
+		if !ok {
+			t.Fatalf("Expected %s to be written", object)
+		}
+		if _, other, _ := remotes[2].object(object); other.Get("X-Amz-Meta-Radio-Tag") != header.Get("X-Amz-Meta-Radio-Tag") {
+			t.Fatalf("Expected the replicas of %s to share a radio tag", object)
+		}
+		return header.Get("X-Amz-Meta-Radio-Tag")
+	}
+
+	metadata := map[string]string{"X-Amz-Meta-Color": "red"}
+	if _, err := l.PutObject(ctx, "bucket", "put", newReader(sha256Hex), ObjectOptions{UserDefined: metadata}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.PutObject(ctx, "src", "object", newReader(sha256Hex), ObjectOptions{UserDefined: metadata}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Replicas of src and bucket cannot be paired, the copy is streamed
+	// without the SHA256 of the data.
+	srcInfo := ObjectInfo{Bucket: "src", Name: "object", Size: int64(len(data)), UserDefined: metadata, PutObjReader: newReader("")}
+	if _, err := l.CopyObject(ctx, "src", "object", "bucket", "streamed", srcInfo, ObjectOptions{}, ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if put, streamed := tag("put"), tag("streamed"); !isContentRadioTag(streamed) || streamed != put {
+		t.Errorf("Expected the streamed copy to be tagged %s like an upload of the same content, got %s", put, streamed)
+	}
+
+	srcInfo, err := l.GetObjectInfo(ctx, "bucket", "put", ObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = l.CopyObject(ctx, "bucket", "put", "bucket", "copied", srcInfo, ObjectOptions{}, ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if copied := tag("copied"); copied == "" || isContentRadioTag(copied) {
+		t.Errorf("Expected the server side copy to be tagged randomly, got %q", copied)
+	}
+
+	if _, err = l.NewMultipartUpload(ctx, "bucket", "multipart", ObjectOptions{UserDefined: metadata}); err != nil {
+		t.Fatal(err)
+	}
+	if len(remotes[1].uploads) != 1 {
+		t.Fatalf("Expected 1 multipart upload, got %d", len(remotes[1].uploads))
+	}
+	for _, upload := range remotes[1].uploads {
+		if radioTag := upload.header.Get("X-Amz-Meta-Radio-Tag"); radioTag == "" || isContentRadioTag(radioTag) {
+			t.Errorf("Expected the multipart upload to be tagged randomly, got %q", radioTag)
+		}
+	}
+}
@@ -0,0 +1,903 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/minio-go/v6/pkg/credentials"
+)
+
+// testRemoteBucket is the bucket served by test remotes.
+const testRemoteBucket = "remote"
+
+// testRemote is an in-memory S3 remote holding the objects of a single
+// bucket, served to minio-go clients so that tests of the object layer
+// see the requests radio sends. Hook, if set, is called first on every
+// request and answers the requests it returns true for, to inject
+// failures.
+type testRemote struct {
+	mu      sync.Mutex
+	objects map[string]*testRemoteObject
+	uploads map[string]*testRemoteUpload
+	nextID  int
+	hook    func(w http.ResponseWriter, r *http.Request) bool
+	// Bytes of object and part data uploaded.
+	received int
+	// Requests received by method, and the radio tags of the objects
+	// written by them in order.
+	methods map[string]int
+	tags    []string
+}
+
+// testRemoteObject is an object of a testRemote, header holds its user
+// metadata and content headers.
+type testRemoteObject struct {
+	data    []byte
+	header  http.Header
+	modTime time.Time
+	etag    string
+}
+
+type testRemoteUpload struct {
+	object string
+	header http.Header
+	parts  map[int][]byte
+}
+
+// newTestRemotes starts n test remotes, returning them along with the
+// clients of their bucket and a function shutting them down.
+func newTestRemotes(t *testing.T, n int) ([]*testRemote, []bucketClient, func()) {
+	return startTestRemotes(t, n, false)
+}
+
+// newTLSTestRemotes is newTestRemotes serving https.
+func newTLSTestRemotes(t *testing.T, n int) ([]*testRemote, []bucketClient, func()) {
+	return startTestRemotes(t, n, true)
+}
+
+// testReplica is a replica of an object tagged radioTag and last
+// modified at modTime, missing if radioTag is empty.
+type testReplica struct {
+	radioTag string
+	modTime  time.Time
+}
+
+// newTestReplicas is newTestRemotes holding replicas of "object", of
+// data "data", on each remote in turn.
+func newTestReplicas(t *testing.T, replicas ...testReplica) ([]*testRemote, []bucketClient, func()) {
+	remotes, clnts, shutdown := newTestRemotes(t, len(replicas))
+	for i, replica := range replicas {
+		if replica.radioTag != "" {
+			remotes[i].putTaggedObject("object", "data", replica.radioTag, replica.modTime)
+		}
+	}
+	return remotes, clnts, shutdown
+}
+
+func startTestRemotes(t *testing.T, n int, secure bool) ([]*testRemote, []bucketClient, func()) {
+	var remotes []*testRemote
+	var clnts []bucketClient
+	var servers []*httptest.Server
+	for i := 0; i < n; i++ {
+		remote := &testRemote{
+			objects: make(map[string]*testRemoteObject),
+			uploads: make(map[string]*testRemoteUpload),
+			methods: make(map[string]int),
+		}
+		var ts *httptest.Server
+		if secure {
+			ts = httptest.NewTLSServer(remote)
+		} else {
+			ts = httptest.NewServer(remote)
+		}
+		servers = append(servers, ts)
+		remotes = append(remotes, remote)
+		clnts = append(clnts, bucketClient{Core: newTestRemoteCore(t, ts), Bucket: testRemoteBucket})
+	}
+	return remotes, clnts, func() {
+		for _, ts := range servers {
+			ts.Close()
+		}
+	}
+}
+
+func newTestRemoteCore(t *testing.T, ts *httptest.Server) *miniogo.Core {
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clnt, err := miniogo.NewWithOptions(u.Host, &miniogo.Options{
+		Creds:  credentials.NewStaticV4("accesskey", "secretkey", ""),
+		Secure: u.Scheme == "https",
+		Region: "us-east-1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts.TLS != nil {
+		clnt.SetCustomTransport(ts.Client().Transport)
+	}
+	return &miniogo.Core{Client: clnt}
+}
+
+// setHook sets the hook of s.
+func (s *testRemote) setHook(hook func(w http.ResponseWriter, r *http.Request) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hook = hook
+}
+
+// deny fails all requests to s with an access denied error until its
+// hook is reset.
+func (s *testRemote) deny() {
+	s.setHook(func(w http.ResponseWriter, r *http.Request) bool {
+		writeTestRemoteError(w, r, http.StatusForbidden, "AccessDenied")
+		return true
+	})
+}
+
+// putObject stores data under key along with metadata.
+func (s *testRemote) putObject(key, data string, metadata map[string]string) {
+	s.putObjectAt(key, data, metadata, time.Now())
+}
+
+// putObjectAt is putObject for an object last modified at modTime.
+func (s *testRemote) putObjectAt(key, data string, metadata map[string]string, modTime time.Time) {
+	header := make(http.Header)
+	for k, v := range metadata {
+		header.Set(k, v)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store(key, []byte(data), header).modTime = modTime.UTC()
+}
+
+// putTaggedObject stores data under key tagged radioTag, last modified
+// at modTime.
+func (s *testRemote) putTaggedObject(key, data, radioTag string, modTime time.Time) {
+	s.putObjectAt(key, data, map[string]string{globalRadioTagKey: radioTag}, modTime)
+}
+
+// requests returns the number of requests of method received by s.
+func (s *testRemote) requests(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.methods[method]
+}
+
+// writtenTags returns the radio tags of the objects written to s by
+// requests, in order.
+func (s *testRemote) writtenTags() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.tags...)
+}
+
+// object returns the data and metadata stored under key, false if
+// there is no such object.
+func (s *testRemote) object(key string) (string, http.Header, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, ok := s.objects[key]
+	if !ok {
+		return "", nil, false
+	}
+	return string(obj.data), obj.header.Clone(), true
+}
+
+// bytesReceived returns the bytes of object and part data uploaded to
+// s, copies excluded.
+func (s *testRemote) bytesReceived() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.received
+}
+
+// uploadIDs returns the sorted IDs of the multipart uploads in progress
+// on s.
+func (s *testRemote) uploadIDs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]string, 0, len(s.uploads))
+	for id := range s.uploads {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// keys returns the sorted keys of the objects of s.
+func (s *testRemote) keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sortedKeys()
+}
+
+func (s *testRemote) sortedKeys() []string {
+	keys := make([]string, 0, len(s.objects))
+	for key := range s.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *testRemote) store(key string, data []byte, header http.Header) *testRemoteObject {
+	sum := md5.Sum(data)
+	obj := &testRemoteObject{
+		data:    data,
+		header:  header,
+		modTime: time.Now().UTC(),
+		etag:    `"` + hex.EncodeToString(sum[:]) + `"`,
+	}
+	s.objects[key] = obj
+	return obj
+}
+
+// write stores an object written by a request.
+func (s *testRemote) write(key string, data []byte, header http.Header) *testRemoteObject {
+	s.tags = append(s.tags, header.Get(globalRadioTagKey))
+	return s.store(key, data, header)
+}
+
+// Headers of SSE-C requests, objects encrypted with a customer key keep
+// its MD5 sum.
+const (
+	testRemoteSSECAlgorithm  = "X-Amz-Server-Side-Encryption-Customer-Algorithm"
+	testRemoteSSECKeyMD5     = "X-Amz-Server-Side-Encryption-Customer-Key-Md5"
+	testRemoteCopySSECKeyMD5 = "X-Amz-Copy-Source-Server-Side-Encryption-Customer-Key-Md5"
+)
+
+// Headers of requests kept as object metadata.
+func testRemoteMetadata(h http.Header) http.Header {
+	header := make(http.Header)
+	for k, v := range h {
+		switch key := http.CanonicalHeaderKey(k); {
+		case strings.HasPrefix(key, "X-Amz-Meta-"), key == "Content-Type", key == "Content-Encoding",
+			key == "Content-Disposition", key == "Content-Language", key == "Cache-Control", key == "Expires",
+			key == "X-Amz-Storage-Class", key == testRemoteSSECAlgorithm, key == testRemoteSSECKeyMD5:
+			header[key] = v
+		}
+	}
+	return header
+}
+
+type testRemoteError struct {
+	XMLName   xml.Name `xml:"Error"`
+	Code      string   `xml:"Code"`
+	Message   string   `xml:"Message"`
+	Resource  string   `xml:"Resource"`
+	RequestID string   `xml:"RequestId"`
+}
+
+// writeTestRemoteError writes an S3 error response.
+func writeTestRemoteError(w http.ResponseWriter, r *http.Request, status int, code string) {
+	if r.Method == http.MethodHead {
+		w.WriteHeader(status)
+		return
+	}
+	data, err := xml.Marshal(testRemoteError{Code: code, Message: code, Resource: r.URL.Path, RequestID: "1"})
+	if err != nil {
+		panic(err)
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+func writeTestRemoteXML(w http.ResponseWriter, v interface{}) {
+	data, err := xml.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.Write(data)
+}
+
+// readTestRemoteBody returns the payload of r, decoding chunk signed
+// uploads.
+func readTestRemoteBody(r *http.Request) []byte {
+	body, _ := ioutil.ReadAll(r.Body)
+	if r.Header.Get("X-Amz-Decoded-Content-Length") == "" {
+		return body
+	}
+	var data []byte
+	for len(body) > 0 {
+		i := bytes.Index(body, []byte("\r\n"))
+		if i < 0 {
+			break
+		}
+		header := string(body[:i])
+		if j := strings.IndexByte(header, ';'); j >= 0 {
+			header = header[:j]
+		}
+		n, err := strconv.ParseInt(header, 16, 64)
+		if err != nil || n == 0 {
+			break
+		}
+		body = body[i+2:]
+		data = append(data, body[:n]...)
+		body = body[n+2:]
+	}
+	return data
+}
+
+// readTestRemoteData returns the uploaded data of r, failing r if it
+// does not match its Content-MD5.
+func readTestRemoteData(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	data := readTestRemoteBody(r)
+	sum := md5.Sum(data)
+	if contentMD5 := r.Header.Get("Content-Md5"); contentMD5 != "" && contentMD5 != base64.StdEncoding.EncodeToString(sum[:]) {
+		writeTestRemoteError(w, r, http.StatusBadRequest, "BadDigest")
+		return nil, false
+	}
+	return data, true
+}
+
+func (s *testRemote) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.methods[r.Method]++
+	hook := s.hook
+	s.mu.Unlock()
+	if hook != nil && hook(w, r) {
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	if path != testRemoteBucket && !strings.HasPrefix(path, testRemoteBucket+"/") {
+		writeTestRemoteError(w, r, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+	key := strings.TrimPrefix(strings.TrimPrefix(path, testRemoteBucket), "/")
+
+	s.mu.Lock()
+	data := s.serve(w, r, key, r.URL.Query())
+	s.mu.Unlock()
+	// Objects are sent unlocked, clients may send other requests while
+	// reading them.
+	w.Write(data)
+}
+
+// serve handles r for key, returning the object data to send.
+func (s *testRemote) serve(w http.ResponseWriter, r *http.Request, key string, query url.Values) []byte {
+	switch {
+	case key == "":
+		s.serveBucket(w, r, query)
+	case query.Get("uploadId") != "" || query["uploads"] != nil:
+		s.serveUpload(w, r, key, query)
+	case r.Method == http.MethodPost && query["restore"] != nil:
+		s.restoreObject(w, r, key)
+	case len(query) > 0 && query.Get("versionId") == "":
+		// Subresources such as ACLs are not supported.
+		writeTestRemoteError(w, r, http.StatusNotImplemented, "NotImplemented")
+	case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+		s.copyObject(w, r, key)
+	case r.Method == http.MethodPut:
+		data, ok := readTestRemoteData(w, r)
+		if !ok {
+			return nil
+		}
+		s.received += len(data)
+		obj := s.write(key, data, testRemoteMetadata(r.Header))
+		w.Header().Set("ETag", obj.etag)
+	case r.Method == http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		return s.getObject(w, r, key)
+	default:
+		writeTestRemoteError(w, r, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+	return nil
+}
+
+func (s *testRemote) getObject(w http.ResponseWriter, r *http.Request, key string) []byte {
+	obj, ok := s.objects[key]
+	if !ok {
+		writeTestRemoteError(w, r, http.StatusNotFound, "NoSuchKey")
+		return nil
+	}
+	if !obj.checkKey(w, r, r.Header.Get(testRemoteSSECKeyMD5)) {
+		return nil
+	}
+	if r.Method == http.MethodGet && obj.archived() && !obj.restored() {
+		writeTestRemoteError(w, r, http.StatusForbidden, "InvalidObjectState")
+		return nil
+	}
+	for k, v := range obj.header {
+		w.Header()[k] = v
+	}
+	w.Header().Set("ETag", obj.etag)
+	w.Header().Set("Last-Modified", obj.modTime.Format(http.TimeFormat))
+	w.Header().Set("Accept-Ranges", "bytes")
+
+	start, end := int64(0), int64(len(obj.data))-1
+	status := http.StatusOK
+	if rng := r.Header.Get("Range"); rng != "" {
+		var err error
+		if start, end, err = parseTestRemoteRange(rng, int64(len(obj.data))); err != nil {
+			writeTestRemoteError(w, r, http.StatusRequestedRangeNotSatisfiable, "InvalidRange")
+			return nil
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(obj.data)))
+		status = http.StatusPartialContent
+	}
+	w.Header().Set("Content-Length", strconv.FormatInt(end-start+1, 10))
+	w.WriteHeader(status)
+	if r.Method != http.MethodGet {
+		return nil
+	}
+	return obj.data[start : end+1]
+}
+
+// checkKey returns true if keyMD5 is the MD5 sum of the customer key
+// obj is encrypted with, failing r otherwise.
+func (obj *testRemoteObject) checkKey(w http.ResponseWriter, r *http.Request, keyMD5 string) bool {
+	switch stored := obj.header.Get(testRemoteSSECKeyMD5); {
+	case stored == keyMD5:
+		return true
+	case keyMD5 == "":
+		writeTestRemoteError(w, r, http.StatusBadRequest, "InvalidRequest")
+	default:
+		writeTestRemoteError(w, r, http.StatusForbidden, "AccessDenied")
+	}
+	return false
+}
+
+// archived returns true if obj is stored in an archive storage class,
+// only read once restored.
+func (obj *testRemoteObject) archived() bool {
+	switch obj.header.Get("X-Amz-Storage-Class") {
+	case "GLACIER", "DEEP_ARCHIVE":
+		return true
+	}
+	return false
+}
+
+// restored returns true if a restored copy of obj can be read.
+func (obj *testRemoteObject) restored() bool {
+	return strings.Contains(obj.header.Get("X-Amz-Restore"), `ongoing-request="false"`)
+}
+
+// restoreObject starts the restore of an archived object, left in
+// progress until completed by the test with putObject.
+func (s *testRemote) restoreObject(w http.ResponseWriter, r *http.Request, key string) {
+	body, _ := ioutil.ReadAll(r.Body)
+	obj, ok := s.objects[key]
+	switch {
+	case !bytes.Contains(body, []byte("<RestoreRequest>")):
+		writeTestRemoteError(w, r, http.StatusBadRequest, "MalformedXML")
+	case !ok:
+		writeTestRemoteError(w, r, http.StatusNotFound, "NoSuchKey")
+	case !obj.archived():
+		writeTestRemoteError(w, r, http.StatusForbidden, "InvalidObjectState")
+	case obj.restored():
+		w.WriteHeader(http.StatusOK)
+	case obj.header.Get("X-Amz-Restore") != "":
+		writeTestRemoteError(w, r, http.StatusConflict, "RestoreAlreadyInProgress")
+	default:
+		obj.header.Set("X-Amz-Restore", `ongoing-request="true"`)
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// parseTestRemoteRange returns the first and last offsets of the range
+// rng of an object of size bytes.
+func parseTestRemoteRange(rng string, size int64) (start, end int64, err error) {
+	spec := strings.TrimPrefix(rng, "bytes=")
+	i := strings.IndexByte(spec, '-')
+	if i < 0 {
+		return 0, 0, fmt.Errorf("invalid range %s", rng)
+	}
+	first, last := spec[:i], spec[i+1:]
+	switch {
+	case first == "":
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n <= 0 {
+			return 0, 0, fmt.Errorf("invalid range %s", rng)
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, size - 1, nil
+	default:
+		if start, err = strconv.ParseInt(first, 10, 64); err != nil {
+			return 0, 0, err
+		}
+		end = size - 1
+		if last != "" {
+			if end, err = strconv.ParseInt(last, 10, 64); err != nil {
+				return 0, 0, err
+			}
+			if end >= size {
+				end = size - 1
+			}
+		}
+	}
+	if start > end || start >= size {
+		return 0, 0, fmt.Errorf("unsatisfiable range %s", rng)
+	}
+	return start, end, nil
+}
+
+// copySource returns the source object of a copy request.
+func (s *testRemote) copySource(w http.ResponseWriter, r *http.Request) (*testRemoteObject, bool) {
+	source, err := url.PathUnescape(r.Header.Get("X-Amz-Copy-Source"))
+	if err != nil {
+		writeTestRemoteError(w, r, http.StatusBadRequest, "InvalidArgument")
+		return nil, false
+	}
+	source = strings.TrimPrefix(source, "/")
+	if !strings.HasPrefix(source, testRemoteBucket+"/") {
+		writeTestRemoteError(w, r, http.StatusNotFound, "NoSuchBucket")
+		return nil, false
+	}
+	src, ok := s.objects[strings.TrimPrefix(source, testRemoteBucket+"/")]
+	if !ok {
+		writeTestRemoteError(w, r, http.StatusNotFound, "NoSuchKey")
+		return nil, false
+	}
+	if match := r.Header.Get("X-Amz-Copy-Source-If-Match"); match != "" && strings.Trim(match, `"`) != strings.Trim(src.etag, `"`) {
+		writeTestRemoteError(w, r, http.StatusPreconditionFailed, "PreconditionFailed")
+		return nil, false
+	}
+	if !src.checkKey(w, r, r.Header.Get(testRemoteCopySSECKeyMD5)) {
+		return nil, false
+	}
+	return src, true
+}
+
+type testRemoteCopyResult struct {
+	XMLName      xml.Name `xml:"CopyObjectResult"`
+	ETag         string   `xml:"ETag"`
+	LastModified string   `xml:"LastModified"`
+}
+
+func (s *testRemote) copyObject(w http.ResponseWriter, r *http.Request, key string) {
+	src, ok := s.copySource(w, r)
+	if !ok {
+		return
+	}
+	header := src.header.Clone()
+	if r.Header.Get("X-Amz-Metadata-Directive") == "REPLACE" {
+		header = testRemoteMetadata(r.Header)
+	}
+	// Copies are encrypted with the customer key of the request only.
+	for _, k := range []string{testRemoteSSECAlgorithm, testRemoteSSECKeyMD5} {
+		header.Del(k)
+		if v := r.Header.Get(k); v != "" {
+			header.Set(k, v)
+		}
+	}
+	obj := s.write(key, append([]byte(nil), src.data...), header)
+	writeTestRemoteXML(w, testRemoteCopyResult{
+		ETag:         obj.etag,
+		LastModified: obj.modTime.Format(time.RFC3339),
+	})
+}
+
+type testRemoteContent struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int    `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+type testRemotePrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+type testRemoteListResult struct {
+	XMLName               xml.Name            `xml:"ListBucketResult"`
+	Name                  string              `xml:"Name"`
+	Prefix                string              `xml:"Prefix"`
+	Marker                string              `xml:"Marker,omitempty"`
+	NextMarker            string              `xml:"NextMarker,omitempty"`
+	StartAfter            string              `xml:"StartAfter,omitempty"`
+	ContinuationToken     string              `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string              `xml:"NextContinuationToken,omitempty"`
+	KeyCount              int                 `xml:"KeyCount,omitempty"`
+	MaxKeys               int                 `xml:"MaxKeys"`
+	Delimiter             string              `xml:"Delimiter,omitempty"`
+	EncodingType          string              `xml:"EncodingType,omitempty"`
+	IsTruncated           bool                `xml:"IsTruncated"`
+	Contents              []testRemoteContent `xml:"Contents"`
+	CommonPrefixes        []testRemotePrefix  `xml:"CommonPrefixes"`
+}
+
+type testRemoteDeleteRequest struct {
+	Objects []struct {
+		Key string `xml:"Key"`
+	} `xml:"Object"`
+}
+
+type testRemoteDeleteResult struct {
+	XMLName xml.Name           `xml:"DeleteResult"`
+	Deleted []testRemotePrefix `xml:"Deleted"`
+}
+
+func (s *testRemote) serveBucket(w http.ResponseWriter, r *http.Request, query url.Values) {
+	switch {
+	case r.Method == http.MethodHead:
+	case r.Method == http.MethodGet && query["location"] != nil:
+		writeTestRemoteXML(w, struct {
+			XMLName xml.Name `xml:"LocationConstraint"`
+		}{})
+	case r.Method == http.MethodGet && query["uploads"] != nil:
+		s.listUploads(w, r, query)
+	case r.Method == http.MethodPost && query["delete"] != nil:
+		var req testRemoteDeleteRequest
+		if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeTestRemoteError(w, r, http.StatusBadRequest, "MalformedXML")
+			return
+		}
+		var result testRemoteDeleteResult
+		for _, obj := range req.Objects {
+			delete(s.objects, obj.Key)
+			result.Deleted = append(result.Deleted, testRemotePrefix{Prefix: obj.Key})
+		}
+		writeTestRemoteXML(w, result)
+	case r.Method == http.MethodGet && (len(query) == 0 || query.Get("list-type") == "2" ||
+		query["prefix"] != nil || query["marker"] != nil || query["max-keys"] != nil):
+		s.listObjects(w, query)
+	default:
+		writeTestRemoteError(w, r, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+// listObjects serves ListObjects and ListObjectsV2, keys are url
+// encoded for clients listing with encoding-type=url.
+func (s *testRemote) listObjects(w http.ResponseWriter, query url.Values) {
+	v2 := query.Get("list-type") == "2"
+	prefix, delimiter := query.Get("prefix"), query.Get("delimiter")
+	maxKeys := 1000
+	if n, err := strconv.Atoi(query.Get("max-keys")); err == nil && n >= 0 {
+		maxKeys = n
+	}
+	marker := query.Get("marker")
+	if v2 {
+		marker = query.Get("start-after")
+		if token := query.Get("continuation-token"); token != "" {
+			decoded, _ := base64.StdEncoding.DecodeString(token)
+			marker = string(decoded)
+		}
+	}
+	encode := func(s string) string {
+		if query.Get("encoding-type") == "url" {
+			return url.QueryEscape(s)
+		}
+		return s
+	}
+
+	result := testRemoteListResult{
+		Name:         testRemoteBucket,
+		Prefix:       encode(prefix),
+		MaxKeys:      maxKeys,
+		Delimiter:    encode(delimiter),
+		EncodingType: query.Get("encoding-type"),
+	}
+	if v2 {
+		result.StartAfter = encode(query.Get("start-after"))
+		result.ContinuationToken = query.Get("continuation-token")
+	} else {
+		result.Marker = encode(marker)
+	}
+
+	var last string
+	seen := make(map[string]bool)
+	for _, key := range s.sortedKeys() {
+		if !strings.HasPrefix(key, prefix) || key <= marker {
+			continue
+		}
+		name := key
+		if delimiter != "" {
+			if i := strings.Index(key[len(prefix):], delimiter); i >= 0 {
+				name = key[:len(prefix)+i+len(delimiter)]
+			}
+		}
+		if seen[name] || (name != key && name <= marker) {
+			continue
+		}
+		if len(result.Contents)+len(result.CommonPrefixes) == maxKeys {
+			result.IsTruncated = true
+			break
+		}
+		seen[name] = true
+		last = name
+		if name != key {
+			result.CommonPrefixes = append(result.CommonPrefixes, testRemotePrefix{Prefix: encode(name)})
+			continue
+		}
+		obj := s.objects[key]
+		storageClass := obj.header.Get("X-Amz-Storage-Class")
+		if storageClass == "" {
+			storageClass = "STANDARD"
+		}
+		result.Contents = append(result.Contents, testRemoteContent{
+			Key:          encode(key),
+			LastModified: obj.modTime.Format(time.RFC3339),
+			ETag:         obj.etag,
+			Size:         len(obj.data),
+			StorageClass: storageClass,
+		})
+	}
+	if result.IsTruncated {
+		if v2 {
+			result.NextContinuationToken = base64.StdEncoding.EncodeToString([]byte(last))
+		} else if delimiter != "" {
+			// Like S3, NextMarker is only returned along with a
+			// delimiter.
+			result.NextMarker = encode(last)
+		}
+	}
+	if v2 {
+		result.KeyCount = len(result.Contents) + len(result.CommonPrefixes)
+	}
+	writeTestRemoteXML(w, result)
+}
+
+type testRemoteInitiateResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	UploadID string   `xml:"UploadId"`
+}
+
+type testRemoteCopyPartResult struct {
+	XMLName      xml.Name `xml:"CopyPartResult"`
+	ETag         string   `xml:"ETag"`
+	LastModified string   `xml:"LastModified"`
+}
+
+type testRemoteCompleteRequest struct {
+	Parts []struct {
+		PartNumber int    `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+	} `xml:"Part"`
+}
+
+type testRemoteCompleteResult struct {
+	XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+	Bucket  string   `xml:"Bucket"`
+	Key     string   `xml:"Key"`
+	ETag    string   `xml:"ETag"`
+}
+
+type testRemoteUploadInfo struct {
+	Key       string `xml:"Key"`
+	UploadID  string `xml:"UploadId"`
+	Initiated string `xml:"Initiated"`
+}
+
+type testRemoteListUploadsResult struct {
+	XMLName     xml.Name               `xml:"ListMultipartUploadsResult"`
+	Bucket      string                 `xml:"Bucket"`
+	Prefix      string                 `xml:"Prefix"`
+	MaxUploads  int                    `xml:"MaxUploads"`
+	IsTruncated bool                   `xml:"IsTruncated"`
+	Uploads     []testRemoteUploadInfo `xml:"Upload"`
+}
+
+func testRemotePartETag(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func (s *testRemote) serveUpload(w http.ResponseWriter, r *http.Request, key string, query url.Values) {
+	if query["uploads"] != nil {
+		if r.Method != http.MethodPost {
+			writeTestRemoteError(w, r, http.StatusNotImplemented, "NotImplemented")
+			return
+		}
+		s.nextID++
+		id := fmt.Sprintf("upload-%d", s.nextID)
+		s.uploads[id] = &testRemoteUpload{object: key, header: testRemoteMetadata(r.Header), parts: make(map[int][]byte)}
+		writeTestRemoteXML(w, testRemoteInitiateResult{Bucket: testRemoteBucket, Key: key, UploadID: id})
+		return
+	}
+
+	id := query.Get("uploadId")
+	upload, ok := s.uploads[id]
+	if !ok || upload.object != key {
+		writeTestRemoteError(w, r, http.StatusNotFound, "NoSuchUpload")
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		partNumber, err := strconv.Atoi(query.Get("partNumber"))
+		if err != nil {
+			writeTestRemoteError(w, r, http.StatusBadRequest, "InvalidArgument")
+			return
+		}
+		if r.Header.Get("X-Amz-Copy-Source") == "" {
+			data, ok := readTestRemoteData(w, r)
+			if !ok {
+				return
+			}
+			s.received += len(data)
+			upload.parts[partNumber] = data
+			w.Header().Set("ETag", testRemotePartETag(data))
+			return
+		}
+		src, ok := s.copySource(w, r)
+		if !ok {
+			return
+		}
+		data := src.data
+		if rng := r.Header.Get("X-Amz-Copy-Source-Range"); rng != "" {
+			start, end, err := parseTestRemoteRange(rng, int64(len(data)))
+			if err != nil {
+				writeTestRemoteError(w, r, http.StatusRequestedRangeNotSatisfiable, "InvalidRange")
+				return
+			}
+			data = data[start : end+1]
+		}
+		upload.parts[partNumber] = append([]byte(nil), data...)
+		writeTestRemoteXML(w, testRemoteCopyPartResult{
+			ETag:         testRemotePartETag(data),
+			LastModified: time.Now().UTC().Format(time.RFC3339),
+		})
+	case http.MethodPost:
+		var req testRemoteCompleteRequest
+		if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeTestRemoteError(w, r, http.StatusBadRequest, "MalformedXML")
+			return
+		}
+		var data, sums []byte
+		for _, part := range req.Parts {
+			partData, ok := upload.parts[part.PartNumber]
+			if !ok || strings.Trim(part.ETag, `"`) != strings.Trim(testRemotePartETag(partData), `"`) {
+				writeTestRemoteError(w, r, http.StatusBadRequest, "InvalidPart")
+				return
+			}
+			sum := md5.Sum(partData)
+			data, sums = append(data, partData...), append(sums, sum[:]...)
+		}
+		obj := s.write(key, data, upload.header)
+		sum := md5.Sum(sums)
+		obj.etag = fmt.Sprintf(`"%s-%d"`, hex.EncodeToString(sum[:]), len(req.Parts))
+		delete(s.uploads, id)
+		writeTestRemoteXML(w, testRemoteCompleteResult{Bucket: testRemoteBucket, Key: key, ETag: obj.etag})
+	case http.MethodDelete:
+		delete(s.uploads, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeTestRemoteError(w, r, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+func (s *testRemote) listUploads(w http.ResponseWriter, r *http.Request, query url.Values) {
+	prefix := query.Get("prefix")
+	result := testRemoteListUploadsResult{Bucket: testRemoteBucket, Prefix: prefix, MaxUploads: 1000}
+	ids := make([]string, 0, len(s.uploads))
+	for id := range s.uploads {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if upload := s.uploads[id]; strings.HasPrefix(upload.object, prefix) {
+			result.Uploads = append(result.Uploads, testRemoteUploadInfo{
+				Key:       upload.object,
+				UploadID:  id,
+				Initiated: time.Now().UTC().Format(time.RFC3339),
+			})
+		}
+	}
+	writeTestRemoteXML(w, result)
+}
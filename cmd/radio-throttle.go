@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	miniogo "github.com/minio/minio-go/v6"
+	xhttp "github.com/minio/radio/cmd/http"
+)
+
+// defaultRetryAfter is the Retry-After in seconds of SlowDown responses
+// when no remote suggested a delay.
+const defaultRetryAfter = 120
+
+// throttleCodes are the error codes of remotes throttling requests.
+var throttleCodes = map[string]struct{}{
+	"SlowDown":                 {},
+	"Throttling":               {},
+	"ThrottlingException":      {},
+	"RequestLimitExceeded":     {},
+	"RequestThrottled":         {},
+	"TooManyRequests":          {},
+	"TooManyRequestsException": {},
+}
+
+// isThrottleErr returns true if err, or the error it wraps, is a remote
+// throttling requests.
+func isThrottleErr(err error) bool {
+	var errResp miniogo.ErrorResponse
+	if !errors.As(err, &errResp) {
+		return false
+	}
+	if errResp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	_, ok := throttleCodes[errResp.Code]
+	return ok
+}
+
+type retryAfterKeyType struct{}
+
+// retryAfterKey holds the retryAfter of the context of a request.
+var retryAfterKey retryAfterKeyType
+
+// retryAfter is the longest delay in seconds the remotes throttling the
+// requests of a client request suggested.
+type retryAfter struct {
+	seconds int64
+}
+
+// withRetryAfter returns a context recording the delays suggested by
+// the remotes throttling the requests made with it.
+func withRetryAfter(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryAfterKey, &retryAfter{})
+}
+
+// suggest records a delay of seconds, keeping the longest.
+func (r *retryAfter) suggest(seconds int64) {
+	for {
+		cur := atomic.LoadInt64(&r.seconds)
+		if seconds <= cur || atomic.CompareAndSwapInt64(&r.seconds, cur, seconds) {
+			return
+		}
+	}
+}
+
+// retryAfterOf returns the longest delay in seconds suggested by the
+// remotes throttling the requests made with ctx, zero if none did.
+func retryAfterOf(ctx context.Context) int64 {
+	r, ok := ctx.Value(retryAfterKey).(*retryAfter)
+	if !ok {
+		return 0
+	}
+	return atomic.LoadInt64(&r.seconds)
+}
+
+// setRetryAfter sets the Retry-After header of the error responses with
+// code asking clients to back off, to the longest delay suggested by the
+// remotes throttling the request, or else to defaultRetryAfter.
+// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Retry-After
+func setRetryAfter(ctx context.Context, w http.ResponseWriter, code string) {
+	switch code {
+	case "SlowDown", "XMinioServerNotInitialized", "XMinioReadQuorum", "XMinioWriteQuorum":
+	default:
+		return
+	}
+	seconds := int64(defaultRetryAfter)
+	if code == "SlowDown" {
+		if suggested := retryAfterOf(ctx); suggested > 0 {
+			seconds = suggested
+		}
+	}
+	w.Header().Set(xhttp.RetryAfter, strconv.FormatInt(seconds, 10))
+}
+
+// parseRetryAfter returns the delay in seconds of a Retry-After header,
+// either seconds or an HTTP date, rounded up. Zero is returned for
+// missing or invalid values.
+func parseRetryAfter(value string) int64 {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return seconds
+	}
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return 0
+	}
+	d := time.Until(t)
+	if d <= 0 {
+		return 0
+	}
+	return int64((d + time.Second - 1) / time.Second)
+}
+
+// throttleTransport records the Retry-After of the throttled responses
+// of a remote in the context of their requests.
+type throttleTransport struct {
+	http.RoundTripper
+}
+
+func newThrottleTransport(transport http.RoundTripper) http.RoundTripper {
+	return &throttleTransport{RoundTripper: transport}
+}
+
+func (t *throttleTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.RoundTripper.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable && resp.StatusCode != http.StatusTooManyRequests {
+		return resp, err
+	}
+	if r, ok := req.Context().Value(retryAfterKey).(*retryAfter); ok {
+		r.suggest(parseRetryAfter(resp.Header.Get(xhttp.RetryAfter)))
+	}
+	return resp, err
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/minio/minio/pkg/hash"
+	xhttp "github.com/minio/radio/cmd/http"
+)
+
+// Tests that remotes throttling writes fail them with SlowDown, asking
+// clients to retry after the longest delay the remotes suggested.
+func TestThrottleRetryAfter(t *testing.T) {
+	testCases := []struct {
+		value   string
+		seconds int64
+	}{
+		{"", 0},
+		{"7", 7},
+		{"-1", 0},
+		{"soon", 0},
+		{time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), 0},
+	}
+	for i, testCase := range testCases {
+		if seconds := parseRetryAfter(testCase.value); seconds != testCase.seconds {
+			t.Errorf("Test %d: expected %d, got %d", i+1, testCase.seconds, seconds)
+		}
+	}
+	if seconds := parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)); seconds < 3500 || seconds > 3600 {
+		t.Errorf("Expected an hour from an HTTP date, got %d", seconds)
+	}
+
+	throttled := func(retryAfter string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ioutil.ReadAll(r.Body)
+			if retryAfter != "" {
+				w.Header().Set(xhttp.RetryAfter, retryAfter)
+			}
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`<Error><Code>SlowDown</Code></Error>`))
+		}))
+	}
+	remoteCases := []struct {
+		retryAfter []string
+		header     string
+	}{
+		{[]string{"7", "30"}, "30"},
+		{[]string{"7", ""}, "7"},
+		{[]string{"", ""}, "120"},
+	}
+	for i, testCase := range remoteCases {
+		var clnts []bucketClient
+		for _, retryAfter := range testCase.retryAfter {
+			ts := throttled(retryAfter)
+			defer ts.Close()
+			core := newTestCore(t, ts.URL)
+			core.SetCustomTransport(newThrottleTransport(http.DefaultTransport))
+			clnts = append(clnts, bucketClient{Core: core, Bucket: "remote"})
+		}
+		l := &radioObjects{
+			nsMutex:       newNSLock(false),
+			mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+		}
+		data := []byte("data")
+		reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), "", "", int64(len(data)), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx := withRetryAfter(context.Background())
+		_, err = l.PutObject(ctx, "bucket", "object", NewPutObjReader(reader, nil, nil), ObjectOptions{})
+		if _, ok := err.(BackendThrottled); !ok {
+			t.Fatalf("Test %d: expected BackendThrottled, got %v", i+1, err)
+		}
+		w := httptest.NewRecorder()
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), &url.URL{Path: "/bucket/object"})
+		if w.Code != http.StatusServiceUnavailable || w.Header().Get(xhttp.RetryAfter) != testCase.header {
+			t.Errorf("Test %d: expected 503 with Retry-After %s, got %d with %q", i+1, testCase.header,
+				w.Code, w.Header().Get(xhttp.RetryAfter))
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"fmt"
+)
+
+// tlsConfig restricts the TLS versions and cipher suites of the server
+// and of the connections to the remotes.
+type tlsConfig struct {
+	// Lowest TLS version accepted, "1.2" or "1.3", TLS 1.2 by default.
+	MinVersion string `yaml:"min_version"`
+	// TLS 1.2 cipher suites allowed, the cipher suites of TLS 1.3 are
+	// not configurable.
+	CipherSuites []string `yaml:"cipher_suites"`
+}
+
+// TLS versions accepted as minimum version.
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLS 1.2 cipher suites accepted in the configuration, RC4, 3DES and
+// RSA key exchange ciphers are left out.
+var tlsCipherSuites = map[string]uint16{
+	"TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305":    tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
+	"TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305":  tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
+	"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256":   tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256": tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384":   tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384": tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	"TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA":      tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA,
+	"TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA":    tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA,
+	"TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA":      tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
+	"TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA":    tls.TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA,
+}
+
+// tlsPolicy is the parsed tlsConfig.
+type tlsPolicy struct {
+	minVersion   uint16
+	cipherSuites []uint16
+}
+
+// defaultTLSPolicy accepts TLS 1.2 and above with the default cipher
+// suites.
+var defaultTLSPolicy = tlsPolicy{minVersion: tls.VersionTLS12}
+
+// parseTLSPolicy validates the TLS versions and cipher suites of cfg.
+func parseTLSPolicy(cfg tlsConfig) (tlsPolicy, error) {
+	p := defaultTLSPolicy
+	if cfg.MinVersion != "" {
+		version, ok := tlsVersions[cfg.MinVersion]
+		if !ok {
+			return p, fmt.Errorf("unsupported minimum TLS version %q", cfg.MinVersion)
+		}
+		p.minVersion = version
+	}
+	if len(cfg.CipherSuites) > 0 && p.minVersion == tls.VersionTLS13 {
+		return p, fmt.Errorf("cipher suites cannot be configured with TLS 1.3")
+	}
+	for _, name := range cfg.CipherSuites {
+		suite, ok := tlsCipherSuites[name]
+		if !ok {
+			return p, fmt.Errorf("unsupported TLS cipher suite %q", name)
+		}
+		p.cipherSuites = append(p.cipherSuites, suite)
+	}
+	return p, nil
+}
+
+// apply restricts c to the TLS versions and cipher suites of p, cipher
+// suites already set in c are kept unless p sets some.
+func (p tlsPolicy) apply(c *tls.Config) {
+	if c == nil {
+		return
+	}
+	c.MinVersion = p.minVersion
+	if len(p.cipherSuites) > 0 {
+		c.CipherSuites = p.cipherSuites
+	}
+}
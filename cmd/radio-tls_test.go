@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"testing"
+)
+
+// Tests validation of the TLS versions and cipher suites.
+func TestParseTLSPolicy(t *testing.T) {
+	testCases := []struct {
+		cfg                  tlsConfig
+		shouldPass           bool
+		expectedMinVersion   uint16
+		expectedCipherSuites []uint16
+	}{
+		{tlsConfig{}, true, tls.VersionTLS12, nil},
+		{tlsConfig{MinVersion: "1.3"}, true, tls.VersionTLS13, nil},
+		{tlsConfig{MinVersion: "1.2", CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"}},
+			true, tls.VersionTLS12, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}},
+		{tlsConfig{MinVersion: "1.1"}, false, 0, nil},
+		{tlsConfig{MinVersion: "TLS1.3"}, false, 0, nil},
+		{tlsConfig{CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}}, false, 0, nil},
+		{tlsConfig{MinVersion: "1.3", CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"}}, false, 0, nil},
+	}
+
+	for i, testCase := range testCases {
+		p, err := parseTLSPolicy(testCase.cfg)
+		if testCase.shouldPass && err != nil {
+			t.Errorf("Test %d: Unexpected error %v", i+1, err)
+			continue
+		}
+		if !testCase.shouldPass {
+			if err == nil {
+				t.Errorf("Test %d: Expected an error", i+1)
+			}
+			continue
+		}
+
+		c := &tls.Config{CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}}
+		p.apply(c)
+		if c.MinVersion != testCase.expectedMinVersion {
+			t.Errorf("Test %d: Expected minimum version %x, got %x", i+1, testCase.expectedMinVersion, c.MinVersion)
+		}
+		expectedCipherSuites := testCase.expectedCipherSuites
+		if expectedCipherSuites == nil {
+			expectedCipherSuites = []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}
+		}
+		if len(c.CipherSuites) != len(expectedCipherSuites) || c.CipherSuites[0] != expectedCipherSuites[0] {
+			t.Errorf("Test %d: Expected cipher suites %v, got %v", i+1, expectedCipherSuites, c.CipherSuites)
+		}
+	}
+}
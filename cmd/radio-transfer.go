@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/minio-go/v6/pkg/s3utils"
+)
+
+// setTransferEndpoint routes the object data requests of clnt through
+// the transfer endpoint urlStr, while requests keep being signed for
+// the main endpoint of clnt. For AWS S3 remotes urlStr is expected to
+// be a transfer acceleration endpoint such as s3-accelerate.amazonaws.com.
+// Other remotes reach urlStr through transport.
+func setTransferEndpoint(clnt *miniogo.Core, bucket, urlStr string, transport http.RoundTripper) error {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return err
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid transfer endpoint %q", urlStr)
+	}
+
+	if s3utils.IsAmazonEndpoint(*clnt.EndpointURL()) {
+		clnt.SetS3TransferAccelerate(u.Host)
+		return nil
+	}
+
+	clnt.SetCustomTransport(&transferTransport{
+		RoundTripper: transport,
+		bucket:       bucket,
+		scheme:       u.Scheme,
+		host:         u.Host,
+	})
+	return nil
+}
+
+// transferTransport sends object requests to an alternate host while
+// preserving the signed Host header of the original request.
+type transferTransport struct {
+	http.RoundTripper
+	bucket string
+	scheme string
+	host   string
+}
+
+func (t *transferTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.isObjectRequest(req) {
+		return t.RoundTripper.RoundTrip(req)
+	}
+	r := req.WithContext(req.Context())
+	u := *req.URL
+	if t.scheme != "" {
+		u.Scheme = t.scheme
+	}
+	u.Host = t.host
+	r.URL = &u
+	if r.Host == "" {
+		r.Host = req.URL.Host
+	}
+	return t.RoundTripper.RoundTrip(r)
+}
+
+// isObjectRequest returns true if req addresses an object rather than
+// the bucket, for both path and virtual host style requests.
+func (t *transferTransport) isObjectRequest(req *http.Request) bool {
+	p := strings.TrimPrefix(req.URL.Path, "/")
+	if strings.HasPrefix(req.URL.Host, t.bucket+".") {
+		return p != ""
+	}
+	return strings.HasPrefix(p, t.bucket+"/") && len(p) > len(t.bucket)+1
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	miniogo "github.com/minio/minio-go/v6"
+)
+
+// Tests that object requests are sent to the transfer endpoint signed
+// for the main endpoint, while bucket requests keep going to the main
+// endpoint.
+func TestTransferEndpoint(t *testing.T) {
+	remotes, clnts, shutdown := newTestRemotes(t, 1)
+	defer shutdown()
+	host := clnts[0].EndpointURL().Host
+
+	var mu sync.Mutex
+	var transferred, hosts []string
+	transfer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		transferred = append(transferred, r.Method+" "+r.URL.Path)
+		hosts = append(hosts, r.Host)
+		mu.Unlock()
+		remotes[0].ServeHTTP(w, r)
+	}))
+	defer transfer.Close()
+
+	if err := setTransferEndpoint(clnts[0].Core, clnts[0].Bucket, transfer.URL, http.DefaultTransport); err != nil {
+		t.Fatal(err)
+	}
+	if err := setTransferEndpoint(clnts[0].Core, clnts[0].Bucket, "invalid", http.DefaultTransport); err == nil {
+		t.Error("Expected an endpoint without a host to be rejected")
+	}
+
+	clnt := clnts[0]
+	if _, err := clnt.PutObject(clnt.Bucket, "dir/object", bytes.NewReader([]byte("hello")), 5, "", "", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	reader, _, _, err := clnt.GetObject(clnt.Bucket, "dir/object", miniogo.GetObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader.Close()
+	if _, err = clnt.ListObjectsV2(clnt.Bucket, "dir/", "", false, "", 10, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"PUT /remote/dir/object", "GET /remote/dir/object"}
+	if len(transferred) != len(want) {
+		t.Fatalf("Expected transfers %v, got %v", want, transferred)
+	}
+	for i := range want {
+		if transferred[i] != want[i] {
+			t.Errorf("Expected transfer %s, got %s", want[i], transferred[i])
+		}
+		if hosts[i] != host {
+			t.Errorf("Expected transfer signed for %s, got host %s", host, hosts[i])
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/minio/pkg/auth"
+)
+
+// userCredentials are the credentials of a remote signing the requests
+// of one radio client, so that the remote attributes them to a user of
+// its own rather than to the credentials of the remote.
+type userCredentials struct {
+	AccessKey    string `yaml:"access_key"`
+	SecretKey    string `yaml:"secret_key"`
+	SessionToken string `yaml:"session_token"`
+}
+
+// userClients are the clients of a remote signing requests with the
+// credentials of a user.
+type userClients struct {
+	core         *miniogo.Core
+	versions     *versionsClient
+	readCore     *miniogo.Core
+	readVersions *versionsClient
+}
+
+// validateUserCredentials checks that the users of the remotes of bucket
+// map the access keys of radio clients, in clientKeys, to credentials.
+func validateUserCredentials(bucket string, remotes []remoteConfig, clientKeys map[string]bool) error {
+	for _, cfg := range remotes {
+		for accessKey, creds := range cfg.Users {
+			if !clientKeys[accessKey] {
+				return fmt.Errorf("bucket %s: remote %s/%s: users: %q is not the access key of a radio client",
+					bucket, cfg.Endpoint, cfg.Bucket, accessKey)
+			}
+			if creds.AccessKey == "" || creds.SecretKey == "" {
+				return fmt.Errorf("bucket %s: remote %s/%s: users: both access_key and secret_key must be set for %q",
+					bucket, cfg.Endpoint, cfg.Bucket, accessKey)
+			}
+			if s3Compat(cfg.Compat) == compatLegacy && creds.SessionToken != "" {
+				return fmt.Errorf("bucket %s: remote %s/%s: users: session tokens are not supported by legacy remotes",
+					bucket, cfg.Endpoint, cfg.Bucket)
+			}
+		}
+	}
+	return nil
+}
+
+// newUserClients returns the clients of the users of the remote of cfg
+// by client access key, probing the remote with the credentials of each
+// user unless it is offline and allowDegraded.
+func newUserClients(cfg remoteConfig, transport http.RoundTripper, allowDegraded bool) (map[string]*userClients, error) {
+	if len(cfg.Users) == 0 {
+		return nil, nil
+	}
+	users := make(map[string]*userClients, len(cfg.Users))
+	for accessKey, creds := range cfg.Users {
+		core, err := newS3(cfg.Bucket, cfg.Endpoint, "", creds.AccessKey, creds.SecretKey, creds.SessionToken, transport)
+		if err != nil && (core == nil || !allowDegraded || !isRemoteOffline(err)) {
+			return nil, fmt.Errorf("user %s: %v", accessKey, err)
+		}
+		if cfg.TransferEndpoint != "" {
+			if err = setTransferEndpoint(core, cfg.Bucket, cfg.TransferEndpoint, transport); err != nil {
+				return nil, err
+			}
+		}
+		versions, err := newVersionsClient(cfg.Endpoint, creds.AccessKey, creds.SecretKey, creds.SessionToken, transport)
+		if err != nil {
+			return nil, err
+		}
+		user := &userClients{core: core, versions: versions}
+		if cfg.ReadEndpoint != "" {
+			// The read endpoint is signed for the user too, rather than
+			// with the read credentials of the remote.
+			readCfg := cfg
+			readCfg.AccessKey, readCfg.SecretKey, readCfg.SessionToken = creds.AccessKey, creds.SecretKey, creds.SessionToken
+			readCfg.ReadAccessKey, readCfg.ReadSecretKey = "", ""
+			if user.readCore, user.readVersions, err = newReadClients(readCfg, transport, allowDegraded); err != nil {
+				return nil, fmt.Errorf("user %s: %v", accessKey, err)
+			}
+		}
+		users[accessKey] = user
+	}
+	return users, nil
+}
+
+// asUser returns c signing requests with the credentials of the user of
+// the radio client accessKey, c itself if the client is not mapped.
+func (c bucketClient) asUser(accessKey string) bucketClient {
+	user, ok := c.users[accessKey]
+	if !ok {
+		return c
+	}
+	c.Core, c.versions = user.core, user.versions
+	if c.readCore != nil {
+		c.readCore, c.readVersions = user.readCore, user.readVersions
+	}
+	return c
+}
+
+// forUser returns m whose replicas sign requests with the credentials
+// of the user of the radio client of ctx on the remotes mapping it. Heals
+// and other requests of radio itself keep the credentials of the remotes.
+func (m mirrorConfig) forUser(ctx context.Context) mirrorConfig {
+	accessKey := clientAccessKey(ctx)
+	if accessKey == "" {
+		return m
+	}
+	var clnts []bucketClient
+	for i, clnt := range m.clnts {
+		if _, ok := clnt.users[accessKey]; !ok {
+			continue
+		}
+		if clnts == nil {
+			clnts = append([]bucketClient(nil), m.clnts...)
+		}
+		clnts[i] = clnt.asUser(accessKey)
+	}
+	if clnts != nil {
+		m.clnts = clnts
+	}
+	return m
+}
+
+// mappedUser returns the access key of the radio client of ctx if a
+// replica of m maps it to a user, empty if all replicas are accessed
+// with the credentials of the remotes.
+func (m mirrorConfig) mappedUser(ctx context.Context) string {
+	accessKey := clientAccessKey(ctx)
+	if accessKey == "" {
+		return ""
+	}
+	for _, clnt := range m.clnts {
+		if _, ok := clnt.users[accessKey]; ok {
+			return accessKey
+		}
+	}
+	return ""
+}
+
+type clientAccessKeyType struct{}
+
+// clientAccessKeyKey holds the access key of the radio client of a
+// request in its context.
+var clientAccessKeyKey clientAccessKeyType
+
+// withClientAccessKey returns a context of a request signed with
+// accessKey.
+func withClientAccessKey(ctx context.Context, accessKey string) context.Context {
+	if accessKey == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, clientAccessKeyKey, accessKey)
+}
+
+func clientAccessKey(ctx context.Context) string {
+	accessKey, _ := ctx.Value(clientAccessKeyKey).(string)
+	return accessKey
+}
+
+// reqAccessKey returns the access key r is signed with, empty for
+// anonymous or malformed requests. The signature is verified by the
+// handlers before the object layer is called.
+func reqAccessKey(r *http.Request) string {
+	var cred auth.Credentials
+	var s3Err APIErrorCode
+	switch getRequestAuthType(r) {
+	case authTypePresignedV2, authTypeSignedV2:
+		cred, s3Err = getReqAccessKeyV2(r)
+	case authTypeSigned, authTypePresigned, authTypeStreamingSigned:
+		cred, s3Err = getReqAccessKeyV4(r, globalServerRegion, serviceS3)
+	default:
+		return ""
+	}
+	if s3Err != ErrNone {
+		return ""
+	}
+	return cred.AccessKey
+}
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/minio-go/v6/pkg/credentials"
+	"github.com/minio/minio/pkg/hash"
+)
+
+func TestValidateUserCredentials(t *testing.T) {
+	clientKeys := map[string]bool{"client": true}
+	testCases := []struct {
+		users      map[string]userCredentials
+		compat     string
+		shouldPass bool
+	}{
+		{nil, "", true},
+		{map[string]userCredentials{"client": {AccessKey: "user", SecretKey: "secret"}}, "", true},
+		{map[string]userCredentials{"unknown": {AccessKey: "user", SecretKey: "secret"}}, "", false},
+		{map[string]userCredentials{"client": {AccessKey: "user"}}, "", false},
+		{map[string]userCredentials{"client": {AccessKey: "user", SecretKey: "secret", SessionToken: "token"}}, "", true},
+		{map[string]userCredentials{"client": {AccessKey: "user", SecretKey: "secret", SessionToken: "token"}}, "legacy", false},
+	}
+	for i, testCase := range testCases {
+		remotes := []remoteConfig{{Endpoint: "https://s3.example.com", Bucket: "remote", Compat: testCase.compat, Users: testCase.users}}
+		err := validateUserCredentials("bucket", remotes, clientKeys)
+		if testCase.shouldPass && err != nil {
+			t.Errorf("Test %d: Unexpected error %v", i+1, err)
+		}
+		if !testCase.shouldPass && err == nil {
+			t.Errorf("Test %d: Expected an error", i+1)
+		}
+	}
+}
+
+// accessKeyHandler records the access keys signing the requests served
+// by handler.
+type accessKeyHandler struct {
+	handler    http.Handler
+	mu         sync.Mutex
+	accessKeys []string
+}
+
+func (h *accessKeyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	accessKey := strings.TrimPrefix(r.Header.Get("Authorization"), signV4Algorithm+" Credential=")
+	if i := strings.Index(accessKey, "/"); i >= 0 {
+		accessKey = accessKey[:i]
+	}
+	h.mu.Lock()
+	h.accessKeys = append(h.accessKeys, accessKey)
+	h.mu.Unlock()
+	h.handler.ServeHTTP(w, r)
+}
+
+func (h *accessKeyHandler) reset() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	accessKeys := h.accessKeys
+	h.accessKeys = nil
+	return accessKeys
+}
+
+func newTestUserCore(t *testing.T, rawURL, accessKey string) *miniogo.Core {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clnt, err := miniogo.NewWithOptions(u.Host, &miniogo.Options{
+		Creds:  credentials.NewStaticV4(accessKey, "secretkey", ""),
+		Region: "us-east-1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &miniogo.Core{Client: clnt}
+}
+
+// Tests that the requests of mapped clients are signed with their own
+// credentials, and those of other clients with the ones of the remote.
+func TestUserCredentials(t *testing.T) {
+	remotes, _, shutdown := newTestRemotes(t, 1)
+	defer shutdown()
+	remote := &accessKeyHandler{handler: remotes[0]}
+	ts := httptest.NewServer(remote)
+	defer ts.Close()
+
+	l := &radioObjects{
+		nsMutex: newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: []bucketClient{{
+			Core:   newTestUserCore(t, ts.URL, "accesskey"),
+			Bucket: testRemoteBucket,
+			users:  map[string]*userClients{"client": {core: newTestUserCore(t, ts.URL, "user")}},
+		}}}},
+	}
+
+	for _, testCase := range []struct {
+		clientKey, remoteKey string
+	}{
+		{"client", "user"},
+		{"other", "accesskey"},
+		{"", "accesskey"},
+	} {
+		ctx := withClientAccessKey(context.Background(), testCase.clientKey)
+		data := []byte("data")
+		reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), "", "", int64(len(data)), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = l.PutObject(ctx, "bucket", "object", NewPutObjReader(reader, nil, nil), ObjectOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = l.GetObjectInfo(ctx, "bucket", "object", ObjectOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		accessKeys := remote.reset()
+		if len(accessKeys) == 0 {
+			t.Fatalf("Client %q: expected requests to the remote", testCase.clientKey)
+		}
+		for _, accessKey := range accessKeys {
+			if accessKey != testCase.remoteKey {
+				t.Errorf("Client %q: expected requests signed by %q, got %v", testCase.clientKey, testCase.remoteKey, accessKeys)
+				break
+			}
+		}
+	}
+}
+
+// Tests that cached listings are not shared by clients mapped to
+// different users, each listing the remote with its own credentials.
+func TestUserCredentialsListCache(t *testing.T) {
+	remotes, _, shutdown := newTestRemotes(t, 1)
+	defer shutdown()
+	remotes[0].putObject("object", "data", nil)
+	remote := &accessKeyHandler{handler: remotes[0]}
+	ts := httptest.NewServer(remote)
+	defer ts.Close()
+
+	l := &radioObjects{
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: []bucketClient{{
+			Core:   newTestUserCore(t, ts.URL, "accesskey"),
+			Bucket: testRemoteBucket,
+			users: map[string]*userClients{
+				"client1": {core: newTestUserCore(t, ts.URL, "user1")},
+				"client2": {core: newTestUserCore(t, ts.URL, "user2")},
+			},
+		}}}},
+		listCache: newListCache(time.Minute),
+	}
+
+	for _, testCase := range []struct {
+		clientKey string
+		// Access keys of the requests reaching the remote, none for
+		// listings served from the cache.
+		remoteKeys []string
+	}{
+		{"client1", []string{"user1"}},
+		{"client2", []string{"user2"}},
+		{"client1", nil},
+		{"client2", nil},
+		{"other", []string{"accesskey"}},
+		{"", nil},
+	} {
+		ctx := withClientAccessKey(context.Background(), testCase.clientKey)
+		for _, v2 := range []bool{false, true} {
+			var err error
+			var objects []ObjectInfo
+			if v2 {
+				var loi ListObjectsV2Info
+				loi, err = l.ListObjectsV2(ctx, "bucket", "", "", "", 1000, false, "")
+				objects = loi.Objects
+			} else {
+				var loi ListObjectsInfo
+				loi, err = l.ListObjects(ctx, "bucket", "", "", "", 1000)
+				objects = loi.Objects
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(objects) != 1 {
+				t.Errorf("Client %q: expected 1 object, got %d", testCase.clientKey, len(objects))
+			}
+			if accessKeys := remote.reset(); !reflect.DeepEqual(accessKeys, testCase.remoteKeys) {
+				t.Errorf("Client %q, v2 %v: expected listings signed by %v, got %v",
+					testCase.clientKey, v2, testCase.remoteKeys, accessKeys)
+			}
+		}
+	}
+}
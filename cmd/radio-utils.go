@@ -1,6 +1,12 @@
 package cmd
 
-import "context"
+import (
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"time"
+)
 
 // Returns number of errors that occurred the most (incl. nil) and the
 // corresponding error value. NB When there is more than one error value that
@@ -51,7 +57,127 @@ func reduceReadQuorumErrs(ctx context.Context, errs []error, ignoredErrs []error
 }
 
 // reduceWriteQuorumErrs behaves like reduceErrs but only for returning
-// values of maximally occurring errors validated against writeQuorum.
+// values of maximally occurring errors validated against writeQuorum,
+// QuorumNotMet reports the number of successes if none reaches it. The
+// error of a throttling replica is preferred over QuorumNotMet, for
+// clients to back off.
 func reduceWriteQuorumErrs(ctx context.Context, errs []error, ignoredErrs []error, writeQuorum int) (maxErr error) {
-	return reduceQuorumErrs(ctx, errs, ignoredErrs, writeQuorum, InsufficientWriteQuorum{})
+	succeeded := 0
+	var throttleErr error
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+		} else if throttleErr == nil && isThrottleErr(err) {
+			throttleErr = err
+		}
+	}
+	quorumErr := error(QuorumNotMet{
+		Succeeded: succeeded,
+		Required:  writeQuorum,
+	})
+	if throttleErr != nil {
+		quorumErr = throttleErr
+	}
+	return reduceQuorumErrs(ctx, errs, ignoredErrs, writeQuorum, quorumErr)
+}
+
+// errReadOnlyReplica is reported for read-only replicas skipped by writes.
+var errReadOnlyReplica = errors.New("replica is read-only")
+
+// errShadowReplica masks the results of writes to shadow replicas.
+var errShadowReplica = errors.New("replica is a shadow")
+
+// skippedReplicaErrs are ignored when reducing the errors of writes.
+var skippedReplicaErrs = []error{errReadOnlyReplica, errShadowReplica, errAsyncReplica}
+
+// failedReplicas returns the indices of the writable replicas whose
+// operation failed or was left to heal, shadow replicas excluded.
+func failedReplicas(errs []error) []int {
+	var failed []int
+	for index, err := range errs {
+		if err != nil && err != errReadOnlyReplica && err != errShadowReplica {
+			failed = append(failed, index)
+		}
+	}
+	return failed
+}
+
+// meterDegraded counts op as degraded if any of the replicas expected
+// to take part in it failed, errs being the results of a completed op.
+func meterDegraded(op string, errs []error) {
+	if len(failedReplicas(errs)) > 0 {
+		degradedOperations.WithLabelValues(op).Inc()
+	}
+}
+
+// firstSucceeded returns the index of the first replica whose operation
+// succeeded, -1 if none did.
+func firstSucceeded(errs []error) int {
+	for index, err := range errs {
+		if err == nil {
+			return index
+		}
+	}
+	return -1
+}
+
+// withDeadline bounds ctx by timeout unless ctx already carries a
+// deadline or timeout is zero.
+func withDeadline(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// errReadStalled is returned by replica reads receiving no data within
+// the configured read stall timeout.
+var errReadStalled = errors.New("replica read stalled")
+
+// stallWatchdog cancels a replica read once it waited on the remote for
+// its timeout, time spent writing out the data read does not count. A
+// nil watchdog never fires.
+type stallWatchdog struct {
+	timeout time.Duration
+	timer   *time.Timer
+	fired   int32
+}
+
+func newStallWatchdog(timeout time.Duration, cancel context.CancelFunc) *stallWatchdog {
+	d := &stallWatchdog{timeout: timeout}
+	d.timer = time.AfterFunc(timeout, func() {
+		atomic.StoreInt32(&d.fired, 1)
+		cancel()
+	})
+	return d
+}
+
+// stalled returns true if the watchdog cancelled the read.
+func (d *stallWatchdog) stalled() bool {
+	return d != nil && atomic.LoadInt32(&d.fired) == 1
+}
+
+func (d *stallWatchdog) stop() {
+	if d != nil {
+		d.timer.Stop()
+	}
+}
+
+// reader returns r watching each of its reads.
+func (d *stallWatchdog) reader(r io.Reader) io.Reader {
+	if d == nil {
+		return r
+	}
+	return &stallReader{Reader: r, watchdog: d}
+}
+
+type stallReader struct {
+	io.Reader
+	watchdog *stallWatchdog
+}
+
+func (r *stallReader) Read(p []byte) (int, error) {
+	r.watchdog.timer.Reset(r.watchdog.timeout)
+	defer r.watchdog.timer.Stop()
+	return r.Reader.Read(p)
 }
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/minio/pkg/hash"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// Tests that withDeadline only bounds contexts carrying no deadline.
+func TestWithDeadline(t *testing.T) {
+	ctx, cancel := withDeadline(context.Background(), 0)
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("Expected no deadline with a zero timeout")
+	}
+
+	ctx, cancel = withDeadline(context.Background(), time.Minute)
+	defer cancel()
+	if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) > time.Minute {
+		t.Errorf("Expected a deadline within a minute, got %v", deadline)
+	}
+
+	parent, cancelParent := context.WithTimeout(context.Background(), time.Hour)
+	defer cancelParent()
+	want, _ := parent.Deadline()
+	ctx, cancel = withDeadline(parent, time.Minute)
+	defer cancel()
+	if deadline, _ := ctx.Deadline(); !deadline.Equal(want) {
+		t.Errorf("Expected the client deadline %v to be kept, got %v", want, deadline)
+	}
+}
+
+// Tests that writes to stalling remotes fail once the write timeout
+// elapsed.
+func TestWriteTimeout(t *testing.T) {
+	remotes, clnts, shutdown := newTestRemotes(t, 2)
+	defer shutdown()
+	stall := make(chan struct{})
+	defer close(stall)
+	for _, remote := range remotes {
+		remote.setHook(func(w http.ResponseWriter, r *http.Request) bool {
+			<-stall
+			return true
+		})
+	}
+	l := &radioObjects{
+		nsMutex:       newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+		timeouts:      timeoutsConfig{Write: 100 * time.Millisecond},
+	}
+
+	data := []byte("hello")
+	reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), "", "", int64(len(data)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if _, err = l.PutObject(context.Background(), "bucket", "object", NewPutObjReader(reader, nil, nil),
+		ObjectOptions{UserDefined: map[string]string{}}); err == nil {
+		t.Fatal("Expected PutObject to fail")
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Expected PutObject to fail after the write timeout, took %v", elapsed)
+	}
+}
+
+// Tests that unmet write quorums are reported with the number of
+// replicas which succeeded.
+func TestReduceWriteQuorumErrs(t *testing.T) {
+	errDisk := errors.New("disk failure")
+	testCases := []struct {
+		errs     []error
+		expected error
+	}{
+		{[]error{nil, nil, errDisk}, nil},
+		{[]error{errDisk, errDisk, nil}, errDisk},
+		{[]error{nil, errDisk, errFileNotFound}, QuorumNotMet{Succeeded: 1, Required: 2}},
+		{[]error{errDisk, errFileNotFound, errFileAccessDenied}, QuorumNotMet{Succeeded: 0, Required: 2}},
+	}
+	for i, testCase := range testCases {
+		if err := reduceWriteQuorumErrs(context.Background(), testCase.errs, nil, 2); err != testCase.expected {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.expected, err)
+		}
+	}
+
+	apiErr := toAPIError(context.Background(), QuorumNotMet{Succeeded: 1, Required: 2})
+	if apiErr.Code != "XRadioQuorumNotMet" || apiErr.HTTPStatusCode != http.StatusServiceUnavailable ||
+		!strings.Contains(apiErr.Description, "1 replicas succeeded, 2 required") {
+		t.Errorf("Unexpected API error %+v", apiErr)
+	}
+}
+
+// Tests that operations are counted as degraded only when a replica
+// failed to take part in them.
+func TestDegradedOperations(t *testing.T) {
+	remotes, clnts, shutdown := newTestRemotes(t, 3)
+	defer shutdown()
+	l := &radioObjects{
+		nsMutex:       newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+	}
+	ctx := context.Background()
+	put := func(object string) {
+		t.Helper()
+		data := []byte("hello")
+		reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), "", "", int64(len(data)), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = l.PutObject(ctx, "bucket", object, NewPutObjReader(reader, nil, nil),
+			ObjectOptions{UserDefined: map[string]string{}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	degraded := func(op string) float64 {
+		return testutil.ToFloat64(degradedOperations.WithLabelValues(op))
+	}
+
+	puts, deletes := degraded("PutObject"), degraded("DeleteObject")
+	put("full")
+	if n := degraded("PutObject") - puts; n != 0 {
+		t.Errorf("Expected no degraded PutObject, got %v", n)
+	}
+
+	remotes[2].setHook(func(w http.ResponseWriter, r *http.Request) bool {
+		writeTestRemoteError(w, r, http.StatusForbidden, "AccessDenied")
+		return true
+	})
+	put("degraded")
+	if n := degraded("PutObject") - puts; n != 1 {
+		t.Errorf("Expected 1 degraded PutObject, got %v", n)
+	}
+	if err := l.DeleteObject(ctx, "bucket", "full"); err != nil {
+		t.Fatal(err)
+	}
+	if n := degraded("DeleteObject") - deletes; n != 1 {
+		t.Errorf("Expected 1 degraded DeleteObject, got %v", n)
+	}
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/minio/pkg/policy"
+	"github.com/minio/minio/pkg/sync/errgroup"
+	"github.com/minio/sha256-simd"
+)
+
+// verifyResult reports whether all replicas of an object hold the same
+// bytes, along with the SHA-256 of the content read from each replica.
+type verifyResult struct {
+	Object   string          `json:"object"`
+	Match    bool            `json:"match"`
+	Replicas []verifyReplica `json:"replicas"`
+}
+
+type verifyReplica struct {
+	SHA256 string `json:"sha256,omitempty"`
+	Size   int64  `json:"size"`
+	Error  string `json:"error,omitempty"`
+}
+
+// VerifyHandler - streams an object from every replica, comparing the
+// SHA-256 of their content without modifying anything. The optional
+// rate parameter limits the bytes read per second from each replica.
+func VerifyHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r, w, "Verify")
+
+	query := r.URL.Query()
+	bucket := query.Get("bucket")
+	object := query.Get("object")
+
+	if s3Error := checkRequestAuthType(ctx, r, policy.GetObjectAction, bucket, object); s3Error != ErrNone {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(s3Error), r.URL)
+		return
+	}
+
+	l, ok := newObjectLayerFn().(*radioObjects)
+	if !ok {
+		writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrServerNotInitialized), r.URL)
+		return
+	}
+	rs3s, ok := l.mirrorClients[bucket]
+	if !ok {
+		writeErrorResponse(ctx, w, toAPIError(ctx, BucketNotFound{Bucket: bucket}), r.URL)
+		return
+	}
+	var rate int64
+	if v := query.Get("rate"); v != "" {
+		var err error
+		if rate, err = strconv.ParseInt(v, 10, 64); err != nil || rate < 0 {
+			writeErrorResponse(ctx, w, errorCodes.ToAPIErr(ErrInvalidQueryParams), r.URL)
+			return
+		}
+	}
+
+	// Hold off writes to the object, which would be reported as a
+	// mismatch.
+	objectLock := l.NewNSLock(ctx, bucket, object)
+	if err := objectLock.GetRLock(globalObjectTimeout); err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	defer objectLock.RUnlock()
+
+	data, err := json.Marshal(verifyObject(ctx, rs3s.forObject(object), object, rate))
+	if err != nil {
+		writeErrorResponse(ctx, w, toAPIError(ctx, err), r.URL)
+		return
+	}
+	writeResponse(w, http.StatusOK, data, mimeJSON)
+}
+
+// verifyObject reads object from all replicas concurrently, at most
+// rate bytes per second from each when rate is positive.
+func verifyObject(ctx context.Context, rs3s mirrorConfig, object string, rate int64) verifyResult {
+	n := len(rs3s.clnts)
+	res := verifyResult{Object: object, Replicas: make([]verifyReplica, n)}
+	g := errgroup.WithNErrs(n)
+	for index := range rs3s.clnts {
+		index := index
+		g.Go(func() error {
+			res.Replicas[index] = verifyReplicaContent(ctx, rs3s.clnts[index], object, rate)
+			return nil
+		}, index)
+	}
+	g.Wait()
+
+	res.Match = true
+	for _, replica := range res.Replicas {
+		if replica.Error != "" || replica.SHA256 != res.Replicas[0].SHA256 {
+			res.Match = false
+		}
+	}
+	return res
+}
+
+// verifyReplicaContent returns the SHA-256 of object as read from clnt.
+func verifyReplicaContent(ctx context.Context, clnt bucketClient, object string, rate int64) (replica verifyReplica) {
+	reader, _, _, err := clnt.GetObjectWithContext(ctx, clnt.Bucket, clnt.objectKey(object), miniogo.GetObjectOptions{})
+	if err != nil {
+		replica.Error = err.Error()
+		return replica
+	}
+	defer reader.Close()
+
+	var src io.Reader = reader
+	if rate > 0 {
+		src = &throttledReader{ctx: ctx, r: reader, rate: rate, start: time.Now()}
+	}
+	h := sha256.New()
+	if replica.Size, err = io.Copy(h, src); err != nil {
+		replica.Error = err.Error()
+		return replica
+	}
+	replica.SHA256 = hex.EncodeToString(h.Sum(nil))
+	return replica
+}
+
+// throttledReader reads from r at most rate bytes per second on average.
+type throttledReader struct {
+	ctx   context.Context
+	r     io.Reader
+	rate  int64
+	start time.Time
+	read  int64
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > t.rate {
+		p = p[:t.rate]
+	}
+	n, err := t.r.Read(p)
+	t.read += int64(n)
+
+	due := t.start.Add(time.Duration(float64(t.read) / float64(t.rate) * float64(time.Second)))
+	if wait := time.Until(due); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-t.ctx.Done():
+			return n, t.ctx.Err()
+		}
+	}
+	return n, err
+}
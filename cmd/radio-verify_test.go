@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/minio-go/v6/pkg/credentials"
+)
+
+// Tests that replicas are reported to match only when all of them hold
+// the same bytes.
+func TestVerifyObject(t *testing.T) {
+	var servers []*httptest.Server
+	defer func() {
+		for _, ts := range servers {
+			ts.Close()
+		}
+	}()
+	newReplica := func(content string) bucketClient {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if content == "" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			// Same ETag whatever the content, as if uploaded in
+			// different parts.
+			w.Header().Set("ETag", `"etag-2"`)
+			w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			w.Write([]byte(content))
+		}))
+		servers = append(servers, ts)
+		u, err := url.Parse(ts.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clnt, err := miniogo.NewWithOptions(u.Host, &miniogo.Options{
+			Creds:  credentials.NewStaticV4("accesskey", "secretkey", ""),
+			Region: "us-east-1",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return bucketClient{Core: &miniogo.Core{Client: clnt}, Bucket: "remote"}
+	}
+
+	testCases := []struct {
+		contents      []string
+		expectedMatch bool
+		expectedErrs  []bool
+	}{
+		{[]string{"data", "data", "data"}, true, []bool{false, false, false}},
+		{[]string{"data", "datb"}, false, []bool{false, false}},
+		{[]string{"data", ""}, false, []bool{false, true}},
+	}
+
+	for i, testCase := range testCases {
+		var rs3s mirrorConfig
+		for _, content := range testCase.contents {
+			rs3s.clnts = append(rs3s.clnts, newReplica(content))
+		}
+		res := verifyObject(context.Background(), rs3s, "object", 0)
+		if res.Match != testCase.expectedMatch {
+			t.Errorf("Test %d: Expected match %t, got %t", i+1, testCase.expectedMatch, res.Match)
+		}
+		for index, replica := range res.Replicas {
+			if (replica.Error != "") != testCase.expectedErrs[index] {
+				t.Errorf("Test %d: Unexpected error %q on replica %d", i+1, replica.Error, index)
+			}
+			if replica.Error == "" && replica.Size != int64(len(testCase.contents[index])) {
+				t.Errorf("Test %d: Expected size %d on replica %d, got %d", i+1, len(testCase.contents[index]), index, replica.Size)
+			}
+		}
+	}
+}
+
+// Tests that throttled reads take as long as the rate requires.
+func TestThrottledReader(t *testing.T) {
+	data := make([]byte, 300)
+	start := time.Now()
+	r := &throttledReader{ctx: context.Background(), r: bytes.NewReader(data), rate: 1000, start: start}
+	n, err := ioutil.ReadAll(r)
+	if err != nil || len(n) != len(data) {
+		t.Fatalf("Unexpected read of %d bytes, error %v", len(n), err)
+	}
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+		t.Errorf("Expected read to take at least 300ms, took %s", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r = &throttledReader{ctx: ctx, r: bytes.NewReader(data), rate: 10, start: time.Now()}
+	if _, err = ioutil.ReadAll(r); err != context.Canceled {
+		t.Errorf("Expected %v, got %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,395 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/minio-go/v6/pkg/credentials"
+	"github.com/minio/minio-go/v6/pkg/s3signer"
+	"github.com/minio/minio-go/v6/pkg/s3utils"
+	"github.com/minio/radio/cmd/logger"
+)
+
+// defaultMergedListingTimeout is how long merged listings wait for all
+// replicas when no timeout is configured.
+const defaultMergedListingTimeout = 10 * time.Second
+
+// Payload hash of requests without a body.
+const emptySHA256Hex = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// versionsClient sends the requests minio-go does not implement to a
+// remote bucket, listing object versions and restoring archived
+// objects.
+type versionsClient struct {
+	endpoint  url.URL
+	creds     *credentials.Credentials
+	region    string
+	transport http.RoundTripper
+}
+
+func newVersionsClient(urlStr, accessKey, secretKey, sessionToken string, transport http.RoundTripper) (*versionsClient, error) {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return nil, err
+	}
+	region := s3utils.GetRegionFromURL(*u)
+	if region == "" {
+		region = "us-east-1"
+	}
+	return &versionsClient{
+		endpoint:  *u,
+		creds:     credentials.NewStaticV4(accessKey, secretKey, sessionToken),
+		region:    region,
+		transport: transport,
+	}, nil
+}
+
+// newRequest returns a signed request of object in bucket, or of bucket
+// if object is empty, with the given query and body.
+func (c *versionsClient) newRequest(ctx context.Context, method, bucket, object string, query url.Values,
+	body []byte) (*http.Request, error) {
+	u := c.endpoint
+	if s3utils.IsAmazonEndpoint(u) {
+		u.Host = bucket + "." + u.Host
+		u.Path = "/" + object
+	} else {
+		u.Path = "/" + bucket + "/" + object
+	}
+	u.RawPath = s3utils.EncodePath(u.Path)
+	u.RawQuery = s3utils.QueryEncode(query)
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	payloadSHA256 := emptySHA256Hex
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		payloadSHA256 = hex.EncodeToString(sum[:])
+	}
+	req.Header.Set("X-Amz-Content-Sha256", payloadSHA256)
+	creds, err := c.creds.Get()
+	if err != nil {
+		return nil, err
+	}
+	return s3signer.SignV4(*req, creds.AccessKeyID, creds.SecretAccessKey, creds.SessionToken, c.region), nil
+}
+
+// listVersionsResult is the ListObjectVersions response of a remote.
+type listVersionsResult struct {
+	CommonPrefixes      []miniogo.CommonPrefix
+	Versions            []versionEntry `xml:"Version"`
+	DeleteMarkers       []versionEntry `xml:"DeleteMarker"`
+	IsTruncated         bool
+	NextKeyMarker       string
+	NextVersionIDMarker string `xml:"NextVersionIdMarker"`
+}
+
+type versionEntry struct {
+	Key          string
+	VersionID    string `xml:"VersionId"`
+	IsLatest     bool
+	LastModified time.Time
+	ETag         string
+	Size         int64
+	StorageClass string
+}
+
+// listObjectVersions lists up to maxKeys versions of the objects under
+// prefix in bucket, following the key and version id markers.
+func (c *versionsClient) listObjectVersions(ctx context.Context, bucket, prefix, keyMarker, versionIDMarker,
+	delimiter string, maxKeys int) (result listVersionsResult, err error) {
+	query := url.Values{}
+	query.Set("versions", "")
+	query.Set("prefix", prefix)
+	query.Set("delimiter", delimiter)
+	if keyMarker != "" {
+		query.Set("key-marker", keyMarker)
+	}
+	if versionIDMarker != "" {
+		query.Set("version-id-marker", versionIDMarker)
+	}
+	if maxKeys > 0 {
+		query.Set("max-keys", strconv.Itoa(maxKeys))
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, bucket, "", query, nil)
+	if err != nil {
+		return result, err
+	}
+
+	resp, err := c.transport.RoundTrip(req)
+	if err != nil {
+		return result, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return result, remoteErrorResponse(resp, bucket, "")
+	}
+	err = xml.NewDecoder(resp.Body).Decode(&result)
+	return result, err
+}
+
+// restoreObject sends restoreRequest to restore the archived object in
+// bucket, returning the status of the remote, 202 Accepted while the
+// object is being retrieved and 200 OK if a restored copy exists.
+func (c *versionsClient) restoreObject(ctx context.Context, bucket, object string, restoreRequest []byte) (int, error) {
+	query := url.Values{}
+	query.Set("restore", "")
+	req, err := c.newRequest(ctx, http.MethodPost, bucket, object, query, restoreRequest)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := c.transport.RoundTrip(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return 0, remoteErrorResponse(resp, bucket, object)
+	}
+	return resp.StatusCode, nil
+}
+
+// remoteErrorResponse returns the error of a failed response of a
+// remote, the status standing in for the code of unparsable errors.
+func remoteErrorResponse(resp *http.Response, bucket, object string) error {
+	errResp := miniogo.ErrorResponse{StatusCode: resp.StatusCode}
+	if xml.NewDecoder(resp.Body).Decode(&errResp) != nil || errResp.Code == "" {
+		errResp.Code = resp.Status
+	}
+	errResp.BucketName = bucket
+	errResp.Key = object
+	return errResp
+}
+
+// objectVersions returns the versions and delete markers of result as
+// ObjectInfo in listing order, newest version of a key first.
+func (c bucketClient) objectVersions(bucket string, result listVersionsResult) []ObjectInfo {
+	objects := make([]ObjectInfo, 0, len(result.Versions)+len(result.DeleteMarkers))
+	for _, v := range result.Versions {
+		objects = append(objects, ObjectInfo{
+			Bucket:       bucket,
+			Name:         c.listKey(v.Key),
+			ModTime:      v.LastModified,
+			Size:         v.Size,
+			ETag:         strings.Trim(v.ETag, "\""),
+			StorageClass: v.StorageClass,
+			VersionID:    v.VersionID,
+			IsLatest:     v.IsLatest,
+		})
+	}
+	for _, v := range result.DeleteMarkers {
+		objects = append(objects, ObjectInfo{
+			Bucket:       bucket,
+			Name:         c.listKey(v.Key),
+			ModTime:      v.LastModified,
+			VersionID:    v.VersionID,
+			IsLatest:     v.IsLatest,
+			DeleteMarker: true,
+		})
+	}
+	sort.SliceStable(objects, func(i, j int) bool {
+		return versionLess(objects[i], objects[j])
+	})
+	return objects
+}
+
+// versionLess orders versions by key, then from newest to oldest.
+func versionLess(a, b ObjectInfo) bool {
+	if a.Name != b.Name {
+		return a.Name < b.Name
+	}
+	return a.ModTime.After(b.ModTime)
+}
+
+// ListObjectVersions lists object versions and delete markers of bucket
+// from the first readable replica supporting version listings. Buckets
+// with merge_versions set merge the versions of all replicas, which is
+// meant for remotes sharing version ids.
+func (l *radioObjects) ListObjectVersions(ctx context.Context, bucket, prefix, marker, versionIDMarker, delimiter string, maxKeys int) (loi ListObjectVersionsInfo, e error) {
+	if err := l.operationAllowed(operationListObjects); err != nil {
+		return loi, err
+	}
+
+	rs3, ok := l.mirrorClients[bucket]
+	if !ok {
+		return loi, BucketNotFound{
+			Bucket: bucket,
+		}
+	}
+	rs3 = rs3.forUser(ctx)
+	prefix, marker = rs3.keys.prefix(prefix), rs3.keys.prefix(marker)
+	if rs3.mergeVersions {
+		return l.listMergedVersions(ctx, bucket, rs3, prefix, marker, versionIDMarker, delimiter, maxKeys)
+	}
+
+	err := error(NotImplemented{})
+	for _, clnt := range rs3.clnts {
+		if !clnt.listsVersions() {
+			continue
+		}
+		var page ListObjectVersionsInfo
+		page, err = clnt.reader().listVersionsPage(ctx, bucket, prefix, marker, versionIDMarker, delimiter, maxKeys)
+		if err == nil {
+			return page, nil
+		}
+	}
+	return loi, ErrorRespToObjectError(err, bucket)
+}
+
+// listMergedVersions merges the versions listed from all replicas which
+// respond within the merged listing timeout. Replicas not responding in
+// time are skipped and reported in the result, which may then miss some
+// versions.
+func (l *radioObjects) listMergedVersions(ctx context.Context, bucket string, rs3 mirrorConfig,
+	prefix, marker, versionIDMarker, delimiter string, maxKeys int) (loi ListObjectVersionsInfo, e error) {
+	timeout := l.timeouts.MergedListing
+	if timeout <= 0 {
+		timeout = defaultMergedListingTimeout
+	}
+	lctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type replicaPage struct {
+		index int
+		page  ListObjectVersionsInfo
+		err   error
+	}
+	results := make(chan replicaPage, len(rs3.clnts))
+	pending := make(map[int]bool)
+	for index, clnt := range rs3.clnts {
+		if !clnt.listsVersions() {
+			continue
+		}
+		pending[index] = true
+		go func(index int, clnt bucketClient) {
+			page, err := clnt.reader().listVersionsPage(lctx, bucket, prefix, marker, versionIDMarker, delimiter, maxKeys)
+			results <- replicaPage{index, page, err}
+		}(index, clnt)
+	}
+
+	var pages []ListObjectVersionsInfo
+	err := error(NotImplemented{})
+wait:
+	for len(pending) > 0 {
+		select {
+		case r := <-results:
+			delete(pending, r.index)
+			if r.err != nil {
+				err = r.err
+				continue
+			}
+			pages = append(pages, r.page)
+		case <-lctx.Done():
+			break wait
+		}
+	}
+	if len(pages) == 0 {
+		if len(pending) > 0 {
+			return loi, errOperationTimedOut
+		}
+		return loi, ErrorRespToObjectError(err, bucket)
+	}
+
+	loi = mergeVersionPages(pages, maxKeys)
+	for index := range pending {
+		loi.SkippedReplicas = append(loi.SkippedReplicas, index)
+	}
+	if len(loi.SkippedReplicas) > 0 {
+		sort.Ints(loi.SkippedReplicas)
+		logger.LogIf(ctx, fmt.Errorf("merged version listing of %s skipped replicas %v not responding within %s",
+			bucket, loi.SkippedReplicas, timeout))
+	}
+	return loi, nil
+}
+
+// listsVersions returns true if versions can be listed from clnt.
+func (c bucketClient) listsVersions() bool {
+	return !c.Shadow && c.Compat != compatLegacy && c.versions != nil
+}
+
+// listVersionsPage lists a page of the versions of c.
+func (c bucketClient) listVersionsPage(ctx context.Context, bucket, prefix, marker, versionIDMarker,
+	delimiter string, maxKeys int) (page ListObjectVersionsInfo, err error) {
+	result, err := c.versions.listObjectVersions(ctx, c.Bucket, c.objectKey(prefix),
+		c.markerKey(marker), versionIDMarker, delimiter, maxKeys)
+	if err != nil {
+		return page, err
+	}
+	page = ListObjectVersionsInfo{
+		IsTruncated:         result.IsTruncated,
+		NextMarker:          c.listKey(result.NextKeyMarker),
+		NextVersionIDMarker: result.NextVersionIDMarker,
+		Objects:             c.objectVersions(bucket, result),
+	}
+	for _, p := range result.CommonPrefixes {
+		page.Prefixes = append(page.Prefixes, c.listKey(p.Prefix))
+	}
+	return page, nil
+}
+
+// mergeVersionPages merges pages of versions listed from different
+// replicas with the same markers. The merged page ends at the earliest
+// end of a truncated page, so that the next page resumes where every
+// replica left off.
+func mergeVersionPages(pages []ListObjectVersionsInfo, maxKeys int) (loi ListObjectVersionsInfo) {
+	var horizon *ObjectInfo
+	seen := make(map[string]bool)
+	prefixes := make(map[string]bool)
+	for _, page := range pages {
+		if page.IsTruncated {
+			loi.IsTruncated = true
+			if n := len(page.Objects); n > 0 && (horizon == nil || versionLess(page.Objects[n-1], *horizon)) {
+				horizon = &page.Objects[n-1]
+			}
+		}
+		for _, object := range page.Objects {
+			id := object.Name + "\x00" + object.VersionID
+			if !seen[id] {
+				seen[id] = true
+				loi.Objects = append(loi.Objects, object)
+			}
+		}
+		for _, prefix := range page.Prefixes {
+			if !prefixes[prefix] {
+				prefixes[prefix] = true
+				loi.Prefixes = append(loi.Prefixes, prefix)
+			}
+		}
+	}
+	sort.SliceStable(loi.Objects, func(i, j int) bool {
+		return versionLess(loi.Objects[i], loi.Objects[j])
+	})
+	sort.Strings(loi.Prefixes)
+
+	end := len(loi.Objects)
+	if horizon != nil {
+		end = sort.Search(len(loi.Objects), func(i int) bool {
+			return versionLess(*horizon, loi.Objects[i])
+		})
+	}
+	if maxKeys > 0 && end > maxKeys {
+		end = maxKeys
+		loi.IsTruncated = true
+	}
+	loi.Objects = loi.Objects[:end]
+	if loi.IsTruncated && end > 0 {
+		loi.NextMarker = loi.Objects[end-1].Name
+		loi.NextVersionIDMarker = loi.Objects[end-1].VersionID
+	}
+	return loi
+}
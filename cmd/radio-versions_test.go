@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Tests that merged version pages end where the earliest truncated
+// replica page ends.
+func TestMergeVersionPages(t *testing.T) {
+	t1 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	version := func(name, versionID string, modTime time.Time) ObjectInfo {
+		return ObjectInfo{Name: name, VersionID: versionID, ModTime: modTime}
+	}
+
+	testCases := []struct {
+		pages       []ListObjectVersionsInfo
+		maxKeys     int
+		expected    []string
+		isTruncated bool
+		nextMarker  string
+		nextVersion string
+	}{
+		// Same versions on both replicas.
+		{
+			[]ListObjectVersionsInfo{
+				{Objects: []ObjectInfo{version("a", "2", t2), version("a", "1", t1)}},
+				{Objects: []ObjectInfo{version("a", "2", t2), version("a", "1", t1)}},
+			},
+			10, []string{"a/2", "a/1"}, false, "", "",
+		},
+		// Second replica truncated before the first.
+		{
+			[]ListObjectVersionsInfo{
+				{Objects: []ObjectInfo{version("a", "1", t1), version("c", "3", t1)}},
+				{IsTruncated: true, Objects: []ObjectInfo{version("a", "1", t1), version("b", "2", t1)}},
+			},
+			2, []string{"a/1", "b/2"}, true, "b", "2",
+		},
+		// Merged page cut at maxKeys.
+		{
+			[]ListObjectVersionsInfo{
+				{Objects: []ObjectInfo{version("a", "1", t1)}},
+				{Objects: []ObjectInfo{version("b", "2", t2), version("b", "1", t1)}},
+			},
+			2, []string{"a/1", "b/2"}, true, "b", "2",
+		},
+	}
+	for i, testCase := range testCases {
+		loi := mergeVersionPages(testCase.pages, testCase.maxKeys)
+		var got []string
+		for _, object := range loi.Objects {
+			got = append(got, object.Name+"/"+object.VersionID)
+		}
+		if !reflect.DeepEqual(got, testCase.expected) {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.expected, got)
+		}
+		if loi.IsTruncated != testCase.isTruncated || loi.NextMarker != testCase.nextMarker ||
+			loi.NextVersionIDMarker != testCase.nextVersion {
+			t.Errorf("Test %d: unexpected truncation %v %q %q", i+1, loi.IsTruncated, loi.NextMarker, loi.NextVersionIDMarker)
+		}
+	}
+}
+
+// Tests that merged version listings skip replicas not responding
+// within the merged listing timeout.
+func TestListMergedVersions(t *testing.T) {
+	testCases := []struct {
+		delays          []time.Duration
+		shouldPass      bool
+		expected        []string
+		expectedSkipped []int
+	}{
+		// All replicas respond.
+		{[]time.Duration{0, 0}, true, []string{"object0", "object1"}, nil},
+		// Slow replica skipped.
+		{[]time.Duration{0, time.Second}, true, []string{"object0"}, []int{1}},
+		// No replica responds.
+		{[]time.Duration{time.Second, time.Second}, false, nil, nil},
+	}
+
+	for i, testCase := range testCases {
+		var clnts []bucketClient
+		var servers []*httptest.Server
+		for index, delay := range testCase.delays {
+			index, delay := index, delay
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-time.After(delay):
+				case <-r.Context().Done():
+					return
+				}
+				fmt.Fprintf(w, "<ListVersionsResult><Version><Key>object%d</Key><VersionId>1</VersionId>"+
+					"<LastModified>2020-01-01T00:00:00Z</LastModified></Version></ListVersionsResult>", index)
+			}))
+			servers = append(servers, ts)
+			versions, err := newVersionsClient(ts.URL, "accesskey", "secretkey", "", http.DefaultTransport)
+			if err != nil {
+				t.Fatal(err)
+			}
+			clnts = append(clnts, bucketClient{Bucket: "remote", versions: versions})
+		}
+		l := &radioObjects{
+			mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts, mergeVersions: true}},
+			timeouts:      timeoutsConfig{MergedListing: 100 * time.Millisecond},
+		}
+
+		loi, err := l.ListObjectVersions(context.Background(), "bucket", "", "", "", "", 100)
+		for _, ts := range servers {
+			ts.Close()
+		}
+		if testCase.shouldPass && err != nil {
+			t.Errorf("Test %d: Unexpected error %v", i+1, err)
+			continue
+		}
+		if !testCase.shouldPass {
+			if err == nil {
+				t.Errorf("Test %d: Expected an error", i+1)
+			}
+			continue
+		}
+		var got []string
+		for _, object := range loi.Objects {
+			got = append(got, object.Name)
+		}
+		if !reflect.DeepEqual(got, testCase.expected) {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, testCase.expected, got)
+		}
+		if !reflect.DeepEqual(loi.SkippedReplicas, testCase.expectedSkipped) {
+			t.Errorf("Test %d: Expected skipped replicas %v, got %v", i+1, testCase.expectedSkipped, loi.SkippedReplicas)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/minio/radio/cmd/logger"
+)
+
+const (
+	// Default number of consecutive heal failures of an entry before
+	// it is reported.
+	defaultWebhookFailures = 3
+	// Default number of events posted per minute.
+	defaultWebhookRate = 60
+	// Events waiting to be posted, further events are dropped.
+	webhookQueueSize = 1000
+	// Attempts to post an event, backing off exponentially.
+	webhookAttempts = 4
+	webhookBackoff  = time.Second
+)
+
+// Webhook event types.
+const (
+	webhookHealQueued = "heal_queued"
+	webhookHealFailed = "heal_failed"
+)
+
+// webhookConfig configures the webhook notified of replica divergence.
+type webhookConfig struct {
+	URL string `yaml:"url"`
+	// Consecutive heal failures of an entry before it is reported.
+	Failures int `yaml:"failures"`
+	// Maximum number of events posted per minute.
+	Rate int `yaml:"rate"`
+}
+
+// webhookEvent is posted as JSON to the webhook.
+type webhookEvent struct {
+	Event    string    `json:"event"`
+	Time     time.Time `json:"time"`
+	Bucket   string    `json:"bucket"`
+	Object   string    `json:"object"`
+	Op       journalOp `json:"op"`
+	RadioTag string    `json:"radioTag,omitempty"`
+	Failures int       `json:"failures,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	// Events dropped since the previous event, as the queue was full.
+	Dropped int `json:"dropped,omitempty"`
+}
+
+// webhookNotifier posts events to the webhook in the background, at
+// most rate events per minute.
+type webhookNotifier struct {
+	url      string
+	failures int
+	interval time.Duration
+	client   *http.Client
+	events   chan webhookEvent
+	dropped  chan struct{}
+}
+
+func newWebhookNotifier(cfg webhookConfig) (*webhookNotifier, error) {
+	if cfg.URL == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid webhook url %q: unsupported scheme", cfg.URL)
+	}
+	failures := cfg.Failures
+	if failures <= 0 {
+		failures = defaultWebhookFailures
+	}
+	rate := cfg.Rate
+	if rate <= 0 {
+		rate = defaultWebhookRate
+	}
+	return &webhookNotifier{
+		url:      cfg.URL,
+		failures: failures,
+		interval: time.Minute / time.Duration(rate),
+		client:   &http.Client{Transport: NewCustomHTTPTransport(), Timeout: 10 * time.Second},
+		events:   make(chan webhookEvent, webhookQueueSize),
+		dropped:  make(chan struct{}, webhookQueueSize),
+	}, nil
+}
+
+// notify queues event, a nil notifier silently drops it.
+func (n *webhookNotifier) notify(event webhookEvent) {
+	if n == nil {
+		return
+	}
+	event.Time = UTCNow()
+	select {
+	case n.events <- event:
+	default:
+		select {
+		case n.dropped <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run posts queued events until ctx is canceled.
+func (n *webhookNotifier) run(ctx context.Context) {
+	ticker := time.NewTicker(n.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-n.events:
+			event.Dropped = n.drainDropped()
+			logger.LogIf(ctx, n.post(ctx, event))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (n *webhookNotifier) drainDropped() (dropped int) {
+	for {
+		select {
+		case <-n.dropped:
+			dropped++
+		default:
+			return dropped
+		}
+	}
+}
+
+// post sends event to the webhook, retrying failures with backoff.
+func (n *webhookNotifier) post(ctx context.Context, event webhookEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, n.client, n.url, data)
+}
+
+// postJSON posts data to webhookURL, retrying failures with backoff.
+func postJSON(ctx context.Context, client *http.Client, webhookURL string, data []byte) error {
+	backoff := webhookBackoff
+	for attempt := 1; ; attempt++ {
+		err := postJSONOnce(ctx, client, webhookURL, data)
+		if err == nil || attempt == webhookAttempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func postJSONOnce(ctx context.Context, client *http.Client, webhookURL string, data []byte) error {
+	req, err := http.NewRequest(http.MethodPost, webhookURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", string(mimeJSON))
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook %s: %s", webhookURL, resp.Status)
+	}
+	return nil
+}
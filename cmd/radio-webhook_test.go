@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Tests that webhook events are retried until the webhook accepts them.
+func TestWebhookNotifierRetry(t *testing.T) {
+	var attempts int32
+	received := make(chan webhookEvent, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var event webhookEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Error(err)
+		}
+		received <- event
+	}))
+	defer ts.Close()
+
+	n, err := newWebhookNotifier(webhookConfig{URL: ts.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go n.run(ctx)
+
+	n.notify(webhookEvent{Event: webhookHealQueued, Bucket: "bucket", Object: "object", Op: opPutObject})
+	select {
+	case event := <-received:
+		if event.Event != webhookHealQueued || event.Bucket != "bucket" || event.Object != "object" || event.Op != opPutObject {
+			t.Errorf("unexpected event %+v", event)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("webhook event not received")
+	}
+	if n := atomic.LoadInt32(&attempts); n != 2 {
+		t.Errorf("expected 2 attempts, got %d", n)
+	}
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// errAsyncReplica is reported for async replicas skipped by object
+// writes, which are journaled for heal to copy them to the replica.
+var errAsyncReplica = errors.New("replica is written asynchronously")
+
+// validateWriteWeights checks the write weights of the remotes of
+// bucket: weights must not be negative, async remotes weigh nothing and
+// require a journal, and the remotes counting towards write quorum must
+// outweigh nothing.
+func validateWriteWeights(bucket string, remotes []remoteConfig, journaled bool) error {
+	total := 0
+	for _, remote := range remotes {
+		switch {
+		case remote.WriteWeight < 0:
+			return fmt.Errorf("bucket %s: remote %s/%s: write_weight must not be negative",
+				bucket, remote.Endpoint, remote.Bucket)
+		case remote.AsyncWrites && (remote.ReadOnly || remote.Shadow || remote.WriteWeight != 0):
+			return fmt.Errorf("bucket %s: remote %s/%s: async remotes are writable and have no write weight",
+				bucket, remote.Endpoint, remote.Bucket)
+		case remote.AsyncWrites && !journaled:
+			return fmt.Errorf("bucket %s: remote %s/%s: async remotes require a journal",
+				bucket, remote.Endpoint, remote.Bucket)
+		}
+		total += bucketClient{
+			ReadOnly:    remote.ReadOnly,
+			Shadow:      remote.Shadow,
+			writeWeight: remote.WriteWeight,
+			asyncWrites: remote.AsyncWrites,
+		}.weight()
+	}
+	if total == 0 && len(remotes) > 0 {
+		return fmt.Errorf("bucket %s: no remote counts towards write quorum", bucket)
+	}
+	return nil
+}
+
+// weight returns the weight of the replica towards write quorum, zero
+// for replicas not counting towards it.
+func (c bucketClient) weight() int {
+	switch {
+	case c.ReadOnly || c.Shadow || c.asyncWrites:
+		return 0
+	case c.writeWeight == 0:
+		return 1
+	}
+	return c.writeWeight
+}
+
+// weighted returns true if the replicas of m do not all count equally
+// towards write quorum.
+func (m mirrorConfig) weighted() bool {
+	for _, clnt := range m.clnts {
+		if clnt.asyncWrites || clnt.writeWeight > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// reduceWriteErrs returns nil if the replicas of m whose write
+// succeeded as reported in errs meet write quorum, the error reported
+// by enough replicas or QuorumNotMet otherwise. With weighted replicas
+// the quorum is met once the replicas which succeeded weigh more than
+// half the total weight.
+func (m mirrorConfig) reduceWriteErrs(ctx context.Context, errs []error) error {
+	if !m.weighted() {
+		return reduceWriteQuorumErrs(ctx, errs, skippedReplicaErrs, m.writeQuorum())
+	}
+	total, succeeded := 0, 0
+	for index, clnt := range m.clnts {
+		total += clnt.weight()
+		if errs[index] == nil {
+			succeeded += clnt.weight()
+		}
+	}
+	if 2*succeeded > total {
+		return nil
+	}
+	maxErr := reduceWriteQuorumErrs(ctx, errs, skippedReplicaErrs, m.writeQuorum())
+	if _, ok := maxErr.(QuorumNotMet); ok || maxErr == nil {
+		return QuorumNotMet{Succeeded: succeeded, Required: total/2 + 1, Weighted: true}
+	}
+	return maxErr
+}
+
+// skipUpload reads the upload r of a replica skipped by the write to
+// its end, for the other replicas to receive it.
+func skipUpload(r io.Reader, err error) error {
+	io.Copy(ioutil.Discard, r)
+	return err
+}
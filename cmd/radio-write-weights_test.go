@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/minio/minio/pkg/hash"
+)
+
+// Tests that write quorum is met by replicas outweighing half the total
+// write weight, and that async replicas are left to heal.
+func TestWriteWeights(t *testing.T) {
+	configs := []struct {
+		remotes   []remoteConfig
+		journaled bool
+		valid     bool
+	}{
+		{[]remoteConfig{{}, {}}, false, true},
+		{[]remoteConfig{{WriteWeight: 2}, {}}, false, true},
+		{[]remoteConfig{{WriteWeight: -1}, {}}, false, false},
+		{[]remoteConfig{{}, {AsyncWrites: true}}, true, true},
+		{[]remoteConfig{{}, {AsyncWrites: true}}, false, false},
+		{[]remoteConfig{{}, {AsyncWrites: true, WriteWeight: 1}}, true, false},
+		{[]remoteConfig{{}, {AsyncWrites: true, ReadOnly: true}}, true, false},
+		{[]remoteConfig{{ReadOnly: true}, {AsyncWrites: true}}, true, false},
+	}
+	for i, config := range configs {
+		if err := validateWriteWeights("bucket", config.remotes, config.journaled); (err == nil) != config.valid {
+			t.Errorf("Config %d: expected valid %v, got %v", i+1, config.valid, err)
+		}
+	}
+
+	failed := errors.New("failed")
+	weighted := mirrorConfig{clnts: []bucketClient{{writeWeight: 2}, {}, {}}}
+	async := mirrorConfig{clnts: []bucketClient{{}, {asyncWrites: true}}}
+	testCases := []struct {
+		m    mirrorConfig
+		errs []error
+		err  error
+	}{
+		{weighted, []error{nil, failed, errShortWrite}, QuorumNotMet{Succeeded: 2, Required: 3, Weighted: true}},
+		{weighted, []error{nil, nil, failed}, nil},
+		{weighted, []error{failed, nil, nil}, QuorumNotMet{Succeeded: 2, Required: 3, Weighted: true}},
+		{weighted, []error{failed, failed, failed}, failed},
+		{async, []error{nil, errAsyncReplica}, nil},
+		{async, []error{failed, errAsyncReplica}, failed},
+		{mirrorConfig{clnts: []bucketClient{{}, {}, {}}}, []error{nil, failed, errShortWrite}, QuorumNotMet{Succeeded: 1, Required: 2}},
+	}
+	for i, testCase := range testCases {
+		if err := testCase.m.reduceWriteErrs(context.Background(), testCase.errs); err != testCase.err {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.err, err)
+		}
+	}
+
+	tmpdir, err := ioutil.TempDir("", "radio-write-weights")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	remotes, clnts, shutdown := newTestRemotes(t, 2)
+	defer shutdown()
+	clnts[1].asyncWrites = true
+	l := &radioObjects{
+		nsMutex:       newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+	}
+	store := &dirJournalStore{dir: tmpdir}
+	if l.healSys, err = newHealSys(l, store, journalConfig{}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Larger than the blocks of uploads, for the async replica to be
+	// sent more than one.
+	data := bytes.Repeat([]byte("data"), 3<<20)
+	reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), "", "", int64(len(data)), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = l.PutObject(context.Background(), "bucket", "object", NewPutObjReader(reader, nil, nil), ObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if n := remotes[1].requests(http.MethodPut); n != 0 {
+		t.Errorf("Expected the async replica skipped, got %d writes", n)
+	}
+	entries, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || len(entries[0].DstClientIDs) != 1 || entries[0].DstClientIDs[0] != 1 {
+		t.Errorf("Expected the async replica journaled for heal, got %+v", entries)
+	}
+}
@@ -3,24 +3,27 @@ package cmd
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/minio/cli"
 	miniogo "github.com/minio/minio-go/v6"
 	"github.com/minio/minio-go/v6/pkg/credentials"
 	"github.com/minio/minio/pkg/dsync"
+	"github.com/minio/minio/pkg/event"
+	xnet "github.com/minio/minio/pkg/net"
 	"gopkg.in/yaml.v2"
 
 	"github.com/minio/minio-go/v6/pkg/encrypt"
 	"github.com/minio/minio-go/v6/pkg/s3utils"
 	"github.com/minio/minio/pkg/sync/errgroup"
 	"github.com/minio/radio/cmd/logger"
-	"github.com/minio/radio/pkg/streamdup"
 )
 
 func init() {
@@ -47,12 +50,7 @@ EXAMPLES:
 
 // Handler for 'minio radio s3' command line.
 func radioMain(ctx *cli.Context) {
-	reader, err := os.Open(ctx.String("config"))
-	if err != nil {
-		logger.FatalIf(err, "Invalid command line arguments")
-	}
-
-	data, err := ioutil.ReadAll(reader)
+	data, err := readRadioConfig(ctx.String("config"))
 	if err != nil {
 		logger.FatalIf(err, "Invalid command line arguments")
 	}
@@ -62,6 +60,9 @@ func radioMain(ctx *cli.Context) {
 	if err != nil {
 		logger.FatalIf(err, "Invalid command line arguments")
 	}
+	logger.FatalIf(logger.SetFormat(rconfig.Logging.Format), "Invalid logging configuration")
+	rconfig.Buckets, err = resolveBucketAliases(rconfig.Buckets)
+	logger.FatalIf(err, "Invalid bucket configuration")
 
 	endpoints, err := createServerEndpoints(ctx.String("address"), rconfig.Distribute.Peers)
 	logger.FatalIf(err, "Invalid command line arguments")
@@ -79,16 +80,21 @@ type Radio struct {
 	rconfig   radioConfig
 }
 
-// newS3 - Initializes a new client by auto probing S3 server signature.
-func newS3(bucket, urlStr, accessKey, secretKey, sessionToken string) (*miniogo.Core, error) {
+// newS3 - Initializes a new client by auto probing S3 server signature,
+// the client is returned along with the error if the probe fails. The
+// region is derived from urlStr if empty.
+func newS3(bucket, urlStr, region, accessKey, secretKey, sessionToken string, transport http.RoundTripper) (*miniogo.Core, error) {
 	u, err := url.Parse(urlStr)
 	if err != nil {
 		return nil, err
 	}
+	if region == "" {
+		region = s3utils.GetRegionFromURL(*u)
+	}
 	options := miniogo.Options{
 		Creds:        credentials.NewStaticV4(accessKey, secretKey, sessionToken),
 		Secure:       u.Scheme == "https",
-		Region:       s3utils.GetRegionFromURL(*u),
+		Region:       region,
 		BucketLookup: miniogo.BucketLookupAuto,
 	}
 
@@ -98,29 +104,102 @@ func newS3(bucket, urlStr, accessKey, secretKey, sessionToken string) (*miniogo.
 	}
 
 	// Set custom transport
-	clnt.SetCustomTransport(NewCustomHTTPTransport())
+	clnt.SetCustomTransport(transport)
 
-	var retry int
-	var maxRetry = 3
-	for {
-		// Check if the provided keys are valid.
-		_, err = clnt.BucketExists(bucket)
-		if err != nil {
-			errResp := miniogo.ToErrorResponse(err)
-			if errResp.Code == "XMinioServerNotInitialized" {
-				logger.LogIf(context.Background(), err)
-				time.Sleep(1 * time.Second)
-				retry++
-				continue
-			}
-			if retry < maxRetry {
-				return nil, err
+	return &miniogo.Core{Client: clnt}, probeS3(clnt, bucket)
+}
+
+// Retries of a failed remote probe, backing off exponentially from
+// probeBackoff.
+const maxProbeRetry = 3
+
+var probeBackoff = time.Second
+
+// remoteProbeError is the error probing a remote bucket.
+type remoteProbeError struct {
+	endpoint string
+	bucket   string
+	attempts int
+	err      error
+}
+
+func (e remoteProbeError) Error() string {
+	return fmt.Sprintf("remote %s/%s unavailable after %d attempt(s): %v", e.endpoint, e.bucket, e.attempts, e.err)
+}
+
+func (e remoteProbeError) Unwrap() error {
+	return e.err
+}
+
+// isRemoteOffline returns true if err is a probe failing to reach a
+// remote on the network.
+func isRemoteOffline(err error) bool {
+	var perr remoteProbeError
+	return errors.As(err, &perr) && xnet.IsNetworkOrHostDown(perr.err)
+}
+
+// isTransientProbeError returns true for errors that may go away on
+// retry, network errors and server side errors, as opposed to invalid
+// credentials or a missing bucket.
+func isTransientProbeError(err error) bool {
+	if xnet.IsNetworkOrHostDown(err) {
+		return true
+	}
+	errResp := miniogo.ToErrorResponse(err)
+	return errResp.StatusCode >= http.StatusInternalServerError ||
+		errResp.Code == "XMinioServerNotInitialized" || errResp.Code == "SlowDown"
+}
+
+// probeS3 checks that the remote is reachable, the provided keys are
+// valid and bucket exists. Transient errors are retried up to
+// maxProbeRetry times.
+func probeS3(clnt *miniogo.Client, bucket string) error {
+	backoff := probeBackoff
+	for attempt := 1; ; attempt++ {
+		exists, err := clnt.BucketExists(bucket)
+		if err == nil && !exists {
+			err = miniogo.ErrorResponse{
+				StatusCode: http.StatusNotFound,
+				Code:       "NoSuchBucket",
+				Message:    "The specified bucket does not exist",
+				BucketName: bucket,
 			}
 		}
-		break
+		if err == nil {
+			return nil
+		}
+		if attempt > maxProbeRetry || !isTransientProbeError(err) {
+			return remoteProbeError{
+				endpoint: clnt.EndpointURL().Host,
+				bucket:   bucket,
+				attempts: attempt,
+				err:      err,
+			}
+		}
+		logger.LogIf(context.Background(), err)
+		time.Sleep(backoff)
+		backoff *= 2
 	}
+}
+
+// Interval between probes of remotes found offline at startup.
+const remoteOnlineProbeInterval = 10 * time.Second
 
-	return &miniogo.Core{Client: clnt}, nil
+// waitRemoteOnline probes a remote found offline at startup until it is
+// reachable again.
+func waitRemoteOnline(clnt *miniogo.Client, endpoint, bucket string) {
+	for {
+		time.Sleep(remoteOnlineProbeInterval)
+		err := probeS3(clnt, bucket)
+		if err == nil {
+			logger.Info("Remote %s/%s is online", endpoint, bucket)
+			return
+		}
+		if !isRemoteOffline(err) {
+			logger.LogIf(context.Background(), err)
+			return
+		}
+	}
 }
 
 // ProtectionType different protection types
@@ -132,16 +211,125 @@ const (
 	ErasureType ProtectionType = "erasure"
 )
 
+// Defaults for bulk delete fan-out.
+const (
+	defaultDeleteParallelism = 4
+	defaultDeleteBatchSize   = 1000
+)
+
 type remoteConfig struct {
 	Bucket       string `yaml:"bucket"`
 	Endpoint     string `yaml:"endpoint"`
 	AccessKey    string `yaml:"access_key"`
 	SecretKey    string `yaml:"secret_key"`
 	SessionToken string `yaml:"session_token"`
+	KeyPrefix    string `yaml:"key_prefix"`
+	// Optional endpoint used for object data transfers, requests
+	// are still signed for Endpoint.
+	TransferEndpoint string `yaml:"transfer_endpoint"`
+	// ReadOnly remotes serve reads and heal sources but are never
+	// written to.
+	ReadOnly bool `yaml:"read_only"`
+	// Shadow remotes are written to on a best effort basis without
+	// counting towards write quorum, and are never read from.
+	Shadow bool `yaml:"shadow"`
+	// Compat is the S3 API level of the remote, "full" or "legacy".
+	Compat string `yaml:"compat"`
+	// HTTP2 negotiates HTTP/2 with https endpoints supporting it.
+	HTTP2 bool `yaml:"http2"`
+	// UnsignedPayload uploads object data without a precomputed
+	// SHA256, relying on TLS and Content-MD5 for integrity. Plain
+	// http remotes fall back to chunk signed uploads.
+	UnsignedPayload bool `yaml:"unsigned_payload"`
+	// Headers are added to every request sent to the remote, for
+	// proxies or gateways requiring them. Authorization, Host and
+	// x-amz-* headers cannot be set.
+	Headers map[string]string `yaml:"headers"`
+	// Retries of requests failing with network or server errors,
+	// bounded by a budget shared by all requests to the remote.
+	Retries retryConfig `yaml:"retries"`
+	// Optional endpoint serving object reads, stats and listings,
+	// while Endpoint serves writes. Requests to it are signed with
+	// the credentials of the remote unless read credentials are set,
+	// and for ReadRegion, derived from the endpoints if empty.
+	ReadEndpoint  string `yaml:"read_endpoint"`
+	ReadAccessKey string `yaml:"read_access_key"`
+	ReadSecretKey string `yaml:"read_secret_key"`
+	ReadRegion    string `yaml:"read_region"`
+	// FreeSpace rejects writes while the remote is low on space.
+	FreeSpace freeSpaceConfig `yaml:"free_space"`
+	// WriteWeight is the weight of the remote towards write quorum, 1
+	// if zero. Writes succeed once the remotes accepting them weigh
+	// more than half the total weight of the writable remotes.
+	WriteWeight int `yaml:"write_weight"`
+	// AsyncWrites remotes are skipped by object writes, which are
+	// journaled for heal to copy the objects to them later, and weigh
+	// nothing towards write quorum.
+	AsyncWrites bool `yaml:"async_writes"`
+	// Users maps the access keys of radio clients to credentials of
+	// the remote signing their requests instead, so that the remote
+	// attributes them to its own users. Clients not mapped use the
+	// credentials of the remote, as do heals.
+	Users map[string]userCredentials `yaml:"users"`
+}
+
+// journalConfig locates the heal journal, either in a local
+// directory or in a bucket on a dedicated remote.
+type journalConfig struct {
+	Dir      string        `yaml:"dir"`
+	Interval time.Duration `yaml:"interval"`
+	Remote   remoteConfig  `yaml:"remote"`
+	// Policy is one of "succeeded", "newest" or "primary".
+	Policy  string `yaml:"policy"`
+	Primary int    `yaml:"primary"`
+	// MaxRetries and TTL bound the heal attempts of an entry, counted
+	// in the entry from its first failed heal, once either is exceeded
+	// the entry is moved to the dead letter area. Zero retries or heals
+	// indefinitely.
+	MaxRetries int           `yaml:"max_retries"`
+	TTL        time.Duration `yaml:"ttl"`
+	// ClaimTTL bounds the heal of an entry, so that the lease keeping
+	// radio peers sharing the journal off the entry is released in time.
+	ClaimTTL time.Duration `yaml:"claim_ttl"`
+	// IsolateBuckets heals the entries of each bucket separately, by up
+	// to BucketConcurrency heals at a time, so that a bucket with
+	// failing remotes cannot hold up the heals of the others.
+	IsolateBuckets    bool `yaml:"isolate_buckets"`
+	BucketConcurrency int  `yaml:"bucket_concurrency"`
+	// DeleteWins resolves objects missing on some replicas while a
+	// journaled delete covers the copies left on the others as
+	// deleted, instead of serving and healing back these copies.
+	DeleteWins bool `yaml:"delete_wins"`
+	// BlockHeal heals replicas holding a copy of the same size as the
+	// source by rewriting only the blocks which differ.
+	BlockHeal blockHealConfig `yaml:"block_heal"`
+	// Checkpoint makes the heals of large objects resumable.
+	Checkpoint healCheckpointConfig `yaml:"checkpoint"`
+}
+
+// timeoutsConfig caps the duration of object layer operations whose
+// client context carries no deadline, zero means no cap.
+type timeoutsConfig struct {
+	Read      time.Duration `yaml:"read"`
+	Write     time.Duration `yaml:"write"`
+	Multipart time.Duration `yaml:"multipart"`
+	// ReadStall aborts a replica read receiving no data for this
+	// long, the read then resumes from another replica.
+	ReadStall time.Duration `yaml:"read_stall"`
+	// WriteStall abandons a replica reading none of an upload for
+	// this long, the write completes on the other replicas and the
+	// replica is healed.
+	WriteStall time.Duration `yaml:"write_stall"`
+	// MergedListing is how long merged version listings wait for all
+	// replicas, slower replicas are skipped.
+	MergedListing time.Duration `yaml:"merged_listing"`
 }
 
 type bucketConfig struct {
-	Bucket     string `yaml:"bucket"`
+	Bucket string `yaml:"bucket"`
+	// Alias is the name clients address the bucket by, the key of
+	// the bucket in the configuration if empty.
+	Alias      string `yaml:"alias"`
 	AccessKey  string `yaml:"access_key"`
 	SecretKey  string `yaml:"secret_key"`
 	Protection struct {
@@ -149,6 +337,39 @@ type bucketConfig struct {
 		Parity int            `json:"parity"`
 	} `json:"protection"`
 	Remotes []remoteConfig `yaml:"remote"`
+	// Allow AppendObject on this bucket.
+	Append   bool           `yaml:"append"`
+	Metadata metadataFilter `yaml:"metadata"`
+	// Merge object versions listed from all remotes.
+	MergeVersions bool `yaml:"merge_versions"`
+	// Canned ACL of objects written without an ACL.
+	ACL string `yaml:"acl"`
+	// Storage class of objects written without a storage class.
+	StorageClass string `yaml:"storage_class"`
+	// Either "error" or "downgrade" writes rejected for their storage
+	// class by a remote.
+	StorageClassFallback string `yaml:"storage_class_fallback"`
+	// Normalization of object names, off by default.
+	Keys keyNormalization `yaml:"keys"`
+	// Fail reads of objects whose replicas hold different versions
+	// instead of serving one of them.
+	StrictReads bool `yaml:"strict_reads"`
+	// Head answers HEAD requests from a single replica.
+	Head headConfig `yaml:"head"`
+	// Objects under these prefixes are written to and read from the
+	// first remote only, without mirroring nor healing: they are lost
+	// if that remote loses them.
+	SingleReplicaPrefixes []string `yaml:"single_replica_prefixes"`
+	// Reject uploads of objects and parts without a Content-MD5.
+	RequireContentMD5 bool `yaml:"require_content_md5"`
+	// Writes of objects return once all reachable replicas hold them,
+	// rather than once the write quorum is met.
+	SyncWrites bool `yaml:"sync_writes"`
+	// Notifications of the objects written to and deleted from the
+	// bucket.
+	Events bucketEventsConfig `yaml:"events"`
+	// Limits of the multipart uploads to the bucket.
+	Multipart multipartLimitsConfig `yaml:"multipart"`
 }
 
 // radioConfig radio configuration
@@ -162,22 +383,281 @@ type radioConfig struct {
 			CAPath   string `yaml:"ca_path"`
 		} `yaml:"certs"`
 	} `yaml:"distribute"`
+	TLS   tlsConfig `yaml:"tls"`
 	Cache struct {
 		Drives  []string `yaml:"drives"`
 		Exclude []string `yaml:"exclude"`
 		Quota   int      `yaml:"quota"`
 		Expiry  int      `yaml:"expiry"`
 	} `yaml:"cache"`
-	Buckets map[string]bucketConfig `json:"buckets"`
+	Startup struct {
+		// CheckBuckets verifies mirrored remote buckets at startup.
+		CheckBuckets bool `yaml:"check_buckets"`
+		// Strict fails startup on any inconsistency rather than warning.
+		Strict bool `yaml:"strict"`
+		// AllowDegradedStart starts with unreachable remotes instead
+		// of failing.
+		AllowDegradedStart bool `yaml:"allow_degraded_start"`
+		// AllowSharedRemotes permits remotes of a bucket pointing to
+		// the same physical bucket.
+		AllowSharedRemotes bool `yaml:"allow_shared_remotes"`
+	} `yaml:"startup"`
+	LagSampler struct {
+		// Interval between samples, zero disables sampling.
+		Interval   time.Duration `yaml:"interval"`
+		SampleSize int           `yaml:"sample_size"`
+	} `yaml:"lag_sampler"`
+	Scanner struct {
+		// Interval between scans, zero disables scanning. Heals are
+		// journaled, the scanner requires the journal.
+		Interval time.Duration `yaml:"interval"`
+		// Keys visited per second.
+		Rate int `yaml:"rate"`
+	} `yaml:"scanner"`
+	Journal   journalConfig  `yaml:"journal"`
+	Timeouts  timeoutsConfig `yaml:"timeouts"`
+	Transport struct {
+		// TTL of the resolved addresses of remote hosts, zero leaves
+		// caching to the system resolver.
+		DNSCacheTTL time.Duration `yaml:"dns_cache_ttl"`
+	} `yaml:"transport"`
+	Admission admissionConfig `yaml:"admission"`
+	// Reads prefer the replica of the first rule matching the client
+	// address or x-radio-region header, see readAffinity.
+	Affinity []affinityRule `yaml:"affinity"`
+	// Object layer operations taking longer than a threshold are
+	// logged along with the duration of each replica call.
+	SlowRequests slowRequestConfig `yaml:"slow_requests"`
+	// Large uploads are spooled for replicas failing them to be
+	// retried, see uploadSpooler.
+	Spool spoolConfig `yaml:"spool"`
+	List  struct {
+		// Entries listed from a remote per request, zero lists the
+		// keys requested by a client at once. See streamList.
+		BatchSize int `yaml:"batch_size"`
+	} `yaml:"list"`
+	ListCache struct {
+		// TTL of cached listings, zero disables the cache.
+		TTL time.Duration `yaml:"ttl"`
+	} `yaml:"list_cache"`
+	Stats struct {
+		// TTL of cached bucket stats.
+		TTL time.Duration `yaml:"ttl"`
+	} `yaml:"stats"`
+	DeleteObjects struct {
+		Parallelism int `yaml:"parallelism"`
+		BatchSize   int `yaml:"batch_size"`
+		// Rate is the number of objects per second each remote is
+		// sent for deletion by a request, unlimited if zero.
+		Rate int `yaml:"rate"`
+	} `yaml:"delete_objects"`
+	// Client operations rejected with MethodNotAllowed, see
+	// disableableOperations.
+	DisabledOperations []string `yaml:"disabled_operations"`
+	// HEAD requests are served the info of objects recently written
+	// when all replicas are unreachable, see recentWrites.
+	RecentWrites recentWritesConfig `yaml:"recent_writes"`
+	// Webhook notified of heal journal entries and failing heals.
+	Webhook webhookConfig `yaml:"webhook"`
+	Logging struct {
+		// Format of all log output, "text" or "json" for a JSON
+		// object per line with level, time, msg, op, bucket, object
+		// and error fields. See logger.SetFormat.
+		Format string `yaml:"format"`
+	} `yaml:"logging"`
+	Debug struct {
+		// ReplicaReads honors the x-radio-replica header of GET and
+		// HEAD requests, off by default. See replicaReadOptions.
+		ReplicaReads bool `yaml:"replica_reads"`
+		// Pprof serves net/http/pprof handlers on Address, off by
+		// default.
+		Pprof bool `yaml:"pprof"`
+		// Address to serve pprof handlers on, required with Pprof
+		// and meant to be reachable by operators only.
+		Address string `yaml:"address"`
+	} `yaml:"debug"`
+	// Metadata key of the tag identifying object versions across
+	// replicas, an x-amz-meta- key.
+	RadioTag string `yaml:"radio_tag"`
+	// RadioTagSource is "uuid" or "content", see newRadioTag. Content
+	// tags only apply to uploads and copies streamed through radio,
+	// server side copies and multipart uploads keep random UUID tags.
+	RadioTagSource string                  `yaml:"radio_tag_source"`
+	Buckets        map[string]bucketConfig `json:"buckets"`
 }
 
 type bucketClient struct {
 	*miniogo.Core
-	Bucket string
+	Bucket   string
+	Prefix   string
+	ReadOnly bool
+	Shadow   bool
+	Compat   s3Compat
+	// UnsignedPayload skips the payload SHA256 of uploads.
+	UnsignedPayload bool
+	versions        *versionsClient
+	// Clients of the read endpoint, nil if the remote has none, see
+	// reader.
+	readCore     *miniogo.Core
+	readVersions *versionsClient
+	// Storage classes to downgrade, nil unless the bucket downgrades
+	// rejected storage classes.
+	downgrades *storageClassDowngrades
+	// Free space of the remote, nil unless guarded.
+	freeSpace *freeSpaceMonitor
+	// Weight towards write quorum, see weight, and whether object
+	// writes are left to heal.
+	writeWeight int
+	asyncWrites bool
+	// Errors of the requests to the remote, see remoteErrorStats.
+	errStats *remoteErrorStats
+	// Clients signing requests for mapped radio clients, see asUser.
+	users map[string]*userClients
+}
+
+// objectKey returns the key under which object is stored on this remote.
+func (c bucketClient) objectKey(object string) string {
+	return c.Prefix + object
+}
+
+// payloadSHA256 returns the SHA256 to sign uploads to this remote
+// with, empty for unsigned payloads.
+func (c bucketClient) payloadSHA256(sha256Hex string) string {
+	if c.UnsignedPayload {
+		return ""
+	}
+	return sha256Hex
+}
+
+// listKey is the inverse of objectKey, for keys returned in listings.
+func (c bucketClient) listKey(key string) string {
+	return strings.TrimPrefix(key, c.Prefix)
+}
+
+// markerKey maps a client supplied marker onto this remote, empty
+// markers are left as is.
+func (c bucketClient) markerKey(marker string) string {
+	if marker == "" {
+		return marker
+	}
+	return c.objectKey(marker)
+}
+
+func (c bucketClient) trimListBucketResult(result miniogo.ListBucketResult) miniogo.ListBucketResult {
+	if c.Prefix == "" {
+		return result
+	}
+	for i := range result.Contents {
+		result.Contents[i].Key = c.listKey(result.Contents[i].Key)
+	}
+	for i := range result.CommonPrefixes {
+		result.CommonPrefixes[i].Prefix = c.listKey(result.CommonPrefixes[i].Prefix)
+	}
+	result.Marker = c.listKey(result.Marker)
+	result.NextMarker = c.listKey(result.NextMarker)
+	result.Prefix = c.listKey(result.Prefix)
+	return result
+}
+
+func (c bucketClient) trimListBucketV2Result(result miniogo.ListBucketV2Result) miniogo.ListBucketV2Result {
+	if c.Prefix == "" {
+		return result
+	}
+	for i := range result.Contents {
+		result.Contents[i].Key = c.listKey(result.Contents[i].Key)
+	}
+	for i := range result.CommonPrefixes {
+		result.CommonPrefixes[i].Prefix = c.listKey(result.CommonPrefixes[i].Prefix)
+	}
+	result.Prefix = c.listKey(result.Prefix)
+	result.StartAfter = c.listKey(result.StartAfter)
+	return result
+}
+
+func (c bucketClient) trimListMultipartUploadsResult(result miniogo.ListMultipartUploadsResult) miniogo.ListMultipartUploadsResult {
+	if c.Prefix == "" {
+		return result
+	}
+	for i := range result.Uploads {
+		result.Uploads[i].Key = c.listKey(result.Uploads[i].Key)
+	}
+	for i := range result.CommonPrefixes {
+		result.CommonPrefixes[i].Prefix = c.listKey(result.CommonPrefixes[i].Prefix)
+	}
+	result.KeyMarker = c.listKey(result.KeyMarker)
+	result.NextKeyMarker = c.listKey(result.NextKeyMarker)
+	result.Prefix = c.listKey(result.Prefix)
+	return result
 }
 
 type mirrorConfig struct {
-	clnts []bucketClient
+	clnts         []bucketClient
+	append        bool
+	metadata      metadataFilter
+	mergeVersions bool
+	acl           string
+	storageClass  string
+	keys          keyNormalization
+	strictReads   bool
+	head          headConfig
+	// Objects under these prefixes are only stored on the first
+	// replica, see forObject.
+	singleReplicaPrefixes []string
+	requireContentMD5     bool
+	syncWrites            bool
+	// Notifier of the writes to the bucket, nil if disabled.
+	events *eventNotifier
+	// Limiter of the multipart uploads to the bucket, nil if unlimited.
+	multipart *multipartLimiter
+}
+
+// writeQuorum returns the number of writable replicas that must accept
+// a write, shadow and async replicas do not count.
+func (m mirrorConfig) writeQuorum() int {
+	writable := 0
+	for _, clnt := range m.clnts {
+		if !clnt.ReadOnly && !clnt.Shadow && !clnt.asyncWrites {
+			writable++
+		}
+	}
+	return writable/2 + 1
+}
+
+// readReplicas returns the indices of the replicas serving reads, all
+// but the shadow replicas.
+func (m mirrorConfig) readReplicas() []int {
+	var readable []int
+	for index, clnt := range m.clnts {
+		if !clnt.Shadow {
+			readable = append(readable, index)
+		}
+	}
+	return readable
+}
+
+// shadowResults logs and meters the failed writes to the shadow
+// replicas of bucket and masks their results in errs, so that they
+// neither count towards quorum nor get healed.
+func (m mirrorConfig) shadowResults(ctx context.Context, bucket string, errs []error) {
+	for index, clnt := range m.clnts {
+		if !clnt.Shadow {
+			continue
+		}
+		if errs[index] != errShadowReplica {
+			logShadowError(ctx, bucket, clnt, errs[index])
+		}
+		errs[index] = errShadowReplica
+	}
+}
+
+// logShadowError logs and meters err failing a write to the shadow
+// replica clnt of bucket, if non-nil.
+func logShadowError(ctx context.Context, bucket string, clnt bucketClient, err error) {
+	if err == nil {
+		return
+	}
+	shadowWriteErrors.WithLabelValues(bucket, clnt.EndpointURL().Host).Inc()
+	logger.LogIf(ctx, fmt.Errorf("shadow remote %s/%s: %w", clnt.EndpointURL().Host, clnt.Bucket, err))
 }
 
 type erasureConfig struct {
@@ -185,16 +665,94 @@ type erasureConfig struct {
 	clnts  []bucketClient
 }
 
-func newBucketClients(bcfgs []remoteConfig) ([]bucketClient, error) {
+// newBucketClients returns clients for all remotes in bcfgs. Unreachable
+// remotes fail unless allowDegraded is set, in which case they are
+// configured anyway and watched until they come online.
+func newBucketClients(bcfgs []remoteConfig, allowDegraded bool) ([]bucketClient, error) {
 	var clnts []bucketClient
 	for _, bCfg := range bcfgs {
-		clnt, err := newS3(bCfg.Bucket, bCfg.Endpoint, bCfg.AccessKey, bCfg.SecretKey, bCfg.SessionToken)
+		compat, err := parseS3Compat(bCfg.Compat)
+		if err != nil {
+			return nil, err
+		}
+		if bCfg.ReadOnly && bCfg.Shadow {
+			return nil, fmt.Errorf("remote %s/%s: a remote cannot be both read-only and shadow",
+				bCfg.Endpoint, bCfg.Bucket)
+		}
+		if compat == compatLegacy && bCfg.SessionToken != "" {
+			return nil, fmt.Errorf("remote %s/%s: session tokens are not supported by legacy remotes",
+				bCfg.Endpoint, bCfg.Bucket)
+		}
+		if err = validateRemoteHeaders(bCfg.Headers); err != nil {
+			return nil, fmt.Errorf("remote %s/%s: %v", bCfg.Endpoint, bCfg.Bucket, err)
+		}
+		if err = bCfg.Retries.validate(); err != nil {
+			return nil, fmt.Errorf("remote %s/%s: %v", bCfg.Endpoint, bCfg.Bucket, err)
+		}
+		if bCfg.ReadEndpoint != "" {
+			if err = validateReadEndpoint(bCfg); err != nil {
+				return nil, fmt.Errorf("remote %s/%s: %v", bCfg.Endpoint, bCfg.Bucket, err)
+			}
+		}
+		var transport http.RoundTripper = NewCustomHTTPTransport()
+		if bCfg.HTTP2 {
+			transport = NewCustomHTTP2Transport()
+		}
+		transport = newRetryTransport(newThrottleTransport(newHeaderTransport(transport, bCfg.Headers)), bCfg.Retries)
+		errStats := newRemoteErrorStats()
+		transport = newErrorStatsTransport(transport, bCfg.Bucket, errStats)
+		clnt, err := newS3(bCfg.Bucket, bCfg.Endpoint, "", bCfg.AccessKey, bCfg.SecretKey, bCfg.SessionToken, transport)
+		if err != nil {
+			if clnt == nil || !allowDegraded || !isRemoteOffline(err) {
+				return nil, err
+			}
+			logger.Info("WARNING: remote %s/%s is offline, starting degraded: %v",
+				bCfg.Endpoint, bCfg.Bucket, err)
+			go waitRemoteOnline(clnt.Client, bCfg.Endpoint, bCfg.Bucket)
+		}
+		if bCfg.TransferEndpoint != "" {
+			if err = setTransferEndpoint(clnt, bCfg.Bucket, bCfg.TransferEndpoint, transport); err != nil {
+				return nil, err
+			}
+		}
+		versions, err := newVersionsClient(bCfg.Endpoint, bCfg.AccessKey, bCfg.SecretKey, bCfg.SessionToken, transport)
 		if err != nil {
 			return nil, err
 		}
+		freeSpace, err := newFreeSpaceMonitor(bCfg.Endpoint, bCfg.AccessKey, bCfg.SecretKey, bCfg.FreeSpace, transport)
+		if err != nil {
+			return nil, fmt.Errorf("remote %s/%s: %v", bCfg.Endpoint, bCfg.Bucket, err)
+		}
+		if freeSpace != nil {
+			go freeSpace.run(context.Background())
+		}
+		var readClnt *miniogo.Core
+		var readVersions *versionsClient
+		if bCfg.ReadEndpoint != "" {
+			if readClnt, readVersions, err = newReadClients(bCfg, transport, allowDegraded); err != nil {
+				return nil, fmt.Errorf("remote %s/%s: %v", bCfg.Endpoint, bCfg.Bucket, err)
+			}
+		}
+		users, err := newUserClients(bCfg, transport, allowDegraded)
+		if err != nil {
+			return nil, fmt.Errorf("remote %s/%s: %v", bCfg.Endpoint, bCfg.Bucket, err)
+		}
 		clnts = append(clnts, bucketClient{
-			Core:   clnt,
-			Bucket: bCfg.Bucket,
+			Core:            clnt,
+			Bucket:          bCfg.Bucket,
+			Prefix:          bCfg.KeyPrefix,
+			ReadOnly:        bCfg.ReadOnly,
+			Shadow:          bCfg.Shadow,
+			Compat:          compat,
+			versions:        versions,
+			readCore:        readClnt,
+			readVersions:    readVersions,
+			UnsignedPayload: bCfg.UnsignedPayload,
+			freeSpace:       freeSpace,
+			writeWeight:     bCfg.WriteWeight,
+			asyncWrites:     bCfg.AsyncWrites,
+			errStats:        errStats,
+			users:           users,
 		})
 	}
 	return clnts, nil
@@ -209,22 +767,136 @@ func (g *Radio) NewRadioLayer() (ObjectLayer, error) {
 
 	s := radioObjects{
 		multipartUploadIDMap: make(map[string][]string),
+		completedUploads:     newCompletedUploads(),
 		endpoints:            g.endpoints,
 		radioLockers:         radioLockers,
 		nsMutex:              newNSLock(len(radioLockers) > 0),
 		mirrorClients:        make(map[string]mirrorConfig),
 		erasureClients:       make(map[string]erasureConfig),
+		deleteParallelism:    g.rconfig.DeleteObjects.Parallelism,
+		deleteBatchSize:      g.rconfig.DeleteObjects.BatchSize,
+		deleteRate:           g.rconfig.DeleteObjects.Rate,
+		listBatchSize:        g.rconfig.List.BatchSize,
+		timeouts:             g.rconfig.Timeouts,
+		replicaReads:         g.rconfig.Debug.ReplicaReads,
+		listCache:            newListCache(g.rconfig.ListCache.TTL),
+		statsCache:           newStatsCache(g.rconfig.Stats.TTL),
+	}
+	if s.deleteParallelism <= 0 {
+		s.deleteParallelism = defaultDeleteParallelism
+	}
+	if s.deleteBatchSize <= 0 {
+		s.deleteBatchSize = defaultDeleteBatchSize
+	}
+	disabledOperations, err := parseDisabledOperations(g.rconfig.DisabledOperations)
+	if err != nil {
+		return nil, err
+	}
+	s.disabledOperations = disabledOperations
+	if s.slowLog, err = newSlowLog(g.rconfig.SlowRequests); err != nil {
+		return nil, err
+	}
+	if s.contentRadioTags, err = parseRadioTagSource(g.rconfig.RadioTagSource); err != nil {
+		return nil, err
+	}
+	if s.affinity, err = newReadAffinity(g.rconfig.Affinity); err != nil {
+		return nil, err
+	}
+	if s.spooler, err = newUploadSpooler(g.rconfig.Spool, g.rconfig.Journal.Dir); err != nil {
+		return nil, err
+	}
+	if s.recentWrites, err = newRecentWrites(g.rconfig.RecentWrites); err != nil {
+		return nil, err
 	}
 
+	store, err := newJournalStore(g.rconfig.Journal)
+	if err != nil {
+		return nil, err
+	}
+	if store != nil {
+		webhook, err := newWebhookNotifier(g.rconfig.Webhook)
+		if err != nil {
+			return nil, err
+		}
+		if s.healSys, err = newHealSys(&s, store, g.rconfig.Journal, webhook); err != nil {
+			return nil, err
+		}
+		if webhook != nil {
+			go webhook.run(context.Background())
+		}
+		go s.healSys.run(context.Background())
+	}
+
+	clientKeys := make(map[string]bool, len(g.rconfig.Buckets))
+	for _, cfg := range g.rconfig.Buckets {
+		clientKeys[cfg.AccessKey] = true
+	}
 	// creds are ignored here, since S3 radio implements chaining all credentials.
 	for bucket, cfg := range g.rconfig.Buckets {
-		clnts, err := newBucketClients(cfg.Remotes)
+		if err = checkDistinctRemotes(bucket, cfg.Remotes, g.rconfig.Startup.AllowSharedRemotes); err != nil {
+			return nil, err
+		}
+		if err = validateUserCredentials(bucket, cfg.Remotes, clientKeys); err != nil {
+			return nil, err
+		}
+		clnts, err := newBucketClients(cfg.Remotes, g.rconfig.Startup.AllowDegradedStart)
 		if err != nil {
 			return nil, err
 		}
 		if cfg.Protection.Scheme == MirrorType {
+			acl, err := parseCannedACL(bucket, cfg.ACL)
+			if err != nil {
+				return nil, err
+			}
+			storageClass, downgrade, err := parseStorageClass(bucket, cfg.StorageClass, cfg.StorageClassFallback)
+			if err != nil {
+				return nil, err
+			}
+			if err = cfg.Keys.validate(bucket); err != nil {
+				return nil, err
+			}
+			if err = validateSingleReplicaPrefixes(bucket, cfg.SingleReplicaPrefixes, cfg.Remotes); err != nil {
+				return nil, err
+			}
+			if err = validateWriteWeights(bucket, cfg.Remotes, store != nil); err != nil {
+				return nil, err
+			}
+			events, err := newEventNotifier(bucket, cfg.Events)
+			if err != nil {
+				return nil, err
+			}
+			multipart, err := newMultipartLimiter(bucket, cfg.Multipart)
+			if err != nil {
+				return nil, err
+			}
+			if events != nil {
+				go events.run(context.Background())
+			}
+			if downgrade {
+				for i := range clnts {
+					clnts[i].downgrades = newStorageClassDowngrades()
+				}
+			}
+			if g.rconfig.Startup.CheckBuckets {
+				if err = checkMirrorConsistency(bucket, clnts, g.rconfig.Startup.Strict); err != nil {
+					return nil, err
+				}
+			}
 			s.mirrorClients[bucket] = mirrorConfig{
-				clnts: clnts,
+				clnts:                 clnts,
+				append:                cfg.Append,
+				metadata:              cfg.Metadata,
+				mergeVersions:         cfg.MergeVersions,
+				acl:                   acl,
+				storageClass:          storageClass,
+				keys:                  cfg.Keys,
+				strictReads:           cfg.StrictReads,
+				head:                  cfg.Head,
+				singleReplicaPrefixes: cfg.SingleReplicaPrefixes,
+				requireContentMD5:     cfg.RequireContentMD5,
+				syncWrites:            cfg.SyncWrites,
+				events:                events,
+				multipart:             multipart,
 			}
 		} else if cfg.Protection.Scheme == ErasureType {
 			s.erasureClients[bucket] = erasureConfig{
@@ -233,6 +905,18 @@ func (g *Radio) NewRadioLayer() (ObjectLayer, error) {
 			}
 		}
 	}
+
+	if g.rconfig.LagSampler.Interval > 0 {
+		go newLagSampler(&s, g.rconfig.LagSampler.Interval,
+			g.rconfig.LagSampler.SampleSize).run(context.Background())
+	}
+	if g.rconfig.Scanner.Interval > 0 {
+		if s.healSys == nil {
+			return nil, fmt.Errorf("scanner requires the heal journal")
+		}
+		go newDivergenceScanner(&s, g.rconfig.Scanner.Interval,
+			g.rconfig.Scanner.Rate).run(context.Background())
+	}
 	return &s, nil
 }
 
@@ -248,7 +932,26 @@ type radioObjects struct {
 	mirrorClients        map[string]mirrorConfig
 	erasureClients       map[string]erasureConfig
 	multipartUploadIDMap map[string][]string
+	completedUploads     *completedUploads
 	nsMutex              *NSLockMap
+	deleteParallelism    int
+	deleteBatchSize      int
+	deleteRate           int
+	listBatchSize        int
+	healSys              *healSys
+	disabledOperations   map[string]bool
+	timeouts             timeoutsConfig
+	slowLog              *slowLog
+	replicaReads         bool
+	contentRadioTags     bool
+	affinity             *readAffinity
+	listCache            *listCache
+	statsCache           *statsCache
+	// Spools large uploads for failed replicas to be retried if not
+	// nil.
+	spooler *uploadSpooler
+	// Info of the objects recently written, nil if disabled.
+	recentWrites *recentWrites
 }
 
 func (l *radioObjects) NewNSLock(ctx context.Context, bucket string, object string) RWLocker {
@@ -283,49 +986,109 @@ func (l *radioObjects) ListBuckets(ctx context.Context) ([]BucketInfo, error) {
 
 // ListObjects lists all blobs in S3 bucket filtered by prefix
 func (l *radioObjects) ListObjects(ctx context.Context, bucket string, prefix string, marker string, delimiter string, maxKeys int) (loi ListObjectsInfo, e error) {
+	if err := l.operationAllowed(operationListObjects); err != nil {
+		return loi, err
+	}
+
 	rs3, ok := l.mirrorClients[bucket]
 	if !ok {
 		return loi, BucketNotFound{
 			Bucket: bucket,
 		}
 	}
+	rs3 = rs3.forUser(ctx)
+	prefix, marker = rs3.keys.prefix(prefix), rs3.keys.prefix(marker)
 
-	var err error
-	for _, clnt := range rs3.clnts {
-		var result miniogo.ListBucketResult
-		result, err = clnt.ListObjects(clnt.Bucket, prefix, marker, delimiter, maxKeys)
-		if err != nil {
-			continue
-		}
-		return FromMinioClientListBucketResult(bucket, result), nil
+	cacheKey := listCacheKey{
+		user:      rs3.mappedUser(ctx),
+		bucket:    bucket,
+		prefix:    prefix,
+		marker:    marker,
+		delimiter: delimiter,
+		maxKeys:   maxKeys,
+	}
+	if cached, ok := l.listCache.get(ctx, cacheKey); ok {
+		return cached.(ListObjectsInfo), nil
+	}
+
+	objects, prefixes, last, err := collectListPages(bucket,
+		l.listObjectsPages(ctx, rs3, prefix, marker, delimiter, maxKeys))
+	if err != nil {
+		return loi, ErrorRespToObjectError(err, bucket)
+	}
+	loi = ListObjectsInfo{
+		IsTruncated: last.truncated,
+		Objects:     objects,
+		Prefixes:    prefixes,
 	}
-	return loi, ErrorRespToObjectError(err, bucket)
+	if last.truncated {
+		loi.NextMarker = last.next
+	}
+	l.listCache.set(cacheKey, loi)
+	return loi, nil
 }
 
 // ListObjectsV2 lists all blobs in S3 bucket filtered by prefix
 func (l *radioObjects) ListObjectsV2(ctx context.Context, bucket, prefix, continuationToken, delimiter string, maxKeys int, fetchOwner bool, startAfter string) (loi ListObjectsV2Info, e error) {
+	if err := l.operationAllowed(operationListObjects); err != nil {
+		return loi, err
+	}
+
 	rs3, ok := l.mirrorClients[bucket]
 	if !ok {
 		return loi, BucketNotFound{
 			Bucket: bucket,
 		}
 	}
-	var err error
-	for _, clnt := range rs3.clnts {
-		var result miniogo.ListBucketV2Result
-		result, err = clnt.ListObjectsV2(clnt.Bucket, prefix,
-			continuationToken, fetchOwner, delimiter,
-			maxKeys, startAfter)
-		if err != nil {
-			continue
-		}
-		return FromMinioClientListBucketV2Result(bucket, result), nil
+	rs3 = rs3.forUser(ctx)
+	prefix, startAfter = rs3.keys.prefix(prefix), rs3.keys.prefix(startAfter)
+	cacheKey := listCacheKey{
+		user:       rs3.mappedUser(ctx),
+		v2:         true,
+		bucket:     bucket,
+		prefix:     prefix,
+		marker:     continuationToken,
+		delimiter:  delimiter,
+		maxKeys:    maxKeys,
+		fetchOwner: fetchOwner,
+		startAfter: startAfter,
+	}
+	if cached, ok := l.listCache.get(ctx, cacheKey); ok {
+		return cached.(ListObjectsV2Info), nil
+	}
+
+	objects, prefixes, last, err := collectListPages(bucket,
+		l.listObjectsV2Pages(ctx, rs3, prefix, continuationToken, delimiter, maxKeys, fetchOwner, startAfter))
+	if err != nil {
+		return loi, ErrorRespToObjectError(err, bucket)
 	}
-	return loi, ErrorRespToObjectError(err, bucket)
+	loi = ListObjectsV2Info{
+		IsTruncated:       last.truncated,
+		Objects:           objects,
+		Prefixes:          prefixes,
+		ContinuationToken: continuationToken,
+	}
+	if last.truncated {
+		loi.NextContinuationToken = last.next
+	}
+	l.listCache.set(cacheKey, loi)
+	return loi, nil
 }
 
 // GetObjectNInfo - returns object info and locked object ReadCloser
 func (l *radioObjects) GetObjectNInfo(ctx context.Context, bucket, object string, rs *HTTPRangeSpec, h http.Header, lockType LockType, o ObjectOptions) (gr *GetObjectReader, err error) {
+	if err := l.operationAllowed(operationGetObject); err != nil {
+		return nil, err
+	}
+	object = l.objectName(bucket, object)
+
+	ctx, cancel := withDeadline(ctx, l.timeouts.Read)
+	defer func() {
+		if err != nil {
+			cancel()
+		}
+	}()
+
 	var nsUnlocker = func() {}
 
 	// Acquire lock
@@ -351,40 +1114,48 @@ func (l *radioObjects) GetObjectNInfo(ctx context.Context, bucket, object string
 			Bucket: bucket,
 		}
 	}
+	rs3s = rs3s.forUser(ctx).forObject(object)
 
 	info, err := l.getObjectInfo(ctx, bucket, object, o)
 	if err != nil {
 		return nil, ErrorRespToObjectError(err, bucket, object)
 	}
+	if isArchivedObject(info) {
+		// Objects are only read once restored.
+		return nil, ObjectArchived{Bucket: bucket, Object: object}
+	}
 
 	startOffset, length, err := rs.GetOffsetLength(info.Size)
 	if err != nil {
 		return nil, ErrorRespToObjectError(err, bucket, object)
 	}
 
+	// Read from the selected replica first, resuming the remaining range
+	// from the other replicas holding the same version on read errors.
+	// The replica preferred for the client comes first when it holds
+	// that version.
+	replicas := []int{info.ReplicaIndex}
+	for _, index := range info.Replicas {
+		if index != info.ReplicaIndex {
+			replicas = append(replicas, index)
+		}
+	}
+	replicas = preferReplica(replicas, l.affinity.preferred(ctx, h))
+
 	pr, pw := io.Pipe()
 	go func() {
-		opts := miniogo.GetObjectOptions{}
-		opts.ServerSideEncryption = o.ServerSideEncryption
-
-		if startOffset >= 0 && length >= 0 {
-			if err := opts.SetRange(startOffset, startOffset+length-1); err != nil {
-				pw.CloseWithError(ErrorRespToObjectError(err, bucket, object))
-				return
+		defer cancel()
+		w := &countingWriter{w: pw}
+		var err error
+		for _, index := range replicas {
+			err = readReplicaRange(ctx, rs3s.clnts[index].reader(), object, startOffset+w.n, length-w.n, o,
+				l.timeouts.ReadStall, w)
+			if err == nil || w.err != nil || ctx.Err() != nil {
+				break
 			}
+			err = rs3s.replicaErr("GetObject", index, err)
+			logger.LogIf(ctx, err)
 		}
-
-		reader, _, _, err := rs3s.clnts[info.ReplicaIndex].GetObjectWithContext(
-			ctx,
-			rs3s.clnts[info.ReplicaIndex].Bucket,
-			object, opts)
-		if err != nil {
-			pw.CloseWithError(ErrorRespToObjectError(err, bucket, object))
-			return
-		}
-		defer reader.Close()
-
-		_, err = io.Copy(pw, reader)
 		pw.CloseWithError(ErrorRespToObjectError(err, bucket, object))
 	}()
 
@@ -394,6 +1165,111 @@ func (l *radioObjects) GetObjectNInfo(ctx context.Context, bucket, object string
 	return NewGetObjectReaderFromReader(pr, info, o.CheckCopyPrecondFn, pipeCloser, nsUnlocker)
 }
 
+// countingWriter counts the bytes written to w and remembers write
+// errors, to tell them apart from read errors.
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	if err != nil {
+		c.err = err
+	}
+	return n, err
+}
+
+// readReplicaRange copies length bytes of object starting at offset
+// from clnt into w, failing with errReadStalled when no data arrives
+// for the stall duration if non-zero.
+func readReplicaRange(ctx context.Context, clnt bucketClient, object string, offset, length int64, o ObjectOptions,
+	stall time.Duration, w io.Writer) error {
+	if length <= 0 {
+		return nil
+	}
+
+	var watchdog *stallWatchdog
+	if stall > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		watchdog = newStallWatchdog(stall, cancel)
+		defer watchdog.stop()
+	}
+
+	opts := miniogo.GetObjectOptions{}
+	opts.ServerSideEncryption = o.ServerSideEncryption
+	if err := opts.SetRange(offset, offset+length-1); err != nil {
+		return err
+	}
+
+	reader, _, _, err := clnt.GetObjectWithContext(ctx, clnt.Bucket, clnt.objectKey(object), opts)
+	if err != nil {
+		if watchdog.stalled() {
+			return errReadStalled
+		}
+		return err
+	}
+	defer reader.Close()
+	watchdog.stop()
+
+	_, err = io.CopyN(w, watchdog.reader(reader), length)
+	if err != nil && watchdog.stalled() {
+		return errReadStalled
+	}
+	return err
+}
+
+// SelectObjectContent forwards an S3 Select request to the replica
+// holding the quorum version of the object, falling back to the other
+// replicas holding that version if it is offline.
+func (l *radioObjects) SelectObjectContent(ctx context.Context, bucket, object string, sopts miniogo.SelectObjectOptions) (*miniogo.SelectResults, error) {
+	if err := l.operationAllowed(operationSelectObject); err != nil {
+		return nil, err
+	}
+	object = l.objectName(bucket, object)
+
+	rs3s, ok := l.mirrorClients[bucket]
+	if !ok {
+		return nil, BucketNotFound{
+			Bucket: bucket,
+		}
+	}
+	rs3s = rs3s.forUser(ctx).forObject(object)
+
+	info, err := l.getObjectInfo(ctx, bucket, object, ObjectOptions{
+		ServerSideEncryption: sopts.ServerSideEncryption,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	replicas := []int{info.ReplicaIndex}
+	for _, index := range info.Replicas {
+		if index != info.ReplicaIndex {
+			replicas = append(replicas, index)
+		}
+	}
+
+	for _, index := range replicas {
+		clnt := rs3s.clnts[index].reader()
+		var results *miniogo.SelectResults
+		results, err = clnt.SelectObjectContent(ctx, clnt.Bucket, clnt.objectKey(object), sopts)
+		if err == nil {
+			return results, nil
+		}
+		offline := xnet.IsNetworkOrHostDown(err)
+		err = rs3s.replicaErr("SelectObjectContent", index, err)
+		if !offline {
+			break
+		}
+	}
+	return nil, ErrorRespToObjectError(err, bucket, object)
+}
+
 // GetObject reads an object from S3. Supports additional
 // parameters like offset and length which are synonymous with
 // HTTP Range requests.
@@ -407,7 +1283,7 @@ func (l *radioObjects) GetObject(ctx context.Context, bucket string, object stri
 func quorumInfo(infos []miniogo.ObjectInfo) (miniogo.ObjectInfo, int, error) {
 	tagCounter := map[string]int{}
 	for _, info := range infos {
-		uuid := info.Metadata.Get("x-amz-meta-radio-tag")
+		uuid := info.Metadata.Get(globalRadioTagKey)
 		_, ok := tagCounter[uuid]
 		if !ok {
 			tagCounter[uuid] = 1
@@ -429,7 +1305,7 @@ func quorumInfo(infos []miniogo.ObjectInfo) (miniogo.ObjectInfo, int, error) {
 	var info miniogo.ObjectInfo
 	var index int
 	for index, info = range infos {
-		uuid := info.Metadata.Get("x-amz-meta-radio-tag")
+		uuid := info.Metadata.Get(globalRadioTagKey)
 		if uuid != maximalUUID {
 			continue
 		}
@@ -445,43 +1321,128 @@ func (l *radioObjects) getObjectInfo(ctx context.Context, bucket string, object
 			Bucket: bucket,
 		}
 	}
+	rs3s = rs3s.forUser(ctx).forObject(object)
+	if opts.Replica != nil {
+		return l.replicaObjectInfo(ctx, bucket, object, rs3s, opts)
+	}
 
-	oinfos := make([]miniogo.ObjectInfo, len(rs3s.clnts))
-	g := errgroup.WithNErrs(len(rs3s.clnts))
-	for index := range rs3s.clnts {
-		index := index
+	slow := l.slowLog.start("GetObjectInfo", bucket, object)
+	defer slow.done(ctx)
+
+	// Shadow replicas are left out, oinfos and errs are indexed like
+	// readable.
+	readable := rs3s.readReplicas()
+	oinfos := make([]miniogo.ObjectInfo, len(readable))
+	g := errgroup.WithNErrs(len(readable))
+	for i, index := range readable {
+		i, clnt := i, rs3s.clnts[index].reader()
 		g.Go(func() error {
+			defer slow.replica(clnt)()
 			nctx, cancel := context.WithTimeout(context.Background(),
 				3*time.Second)
 			defer cancel()
 
 			var perr error
-			oinfos[index], perr = rs3s.clnts[index].StatObjectWithContext(
-				nctx,
-				rs3s.clnts[index].Bucket, object,
-				miniogo.StatObjectOptions{
-					GetObjectOptions: miniogo.GetObjectOptions{
-						ServerSideEncryption: opts.ServerSideEncryption,
-					},
-				})
+			oinfos[i], perr = statReplica(nctx, clnt, object, opts)
 			return perr
-		}, index)
+		}, i)
 	}
 
-	if maxErr := reduceReadQuorumErrs(ctx, g.Wait(), nil, len(rs3s.clnts)/2); maxErr != nil {
-		return ObjectInfo{}, ErrorRespToObjectError(maxErr, bucket, object)
+	errs := g.Wait()
+	if l.healSys.tombstoned(ctx, bucket, object, oinfos, errs) {
+		return ObjectInfo{}, ObjectNotFound{Bucket: bucket, Object: object}
+	}
+	if l.healSys.quarantined(bucket, object) && replicasDiverge(oinfos, errs) {
+		return ObjectInfo{}, ObjectQuarantined{Bucket: bucket, Object: object}
+	}
+	if rs3s.strictReads {
+		if err = l.checkReplicaConflict(ctx, bucket, object, readable, oinfos, errs); err != nil {
+			return ObjectInfo{}, err
+		}
+	}
+	var info miniogo.ObjectInfo
+	rindex := -1
+	err = reduceReadQuorumErrs(ctx, errs, nil, len(readable)/2)
+	if err == nil {
+		info, rindex, err = quorumInfo(oinfos)
 	}
-
-	info, rindex, err := quorumInfo(oinfos)
 	if err != nil {
-		return ObjectInfo{}, ErrorRespToObjectError(err, bucket, object)
+		// Replicas disagree while a journaled write is being healed,
+		// the replica the write succeeded on still holds a consistent
+		// copy.
+		if rindex = l.healSys.pendingSource(ctx, bucket, object, readable, oinfos, errs); rindex < 0 {
+			// The info of an object written moments ago outlives
+			// a blip of all remotes.
+			if allReplicasDown(errs) && isRecentWriteRead(ctx) {
+				if info, ok := l.recentWrites.get(bucket, object); ok {
+					staleObjectInfo.WithLabelValues(bucket).Inc()
+					return info, nil
+				}
+			}
+			return ObjectInfo{}, rs3s.toObjectError(ctx, "GetObjectInfo", readable, errs, err, bucket, object)
+		}
+		info = oinfos[rindex]
+	}
+	meterDegraded("GetObjectInfo", errs)
+
+	// Archived copies without a restored copy cannot be read, the object
+	// is reported by a replica which can serve it if any, and only such
+	// replicas are listed.
+	radioTag := info.Metadata.Get(globalRadioTagKey)
+	sameVersion := func(i int) bool {
+		return errs[i] == nil && oinfos[i].Metadata.Get(globalRadioTagKey) == radioTag
+	}
+	archived := isArchivedReplica(info)
+	if archived {
+		for i := range oinfos {
+			if sameVersion(i) && !isArchivedReplica(oinfos[i]) {
+				info, rindex, archived = oinfos[i], i, false
+				break
+			}
+		}
 	}
 
-	return FromMinioClientObjectInfo(bucket, info, rindex), nil
+	objInfo = FromMinioClientObjectInfo(bucket, info, readable[rindex])
+	for i := range oinfos {
+		if sameVersion(i) && (archived || !isArchivedReplica(oinfos[i])) {
+			objInfo.Replicas = append(objInfo.Replicas, readable[i])
+		}
+	}
+	return objInfo, nil
+}
+
+// statReplica stats object on clnt, telling objects encrypted with a
+// different customer key than the one of opts apart.
+func statReplica(ctx context.Context, clnt bucketClient, object string, opts ObjectOptions) (miniogo.ObjectInfo, error) {
+	oinfo, err := clnt.StatObjectWithContext(ctx,
+		clnt.Bucket, clnt.objectKey(object),
+		miniogo.StatObjectOptions{
+			GetObjectOptions: miniogo.GetObjectOptions{
+				ServerSideEncryption: opts.ServerSideEncryption,
+			},
+		})
+	oinfo.Key = object
+	if isEncryptedObjectErr(err) {
+		switch {
+		case opts.ServerSideEncryption == nil:
+			err = errEncryptedObject
+		case ssecKey(opts.ServerSideEncryption) != nil:
+			err = ErrInvalidCustomerKey
+		}
+	}
+	return oinfo, err
 }
 
 // GetObjectInfo reads object info and replies back ObjectInfo
 func (l *radioObjects) GetObjectInfo(ctx context.Context, bucket string, object string, opts ObjectOptions) (objInfo ObjectInfo, err error) {
+	if err := l.operationAllowed(operationHeadObject); err != nil {
+		return objInfo, err
+	}
+	object = l.objectName(bucket, object)
+
+	ctx, cancel := withDeadline(ctx, l.timeouts.Read)
+	defer cancel()
+
 	// Lock the object before reading.
 	objectLock := l.NewNSLock(ctx, bucket, object)
 	if err := objectLock.GetRLock(globalObjectTimeout); err != nil {
@@ -494,7 +1455,20 @@ func (l *radioObjects) GetObjectInfo(ctx context.Context, bucket string, object
 
 // PutObject creates a new object with the incoming data,
 func (l *radioObjects) PutObject(ctx context.Context, bucket string, object string, r *PutObjReader, opts ObjectOptions) (objInfo ObjectInfo, err error) {
+	if err := l.operationAllowed(operationPutObject); err != nil {
+		return ObjectInfo{}, err
+	}
+	if object, err = l.newObjectName(bucket, object); err != nil {
+		return ObjectInfo{}, err
+	}
+
+	ctx, cancel := withDeadline(ctx, l.timeouts.Write)
+	defer cancel()
+
 	data := r.Reader
+	if err = l.mirrorClients[bucket].checkContentMD5(data.MD5Base64String()); err != nil {
+		return ObjectInfo{}, err
+	}
 
 	// Lock the object before reading.
 	objectLock := l.NewNSLock(ctx, bucket, object)
@@ -503,55 +1477,159 @@ func (l *radioObjects) PutObject(ctx context.Context, bucket string, object stri
 	}
 	defer objectLock.Unlock()
 
+	// Preconditions are evaluated under the object lock so that no
+	// other write can slip in between the check and the write.
+	if opts.CheckPutPrecondFn != nil {
+		oi, err := l.getObjectInfo(ctx, bucket, object, opts)
+		exists := err == nil
+		if _, ok := err.(ObjectNotFound); err != nil && !ok {
+			return ObjectInfo{}, err
+		}
+		if opts.CheckPutPrecondFn(oi, exists) {
+			return ObjectInfo{}, PreConditionFailed{}
+		}
+	}
+
+	return l.putObject(ctx, bucket, object, data, data.Size(),
+		data.MD5Base64String(), data.SHA256HexString(), opts)
+}
+
+// putObject writes data to all replicas of bucket, callers must hold
+// the object lock.
+func (l *radioObjects) putObject(ctx context.Context, bucket, object string, data io.Reader, size int64, md5Base64, sha256Hex string, opts ObjectOptions) (objInfo ObjectInfo, err error) {
 	rs3s, ok := l.mirrorClients[bucket]
 	if !ok {
 		return objInfo, BucketNotFound{Bucket: bucket}
 	}
+	rs3s = rs3s.forUser(ctx).forObject(object)
+	if err = rs3s.checkFreeSpace(); err != nil {
+		return objInfo, err
+	}
 
-	readers, err := streamdup.New(data, len(rs3s.clnts))
+	digest := &digestReader{r: data}
+	var source io.Reader = digest
+	spool := l.spooler.spool(ctx, digest, size)
+	defer spool.remove(ctx)
+	if spool != nil {
+		source = spool
+	}
+	var readers []io.Reader
+	if size == 0 {
+		// Empty objects are sent with their Content-MD5, for all
+		// replicas to store them under the same ETag.
+		readers, err = emptyUploadReaders(data, len(rs3s.clnts))
+		md5Base64 = emptyObjectMD5Base64
+	} else {
+		readers, err = newStreamDup(source, len(rs3s.clnts), l.timeouts.WriteStall)
+	}
 	if err != nil {
 		return objInfo, ErrorRespToObjectError(err, bucket, object)
 	}
 
-	opts.UserDefined["x-amz-meta-radio-tag"] = mustGetUUID()
+	radioTag := l.newRadioTag(sha256Hex, opts.UserDefined)
+	opts.UserDefined = withDefaultStorageClass(withDefaultACL(withRadioTag(opts.UserDefined, radioTag), rs3s.acl), rs3s.storageClass)
+	if opts.UserDefined, err = rs3s.metadata.apply(opts.UserDefined); err != nil {
+		return objInfo, err
+	}
+
+	slow := l.slowLog.start("PutObject", bucket, object)
+	defer slow.done(ctx)
 
 	oinfos := make([]miniogo.ObjectInfo, len(rs3s.clnts))
 	g := errgroup.WithNErrs(len(rs3s.clnts))
 	for index := range rs3s.clnts {
 		index := index
 		g.Go(func() error {
+			if rs3s.clnts[index].ReadOnly {
+				return skipUpload(readers[index], errReadOnlyReplica)
+			}
+			if rs3s.clnts[index].asyncWrites {
+				return skipUpload(readers[index], errAsyncReplica)
+			}
+			defer slow.replica(rs3s.clnts[index])()
+			metadata := rs3s.clnts[index].storageClassMetadata(opts.UserDefined)
+			reader := &deliveryReader{r: readers[index]}
+			rctx, cancel := withStallCancel(ctx, readers[index])
+			defer cancel()
 			var perr error
-			oinfos[index], perr = rs3s.clnts[index].PutObjectWithContext(ctx,
-				rs3s.clnts[index].Bucket, object,
-				readers[index], data.Size(),
-				data.MD5Base64String(), data.SHA256HexString(),
-				ToMinioClientMetadata(opts.UserDefined), opts.ServerSideEncryption)
+			oinfos[index], perr = rs3s.clnts[index].PutObjectWithContext(rctx,
+				rs3s.clnts[index].Bucket, rs3s.clnts[index].objectKey(object),
+				reader, size, md5Base64, rs3s.clnts[index].payloadSHA256(sha256Hex),
+				ToMinioClientMetadata(metadata), opts.ServerSideEncryption)
 			oinfos[index].Key = object
-			oinfos[index].Metadata = ToMinioClientObjectInfoMetadata(opts.UserDefined)
-			return perr
+			oinfos[index].Metadata = ToMinioClientObjectInfoMetadata(metadata)
+			// The data is consumed, a downgraded write to this
+			// replica is left to heal.
+			rs3s.clnts[index].downgradeStorageClass(ctx, metadata, perr)
+			if err := checkDelivered(bucket, reader, size, perr); err != nil {
+				// The replica may have failed without reading the
+				// upload, which the other replicas wait for.
+				return skipUpload(readers[index], err)
+			}
+			return nil
 		}, index)
 	}
 
 	errs := g.Wait()
-	if maxErr := reduceWriteQuorumErrs(ctx, errs, nil, len(rs3s.clnts)/2+1); maxErr != nil {
+	if spool != nil {
+		spool.retry(ctx, rs3s, bucket, object, readers, size, md5Base64, sha256Hex,
+			opts.UserDefined, opts.ServerSideEncryption, oinfos, errs)
+	}
+	l.listCache.invalidate(bucket, object)
+	l.recentWrites.forget(bucket, object)
+	rs3s.shadowResults(ctx, bucket, errs)
+	if maxErr := rs3s.reduceWriteErrs(ctx, errs); maxErr != nil {
 		for index, err := range errs {
-			if err == nil {
-				rs3s.clnts[index].RemoveObject(rs3s.clnts[index].Bucket, object)
+			if err == nil || err == errShortWrite {
+				rs3s.clnts[index].RemoveObject(rs3s.clnts[index].Bucket,
+					rs3s.clnts[index].objectKey(object))
 			}
 		}
-		return objInfo, ErrorRespToObjectError(maxErr, bucket, object)
-	}
+		if digest.err != nil {
+			return objInfo, digest.err
+		}
+		return objInfo, rs3s.toObjectError(ctx, "PutObject", nil, errs, maxErr, bucket, object)
+	}
+	meterDegraded("PutObject", errs)
+
+	// All replicas that accepted the write hold the same version,
+	// though not necessarily under the same ETag.
+	rindex, diverged := putETagReplica(md5Base64, oinfos, errs)
+	if diverged {
+		logETagDivergence(ctx, bucket, object, rs3s, oinfos, errs, rindex)
+	}
+	info := oinfos[rindex]
+	rs3s.syncReplicas(ctx, bucket, object, rindex, errs, opts.ServerSideEncryption)
+
+	l.healSys.queue(ctx, journalEntry{
+		Bucket:       bucket,
+		Object:       object,
+		Op:           opPutObject,
+		RadioTag:     radioTag,
+		SrcClientID:  rindex,
+		DstClientIDs: failedReplicas(errs),
+		sse:          opts.ServerSideEncryption,
+	})
+
+	objInfo = FromMinioClientObjectInfo(bucket, info, rindex)
+	l.recentWrites.add(objInfo, opts.UserDefined)
+	rs3s.events.notify(event.ObjectCreatedPut, objInfo)
+	return objInfo, nil
+}
 
-	info, rindex, err := quorumInfo(oinfos)
-	if err != nil {
+// CopyObject copies an object from source bucket to a destination bucket.
+func (l *radioObjects) CopyObject(ctx context.Context, srcBucket string, srcObject string, dstBucket string, dstObject string, srcInfo ObjectInfo, srcOpts, dstOpts ObjectOptions) (objInfo ObjectInfo, err error) {
+	if err := l.operationAllowed(operationCopyObject); err != nil {
+		return objInfo, err
+	}
+	srcObject = l.objectName(srcBucket, srcObject)
+	if dstObject, err = l.newObjectName(dstBucket, dstObject); err != nil {
 		return objInfo, err
 	}
 
-	return FromMinioClientObjectInfo(bucket, info, rindex), nil
-}
+	ctx, cancel := withDeadline(ctx, l.timeouts.Write)
+	defer cancel()
 
-// CopyObject copies an object from source bucket to a destination bucket.
-func (l *radioObjects) CopyObject(ctx context.Context, srcBucket string, srcObject string, dstBucket string, dstObject string, srcInfo ObjectInfo, srcOpts, dstOpts ObjectOptions) (objInfo ObjectInfo, err error) {
 	// Check if this request is only metadata update.
 	cpSrcDstSame := isStringEqual(pathJoin(srcBucket, srcObject), pathJoin(dstBucket, dstObject))
 	if !cpSrcDstSame {
@@ -565,29 +1643,31 @@ func (l *radioObjects) CopyObject(ctx context.Context, srcBucket string, srcObje
 	if srcOpts.CheckCopyPrecondFn != nil && srcOpts.CheckCopyPrecondFn(srcInfo) {
 		return ObjectInfo{}, PreConditionFailed{}
 	}
+
+	rs3sSrc := l.mirrorClients[srcBucket].forUser(ctx).forObject(srcObject)
+	rs3sDest := l.mirrorClients[dstBucket].forUser(ctx).forObject(dstObject)
+	if err = rs3sDest.checkFreeSpace(); err != nil {
+		return objInfo, err
+	}
+	if len(rs3sSrc.clnts) != len(rs3sDest.clnts) {
+		// Replicas cannot be paired for server side copies, stream
+		// the object through radio instead.
+		return l.copyObjectByStream(ctx, dstBucket, dstObject, srcInfo, dstOpts)
+	}
+
 	// Set this header such that following CopyObject() always sets the right metadata on the destination.
 	// metadata input is already a trickled down value from interpreting x-amz-metadata-directive at
 	// handler layer. So what we have right now is supposed to be applied on the destination object anyways.
 	// So preserve it by adding "REPLACE" directive to save all the metadata set by CopyObject API.
+	srcInfo.UserDefined = withDefaultStorageClass(withDefaultACL(withRadioTag(srcInfo.UserDefined, l.newRadioTag("", srcInfo.UserDefined)), rs3sDest.acl), rs3sDest.storageClass)
 	srcInfo.UserDefined["x-amz-metadata-directive"] = "REPLACE"
-	srcInfo.UserDefined["x-amz-copy-source-if-match"] = srcInfo.ETag
-	header := make(http.Header)
-	if srcOpts.ServerSideEncryption != nil {
-		encrypt.SSECopy(srcOpts.ServerSideEncryption).Marshal(header)
-	}
-
-	if dstOpts.ServerSideEncryption != nil {
-		dstOpts.ServerSideEncryption.Marshal(header)
-	}
-	for k, v := range header {
-		srcInfo.UserDefined[k] = v[0]
+	srcInfo.UserDefined = copyRequestHeaders(srcInfo.UserDefined, srcInfo.ETag, srcOpts, dstOpts)
+	if srcInfo.UserDefined, err = rs3sDest.metadata.apply(srcInfo.UserDefined); err != nil {
+		return objInfo, err
 	}
 
-	rs3sSrc := l.mirrorClients[srcBucket]
-	rs3sDest := l.mirrorClients[dstBucket]
-	if len(rs3sSrc.clnts) != len(rs3sDest.clnts) {
-		return objInfo, errors.New("unexpected")
-	}
+	slow := l.slowLog.start("CopyObject", dstBucket, dstObject)
+	defer slow.done(ctx)
 
 	n := len(rs3sDest.clnts)
 	oinfos := make([]miniogo.ObjectInfo, n)
@@ -596,32 +1676,108 @@ func (l *radioObjects) CopyObject(ctx context.Context, srcBucket string, srcObje
 	for index := 0; index < n; index++ {
 		index := index
 		g.Go(func() error {
-			var err error
-			oinfos[index], err = rs3sSrc.clnts[index].CopyObjectWithContext(
-				ctx,
-				rs3sSrc.clnts[index].Bucket, srcObject,
-				rs3sDest.clnts[index].Bucket, dstObject, srcInfo.UserDefined)
+			if rs3sDest.clnts[index].ReadOnly {
+				return errReadOnlyReplica
+			}
+			if rs3sDest.clnts[index].asyncWrites {
+				return errAsyncReplica
+			}
+			defer slow.replica(rs3sDest.clnts[index])()
+			src, dst := rs3sSrc.clnts[index], rs3sDest.clnts[index]
+			copyObject := func(metadata map[string]string) (err error) {
+				oinfos[index], err = src.CopyObjectWithContext(ctx,
+					src.Bucket, src.objectKey(srcObject),
+					dst.Bucket, dst.objectKey(dstObject),
+					src.copyMetadata(metadata))
+				return err
+			}
+			metadata := dst.storageClassMetadata(srcInfo.UserDefined)
+			err := copyObject(metadata)
+			if dst.downgradeStorageClass(ctx, metadata, err) {
+				err = copyObject(dst.storageClassMetadata(metadata))
+			}
 			return err
 		}, index)
 	}
 
 	errs := g.Wait()
-	if maxErr := reduceWriteQuorumErrs(ctx, errs, nil, len(rs3sSrc.clnts)/2+1); maxErr != nil {
+	l.listCache.invalidate(dstBucket, dstObject)
+	l.recentWrites.forget(dstBucket, dstObject)
+	rs3sDest.shadowResults(ctx, dstBucket, errs)
+	if maxErr := rs3sDest.reduceWriteErrs(ctx, errs); maxErr != nil {
 		for index, err := range errs {
 			if err == nil {
 				rs3sDest.clnts[index].RemoveObject(
 					rs3sDest.clnts[index].Bucket,
-					dstObject)
+					rs3sDest.clnts[index].objectKey(dstObject))
 			}
 		}
-		return objInfo, ErrorRespToObjectError(maxErr, srcBucket, srcObject)
+		return objInfo, rs3sDest.toObjectError(ctx, "CopyObject", nil, errs, maxErr, srcBucket, srcObject)
 	}
+	meterDegraded("CopyObject", errs)
 
-	return l.getObjectInfo(ctx, dstBucket, dstObject, dstOpts)
+	objInfo, err = l.getObjectInfo(ctx, dstBucket, dstObject, dstOpts)
+	if err != nil {
+		return objInfo, err
+	}
+
+	l.healSys.queue(ctx, journalEntry{
+		Bucket:       dstBucket,
+		Object:       dstObject,
+		Op:           opCopyObject,
+		RadioTag:     objInfo.UserDefined[globalRadioTagKey],
+		SrcClientID:  firstSucceeded(errs),
+		DstClientIDs: failedReplicas(errs),
+		sse:          dstOpts.ServerSideEncryption,
+	})
+	rs3sDest.events.notify(event.ObjectCreatedCopy, objInfo)
+	return objInfo, nil
+}
+
+// copyObjectByStream copies the source object read by the caller into
+// srcInfo onto all replicas of dstBucket, callers must hold the lock on
+// the destination object.
+func (l *radioObjects) copyObjectByStream(ctx context.Context, dstBucket, dstObject string, srcInfo ObjectInfo, dstOpts ObjectOptions) (ObjectInfo, error) {
+	if srcInfo.PutObjReader == nil {
+		return ObjectInfo{}, NotImplemented{}
+	}
+
+	data := srcInfo.PutObjReader.Reader
+	var reader io.Reader = data
+	var sha256Hex string
+	if l.contentRadioTags {
+		// Content radio tags are derived from the SHA256 of the data,
+		// buffer the copy to compute it before writing the replicas.
+		var dir string
+		if l.spooler != nil {
+			dir = l.spooler.dir
+		}
+		f, sum, err := bufferSHA256(data, dir)
+		if err != nil {
+			return ObjectInfo{}, ErrorRespToObjectError(err, dstBucket, dstObject)
+		}
+		defer func() {
+			f.Close()
+			os.Remove(f.Name())
+		}()
+		reader, sha256Hex = f, sum
+	}
+	return l.putObject(ctx, dstBucket, dstObject, reader, data.Size(), "", sha256Hex, ObjectOptions{
+		UserDefined:          copyableMetadata(srcInfo.UserDefined),
+		ServerSideEncryption: dstOpts.ServerSideEncryption,
+	})
 }
 
 // DeleteObject deletes a blob in bucket
 func (l *radioObjects) DeleteObject(ctx context.Context, bucket string, object string) error {
+	if err := l.operationAllowed(operationDeleteObject); err != nil {
+		return err
+	}
+	object = l.objectName(bucket, object)
+
+	ctx, cancel := withDeadline(ctx, l.timeouts.Write)
+	defer cancel()
+
 	objectLock := l.NewNSLock(ctx, bucket, object)
 	if err := objectLock.GetLock(globalObjectTimeout); err != nil {
 		return err
@@ -634,20 +1790,76 @@ func (l *radioObjects) DeleteObject(ctx context.Context, bucket string, object s
 			Bucket: bucket,
 		}
 	}
+	rs3s = rs3s.forUser(ctx).forObject(object)
+
+	slow := l.slowLog.start("DeleteObject", bucket, object)
+	defer slow.done(ctx)
 
 	n := len(rs3s.clnts)
 	g := errgroup.WithNErrs(n)
 	for index := 0; index < n; index++ {
 		index := index
 		g.Go(func() error {
-			return rs3s.clnts[index].RemoveObject(rs3s.clnts[index].Bucket, object)
+			if rs3s.clnts[index].ReadOnly {
+				return errReadOnlyReplica
+			}
+			defer slow.replica(rs3s.clnts[index])()
+			return rs3s.clnts[index].RemoveObject(rs3s.clnts[index].Bucket,
+				rs3s.clnts[index].objectKey(object))
 		}, index)
 	}
 
-	return reduceWriteQuorumErrs(ctx, g.Wait(), nil, len(rs3s.clnts)/2+1)
+	errs := g.Wait()
+	l.listCache.invalidate(bucket, object)
+	l.recentWrites.forget(bucket, object)
+	rs3s.shadowResults(ctx, bucket, errs)
+	if maxErr := rs3s.reduceWriteErrs(ctx, errs); maxErr != nil {
+		rs3s.logReplicaErrs(ctx, "DeleteObject", nil, errs)
+		return maxErr
+	}
+	meterDegraded("DeleteObject", errs)
+
+	// Journal a tombstone for the replicas that missed the delete, with
+	// the version they hold when reachable so that heal removes it
+	// rather than copying it back.
+	dstClientIDs := failedReplicas(errs)
+	var radioTag string
+	for _, index := range dstClientIDs {
+		if l.healSys == nil {
+			break
+		}
+		clnt := rs3s.clnts[index]
+		info, err := clnt.StatObjectWithContext(ctx, clnt.Bucket, clnt.objectKey(object), miniogo.StatObjectOptions{})
+		if err == nil {
+			radioTag = info.Metadata.Get(globalRadioTagKey)
+			break
+		}
+	}
+	l.healSys.queue(ctx, journalEntry{
+		Bucket:       bucket,
+		Object:       object,
+		Op:           opDeleteObject,
+		RadioTag:     radioTag,
+		SrcClientID:  firstSucceeded(errs),
+		DstClientIDs: dstClientIDs,
+	})
+	rs3s.events.notify(event.ObjectRemovedDelete, ObjectInfo{Bucket: bucket, Name: object})
+	return nil
 }
 
 func (l *radioObjects) DeleteObjects(ctx context.Context, bucket string, objects []string) ([]error, error) {
+	if err := l.operationAllowed(operationDeleteObject); err != nil {
+		return nil, err
+	}
+	names := make([]string, len(objects))
+	for i, object := range objects {
+		names[i] = l.objectName(bucket, object)
+	}
+	objects = names
+
+	ctx, cancel := withDeadline(ctx, l.timeouts.Write)
+	defer cancel()
+
 	errs := make([]error, len(objects))
 
 	objectLock := l.NewNSLock(ctx, bucket, "")
@@ -662,56 +1874,145 @@ func (l *radioObjects) DeleteObjects(ctx context.Context, bucket string, objects
 			Bucket: bucket,
 		}
 	}
+	rs3s = rs3s.forUser(ctx)
+	defer func() {
+		for _, object := range objects {
+			l.listCache.invalidate(bucket, object)
+			l.recentWrites.forget(bucket, object)
+		}
+	}()
 
 	n := len(rs3s.clnts)
 
-	objectsChs := make([]chan string, n)
-	for index := 0; index < n; index++ {
-		index := index
-		objectsChs[index] = make(chan string)
-		go func() {
-			defer close(objectsChs[index])
-			for _, object := range objects {
-				objectsChs[index] <- object
+	// Positions of each object name in the request, names may repeat.
+	positions := make(map[string][]int, len(objects))
+	objectErrs := make([][]error, len(objects))
+	for i, object := range objects {
+		positions[object] = append(positions[object], i)
+		objectErrs[i] = make([]error, n)
+		for index, clnt := range rs3s.clnts {
+			if clnt.ReadOnly {
+				objectErrs[i][index] = errReadOnlyReplica
 			}
-		}()
+		}
 	}
 
-	var errsCh = make([]<-chan miniogo.RemoveObjectError, n)
-	for index := 0; index < n; index++ {
-		errsCh[index] = rs3s.clnts[index].RemoveObjectsWithContext(ctx,
-			rs3s.clnts[index].Bucket,
-			objectsChs[index])
+	type deleteJob struct {
+		index   int
+		objects []string
 	}
 
-	multiObjectError := make(map[string][]error)
-	var exitCount int
-	for {
-		for _, errCh := range errsCh {
-			select {
-			case err, ok := <-errCh:
-				if !ok {
-					exitCount++
-					break
+	// Feed batches of objects to a bounded set of workers so that large
+	// requests do not overwhelm the remotes.
+	jobs := make(chan deleteJob)
+	go func() {
+		defer close(jobs)
+		for start := 0; start < len(objects); start += l.deleteBatchSize {
+			end := start + l.deleteBatchSize
+			if end > len(objects) {
+				end = len(objects)
+			}
+			for index := 0; index < n; index++ {
+				if rs3s.clnts[index].ReadOnly {
+					continue
+				}
+				select {
+				case jobs <- deleteJob{index: index, objects: objects[start:end]}:
+				case <-ctx.Done():
+					return
 				}
-				multiObjectError[err.ObjectName] = append(
-					multiObjectError[err.ObjectName],
-					err.Err,
-				)
-			default:
-				continue
 			}
 		}
-		if exitCount == len(errsCh) {
-			break
+	}()
+
+	// Objects are sent to each remote at up to the delete rate.
+	tickers := make([]*time.Ticker, n)
+	if l.deleteRate > 0 {
+		for index := range tickers {
+			tickers[index] = time.NewTicker(time.Second / time.Duration(l.deleteRate))
+			defer tickers[index].Stop()
 		}
 	}
 
-	for objName, errs := range multiObjectError {
-		for idx, robjName := range objects {
-			if objName == robjName {
-				errs[idx] = reduceWriteQuorumErrs(ctx, errs, nil, len(rs3s.clnts)/2+1)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for w := 0; w < l.deleteParallelism; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				clnt := rs3s.clnts[job.index]
+				objectsCh := make(chan string)
+				go func(objects []string, ticker *time.Ticker) {
+					defer close(objectsCh)
+					for _, object := range objects {
+						if ticker != nil {
+							select {
+							case <-ticker.C:
+							case <-ctx.Done():
+								return
+							}
+						}
+						select {
+						case objectsCh <- clnt.objectKey(object):
+						case <-ctx.Done():
+							return
+						}
+					}
+				}(job.objects, tickers[job.index])
+
+				for rerr := range clnt.RemoveObjectsWithContext(ctx, clnt.Bucket, objectsCh) {
+					mu.Lock()
+					if rerr.ObjectName == "" {
+						// The request failed as a whole, none of the
+						// batch is known to be deleted.
+						for _, object := range job.objects {
+							for _, i := range positions[object] {
+								objectErrs[i][job.index] = rerr.Err
+							}
+						}
+					} else {
+						for _, i := range positions[clnt.listKey(rerr.ObjectName)] {
+							objectErrs[i][job.index] = rerr.Err
+						}
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Objects not yet handed to the remotes would otherwise be
+	// reported as deleted.
+	if err := ctx.Err(); err != nil {
+		return errs, err
+	}
+
+	for i, object := range objects {
+		// Objects stored on the primary replica only are deleted from
+		// all replicas, but only the primary counts.
+		m := rs3s.forObject(object)
+		objectErrs[i] = objectErrs[i][:len(m.clnts)]
+		m.shadowResults(ctx, bucket, objectErrs[i])
+		errs[i] = m.toObjectError(ctx, "DeleteObjects", nil, objectErrs[i],
+			m.reduceWriteErrs(ctx, objectErrs[i]), bucket, object)
+		if errs[i] == nil {
+			meterDegraded("DeleteObjects", objectErrs[i])
+			if positions[object][0] == i {
+				// Journal a tombstone for the replicas that missed the
+				// delete. Unlike DeleteObject, the versions they hold
+				// are not stated, the tombstone covers the versions
+				// written before it.
+				l.healSys.queue(ctx, journalEntry{
+					Bucket:       bucket,
+					Object:       object,
+					Op:           opDeleteObject,
+					SrcClientID:  firstSucceeded(objectErrs[i]),
+					DstClientIDs: failedReplicas(objectErrs[i]),
+				})
 			}
+			rs3s.events.notify(event.ObjectRemovedDelete, ObjectInfo{Bucket: bucket, Name: object})
 		}
 	}
 	return errs, nil
@@ -719,29 +2020,43 @@ func (l *radioObjects) DeleteObjects(ctx context.Context, bucket string, objects
 
 // ListMultipartUploads lists all multipart uploads.
 func (l *radioObjects) ListMultipartUploads(ctx context.Context, bucket string, prefix string, keyMarker string, uploadIDMarker string, delimiter string, maxUploads int) (lmi ListMultipartsInfo, e error) {
+	if err := l.operationAllowed(operationMultipart); err != nil {
+		return lmi, err
+	}
+
 	rs3, ok := l.mirrorClients[bucket]
 	if !ok {
 		return lmi, BucketNotFound{Bucket: bucket}
 	}
+	rs3 = rs3.forUser(ctx)
+	prefix, keyMarker = rs3.keys.prefix(prefix), rs3.keys.prefix(keyMarker)
 
 	var err error
 	for _, clnt := range rs3.clnts {
+		if clnt.Shadow {
+			continue
+		}
 		var result miniogo.ListMultipartUploadsResult
-		result, err = clnt.ListMultipartUploads(clnt.Bucket, prefix,
-			keyMarker, uploadIDMarker, delimiter, maxUploads)
+		result, err = clnt.ListMultipartUploads(clnt.Bucket, clnt.objectKey(prefix),
+			clnt.markerKey(keyMarker), uploadIDMarker, delimiter, maxUploads)
 		if err != nil {
 			continue
 		}
-		return FromMinioClientListMultipartsInfo(result), nil
+		return FromMinioClientListMultipartsInfo(clnt.trimListMultipartUploadsResult(result)), nil
 	}
 	return lmi, ErrorRespToObjectError(err, bucket)
 }
 
 // NewMultipartUpload upload object in multiple parts
 func (l *radioObjects) NewMultipartUpload(ctx context.Context, bucket string, object string, o ObjectOptions) (string, error) {
+	if err := l.operationAllowed(operationMultipart); err != nil {
+		return "", err
+	}
+	object, err := l.newObjectName(bucket, object)
+	if err != nil {
+		return "", err
+	}
 
-	// Create PutObject options
-	opts := miniogo.PutObjectOptions{UserMetadata: o.UserDefined, ServerSideEncryption: o.ServerSideEncryption}
 	uploadID := mustGetUUID()
 
 	uploadIDLock := l.NewNSLock(ctx, bucket, pathJoin(object, uploadID))
@@ -754,23 +2069,72 @@ func (l *radioObjects) NewMultipartUpload(ctx context.Context, bucket string, ob
 	if !ok {
 		return uploadID, BucketNotFound{Bucket: bucket}
 	}
-
-	for _, clnt := range rs3s.clnts {
-		id, err := clnt.NewMultipartUpload(clnt.Bucket, object, opts)
-		if err != nil {
-			// Abort any failed uploads to one of the radios
-			clnt.AbortMultipartUpload(clnt.Bucket, object, uploadID)
-			return uploadID, ErrorRespToObjectError(err, bucket, object)
+	rs3s = rs3s.forUser(ctx).forObject(object)
+	if err = rs3s.checkFreeSpace(); err != nil {
+		return uploadID, err
+	}
+	if err = rs3s.multipart.startUpload(bucket); err != nil {
+		return uploadID, err
+	}
+	created := false
+	defer func() {
+		if !created {
+			rs3s.multipart.endUpload(bucket)
 		}
-		l.multipartUploadIDMap[uploadID] = append(l.multipartUploadIDMap[uploadID], id)
+	}()
+
+	metadata, err := rs3s.metadata.apply(withDefaultStorageClass(withDefaultACL(withRadioTag(o.UserDefined, l.newRadioTag("", o.UserDefined)), rs3s.acl), rs3s.storageClass))
+	if err != nil {
+		return uploadID, err
+	}
 
+	// Upload IDs of the replicas, empty for replicas without an upload.
+	ids := make([]string, 0, len(rs3s.clnts))
+	for index, clnt := range rs3s.clnts {
+		if clnt.ReadOnly {
+			// Keep upload IDs aligned with the replicas.
+			ids = append(ids, "")
+			continue
+		}
+		// Create PutObject options
+		opts := miniogo.PutObjectOptions{UserMetadata: clnt.storageClassMetadata(metadata), ServerSideEncryption: o.ServerSideEncryption}
+		id, err := clnt.NewMultipartUpload(clnt.Bucket, clnt.objectKey(object), opts)
+		if clnt.downgradeStorageClass(ctx, opts.UserMetadata, err) {
+			opts.UserMetadata = clnt.storageClassMetadata(opts.UserMetadata)
+			id, err = clnt.NewMultipartUpload(clnt.Bucket, clnt.objectKey(object), opts)
+		}
+		if err != nil && clnt.Shadow {
+			// Parts are not uploaded to shadow replicas without an upload.
+			logShadowError(ctx, bucket, clnt, err)
+			id = ""
+		} else if err != nil {
+			// No upload was initiated on this replica, abort the
+			// uploads initiated on the preceding replicas, which
+			// clients cannot abort without an upload ID.
+			abortUploads(ctx, bucket, object, rs3s, ids)
+			return uploadID, ErrorRespToObjectError(rs3s.replicaErr("NewMultipartUpload", index, err), bucket, object)
+		}
+		ids = append(ids, id)
 	}
+	l.multipartUploadIDMap[uploadID] = ids
+	created = true
 	return uploadID, nil
 }
 
 // PutObjectPart puts a part of object in bucket
 func (l *radioObjects) PutObjectPart(ctx context.Context, bucket string, object string, uploadID string, partID int, r *PutObjReader, opts ObjectOptions) (pi PartInfo, e error) {
+	if err := l.operationAllowed(operationMultipart); err != nil {
+		return pi, err
+	}
+	object = l.objectName(bucket, object)
+
+	ctx, cancel := withDeadline(ctx, l.timeouts.Multipart)
+	defer cancel()
+
 	data := r.Reader
+	if err := l.mirrorClients[bucket].checkContentMD5(data.MD5Base64String()); err != nil {
+		return pi, err
+	}
 
 	uploadIDLock := l.NewNSLock(ctx, bucket, pathJoin(object, uploadID))
 	if err := uploadIDLock.GetLock(globalOperationTimeout); err != nil {
@@ -787,39 +2151,114 @@ func (l *radioObjects) PutObjectPart(ctx context.Context, bucket string, object
 		}
 	}
 
-	rs3s := l.mirrorClients[bucket]
+	rs3s := l.mirrorClients[bucket].forUser(ctx).forObject(object)
+	if err := rs3s.checkFreeSpace(); err != nil {
+		return pi, err
+	}
+	if err := rs3s.multipart.startPart(bucket); err != nil {
+		return pi, err
+	}
+	defer rs3s.multipart.endPart()
 
-	readers, err := streamdup.New(data, len(rs3s.clnts))
+	digest := &digestReader{r: data}
+	readers, err := newStreamDup(digest, len(rs3s.clnts), l.timeouts.WriteStall)
 	if err != nil {
 		return pi, err
 	}
 
+	slow := l.slowLog.start("PutObjectPart", bucket, object)
+	defer slow.done(ctx)
+
 	pinfos := make([]miniogo.ObjectPart, len(rs3s.clnts))
 	g := errgroup.WithNErrs(len(rs3s.clnts))
 	for index := range rs3s.clnts {
 		index := index
 		g.Go(func() error {
+			if rs3s.clnts[index].ReadOnly {
+				return skipUpload(readers[index], errReadOnlyReplica)
+			}
+			if uploadIDs[index] == "" {
+				return skipUpload(readers[index], errShadowReplica)
+			}
+			defer slow.replica(rs3s.clnts[index])()
+			reader := &deliveryReader{r: readers[index]}
+			rctx, cancel := withStallCancel(ctx, readers[index])
+			defer cancel()
 			var err error
 			pinfos[index], err = rs3s.clnts[index].PutObjectPartWithContext(
-				ctx,
-				rs3s.clnts[index].Bucket, object,
-				uploadIDs[index], partID, readers[index], data.Size(),
-				data.MD5Base64String(), data.SHA256HexString(), opts.ServerSideEncryption)
-			return err
+				rctx,
+				rs3s.clnts[index].Bucket, rs3s.clnts[index].objectKey(object),
+				uploadIDs[index], partID, reader, data.Size(),
+				data.MD5Base64String(), rs3s.clnts[index].payloadSHA256(data.SHA256HexString()),
+				opts.ServerSideEncryption)
+			if err = checkDelivered(bucket, reader, data.Size(), err); err != nil {
+				// The replica may have failed without reading the
+				// part, which the other replicas wait for.
+				return skipUpload(readers[index], err)
+			}
+			return nil
 		}, index)
 	}
 
-	if maxErr := reduceWriteQuorumErrs(ctx, g.Wait(), nil, len(rs3s.clnts)/2+1); maxErr != nil {
-		return pi, ErrorRespToObjectError(maxErr, bucket, object)
+	errs := g.Wait()
+	rs3s.shadowResults(ctx, bucket, errs)
+	if maxErr := rs3s.reduceWriteErrs(ctx, errs); maxErr != nil {
+		if digest.err != nil {
+			return pi, digest.err
+		}
+		return pi, rs3s.toObjectError(ctx, "PutObjectPart", nil, errs, maxErr, bucket, object)
+	}
+	meterDegraded("PutObjectPart", errs)
+
+	// Report the part as seen by a replica that accepted it, remotes
+	// do not return a modification time for uploaded parts.
+	rindex := firstSucceeded(errs)
+	pinfos[rindex].Size = data.Size()
+	if pinfos[rindex].LastModified.IsZero() {
+		pinfos[rindex].LastModified = UTCNow()
+	}
+	return FromMinioClientObjectPart(pinfos[rindex]), nil
+}
+
+// copyRequestHeaders returns metadata along with the headers of a server
+// side copy of the source object with etag. The copy is conditional on
+// the source etag unless the source is SSE-C encrypted, as replicas
+// then hold different etags for the same version.
+func copyRequestHeaders(metadata map[string]string, etag string, srcOpts, dstOpts ObjectOptions) map[string]string {
+	header := make(http.Header)
+	if sse := srcOpts.ServerSideEncryption; sse != nil && sse.Type() == encrypt.SSEC {
+		encrypt.SSECopy(sse).Marshal(header)
+	} else {
+		header.Set("x-amz-copy-source-if-match", etag)
+	}
+	if dstOpts.ServerSideEncryption != nil {
+		dstOpts.ServerSideEncryption.Marshal(header)
 	}
 
-	return FromMinioClientObjectPart(pinfos[0]), nil
+	headers := make(map[string]string, len(metadata)+len(header))
+	for k, v := range metadata {
+		headers[k] = v
+	}
+	for k, v := range header {
+		headers[k] = v[0]
+	}
+	return headers
 }
 
 // CopyObjectPart creates a part in a multipart upload by copying
 // existing object or a part of it.
 func (l *radioObjects) CopyObjectPart(ctx context.Context, srcBucket, srcObject, destBucket, destObject, uploadID string,
 	partID int, startOffset, length int64, srcInfo ObjectInfo, srcOpts, dstOpts ObjectOptions) (p PartInfo, err error) {
+	if err := l.operationAllowed(operationMultipart); err != nil {
+		return p, err
+	}
+	srcObject, destObject = l.objectName(srcBucket, srcObject), l.objectName(destBucket, destObject)
+	if err := l.operationAllowed(operationCopyObject); err != nil {
+		return p, err
+	}
+
+	ctx, cancel := withDeadline(ctx, l.timeouts.Multipart)
+	defer cancel()
 
 	uploadIDLock := l.NewNSLock(ctx, destBucket, pathJoin(destObject, uploadID))
 	if err := uploadIDLock.GetLock(globalOperationTimeout); err != nil {
@@ -830,19 +2269,12 @@ func (l *radioObjects) CopyObjectPart(ctx context.Context, srcBucket, srcObject,
 	if srcOpts.CheckCopyPrecondFn != nil && srcOpts.CheckCopyPrecondFn(srcInfo) {
 		return PartInfo{}, PreConditionFailed{}
 	}
-	srcInfo.UserDefined = map[string]string{
-		"x-amz-copy-source-if-match": srcInfo.ETag,
-	}
-	header := make(http.Header)
-	if srcOpts.ServerSideEncryption != nil {
-		encrypt.SSECopy(srcOpts.ServerSideEncryption).Marshal(header)
-	}
+	srcInfo.UserDefined = copyRequestHeaders(nil, srcInfo.ETag, srcOpts, dstOpts)
 
-	if dstOpts.ServerSideEncryption != nil {
-		dstOpts.ServerSideEncryption.Marshal(header)
-	}
-	for k, v := range header {
-		srcInfo.UserDefined[k] = v[0]
+	// Copy whole objects without a range, which would be invalid for
+	// empty objects.
+	if startOffset == 0 && length == srcInfo.Size {
+		length = -1
 	}
 
 	uploadIDs, ok := l.multipartUploadIDMap[uploadID]
@@ -854,13 +2286,23 @@ func (l *radioObjects) CopyObjectPart(ctx context.Context, srcBucket, srcObject,
 		}
 	}
 
-	rs3sSrc := l.mirrorClients[srcBucket]
-	rs3sDest := l.mirrorClients[destBucket]
+	rs3sSrc := l.mirrorClients[srcBucket].forUser(ctx).forObject(srcObject)
+	rs3sDest := l.mirrorClients[destBucket].forUser(ctx).forObject(destObject)
+	if err := rs3sDest.checkFreeSpace(); err != nil {
+		return p, err
+	}
+	if err := rs3sDest.multipart.startPart(destBucket); err != nil {
+		return p, err
+	}
+	defer rs3sDest.multipart.endPart()
 
 	if len(rs3sSrc.clnts) != len(rs3sDest.clnts) {
 		return p, errors.New("unexpected")
 	}
 
+	slow := l.slowLog.start("CopyObjectPart", destBucket, destObject)
+	defer slow.done(ctx)
+
 	n := len(rs3sDest.clnts)
 	pinfos := make([]miniogo.CompletePart, n)
 
@@ -868,22 +2310,34 @@ func (l *radioObjects) CopyObjectPart(ctx context.Context, srcBucket, srcObject,
 	for index := 0; index < n; index++ {
 		index := index
 		g.Go(func() error {
+			if rs3sDest.clnts[index].ReadOnly {
+				return errReadOnlyReplica
+			}
+			if uploadIDs[index] == "" {
+				return errShadowReplica
+			}
+			defer slow.replica(rs3sDest.clnts[index])()
 			var err error
 			pinfos[index], err = rs3sSrc.clnts[index].CopyObjectPartWithContext(
 				ctx,
-				rs3sSrc.clnts[index].Bucket,
-				srcObject, rs3sDest.clnts[index].Bucket, destObject,
-				uploadIDs[index], partID, startOffset, length, srcInfo.UserDefined)
+				rs3sSrc.clnts[index].Bucket, rs3sSrc.clnts[index].objectKey(srcObject),
+				rs3sDest.clnts[index].Bucket, rs3sDest.clnts[index].objectKey(destObject),
+				uploadIDs[index], partID, startOffset, length,
+				rs3sSrc.clnts[index].copyMetadata(srcInfo.UserDefined))
 			return err
 		}, index)
 	}
 
-	if maxErr := reduceWriteQuorumErrs(ctx, g.Wait(), nil, len(rs3sDest.clnts)/2+1); maxErr != nil {
-		return p, ErrorRespToObjectError(maxErr, srcBucket, srcObject)
+	errs := g.Wait()
+	rs3sDest.shadowResults(ctx, destBucket, errs)
+	if maxErr := rs3sDest.reduceWriteErrs(ctx, errs); maxErr != nil {
+		return p, rs3sDest.toObjectError(ctx, "CopyObjectPart", nil, errs, maxErr, srcBucket, srcObject)
 	}
+	meterDegraded("CopyObjectPart", errs)
 
-	p.PartNumber = pinfos[0].PartNumber
-	p.ETag = pinfos[0].ETag
+	rindex := firstSucceeded(errs)
+	p.PartNumber = pinfos[rindex].PartNumber
+	p.ETag = pinfos[rindex].ETag
 	return p, nil
 }
 
@@ -894,6 +2348,11 @@ func (l *radioObjects) ListObjectParts(ctx context.Context, bucket string, objec
 
 // AbortMultipartUpload aborts a ongoing multipart upload
 func (l *radioObjects) AbortMultipartUpload(ctx context.Context, bucket string, object string, uploadID string) error {
+	object = l.objectName(bucket, object)
+
+	ctx, cancel := withDeadline(ctx, l.timeouts.Multipart)
+	defer cancel()
+
 	uploadIDLock := l.NewNSLock(ctx, bucket, pathJoin(object, uploadID))
 	if err := uploadIDLock.GetLock(globalOperationTimeout); err != nil {
 		return err
@@ -909,19 +2368,35 @@ func (l *radioObjects) AbortMultipartUpload(ctx context.Context, bucket string,
 		}
 	}
 
-	rs3s := l.mirrorClients[bucket]
+	rs3s := l.mirrorClients[bucket].forUser(ctx).forObject(object)
 	for index, id := range uploadIDs {
-		if err := rs3s.clnts[index].AbortMultipartUploadWithContext(
-			ctx, rs3s.clnts[index].Bucket, object, id); err != nil {
-			return ErrorRespToObjectError(err, bucket, object)
+		if id == "" {
+			continue
+		}
+		err := rs3s.clnts[index].AbortMultipartUploadWithContext(
+			ctx, rs3s.clnts[index].Bucket, rs3s.clnts[index].objectKey(object), id)
+		if rs3s.clnts[index].Shadow {
+			logShadowError(ctx, bucket, rs3s.clnts[index], err)
+			continue
+		}
+		if err != nil {
+			return ErrorRespToObjectError(rs3s.replicaErr("AbortMultipartUpload", index, err), bucket, object)
 		}
 	}
 	delete(l.multipartUploadIDMap, uploadID)
+	rs3s.multipart.endUpload(bucket)
 	return nil
 }
 
 // CompleteMultipartUpload completes ongoing multipart upload and finalizes object
 func (l *radioObjects) CompleteMultipartUpload(ctx context.Context, bucket string, object string, uploadID string, uploadedParts []CompletePart, opts ObjectOptions) (oi ObjectInfo, err error) {
+	if err := l.operationAllowed(operationMultipart); err != nil {
+		return oi, err
+	}
+	object = l.objectName(bucket, object)
+
+	ctx, cancel := withDeadline(ctx, l.timeouts.Multipart)
+	defer cancel()
 
 	// Hold read-locks to verify uploaded parts, also disallows
 	// parallel part uploads as well.
@@ -941,6 +2416,10 @@ func (l *radioObjects) CompleteMultipartUpload(ctx context.Context, bucket strin
 
 	uploadIDs, ok := l.multipartUploadIDMap[uploadID]
 	if !ok {
+		// Retries of a completed upload get the same result.
+		if oi, ok = l.completedUploads.get(uploadID, bucket, object); ok {
+			return oi, nil
+		}
 		return oi, InvalidUploadID{
 			Bucket:   bucket,
 			Object:   object,
@@ -948,17 +2427,38 @@ func (l *radioObjects) CompleteMultipartUpload(ctx context.Context, bucket strin
 		}
 	}
 
-	rs3s := l.mirrorClients[bucket]
+	rs3s := l.mirrorClients[bucket].forUser(ctx).forObject(object)
+	defer l.listCache.invalidate(bucket, object)
+	defer l.recentWrites.forget(bucket, object)
+
 	var etag string
 	for index, id := range uploadIDs {
-		etag, err = rs3s.clnts[index].CompleteMultipartUploadWithContext(
+		if id == "" {
+			continue
+		}
+		clnt := rs3s.clnts[index]
+		if clnt.Shadow {
+			_, serr := clnt.CompleteMultipartUploadWithContext(ctx, clnt.Bucket, clnt.objectKey(object),
+				id, ToMinioClientCompleteParts(uploadedParts))
+			logShadowError(ctx, bucket, clnt, serr)
+			continue
+		}
+		etag, err = clnt.CompleteMultipartUploadWithContext(
 			ctx,
-			rs3s.clnts[index].Bucket,
-			object, id, ToMinioClientCompleteParts(uploadedParts))
+			clnt.Bucket, clnt.objectKey(object),
+			id, ToMinioClientCompleteParts(uploadedParts))
 		if err != nil {
+			// Uploads completed on the preceding replicas are left to
+			// the heal of the object, log the replica failing it.
+			err = rs3s.replicaErr("CompleteMultipartUpload", index, err)
+			logger.LogIf(ctx, err)
 			return oi, ErrorRespToObjectError(err, bucket, object)
 		}
 	}
 	delete(l.multipartUploadIDMap, uploadID)
-	return ObjectInfo{Bucket: bucket, Name: object, ETag: etag}, nil
+	rs3s.multipart.endUpload(bucket)
+	l.completedUploads.add(uploadID, bucket, object, etag)
+	oi = ObjectInfo{Bucket: bucket, Name: object, ETag: etag}
+	rs3s.events.notify(event.ObjectCreatedCompleteMultipartUpload, oi)
+	return oi, nil
 }
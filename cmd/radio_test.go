@@ -0,0 +1,368 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	miniogo "github.com/minio/minio-go/v6"
+	"github.com/minio/minio-go/v6/pkg/credentials"
+	"github.com/minio/minio-go/v6/pkg/encrypt"
+	"github.com/minio/minio/pkg/hash"
+	xhttp "github.com/minio/radio/cmd/http"
+)
+
+// Tests that copying a range of an SSE-C encrypted object into a part
+// sends both the copy source range and the SSE-C copy headers.
+func TestCopyObjectPartEncryptedRange(t *testing.T) {
+	var mu sync.Mutex
+	var got http.Header
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		got = r.Header.Clone()
+		mu.Unlock()
+		w.Write([]byte(`<CopyPartResult><LastModified>2020-01-01T00:00:00.000Z</LastModified><ETag>"etag"</ETag></CopyPartResult>`))
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clnt, err := miniogo.NewWithOptions(u.Host, &miniogo.Options{
+		Creds:  credentials.NewStaticV4("accesskey", "secretkey", ""),
+		Secure: true,
+		Region: "us-east-1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clnt.SetCustomTransport(ts.Client().Transport)
+
+	l := &radioObjects{
+		nsMutex: newNSLock(false),
+		mirrorClients: map[string]mirrorConfig{
+			"bucket": {clnts: []bucketClient{{Core: &miniogo.Core{Client: clnt}, Bucket: "remote"}}},
+		},
+		multipartUploadIDMap: map[string][]string{"upload": {"remote-upload"}},
+	}
+
+	srcKey, err := encrypt.NewSSEC(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dstKey, err := encrypt.NewSSEC(append(make([]byte, 31), 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srcInfo := ObjectInfo{Bucket: "bucket", Name: "src", Size: 100, ETag: "srcetag"}
+
+	testCases := []struct {
+		startOffset, length int64
+		srcOpts, dstOpts    ObjectOptions
+		expectedHeaders     map[string]string
+	}{
+		// Range of an encrypted object into an encrypted part.
+		{10, 20, ObjectOptions{ServerSideEncryption: srcKey}, ObjectOptions{ServerSideEncryption: dstKey},
+			map[string]string{
+				"X-Amz-Copy-Source-Range":                                     "bytes=10-29",
+				"X-Amz-Copy-Source-Server-Side-Encryption-Customer-Algorithm": "AES256",
+				"X-Amz-Server-Side-Encryption-Customer-Algorithm":             "AES256",
+				"X-Amz-Copy-Source-If-Match":                                  "",
+			}},
+		// Whole encrypted object.
+		{0, 100, ObjectOptions{ServerSideEncryption: srcKey}, ObjectOptions{},
+			map[string]string{
+				"X-Amz-Copy-Source-Range":                                     "",
+				"X-Amz-Copy-Source-Server-Side-Encryption-Customer-Algorithm": "AES256",
+				"X-Amz-Server-Side-Encryption-Customer-Algorithm":             "",
+			}},
+		// Range of a plain object.
+		{50, 50, ObjectOptions{}, ObjectOptions{},
+			map[string]string{
+				"X-Amz-Copy-Source-Range":                                     "bytes=50-99",
+				"X-Amz-Copy-Source-Server-Side-Encryption-Customer-Algorithm": "",
+				"X-Amz-Copy-Source-If-Match":                                  "srcetag",
+			}},
+	}
+	for i, testCase := range testCases {
+		_, err = l.CopyObjectPart(context.Background(), "bucket", "src", "bucket", "dst", "upload", 1,
+			testCase.startOffset, testCase.length, srcInfo, testCase.srcOpts, testCase.dstOpts)
+		if err != nil {
+			t.Fatalf("Test %d: unexpected error: %v", i+1, err)
+		}
+		mu.Lock()
+		for k, v := range testCase.expectedHeaders {
+			if got.Get(k) != v {
+				t.Errorf("Test %d: expected %s %q, got %q", i+1, k, v, got.Get(k))
+			}
+		}
+		mu.Unlock()
+	}
+}
+
+// Tests that unreachable remotes fail startup unless degraded starts
+// are allowed, while remotes rejecting the credentials always fail it.
+func TestNewBucketClientsDegraded(t *testing.T) {
+	remotes, clnts, shutdown := newTestRemotes(t, 1)
+	defer shutdown()
+	offline := httptest.NewServer(http.NotFoundHandler())
+	offline.Close()
+
+	cfgs := []remoteConfig{
+		{Bucket: testRemoteBucket, Endpoint: clnts[0].EndpointURL().String(), AccessKey: "accesskey", SecretKey: "secretkey"},
+		{Bucket: testRemoteBucket, Endpoint: offline.URL, AccessKey: "accesskey", SecretKey: "secretkey"},
+	}
+	if _, err := newBucketClients(cfgs, false); err == nil {
+		t.Error("Expected an unreachable remote to fail startup")
+	}
+	degraded, err := newBucketClients(cfgs, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(degraded) != 2 {
+		t.Errorf("Expected 2 clients, got %d", len(degraded))
+	}
+
+	remotes[0].setHook(func(w http.ResponseWriter, r *http.Request) bool {
+		writeTestRemoteError(w, r, http.StatusForbidden, "AccessDenied")
+		return true
+	})
+	if _, err = newBucketClients(cfgs[:1], true); err == nil {
+		t.Error("Expected a remote rejecting the credentials to fail startup")
+	}
+}
+
+// Tests that PutObject evaluates If-Match and If-None-Match against the
+// object it overwrites before writing any replica.
+func TestPutObjectPreconditions(t *testing.T) {
+	// The ETag of the existing object.
+	const etag = `"8d777f385d3dfec8815d20f7496026dc"`
+	testCases := []struct {
+		exists      bool
+		header      string
+		value       string
+		shouldWrite bool
+	}{
+		{false, xhttp.IfNoneMatch, "*", true},
+		{true, xhttp.IfNoneMatch, "*", false},
+		{true, xhttp.IfNoneMatch, `"other"`, true},
+		{true, xhttp.IfNoneMatch, `"other", ` + etag, false},
+		{true, xhttp.IfMatch, etag, true},
+		{true, xhttp.IfMatch, `"other"`, false},
+		{false, xhttp.IfMatch, "*", false},
+		{true, xhttp.IfMatch, "*", true},
+	}
+
+	for i, testCase := range testCases {
+		replica := testReplica{}
+		if testCase.exists {
+			replica = testReplica{"tag", time.Now()}
+		}
+		remotes, clnts, shutdown := newTestReplicas(t, replica)
+		l := &radioObjects{
+			nsMutex:       newNSLock(false),
+			mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+		}
+
+		data := []byte("data")
+		reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), "", "", int64(len(data)), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h := http.Header{}
+		h.Set(testCase.header, testCase.value)
+		_, err = l.PutObject(context.Background(), "bucket", "object", NewPutObjReader(reader, nil, nil),
+			ObjectOptions{CheckPutPrecondFn: putPreconditionFn(h)})
+		shutdown()
+
+		if testCase.shouldWrite && err != nil {
+			t.Errorf("Test %d: Unexpected error %v", i+1, err)
+		}
+		if !testCase.shouldWrite && !isErrPreconditionFailed(err) {
+			t.Errorf("Test %d: Expected PreConditionFailed, got %v", i+1, err)
+		}
+		if wrote := len(remotes[0].writtenTags()) > 0; wrote != testCase.shouldWrite {
+			t.Errorf("Test %d: Expected written %v, got %v", i+1, testCase.shouldWrite, wrote)
+		}
+	}
+}
+
+// rangeTestReads records the ranges read from the replicas of an
+// object, cutting the first read after failAfter bytes, or stalling it
+// there if stall is set.
+type rangeTestReads struct {
+	mu        sync.Mutex
+	failAfter int
+	stall     bool
+	ranges    []string
+}
+
+// hook returns a test remote hook reading data as the object tagged
+// radioTag, last modified at modTime, through reads.
+func (reads *rangeTestReads) hook(data []byte, modTime time.Time) func(w http.ResponseWriter, r *http.Request) bool {
+	return func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method != http.MethodGet {
+			return false
+		}
+		reads.mu.Lock()
+		reads.ranges = append(reads.ranges, r.Header.Get("Range"))
+		failAfter, stall := reads.failAfter, reads.stall
+		reads.failAfter, reads.stall = 0, false
+		reads.mu.Unlock()
+
+		switch {
+		case failAfter == 0 && !stall:
+			return false
+		case failAfter == 0:
+			// Stall before responding, until the read is given up.
+			<-r.Context().Done()
+			return true
+		}
+		var start, end int
+		fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end)
+		w.Header().Set("ETag", testRemotePartETag(data))
+		w.Header().Set("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+		w.Header().Set(globalRadioTagKey, "tag")
+		w.Header().Set("Content-Length", strconv.Itoa(end-start+1))
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+		w.WriteHeader(http.StatusPartialContent)
+		// Cut the body mid-stream.
+		w.Write(data[start : start+failAfter])
+		w.(http.Flusher).Flush()
+		if stall {
+			<-r.Context().Done()
+			return true
+		}
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// Tests that a read failing or stalling mid-stream resumes the remaining
+// range from the next replica.
+func TestGetObjectFailover(t *testing.T) {
+	data := []byte("0123456789abcdef")
+	testCases := []struct {
+		rs             *HTTPRangeSpec
+		failAfter      int
+		stall          bool
+		expectedRanges []string
+		expectedData   string
+	}{
+		// Whole object, failing after 3 bytes.
+		{nil, 3, false, []string{"bytes=0-15", "bytes=3-15"}, string(data)},
+		// Range, failing after 4 bytes.
+		{&HTTPRangeSpec{Start: 2, End: 9}, 4, false, []string{"bytes=2-9", "bytes=6-9"}, "23456789"},
+		// No failure.
+		{&HTTPRangeSpec{Start: 10, End: 15}, 0, false, []string{"bytes=10-15"}, "abcdef"},
+		// Stalled before responding.
+		{nil, 0, true, []string{"bytes=0-15", "bytes=0-15"}, string(data)},
+		// Range, stalled after 5 bytes.
+		{&HTTPRangeSpec{Start: 1, End: 12}, 5, true, []string{"bytes=1-12", "bytes=6-12"}, "123456789abc"},
+	}
+	for i, testCase := range testCases {
+		reads := &rangeTestReads{failAfter: testCase.failAfter, stall: testCase.stall}
+		modTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		remotes, clnts, shutdown := newTestRemotes(t, 2)
+		defer shutdown()
+		for _, remote := range remotes {
+			remote.putTaggedObject("object", string(data), "tag", modTime)
+			remote.setHook(reads.hook(data, modTime))
+		}
+		l := &radioObjects{
+			nsMutex:       newNSLock(false),
+			mirrorClients: map[string]mirrorConfig{"bucket": {clnts: clnts}},
+			timeouts:      timeoutsConfig{ReadStall: 100 * time.Millisecond},
+		}
+
+		gr, err := l.GetObjectNInfo(context.Background(), "bucket", "object", testCase.rs, nil, NoLock, ObjectOptions{})
+		if err != nil {
+			t.Fatalf("Test %d: unexpected error: %v", i+1, err)
+		}
+		got, err := ioutil.ReadAll(gr)
+		gr.Close()
+		if err != nil {
+			t.Fatalf("Test %d: unexpected error: %v", i+1, err)
+		}
+		if string(got) != testCase.expectedData {
+			t.Errorf("Test %d: expected %q, got %q", i+1, testCase.expectedData, got)
+		}
+		reads.mu.Lock()
+		if !reflect.DeepEqual(reads.ranges, testCase.expectedRanges) {
+			t.Errorf("Test %d: expected ranges %v, got %v", i+1, testCase.expectedRanges, reads.ranges)
+		}
+		reads.mu.Unlock()
+	}
+}
+
+// Tests that objects are streamed between buckets with different
+// replica counts, without server side copies.
+func TestCopyObjectByStream(t *testing.T) {
+	testCases := []struct {
+		srcReplicas, dstReplicas int
+		withReader               bool
+	}{
+		{1, 2, true},
+		{3, 2, true},
+		// Nothing to stream from.
+		{1, 2, false},
+	}
+
+	for i, testCase := range testCases {
+		remotes, clnts, shutdown := newTestRemotes(t, testCase.srcReplicas+testCase.dstReplicas)
+		defer shutdown()
+		for _, remote := range remotes {
+			remote.putTaggedObject("src", "data", "v1", time.Now())
+		}
+		srcRemotes, dstRemotes := remotes[:testCase.srcReplicas], remotes[testCase.srcReplicas:]
+		srcClnts, dstClnts := clnts[:testCase.srcReplicas], clnts[testCase.srcReplicas:]
+		l := &radioObjects{
+			nsMutex: newNSLock(false),
+			mirrorClients: map[string]mirrorConfig{
+				"src": {clnts: srcClnts},
+				"dst": {clnts: dstClnts},
+			},
+		}
+
+		data := []byte("data")
+		srcInfo := ObjectInfo{Bucket: "src", Name: "src", Size: int64(len(data)), UserDefined: map[string]string{}}
+		if testCase.withReader {
+			reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), "", "", int64(len(data)), false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			srcInfo.PutObjReader = NewPutObjReader(reader, nil, nil)
+		}
+		_, err := l.CopyObject(context.Background(), "src", "src", "dst", "dst", srcInfo, ObjectOptions{}, ObjectOptions{})
+		if !testCase.withReader {
+			if _, ok := err.(NotImplemented); !ok {
+				t.Errorf("Test %d: expected NotImplemented, got %v", i+1, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Test %d: unexpected error: %v", i+1, err)
+		}
+		for index, remote := range dstRemotes {
+			if data, _, ok := remote.object("dst"); !ok || data != "data" {
+				t.Errorf("Test %d: expected the object streamed onto replica %d, got %q", i+1, index, data)
+			}
+		}
+		for index, remote := range srcRemotes {
+			for _, method := range []string{http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPost, http.MethodDelete} {
+				if n := remote.requests(method); n != 0 {
+					t.Errorf("Test %d: expected no requests to source replica %d, got %d %s", i+1, index, n, method)
+				}
+			}
+		}
+	}
+}
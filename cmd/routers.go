@@ -8,6 +8,8 @@ var globalHandlers = []HandlerFunc{
 	addSecurityHeaders,
 	// Validate all the incoming requests.
 	setRequestValidityHandler,
+	// Rejects client operations beyond the configured limits.
+	setAdmissionHandler,
 	// Network statistics
 	setHTTPStatsHandler,
 	// Limits all requests size to a maximum fixed limit
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"strconv"
+)
+
+// Maximum payload carried by a single Records message, chosen for
+// compatibility with the AWS Java SDK event stream decoder.
+const maxSelectRecordsPayload = (128 << 10) - 512
+
+// writeEventHeader encodes a string valued event stream header.
+func writeEventHeader(buf *bytes.Buffer, name, value string) {
+	buf.WriteByte(byte(len(name)))
+	buf.WriteString(name)
+	buf.WriteByte(7) // string value type
+	binary.Write(buf, binary.BigEndian, uint16(len(value)))
+	buf.WriteString(value)
+}
+
+// genEventMessage frames headers and payload as an event stream message
+// as described in
+// https://docs.aws.amazon.com/AmazonS3/latest/API/RESTSelectObjectAppendix.html
+func genEventMessage(headers [][2]string, payload []byte) []byte {
+	hbuf := new(bytes.Buffer)
+	for _, h := range headers {
+		writeEventHeader(hbuf, h[0], h[1])
+	}
+
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.BigEndian, uint32(4+4+4+hbuf.Len()+len(payload)+4))
+	binary.Write(buf, binary.BigEndian, uint32(hbuf.Len()))
+	binary.Write(buf, binary.BigEndian, crc32.ChecksumIEEE(buf.Bytes()))
+	buf.Write(hbuf.Bytes())
+	buf.Write(payload)
+	binary.Write(buf, binary.BigEndian, crc32.ChecksumIEEE(buf.Bytes()))
+	return buf.Bytes()
+}
+
+func newSelectRecordsMessage(payload []byte) []byte {
+	return genEventMessage([][2]string{
+		{":message-type", "event"},
+		{":content-type", "application/octet-stream"},
+		{":event-type", "Records"},
+	}, payload)
+}
+
+func newSelectStatsMessage(bytesScanned, bytesProcessed, bytesReturned int64) []byte {
+	payload := []byte(`<?xml version="1.0" encoding="UTF-8"?><Stats><BytesScanned>` +
+		strconv.FormatInt(bytesScanned, 10) + `</BytesScanned><BytesProcessed>` +
+		strconv.FormatInt(bytesProcessed, 10) + `</BytesProcessed><BytesReturned>` +
+		strconv.FormatInt(bytesReturned, 10) + `</BytesReturned></Stats>`)
+	return genEventMessage([][2]string{
+		{":message-type", "event"},
+		{":content-type", "text/xml"},
+		{":event-type", "Stats"},
+	}, payload)
+}
+
+func newSelectEndMessage() []byte {
+	return genEventMessage([][2]string{
+		{":message-type", "event"},
+		{":event-type", "End"},
+	}, nil)
+}
+
+func newSelectErrorMessage(errorCode, errorMessage string) []byte {
+	return genEventMessage([][2]string{
+		{":message-type", "error"},
+		{":error-message", errorMessage},
+		{":error-code", errorCode},
+	}, nil)
+}
+
+// copySelectRecords re-frames the decoded records of a select response
+// into Records messages on w, returns the number of payload bytes written.
+func copySelectRecords(w io.Writer, r io.Reader, flush func()) (int64, error) {
+	var n int64
+	buf := make([]byte, maxSelectRecordsPayload)
+	for {
+		m, err := r.Read(buf)
+		if m > 0 {
+			if _, werr := w.Write(newSelectRecordsMessage(buf[:m])); werr != nil {
+				return n, werr
+			}
+			flush()
+			n += int64(m)
+		}
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+	}
+}
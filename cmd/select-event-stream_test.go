@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	miniogo "github.com/minio/minio-go/v6"
+)
+
+// Tests that re-framed select responses decode as Records, Stats and
+// End messages, with valid prelude and message CRCs.
+func TestSelectEventStream(t *testing.T) {
+	// Records larger than a message are split over several.
+	records := bytes.Repeat([]byte("a,b,c\n"), maxSelectRecordsPayload/3)
+	stream := new(bytes.Buffer)
+	flushes := 0
+	n, err := copySelectRecords(stream, bytes.NewReader(records), func() { flushes++ })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(records)) || flushes != 2 {
+		t.Errorf("Expected %d bytes in 2 messages, got %d bytes in %d", len(records), n, flushes)
+	}
+	stream.Write(newSelectStatsMessage(100, 90, n))
+	stream.Write(newSelectEndMessage())
+
+	decode := func(data []byte) ([]byte, *miniogo.StatsMessage, error) {
+		results, err := miniogo.NewSelectResults(&http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(bytes.NewReader(data)),
+		}, "bucket")
+		if err != nil {
+			return nil, nil, err
+		}
+		defer results.Close()
+		got, err := ioutil.ReadAll(results)
+		return got, results.Stats(), err
+	}
+	got, stats, err := decode(stream.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, records) {
+		t.Errorf("Expected the records decoded, got %d bytes", len(got))
+	}
+	if stats == nil || stats.BytesScanned != 100 || stats.BytesProcessed != 90 || stats.BytesReturned != n {
+		t.Errorf("Expected the stats decoded, got %+v", stats)
+	}
+
+	// Corrupting the prelude or the headers of a message fails its CRCs.
+	for _, offset := range []int{2, 20} {
+		corrupt := append([]byte(nil), stream.Bytes()...)
+		corrupt[offset] ^= 0xff
+		if _, _, err = decode(corrupt); err == nil {
+			t.Errorf("Offset %d: expected a CRC mismatch", offset)
+		}
+	}
+}
@@ -29,6 +29,12 @@ func handleSignals() {
 			logger.LogIf(context.Background(), err)
 		}
 
+		// Make heal entries durable before exiting.
+		if l, ok := newObjectLayerFn().(*radioObjects); ok && l.healSys != nil {
+			_, oerr = l.healSys.flush()
+			logger.LogIf(context.Background(), oerr)
+		}
+
 		// send signal to various go-routines that they need to quit.
 		close(GlobalServiceDoneCh)
 
@@ -25,6 +25,14 @@ var errOperationTimedOut = errors.New("Operation timed out")
 // errInvalidRange - returned when given range value is not valid.
 var errInvalidRange = errors.New("Invalid range")
 
+// errMultipleRanges - returned for range values requesting more than
+// one range, which are not supported.
+var errMultipleRanges = errors.New("Multiple ranges are not supported")
+
+// errMissingContentMD5 - returned for uploads without a Content-MD5 to
+// buckets requiring one.
+var errMissingContentMD5 = errors.New("Missing required header for this request: Content-Md5")
+
 // errInvalidRangeSource - returned when given range value exceeds
 // the source object size.
 var errInvalidRangeSource = errors.New("Range specified exceeds source object size")
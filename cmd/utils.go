@@ -178,11 +178,15 @@ func newCustomDialContext(dialTimeout, dialKeepAlive time.Duration) dialContext
 }
 
 func newCustomHTTPTransport(tlsConfig *tls.Config, dialTimeout, dialKeepAlive time.Duration) func() *http.Transport {
+	dial := newCustomDialContext(dialTimeout, dialKeepAlive)
+	if globalDNSCache != nil {
+		dial = globalDNSCache.dialContext(dial)
+	}
 	// For more details about various values used here refer
 	// https://golang.org/pkg/net/http/#Transport documentation
 	tr := &http.Transport{
 		Proxy:                 http.ProxyFromEnvironment,
-		DialContext:           newCustomDialContext(dialTimeout, dialKeepAlive),
+		DialContext:           dial,
 		MaxIdleConnsPerHost:   256,
 		IdleConnTimeout:       60 * time.Second,
 		TLSHandshakeTimeout:   30 * time.Second,
@@ -203,9 +207,20 @@ func newCustomHTTPTransport(tlsConfig *tls.Config, dialTimeout, dialKeepAlive ti
 // This sets the value for MaxIdleConnsPerHost from 2 (go default)
 // to 256.
 func NewCustomHTTPTransport() *http.Transport {
-	return newCustomHTTPTransport(&tls.Config{
+	tlsConfig := &tls.Config{
 		RootCAs: globalRootCAs,
-	}, defaultDialTimeout, defaultDialKeepAlive)()
+	}
+	globalTLSPolicy.apply(tlsConfig)
+	return newCustomHTTPTransport(tlsConfig, defaultDialTimeout, defaultDialKeepAlive)()
+}
+
+// NewCustomHTTP2Transport returns NewCustomHTTPTransport negotiating
+// HTTP/2 with TLS backends supporting it, falling back to HTTP/1.1.
+// Plain HTTP backends are always reached over HTTP/1.1.
+func NewCustomHTTP2Transport() *http.Transport {
+	tr := NewCustomHTTPTransport()
+	tr.ForceAttemptHTTP2 = true
+	return tr
 }
 
 // Load the json (typically from disk file).
@@ -251,7 +266,7 @@ func newContext(r *http.Request, w http.ResponseWriter, api string) context.Cont
 		BucketName:   bucket,
 		ObjectName:   object,
 	}
-	return logger.SetReqInfo(r.Context(), reqInfo)
+	return withClientAccessKey(withRetryAfter(logger.SetReqInfo(r.Context(), reqInfo)), reqAccessKey(r))
 }
 
 // Used for registering with rest handlers (have a look at registerStorageRESTHandlers for usage example)
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// Benchmarks concurrent small requests, as issued by stats and part
+// uploads, against a TLS backend supporting HTTP/2. The conns/op metric
+// reports the connections opened to the backend per iteration.
+func BenchmarkCustomHTTPTransport(b *testing.B) {
+	b.Run("HTTP1", func(b *testing.B) { benchmarkTransportConns(b, false) })
+	b.Run("HTTP2", func(b *testing.B) { benchmarkTransportConns(b, true) })
+}
+
+func benchmarkTransportConns(b *testing.B, http2 bool) {
+	var conns int64
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"d41d8cd98f00b204e9800998ecf8427e"`)
+	}))
+	ts.EnableHTTP2 = true
+	ts.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt64(&conns, 1)
+		}
+	}
+	ts.StartTLS()
+	defer ts.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(ts.Certificate())
+	tr := newCustomHTTPTransport(&tls.Config{RootCAs: rootCAs}, defaultDialTimeout, defaultDialKeepAlive)()
+	tr.ForceAttemptHTTP2 = http2
+	defer tr.CloseIdleConnections()
+	clnt := &http.Client{Transport: tr}
+
+	// Warm up the connection pool the way a long running server would
+	// have, so that concurrent requests find an established connection.
+	resp, err := clnt.Head(ts.URL)
+	if err != nil {
+		b.Fatal(err)
+	}
+	resp.Body.Close()
+
+	b.SetParallelism(32)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			resp, err := clnt.Head(ts.URL + "/bucket/object")
+			if err != nil {
+				b.Error(err)
+				return
+			}
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+	})
+	b.StopTimer()
+	b.ReportMetric(float64(atomic.LoadInt64(&conns))/float64(b.N), "conns/op")
+}
@@ -20,6 +20,8 @@ import (
 	"errors"
 	"io"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	humanize "github.com/dustin/go-humanize"
 	"github.com/minio/minio/pkg/sync/errgroup"
@@ -35,8 +37,38 @@ var streamPool = sync.Pool{
 	},
 }
 
+// ErrStalled is returned by the readers of consumers abandoned for
+// reading none of the stream within the stall timeout.
+var ErrStalled = errors.New("stream consumer stalled")
+
+// progressReader records the time of the last read of a consumer.
+type progressReader struct {
+	// lastRead is the time of the last read in Unix nanoseconds,
+	// first for 64-bit alignment.
+	lastRead int64
+	*io.PipeReader
+	stalled chan struct{}
+}
+
+// Stalled returns a channel closed once the consumer is abandoned, for
+// consumers blocked elsewhere than reading to be canceled.
+func (r *progressReader) Stalled() <-chan struct{} {
+	return r.stalled
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.PipeReader.Read(p)
+	atomic.StoreInt64(&r.lastRead, time.Now().UnixNano())
+	return n, err
+}
+
 type multiWriter struct {
 	writers []*io.PipeWriter
+	readers []*progressReader
+	// Consumers reading nothing for stallTimeout are abandoned, never
+	// if not positive.
+	stallTimeout time.Duration
+	abandoned    []bool
 }
 
 func (t *multiWriter) CloseWithError(err error) error {
@@ -52,19 +84,16 @@ func (t *multiWriter) Close() error {
 	}
 	return nil
 }
+
 func (t *multiWriter) Write(p []byte) (int, error) {
 	g := errgroup.WithNErrs(len(t.writers))
 	for index := range t.writers {
+		if t.abandoned[index] {
+			continue
+		}
 		index := index
 		g.Go(func() error {
-			n, err := t.writers[index].Write(p)
-			if err != nil {
-				return err
-			}
-			if n != len(p) {
-				return io.ErrShortWrite
-			}
-			return nil
+			return t.write(index, p)
 		}, index)
 	}
 	for _, err := range g.Wait() {
@@ -73,25 +102,96 @@ func (t *multiWriter) Write(p []byte) (int, error) {
 			return 0, err
 		}
 	}
-	return len(p), nil
+	for _, abandoned := range t.abandoned {
+		if !abandoned {
+			return len(p), nil
+		}
+	}
+	// No consumer is left to read the stream.
+	return 0, ErrStalled
+}
+
+// write writes p to the consumer index, abandoning the consumer if it
+// reads none of p for stallTimeout.
+func (t *multiWriter) write(index int, p []byte) error {
+	if t.stallTimeout <= 0 {
+		return writeFull(t.writers[index], p)
+	}
+	start := time.Now().UnixNano()
+	done := make(chan error, 1)
+	go func() {
+		done <- writeFull(t.writers[index], p)
+	}()
+	timer := time.NewTimer(t.stallTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case err := <-done:
+			return err
+		case <-timer.C:
+			lastRead := atomic.LoadInt64(&t.readers[index].lastRead)
+			if lastRead < start {
+				lastRead = start
+			}
+			idle := time.Since(time.Unix(0, lastRead))
+			if idle < t.stallTimeout {
+				timer.Reset(t.stallTimeout - idle)
+				continue
+			}
+			// Fail the reads of the consumer, unblocking the write.
+			t.writers[index].CloseWithError(ErrStalled)
+			close(t.readers[index].stalled)
+			<-done
+			t.abandoned[index] = true
+			return nil
+		}
+	}
+}
+
+// writeFull writes all of p to w.
+func writeFull(w *io.PipeWriter, p []byte) error {
+	n, err := w.Write(p)
+	if err != nil {
+		return err
+	}
+	if n != len(p) {
+		return io.ErrShortWrite
+	}
+	return nil
 }
 
 // New initializes and returns a list of readers
 // reach of these readers have a duplicated stream
 // of the input reader 'r', as specified by dupN.
 func New(r io.Reader, dupN int) ([]io.Reader, error) {
+	return NewWithWatchdog(r, dupN, 0)
+}
+
+// NewWithWatchdog is like New, except that the consumer of a reader
+// which reads none of the stream for stallTimeout while data is pending
+// is abandoned, its reader then returns ErrStalled and closes the
+// channel of its Stalled method, while the other readers keep receiving
+// the stream. Consumers are never abandoned if
+// stallTimeout is not positive.
+func NewWithWatchdog(r io.Reader, dupN int, stallTimeout time.Duration) ([]io.Reader, error) {
 	if dupN < 0 {
 		return nil, errors.New("invalid argument")
 	}
 	if dupN == 0 {
 		return []io.Reader{r}, nil
 	}
+	w := &multiWriter{
+		writers:      make([]*io.PipeWriter, dupN),
+		readers:      make([]*progressReader, dupN),
+		stallTimeout: stallTimeout,
+		abandoned:    make([]bool, dupN),
+	}
 	readers := make([]io.Reader, dupN)
-	writers := make([]*io.PipeWriter, dupN)
 	for i := range readers {
-		readers[i], writers[i] = io.Pipe()
+		pr, pw := io.Pipe()
+		w.readers[i], w.writers[i] = &progressReader{PipeReader: pr, stalled: make(chan struct{})}, pw
+		readers[i] = w.readers[i]
 	}
-	w := &multiWriter{writers: writers}
 	bufp := streamPool.Get().(*[]byte)
 	go func() {
 		defer streamPool.Put(bufp)
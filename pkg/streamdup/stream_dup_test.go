@@ -22,6 +22,7 @@ import (
 	"io/ioutil"
 	"sync"
 	"testing"
+	"time"
 
 	humanize "github.com/dustin/go-humanize"
 )
@@ -72,3 +73,34 @@ func TestNew(t *testing.T) {
 		})
 	}
 }
+
+func TestNewWithWatchdog(t *testing.T) {
+	data := bytes.Repeat([]byte("10101010"), humanize.MiByte)
+	readers, err := NewWithWatchdog(bytes.NewReader(data), 3, 100*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The last consumer never reads, the others read the whole stream.
+	var wg sync.WaitGroup
+	for _, rd := range readers[:2] {
+		wg.Add(1)
+		go func(rd io.Reader) {
+			defer wg.Done()
+			n, err := io.Copy(ioutil.Discard, rd)
+			if err != nil || n != int64(len(data)) {
+				t.Errorf("Expected data %d, got %d (%v)", len(data), n, err)
+			}
+		}(rd)
+	}
+	wg.Wait()
+
+	if _, err = readers[2].Read(make([]byte, 1)); err != ErrStalled {
+		t.Errorf("Expected the stalled consumer abandoned, got %v", err)
+	}
+	select {
+	case <-readers[2].(interface{ Stalled() <-chan struct{} }).Stalled():
+	default:
+		t.Errorf("Expected the stalled consumer notified")
+	}
+}